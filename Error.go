@@ -19,3 +19,18 @@ type WSStopError struct {
 func (e *WSStopError) Error() string {
 	return fmt.Sprintf("websocket stop error: %s", e.Msg)
 }
+
+type BookChecksumError struct {
+	Exchange  string
+	ProductId string
+	Expect    int64 // the checksum from exchange
+	Actual    int64 // the checksum of local order book
+	FailCount int64 // the total checksum fail times of the product
+}
+
+func (e *BookChecksumError) Error() string {
+	return fmt.Sprintf(
+		"order book checksum error: %s %s expect %d actual %d, fail %d times",
+		e.Exchange, e.ProductId, e.Expect, e.Actual, e.FailCount,
+	)
+}
@@ -36,3 +36,8 @@ type FutureWebsocketAPI interface {
 
 	Restart()
 }
+
+// FutureKlineBeforeAPI return the latest klines before the end (excluded), the kline backfill page backward by it.
+type FutureKlineBeforeAPI interface {
+	GetKlineRecordsBefore(contractType string, pair Pair, period, size, end int) ([]*FutureKline, []byte, error)
+}
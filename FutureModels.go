@@ -131,7 +131,7 @@ type FutureCandle struct {
  **/
 
 type FutureOrder struct {
-	// cid is important, when the order api return wrong, you can find it in unfinished api
+	// cid is important, when the order api return wrong, you can find it in unfinished api, the RetryFuture do it automatically
 	Cid            string
 	OrderId        string
 	Price          float64
@@ -0,0 +1,83 @@
+package goghostex
+
+import (
+	"fmt"
+)
+
+// OrderBookFeed is the local order book of the exchange websocket. The adapter in every exchange
+// normalize the snapshot into the Depth and the update message into the BookUpdate.
+type OrderBookFeed interface {
+	GetExchangeName() string
+
+	// GetMarket return the TRADE_TYPE_SPOT or TRADE_TYPE_SWAP
+	GetMarket() string
+
+	Start() error
+
+	Stop()
+
+	Subscribe(pair Pair)
+
+	Unsubscribe(pair Pair)
+
+	Snapshot(pair Pair) (*Depth, error)
+
+	// SetUpdateHandler the handler is called after the book of the pair updated, the nil handler means drop it.
+	SetUpdateHandler(handler func(*BookUpdate))
+}
+
+// BookKey is the key of the book in the OrderBookManager.
+type BookKey struct {
+	Exchange string
+	Market   string
+	Pair     Pair
+}
+
+func (key BookKey) String() string {
+	return fmt.Sprintf("%s:%s:%s", key.Exchange, key.Market, key.Pair.ToSymbol("_", false))
+}
+
+// BookUpdate is the notification of the book updated, the snapshot is got by the key.
+type BookUpdate struct {
+	BookKey
+	ProductId string // the instrument id of the exchange, eg: BTC-USDT-SWAP btcusdt PF_XBTUSD
+	Timestamp int64
+}
+
+const (
+	BOOK_SIDE_BID = "bid"
+	BOOK_SIDE_ASK = "ask"
+)
+
+// BookLevelEvent is the level changes of one book message. The consumer can maintain its own view by the
+// changes, and should clear the view when the Snapshot is true, the changes of it are the whole book.
+type BookLevelEvent struct {
+	Exchange     string
+	ProductId    string
+	Snapshot     bool
+	Sequence     int64
+	PrevSequence int64
+	Timestamp    int64
+	Changes      []BookLevelChange
+}
+
+// BookLevelChange the removed level has the zero NewAmount.
+type BookLevelChange struct {
+	Side      string // BOOK_SIDE_BID or BOOK_SIDE_ASK
+	Price     float64
+	OldAmount float64
+	NewAmount float64
+}
+
+// Add the change of the level, the no change level is ignored. It's safe to add on the nil event.
+func (event *BookLevelEvent) Add(side string, price, oldAmount, newAmount float64) {
+	if event == nil || oldAmount == newAmount {
+		return
+	}
+	event.Changes = append(event.Changes, BookLevelChange{
+		Side:      side,
+		Price:     price,
+		OldAmount: oldAmount,
+		NewAmount: newAmount,
+	})
+}
@@ -0,0 +1,433 @@
+package goghostex
+
+import (
+	"errors"
+)
+
+// CidDedupeAPI the rest api reject the duplicated cid for good, even the order with the cid is finished.
+// The retry wrappers place the order again after the ambiguous error only when the api dedupe the cid.
+type CidDedupeAPI interface {
+	DedupeCid() bool
+}
+
+func dedupeCid(api interface{}) bool {
+	var dedupe, ok = api.(CidDedupeAPI)
+	return ok && dedupe.DedupeCid()
+}
+
+// RetrySpot retry the read-only calls, and place the order at most once, the ambiguous order is looked up by the cid.
+type RetrySpot struct {
+	SpotRestAPI
+	option *RetryOption
+}
+
+func NewRetrySpot(api SpotRestAPI, option *RetryOption) *RetrySpot {
+	return &RetrySpot{SpotRestAPI: api, option: option.init()}
+}
+
+func (this *RetrySpot) GetTicker(pair Pair) (ticker *Ticker, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		ticker, resp, err = this.SpotRestAPI.GetTicker(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetrySpot) GetDepth(pair Pair, size int) (depth *Depth, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		depth, resp, err = this.SpotRestAPI.GetDepth(pair, size)
+		return err
+	})
+	return
+}
+
+func (this *RetrySpot) GetKlineRecords(pair Pair, period, size, since int) (klines []*Kline, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		klines, resp, err = this.SpotRestAPI.GetKlineRecords(pair, period, size, since)
+		return err
+	})
+	return
+}
+
+func (this *RetrySpot) GetTrades(pair Pair, since int64) (trades []*Trade, err error) {
+	err = this.option.retry(func() error {
+		trades, err = this.SpotRestAPI.GetTrades(pair, since)
+		return err
+	})
+	return
+}
+
+func (this *RetrySpot) GetAccount() (account *Account, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		account, resp, err = this.SpotRestAPI.GetAccount()
+		return err
+	})
+	return
+}
+
+func (this *RetrySpot) GetOrder(order *Order) (resp []byte, err error) {
+	err = this.option.retry(func() error {
+		resp, err = this.SpotRestAPI.GetOrder(order)
+		return err
+	})
+	return
+}
+
+func (this *RetrySpot) GetOrders(pair Pair) (orders []*Order, err error) {
+	err = this.option.retry(func() error {
+		orders, err = this.SpotRestAPI.GetOrders(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetrySpot) GetUnFinishOrders(pair Pair) (orders []*Order, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		orders, resp, err = this.SpotRestAPI.GetUnFinishOrders(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetrySpot) GetOHLCs(symbol string, period, size, since int) (ohlcs []*OHLC, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		ohlcs, resp, err = this.SpotRestAPI.GetOHLCs(symbol, period, size, since)
+		return err
+	})
+	return
+}
+
+func (this *RetrySpot) PlaceOrder(order *Order) (resp []byte, err error) {
+	err = this.option.placeOrder(order.Cid, dedupeCid(this.SpotRestAPI), func() error {
+		resp, err = this.SpotRestAPI.PlaceOrder(order)
+		return err
+	}, func() (bool, error) {
+		return this.lookup(order)
+	})
+	return
+}
+
+// lookup the order by the cid in the order api, or in the unfinished orders and the dealed orders when the order api fails.
+func (this *RetrySpot) lookup(order *Order) (bool, error) {
+	var query = *order
+	query.OrderId = ""
+	var _, err = this.SpotRestAPI.GetOrder(&query)
+	if err == nil && query.OrderId != "" && query.Cid == order.Cid {
+		*order = query
+		return true, nil
+	}
+	if errors.Is(err, ErrOrderNotFound) {
+		return false, nil
+	}
+
+	var unfinished, _, unfinishedErr = this.SpotRestAPI.GetUnFinishOrders(order.Pair)
+	var dealed, dealedErr = this.SpotRestAPI.GetOrders(order.Pair)
+	for _, o := range append(unfinished, dealed...) {
+		if o.Cid == order.Cid {
+			*order = *o
+			return true, nil
+		}
+	}
+	if unfinishedErr != nil {
+		return false, unfinishedErr
+	}
+	return false, dealedErr
+}
+
+// RetrySwap retry the read-only calls, and place the order at most once, the ambiguous order is looked up by the cid.
+type RetrySwap struct {
+	SwapRestAPI
+	option *RetryOption
+}
+
+func NewRetrySwap(api SwapRestAPI, option *RetryOption) *RetrySwap {
+	return &RetrySwap{SwapRestAPI: api, option: option.init()}
+}
+
+func (this *RetrySwap) GetTicker(pair Pair) (ticker *SwapTicker, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		ticker, resp, err = this.SwapRestAPI.GetTicker(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetDepth(pair Pair, size int) (depth *SwapDepth, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		depth, resp, err = this.SwapRestAPI.GetDepth(pair, size)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetLimit(pair Pair) (high float64, low float64, err error) {
+	err = this.option.retry(func() error {
+		high, low, err = this.SwapRestAPI.GetLimit(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetKline(pair Pair, period, size, since int) (klines []*SwapKline, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		klines, resp, err = this.SwapRestAPI.GetKline(pair, period, size, since)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetOpenAmount(pair Pair) (amount float64, timestamp int64, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		amount, timestamp, resp, err = this.SwapRestAPI.GetOpenAmount(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetFundingFees(pair Pair) (fees [][]interface{}, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		fees, resp, err = this.SwapRestAPI.GetFundingFees(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetFundingFee(pair Pair) (fee float64, err error) {
+	err = this.option.retry(func() error {
+		fee, err = this.SwapRestAPI.GetFundingFee(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetAccount() (account *SwapAccount, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		account, resp, err = this.SwapRestAPI.GetAccount()
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetOrder(order *SwapOrder) (resp []byte, err error) {
+	err = this.option.retry(func() error {
+		resp, err = this.SwapRestAPI.GetOrder(order)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetOrders(pair Pair) (orders []*SwapOrder, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		orders, resp, err = this.SwapRestAPI.GetOrders(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetUnFinishOrders(pair Pair) (orders []*SwapOrder, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		orders, resp, err = this.SwapRestAPI.GetUnFinishOrders(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetPosition(pair Pair, openType FutureType) (position *SwapPosition, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		position, resp, err = this.SwapRestAPI.GetPosition(pair, openType)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetAccountFlow() (items []*SwapAccountItem, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		items, resp, err = this.SwapRestAPI.GetAccountFlow()
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) GetPairFlow(pair Pair) (items []*SwapAccountItem, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		items, resp, err = this.SwapRestAPI.GetPairFlow(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetrySwap) PlaceOrder(order *SwapOrder) (resp []byte, err error) {
+	err = this.option.placeOrder(order.Cid, dedupeCid(this.SwapRestAPI), func() error {
+		resp, err = this.SwapRestAPI.PlaceOrder(order)
+		return err
+	}, func() (bool, error) {
+		return this.lookup(order)
+	})
+	return
+}
+
+// lookup the order by the cid in the order api, or in the unfinished orders and the dealed orders when the order api fails.
+func (this *RetrySwap) lookup(order *SwapOrder) (bool, error) {
+	var query = *order
+	query.OrderId = ""
+	var _, err = this.SwapRestAPI.GetOrder(&query)
+	if err == nil && query.OrderId != "" && query.Cid == order.Cid {
+		*order = query
+		return true, nil
+	}
+	if errors.Is(err, ErrOrderNotFound) {
+		return false, nil
+	}
+
+	var unfinished, _, unfinishedErr = this.SwapRestAPI.GetUnFinishOrders(order.Pair)
+	var dealed, _, dealedErr = this.SwapRestAPI.GetOrders(order.Pair)
+	for _, o := range append(unfinished, dealed...) {
+		if o.Cid == order.Cid {
+			*order = *o
+			return true, nil
+		}
+	}
+	if unfinishedErr != nil {
+		return false, unfinishedErr
+	}
+	return false, dealedErr
+}
+
+// RetryFuture retry the read-only calls, and place the order at most once, the ambiguous order is looked up by the cid.
+type RetryFuture struct {
+	FutureRestAPI
+	option *RetryOption
+}
+
+func NewRetryFuture(api FutureRestAPI, option *RetryOption) *RetryFuture {
+	return &RetryFuture{FutureRestAPI: api, option: option.init()}
+}
+
+func (this *RetryFuture) GetContract(pair Pair, contractType string) (contract *FutureContract, err error) {
+	err = this.option.retry(func() error {
+		contract, err = this.FutureRestAPI.GetContract(pair, contractType)
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) GetTicker(pair Pair, contractType string) (ticker *FutureTicker, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		ticker, resp, err = this.FutureRestAPI.GetTicker(pair, contractType)
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) GetDepth(pair Pair, contractType string, size int) (depth *FutureDepth, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		depth, resp, err = this.FutureRestAPI.GetDepth(pair, contractType, size)
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) GetLimit(pair Pair, contractType string) (high float64, low float64, err error) {
+	err = this.option.retry(func() error {
+		high, low, err = this.FutureRestAPI.GetLimit(pair, contractType)
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) GetIndex(pair Pair) (index float64, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		index, resp, err = this.FutureRestAPI.GetIndex(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) GetMark(pair Pair, contractType string) (mark float64, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		mark, resp, err = this.FutureRestAPI.GetMark(pair, contractType)
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) GetKlineRecords(contractType string, pair Pair, period, size, since int) (klines []*FutureKline, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		klines, resp, err = this.FutureRestAPI.GetKlineRecords(contractType, pair, period, size, since)
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) GetTrades(pair Pair, contractType string) (trades []*Trade, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		trades, resp, err = this.FutureRestAPI.GetTrades(pair, contractType)
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) GetAccount() (account *FutureAccount, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		account, resp, err = this.FutureRestAPI.GetAccount()
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) GetOrders(pair Pair, contractType string) (orders []*FutureOrder, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		orders, resp, err = this.FutureRestAPI.GetOrders(pair, contractType)
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) GetOrder(order *FutureOrder) (resp []byte, err error) {
+	err = this.option.retry(func() error {
+		resp, err = this.FutureRestAPI.GetOrder(order)
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) GetPairFlow(pair Pair) (items []*FutureAccountItem, resp []byte, err error) {
+	err = this.option.retry(func() error {
+		items, resp, err = this.FutureRestAPI.GetPairFlow(pair)
+		return err
+	})
+	return
+}
+
+func (this *RetryFuture) PlaceOrder(order *FutureOrder) (resp []byte, err error) {
+	err = this.option.placeOrder(order.Cid, dedupeCid(this.FutureRestAPI), func() error {
+		resp, err = this.FutureRestAPI.PlaceOrder(order)
+		return err
+	}, func() (bool, error) {
+		return this.lookup(order)
+	})
+	return
+}
+
+// lookup the order by the cid in the order api, or in the orders of the contract when the order api fails.
+func (this *RetryFuture) lookup(order *FutureOrder) (bool, error) {
+	var query = *order
+	query.OrderId = ""
+	var _, err = this.FutureRestAPI.GetOrder(&query)
+	if err == nil && query.OrderId != "" && query.Cid == order.Cid {
+		*order = query
+		return true, nil
+	}
+	if errors.Is(err, ErrOrderNotFound) {
+		return false, nil
+	}
+
+	var orders, _, ordersErr = this.FutureRestAPI.GetOrders(order.Pair, order.ContractType)
+	for _, o := range orders {
+		if o.Cid == order.Cid {
+			*order = *o
+			return true, nil
+		}
+	}
+	return false, ordersErr
+}
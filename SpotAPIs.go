@@ -25,3 +25,8 @@ type SpotRestAPI interface {
 	// v2 API
 	GetOHLCs(symbol string, period, size, since int) ([]*OHLC, []byte, error)
 }
+
+// SpotKlineBeforeAPI return the latest klines before the end (excluded), the kline backfill page backward by it.
+type SpotKlineBeforeAPI interface {
+	GetKlineRecordsBefore(pair Pair, period, size, end int) ([]*Kline, []byte, error)
+}
@@ -134,7 +134,7 @@ func (depth *Depth) Verify() error {
  *
  **/
 type Order struct {
-	// cid is important, when the order api return wrong, you can find it in unfinished api
+	// cid is important, when the order api return wrong, you can find it in unfinished api, the RetrySpot do it automatically
 	Cid        string
 	Price      float64
 	Amount     float64
@@ -172,8 +172,9 @@ type APIConfig struct {
 	ApiKey        string
 	ApiSecretKey  string
 	ApiPassphrase string //for okex.com v3 api
-	ClientId      string //for bitstamp.net , huobi.pro
+	ClientId      string //for huobi.pro, bitstamp.net v2 api does not need it
 	Location      *time.Location
+	RateLimit     string // the mode of the rate limiter, RATE_LIMIT_BLOCK(default) RATE_LIMIT_FAIL_FAST RATE_LIMIT_OFF
 }
 
 type Rule struct {
@@ -26,3 +26,8 @@ type SwapRestAPI interface {
 	// util api
 	KeepAlive()
 }
+
+// SwapKlineBeforeAPI return the latest klines before the end (excluded), the kline backfill page backward by it.
+type SwapKlineBeforeAPI interface {
+	GetKlineBefore(pair Pair, period, size, end int) ([]*SwapKline, []byte, error)
+}
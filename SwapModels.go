@@ -66,7 +66,7 @@ type SwapKline struct {
 }
 
 type SwapOrder struct {
-	// cid is important, when the order api return wrong, you can find it in unfinished api
+	// cid is important, when the order api return wrong, you can find it in unfinished api, the RetrySwap do it automatically
 	Cid            string
 	OrderId        string
 	Price          float64
@@ -158,3 +158,23 @@ type SwapContract struct {
 	PricePrecision  int64   `json:"price_precision"`
 	AmountPrecision int64   `json:"amount_precision"`
 }
+
+type FundingRate struct {
+	Pair     Pair   `json:"-"`
+	Exchange string `json:"exchange"`
+	// The current funding rate, the next funding rate is the predicted one.
+	Rate             float64 `json:"rate"`
+	NextRate         float64 `json:"next_rate"`
+	FundingTimestamp int64   `json:"funding_timestamp"` // the settle time of the current funding rate
+	Timestamp        int64   `json:"timestamp"`         // unit:ms
+	Date             string  `json:"date"`
+}
+
+type MarkPrice struct {
+	Pair       Pair    `json:"-"`
+	Exchange   string  `json:"exchange"`
+	Price      float64 `json:"price"`
+	IndexPrice float64 `json:"index_price"`
+	Timestamp  int64   `json:"timestamp"` // unit:ms
+	Date       string  `json:"date"`
+}
@@ -0,0 +1,434 @@
+package goghostex
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CONFIG_ENV_PREFIX the environment of the profile is GOGHOSTEX_<EXCHANGE>[_<ACCOUNT>]_<FIELD>,
+	// eg: GOGHOSTEX_OKEX_API_KEY GOGHOSTEX_OKEX_SUB1_API_KEY, the default is GOGHOSTEX_<FIELD>, eg: GOGHOSTEX_PROXY.
+	CONFIG_ENV_PREFIX = "GOGHOSTEX"
+	// CONFIG_ENV_FILE the environment of the config file path.
+	CONFIG_ENV_FILE = "GOGHOSTEX_CONFIG"
+
+	DEFAULT_CONFIG_TIMEOUT = 15 // unit: second
+)
+
+// ProfileConfig is the api config of one account in the exchange, the empty field is inherited from the default.
+type ProfileConfig struct {
+	Exchange      string `json:"exchange"`
+	Account       string `json:"account"` // the sub-account name, the empty is the main account
+	Endpoint      string `json:"endpoint"`
+	ApiKey        string `json:"api_key"`
+	ApiSecretKey  string `json:"api_secret_key"`
+	ApiPassphrase string `json:"api_passphrase"`
+	ClientId      string `json:"client_id"`
+	Proxy         string `json:"proxy"`
+	Timeout       int64  `json:"timeout"`  // unit: second
+	Location      string `json:"location"` // the name of the time zone, eg: Asia/Shanghai
+	RateLimit     string `json:"rate_limit"`
+}
+
+// Profiles is the config file, the key of the profile is the exchange or the exchange.account, eg:
+//
+//	{
+//	  "default": {"proxy": "socks5://127.0.0.1:1080", "timeout": 15, "location": "Asia/Shanghai"},
+//	  "profiles": {
+//	    "okex": {"api_key": "...", "api_secret_key": "...", "api_passphrase": "..."},
+//	    "okex.sub1": {"api_key": "...", "api_secret_key": "...", "api_passphrase": "..."},
+//	    "binance": {"api_key": "...", "api_secret_key": "..."}
+//	  }
+//	}
+//
+// The file with the .toml suffix is the same tables, the sub-account key is quoted, eg:
+//
+//	[default]
+//	proxy = "socks5://127.0.0.1:1080"
+//	timeout = 15
+//
+//	[profiles."okex.sub1"]
+//	api_key = "..."
+//
+// The file with the .env suffix is the KEY=VALUE lines of the environment, eg: GOGHOSTEX_OKEX_API_KEY=...
+// The environment overwrite the file.
+type Profiles struct {
+	Default  ProfileConfig             `json:"default"`
+	Profiles map[string]*ProfileConfig `json:"profiles"`
+
+	// the environment lookup, it's os.LookupEnv and the .env file.
+	lookupEnv func(string) (string, bool)
+}
+
+// LoadProfiles load the config file and the environment, the empty path is the GOGHOSTEX_CONFIG environment,
+// only the environment is loaded when both of them are empty.
+func LoadProfiles(path string) (*Profiles, error) {
+	if path == "" {
+		path = os.Getenv(CONFIG_ENV_FILE)
+	}
+
+	var profiles = &Profiles{
+		Profiles:  make(map[string]*ProfileConfig),
+		lookupEnv: os.LookupEnv,
+	}
+	if path == "" {
+		return profiles, nil
+	}
+
+	var file, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.ToLower(filepath.Ext(path)) == ".env" {
+		var envs = make(map[string]string)
+		var scanner = bufio.NewScanner(file)
+		for scanner.Scan() {
+			var line = strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			var kv = strings.SplitN(strings.TrimPrefix(line, "export "), "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("The line %s in %s is not KEY=VALUE. ", line, path)
+			}
+			envs[strings.TrimSpace(kv[0])] = strings.Trim(strings.TrimSpace(kv[1]), `"'`)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		profiles.lookupEnv = func(key string) (string, bool) {
+			if value, exist := os.LookupEnv(key); exist {
+				return value, true
+			}
+			var value, exist = envs[key]
+			return value, exist
+		}
+		return profiles, nil
+	}
+
+	if strings.ToLower(filepath.Ext(path)) == ".toml" {
+		if err := decodeToml(file, profiles); err != nil {
+			return nil, fmt.Errorf("The toml %s is wrong: %s ", path, err.Error())
+		}
+	} else if err := json.NewDecoder(file).Decode(profiles); err != nil {
+		return nil, err
+	}
+	if profiles.Profiles == nil {
+		profiles.Profiles = make(map[string]*ProfileConfig)
+	}
+	return profiles, nil
+}
+
+func profileKey(exchange, account string) string {
+	if account == "" {
+		return strings.ToLower(exchange)
+	}
+	return strings.ToLower(exchange) + "." + strings.ToLower(account)
+}
+
+// fromEnv overwrite the fields by the environment of the prefix.
+func (p *Profiles) fromEnv(profile *ProfileConfig, prefix string) error {
+	if p.lookupEnv == nil {
+		return nil
+	}
+	var fields = map[string]*string{
+		"ENDPOINT":       &profile.Endpoint,
+		"API_KEY":        &profile.ApiKey,
+		"API_SECRET_KEY": &profile.ApiSecretKey,
+		"API_PASSPHRASE": &profile.ApiPassphrase,
+		"CLIENT_ID":      &profile.ClientId,
+		"PROXY":          &profile.Proxy,
+		"LOCATION":       &profile.Location,
+		"RATE_LIMIT":     &profile.RateLimit,
+	}
+	for name, field := range fields {
+		if value, exist := p.lookupEnv(prefix + name); exist {
+			*field = value
+		}
+	}
+	if value, exist := p.lookupEnv(prefix + "TIMEOUT"); exist {
+		var timeout, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("The %sTIMEOUT %s is not an integer. ", prefix, value)
+		}
+		profile.Timeout = timeout
+	}
+	return nil
+}
+
+func inherit(profile *ProfileConfig, parent ProfileConfig) {
+	if profile.Endpoint == "" {
+		profile.Endpoint = parent.Endpoint
+	}
+	if profile.Proxy == "" {
+		profile.Proxy = parent.Proxy
+	}
+	if profile.Timeout == 0 {
+		profile.Timeout = parent.Timeout
+	}
+	if profile.Location == "" {
+		profile.Location = parent.Location
+	}
+	if profile.RateLimit == "" {
+		profile.RateLimit = parent.RateLimit
+	}
+}
+
+// Profile the config of the account in the exchange, the empty account is the main account.
+// The sub-account inherit the proxy, timeout, location and rate limit of the main account, then the default.
+func (p *Profiles) Profile(exchange, account string) (*ProfileConfig, error) {
+	var exchangePrefix = CONFIG_ENV_PREFIX + "_" + strings.ToUpper(exchange) + "_"
+
+	var defaultProfile = p.Default
+	if err := p.fromEnv(&defaultProfile, CONFIG_ENV_PREFIX+"_"); err != nil {
+		return nil, err
+	}
+	// the endpoint and the keys are not shared between the exchanges.
+	defaultProfile.Endpoint = ""
+
+	var main = ProfileConfig{}
+	if profile, exist := p.Profiles[profileKey(exchange, "")]; exist {
+		main = *profile
+	}
+	if err := p.fromEnv(&main, exchangePrefix); err != nil {
+		return nil, err
+	}
+	inherit(&main, defaultProfile)
+
+	var profile = main
+	if account != "" {
+		profile = ProfileConfig{}
+		if sub, exist := p.Profiles[profileKey(exchange, account)]; exist {
+			profile = *sub
+		}
+		if err := p.fromEnv(&profile, exchangePrefix+strings.ToUpper(account)+"_"); err != nil {
+			return nil, err
+		}
+		inherit(&profile, main)
+	}
+
+	profile.Exchange = strings.ToLower(exchange)
+	profile.Account = account
+	if profile.Timeout == 0 {
+		profile.Timeout = DEFAULT_CONFIG_TIMEOUT
+	}
+	return &profile, nil
+}
+
+// APIConfig build the config of the account in the exchange, the endpoint is used when the profile has no endpoint.
+func (p *Profiles) APIConfig(exchange, account, endpoint string) (*APIConfig, error) {
+	var profile, err = p.Profile(exchange, account)
+	if err != nil {
+		return nil, err
+	}
+	if account != "" && profile.ApiKey == "" {
+		return nil, fmt.Errorf("The account %s of %s is not found in the profiles. ", account, exchange)
+	}
+	return profile.APIConfig(endpoint)
+}
+
+// APIConfig build the config, the endpoint is used when the profile has no endpoint.
+func (profile *ProfileConfig) APIConfig(endpoint string) (*APIConfig, error) {
+	var location = time.Now().Location()
+	if profile.Location != "" {
+		var loc, err = time.LoadLocation(profile.Location)
+		if err != nil {
+			return nil, err
+		}
+		location = loc
+	}
+	var client, err = NewHttpClient(profile.Proxy, time.Duration(profile.Timeout)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if profile.Endpoint != "" {
+		endpoint = profile.Endpoint
+	}
+
+	return &APIConfig{
+		HttpClient:    client,
+		Endpoint:      endpoint,
+		ApiKey:        profile.ApiKey,
+		ApiSecretKey:  profile.ApiSecretKey,
+		ApiPassphrase: profile.ApiPassphrase,
+		ClientId:      profile.ClientId,
+		Location:      location,
+		RateLimit:     profile.RateLimit,
+	}, nil
+}
+
+// NewHttpClient the http client with the proxy, the empty proxy means no proxy.
+func NewHttpClient(proxy string, timeout time.Duration) (*http.Client, error) {
+	if proxy == "" {
+		return &http.Client{Timeout: timeout}, nil
+	}
+	var proxyUrl, err = url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	if proxyUrl.Scheme == "" || proxyUrl.Host == "" {
+		return nil, errors.New("The proxy must be the url with the scheme and host. ")
+	}
+	return &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyURL(proxyUrl)},
+		Timeout:   timeout,
+	}, nil
+}
+
+// decodeToml the tables, the strings, the integers, the floats and the booleans of toml are supported,
+// the arrays and the inline tables are not. The toml is converted to the json, then decoded by the json tags.
+func decodeToml(reader io.Reader, v interface{}) error {
+	var root = make(map[string]interface{})
+	var table = root
+	var scanner = bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var line = strings.TrimSpace(stripTomlComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return fmt.Errorf("The table %s is not supported. ", line)
+			}
+			var keys, err = splitTomlKeys(line[1 : len(line)-1])
+			if err != nil {
+				return err
+			}
+			if table, err = tomlTable(root, keys); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var kv = strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("The line %s is not key = value. ", line)
+		}
+		var keys, err = splitTomlKeys(kv[0])
+		if err != nil {
+			return err
+		}
+		parent, err := tomlTable(table, keys[:len(keys)-1])
+		if err != nil {
+			return err
+		}
+		value, err := tomlValue(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return err
+		}
+		parent[keys[len(keys)-1]] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	var data, err = json.Marshal(root)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stripTomlComment remove the comment after the #, the # in the string is kept.
+func stripTomlComment(line string) string {
+	var quote = rune(0)
+	for i, c := range line {
+		switch {
+		case quote != 0 && c == quote && (quote == '\'' || i == 0 || line[i-1] != '\\'):
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitTomlKeys split the dotted keys, the dot in the quoted key is kept, eg: profiles."okex.sub1".
+func splitTomlKeys(text string) ([]string, error) {
+	var keys = make([]string, 0)
+	for _, part := range splitOutsideQuotes(text, '.') {
+		var key = strings.TrimSpace(part)
+		if strings.HasPrefix(key, `"`) || strings.HasPrefix(key, "'") {
+			var value, err = tomlValue(key)
+			if err != nil {
+				return nil, err
+			}
+			key, _ = value.(string)
+		} else if key == "" || strings.ContainsAny(key, " \t\"'") {
+			return nil, fmt.Errorf("The key %s is wrong. ", text)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func splitOutsideQuotes(text string, sep rune) []string {
+	var parts = make([]string, 0)
+	var quote, start = rune(0), 0
+	for i, c := range text {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == sep:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// tomlTable the table of the keys in the root, the missing tables are created.
+func tomlTable(root map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	var table = root
+	for _, key := range keys {
+		var child, exist = table[key]
+		if !exist {
+			child = make(map[string]interface{})
+			table[key] = child
+		}
+		var next, ok = child.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("The key %s is not a table. ", key)
+		}
+		table = next
+	}
+	return table, nil
+}
+
+func tomlValue(text string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, `"`):
+		return strconv.Unquote(text)
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("The string %s is not closed. ", text)
+		}
+		return text[1 : len(text)-1], nil
+	case text == "true" || text == "false":
+		return text == "true", nil
+	}
+	if value, err := strconv.ParseInt(strings.ReplaceAll(text, "_", ""), 10, 64); err == nil {
+		return value, nil
+	}
+	if value, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64); err == nil {
+		return value, nil
+	}
+	return nil, fmt.Errorf("The value %s is not supported. ", text)
+}
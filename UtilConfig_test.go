@@ -0,0 +1,150 @@
+package goghostex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// go test -v ./... -count=1 -run=TestLoadProfiles
+func TestLoadProfiles(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "profiles.json")
+	var content = `{
+		"default": {"proxy": "http://127.0.0.1:1080", "timeout": 20, "location": "Asia/Shanghai"},
+		"profiles": {
+			"okex": {"api_key": "ok-key", "api_secret_key": "ok-secret", "api_passphrase": "ok-pass"},
+			"okex.sub1": {"api_key": "sub-key", "api_secret_key": "sub-secret", "timeout": 5},
+			"binance": {"api_key": "bn-key", "api_secret_key": "bn-secret", "proxy": "http://127.0.0.1:2080"}
+		}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOGHOSTEX_BINANCE_API_KEY", "bn-env-key")
+	t.Setenv("GOGHOSTEX_KRAKEN_API_KEY", "kk-env-key")
+
+	var profiles, err = LoadProfiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	okex, err := profiles.APIConfig(OKEX, "", "https://www.okx.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if okex.ApiKey != "ok-key" || okex.ApiPassphrase != "ok-pass" || okex.Endpoint != "https://www.okx.com" ||
+		okex.Location.String() != "Asia/Shanghai" || okex.HttpClient.Timeout != 20*time.Second {
+		t.Error("The okex config is wrong: ", okex)
+	}
+
+	sub, err := profiles.Profile(OKEX, "sub1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.ApiKey != "sub-key" || sub.ApiPassphrase != "" || sub.Timeout != 5 ||
+		sub.Proxy != "http://127.0.0.1:1080" || sub.Account != "sub1" {
+		t.Error("The sub-account profile is wrong: ", sub)
+	}
+	if _, err := profiles.APIConfig(OKEX, "sub2", ""); err == nil {
+		t.Error("The sub2 is not in the profiles, it should be an error. ")
+	}
+
+	binance, err := profiles.Profile(BINANCE, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binance.ApiKey != "bn-env-key" || binance.ApiSecretKey != "bn-secret" || binance.Proxy != "http://127.0.0.1:2080" {
+		t.Error("The environment should overwrite the file: ", binance)
+	}
+
+	kraken, err := profiles.Profile(KRAKEN, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kraken.ApiKey != "kk-env-key" || kraken.Timeout != 20 {
+		t.Error("The profile only in the environment is wrong: ", kraken)
+	}
+}
+
+// go test -v ./... -count=1 -run=TestLoadProfiles_Env
+func TestLoadProfiles_Env(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "goghostex.env")
+	var content = "# the keys\nexport GOGHOSTEX_GATE_API_KEY=gate-key\nGOGHOSTEX_GATE_API_SECRET_KEY='gate-secret'\nGOGHOSTEX_TIMEOUT=3\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(CONFIG_ENV_FILE, path)
+	t.Setenv("GOGHOSTEX_GATE_API_SECRET_KEY", "gate-env-secret")
+
+	var profiles, err = LoadProfiles("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gate, err := profiles.APIConfig(GATE, "", "https://api.gateio.ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gate.ApiKey != "gate-key" || gate.ApiSecretKey != "gate-env-secret" || gate.HttpClient.Timeout != 3*time.Second {
+		t.Error("The gate config is wrong: ", gate)
+	}
+
+	t.Setenv("GOGHOSTEX_TIMEOUT", "three")
+	if _, err := profiles.Profile(GATE, ""); err == nil {
+		t.Error("The timeout is not an integer, it should be an error. ")
+	}
+	if _, err := NewHttpClient("127.0.0.1:1080", time.Second); err == nil {
+		t.Error("The proxy without the scheme should be an error. ")
+	}
+}
+
+// go test -v ./... -count=1 -run=TestLoadProfiles_Toml
+func TestLoadProfiles_Toml(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "profiles.toml")
+	var content = `
+# the default of all the exchanges
+[default]
+proxy = "http://127.0.0.1:1080" # the local proxy
+timeout = 20
+location = 'Asia/Shanghai'
+
+[profiles.okex]
+api_key = "ok-key"
+api_secret_key = "ok#secret"
+api_passphrase = "ok-pass"
+
+[profiles."okex.sub1"]
+api_key = "sub-key"
+timeout = 5
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var profiles, err = LoadProfiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	okex, err := profiles.Profile(OKEX, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if okex.ApiKey != "ok-key" || okex.ApiSecretKey != "ok#secret" || okex.Timeout != 20 ||
+		okex.Proxy != "http://127.0.0.1:1080" || okex.Location != "Asia/Shanghai" {
+		t.Error("The okex profile is wrong: ", okex)
+	}
+	sub, err := profiles.Profile(OKEX, "sub1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.ApiKey != "sub-key" || sub.Timeout != 5 || sub.Proxy != "http://127.0.0.1:1080" {
+		t.Error("The sub-account profile is wrong: ", sub)
+	}
+
+	if err := os.WriteFile(path, []byte("[profiles.okex]\napi_key = [\"a\"]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProfiles(path); err == nil {
+		t.Error("The array is not supported, it should be an error. ")
+	}
+}
@@ -0,0 +1,223 @@
+package goghostex
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ConsolidatedBook merge the books of the same basis in many venues into one view. The price of every venue
+// is converted into the Quote by the rate of its counter currency, eg: the rate of USDT is 0.9998 when the
+// quote is USD. The books are held by the OrderBookManager, the feeds of the venues should be added into it.
+//
+// The amount of every venue is summed as it is, so the feeds must give the amount in the basis, eg: the okex
+// SwapBookFeed convert the contracts into the basis. The venues of the spot and the swap markets are merged
+// together when they're added, the swap price is not adjusted by the funding or the basis spread, the market
+// of every level is in its Sources.
+type ConsolidatedBook struct {
+	Manager *OrderBookManager
+	Quote   Currency
+
+	venues []BookKey
+	rates  map[string]float64
+	mux    sync.RWMutex
+}
+
+// LevelSource is the level of the venue in the consolidated level.
+type LevelSource struct {
+	BookKey
+	Price  float64 // the raw price in the counter currency of the venue
+	Amount float64
+}
+
+type ConsolidatedLevel struct {
+	Price   float64 // the price in the quote currency
+	Amount  float64 // the sum of the sources
+	Sources []LevelSource
+}
+
+type ConsolidatedDepth struct {
+	Quote     Currency
+	Timestamp int64                // the latest timestamp of the venues
+	AskList   []*ConsolidatedLevel // Ascending order
+	BidList   []*ConsolidatedLevel // Descending order
+	Missing   []BookKey            // the books not ready, they're not in the depth
+}
+
+func NewConsolidatedBook(manager *OrderBookManager, quote Currency) *ConsolidatedBook {
+	return &ConsolidatedBook{
+		Manager: manager,
+		Quote:   quote,
+		venues:  make([]BookKey, 0),
+		rates:   make(map[string]float64),
+	}
+}
+
+// SetRate the price of the counter multiply the rate is the price of the quote.
+func (b *ConsolidatedBook) SetRate(counter Currency, rate float64) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.rates[strings.ToUpper(counter.Symbol)] = rate
+}
+
+func (b *ConsolidatedBook) Rate(counter Currency) (float64, bool) {
+	if strings.ToUpper(counter.Symbol) == strings.ToUpper(b.Quote.Symbol) {
+		return 1, true
+	}
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	var rate, exist = b.rates[strings.ToUpper(counter.Symbol)]
+	return rate, exist
+}
+
+// AddVenue subscribe the book in the manager, all the venues must have the same basis.
+func (b *ConsolidatedBook) AddVenue(key BookKey) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	for _, venue := range b.venues {
+		if venue.String() == key.String() {
+			return nil
+		}
+		if !venue.Pair.Basis.Eq(key.Pair.Basis) {
+			return fmt.Errorf("The basis of %s is not %s. ", key.String(), venue.Pair.Basis.Symbol)
+		}
+	}
+	if err := b.Manager.Subscribe(key); err != nil {
+		return err
+	}
+	b.venues = append(b.venues, key)
+	return nil
+}
+
+func (b *ConsolidatedBook) RemoveVenue(key BookKey) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	for i, venue := range b.venues {
+		if venue.String() == key.String() {
+			b.venues = append(b.venues[:i], b.venues[i+1:]...)
+			return b.Manager.Unsubscribe(key)
+		}
+	}
+	return nil
+}
+
+func (b *ConsolidatedBook) Venues() []BookKey {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	return append([]BookKey{}, b.venues...)
+}
+
+// Snapshot merge the levels of the same quote price, the venue without the rate is an error.
+func (b *ConsolidatedBook) Snapshot() (*ConsolidatedDepth, error) {
+	var venues = b.Venues()
+	if len(venues) == 0 {
+		return nil, errors.New("There is no venue in the consolidated book. ")
+	}
+
+	var depth = &ConsolidatedDepth{
+		Quote:   b.Quote,
+		AskList: make([]*ConsolidatedLevel, 0),
+		BidList: make([]*ConsolidatedLevel, 0),
+		Missing: make([]BookKey, 0),
+	}
+	var asks, bids = make(map[int64]*ConsolidatedLevel), make(map[int64]*ConsolidatedLevel)
+	for _, venue := range venues {
+		var rate, exist = b.Rate(venue.Pair.Counter)
+		if !exist {
+			return nil, fmt.Errorf("The rate of %s to %s is not set. ", venue.Pair.Counter.Symbol, b.Quote.Symbol)
+		}
+
+		var venueDepth, err = b.Manager.Snapshot(venue)
+		if err != nil {
+			depth.Missing = append(depth.Missing, venue)
+			continue
+		}
+		if venueDepth.Timestamp > depth.Timestamp {
+			depth.Timestamp = venueDepth.Timestamp
+		}
+		mergeLevels(asks, venue, venueDepth.AskList, rate)
+		mergeLevels(bids, venue, venueDepth.BidList, rate)
+	}
+
+	for _, level := range asks {
+		depth.AskList = append(depth.AskList, level)
+	}
+	for _, level := range bids {
+		depth.BidList = append(depth.BidList, level)
+	}
+	sort.Slice(depth.AskList, func(i, j int) bool { return depth.AskList[i].Price < depth.AskList[j].Price })
+	sort.Slice(depth.BidList, func(i, j int) bool { return depth.BidList[i].Price > depth.BidList[j].Price })
+	return depth, nil
+}
+
+func mergeLevels(levels map[int64]*ConsolidatedLevel, venue BookKey, records DepthRecords, rate float64) {
+	for _, record := range records {
+		if record.Amount <= 0 {
+			continue
+		}
+		var price = record.Price * rate
+		var stdPrice = int64(math.Round(price * 100000000))
+		var level, exist = levels[stdPrice]
+		if !exist {
+			level = &ConsolidatedLevel{Price: float64(stdPrice) / 100000000, Sources: make([]LevelSource, 0, 1)}
+			levels[stdPrice] = level
+		}
+		level.Amount += record.Amount
+		level.Sources = append(level.Sources, LevelSource{BookKey: venue, Price: record.Price, Amount: record.Amount})
+	}
+}
+
+func (d *ConsolidatedDepth) BestBid() *ConsolidatedLevel {
+	if len(d.BidList) == 0 {
+		return nil
+	}
+	return d.BidList[0]
+}
+
+func (d *ConsolidatedDepth) BestAsk() *ConsolidatedLevel {
+	if len(d.AskList) == 0 {
+		return nil
+	}
+	return d.AskList[0]
+}
+
+// the buy side take the asks, the sell side take the bids.
+func (d *ConsolidatedDepth) takeList(side TradeSide) []*ConsolidatedLevel {
+	if side == BUY || side == BUY_MARKET {
+		return d.AskList
+	}
+	return d.BidList
+}
+
+// DepthAtPrice the amount can be taken at the price or better.
+func (d *ConsolidatedDepth) DepthAtPrice(side TradeSide, price float64) float64 {
+	var isBuy = side == BUY || side == BUY_MARKET
+	var amount = 0.0
+	for _, level := range d.takeList(side) {
+		if (isBuy && level.Price > price) || (!isBuy && level.Price < price) {
+			break
+		}
+		amount += level.Amount
+	}
+	return amount
+}
+
+// VWAP the average quote price to take the amount, the filled is less than the amount when the depth is not enough.
+func (d *ConsolidatedDepth) VWAP(side TradeSide, amount float64) (float64, float64) {
+	var filled, cost = 0.0, 0.0
+	for _, level := range d.takeList(side) {
+		if filled >= amount {
+			break
+		}
+		var take = math.Min(level.Amount, amount-filled)
+		filled += take
+		cost += take * level.Price
+	}
+	if filled == 0 {
+		return 0, 0
+	}
+	return cost / filled, filled
+}
@@ -0,0 +1,151 @@
+package goghostex
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeDepthSpot return the depth of the pair, the depth is counted.
+type fakeDepthSpot struct {
+	SpotRestAPI
+	depths int
+}
+
+func (this *fakeDepthSpot) GetExchangeName() string {
+	return COINBASE
+}
+
+func (this *fakeDepthSpot) GetDepth(pair Pair, size int) (*Depth, []byte, error) {
+	this.depths++
+	return &Depth{
+		Pair:      pair,
+		Timestamp: 1700000000000 + int64(this.depths),
+		BidList:   DepthRecords{{Price: 99.9, Amount: 1}},
+		AskList:   DepthRecords{{Price: 100.05, Amount: 1}, {Price: 100.2, Amount: 2}},
+	}, nil, nil
+}
+
+func newTestConsolidatedBook(t *testing.T) (*ConsolidatedBook, *OrderBookManager) {
+	var manager = NewOrderBookManager(16)
+	var okexFeed, krakenFeed = newFakeBookFeed(OKEX, TRADE_TYPE_SWAP), newFakeBookFeed(KRAKEN, TRADE_TYPE_SPOT)
+	var coinbaseFeed = NewRestBookFeed(&fakeDepthSpot{}, time.Hour, 10)
+	for _, feed := range []OrderBookFeed{okexFeed, krakenFeed, coinbaseFeed} {
+		if err := manager.AddFeed(feed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var book = NewConsolidatedBook(manager, USD)
+	book.SetRate(USDT, 0.999)
+	for _, key := range []BookKey{
+		{Exchange: OKEX, Market: TRADE_TYPE_SWAP, Pair: Pair{Basis: BTC, Counter: USDT}},
+		{Exchange: KRAKEN, Market: TRADE_TYPE_SPOT, Pair: Pair{Basis: BTC, Counter: USD}},
+		{Exchange: COINBASE, Market: TRADE_TYPE_SPOT, Pair: Pair{Basis: BTC, Counter: USD}},
+	} {
+		if err := book.AddVenue(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	okexFeed.depths["btcusdt"] = &Depth{
+		Timestamp: 1700000000000,
+		BidList:   DepthRecords{{Price: 100.1, Amount: 2}, {Price: 100, Amount: 3}},
+		AskList:   DepthRecords{{Price: 100.2, Amount: 1}, {Price: 100.3, Amount: 4}},
+	}
+	krakenFeed.depths["btcusd"] = &Depth{
+		Timestamp: 1700000000500,
+		BidList:   DepthRecords{{Price: 100, Amount: 1}, {Price: 99.8, Amount: 5}},
+		AskList:   DepthRecords{{Price: 100.1, Amount: 0.5}, {Price: 100.2, Amount: 2}},
+	}
+	return book, manager
+}
+
+// go test -v ./ -count=1 -run=TestConsolidatedBook_Snapshot
+func TestConsolidatedBook_Snapshot(t *testing.T) {
+	var book, manager = newTestConsolidatedBook(t)
+	defer manager.Stop()
+
+	if err := book.AddVenue(BookKey{Exchange: OKEX, Market: TRADE_TYPE_SWAP, Pair: Pair{Basis: ETH, Counter: USDT}}); err == nil {
+		t.Error("The venue of the other basis should not be added. ")
+	}
+
+	// the coinbase is not polled yet.
+	var depth, err = book.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(depth.Missing) != 1 || depth.Missing[0].Exchange != COINBASE {
+		t.Error("The coinbase book should be missing. ", depth.Missing)
+	}
+	if depth.Timestamp != 1700000000500 {
+		t.Error("The timestamp should be the latest one. ", depth.Timestamp)
+	}
+
+	// kraken 100 is the best bid, okex 100.1*0.999=99.9999 is the next one.
+	var bid = depth.BestBid()
+	if bid == nil || bid.Price != 100 || bid.Amount != 1 || bid.Sources[0].Exchange != KRAKEN {
+		t.Error("The best bid is wrong. ", bid)
+	}
+	if len(depth.BidList) != 4 || math.Abs(depth.BidList[1].Price-99.9999) > 1e-9 ||
+		depth.BidList[1].Sources[0].Exchange != OKEX || depth.BidList[1].Sources[0].Price != 100.1 {
+		t.Error("The bids are wrong. ", depth.BidList[1])
+	}
+	var ask = depth.BestAsk()
+	if ask == nil || ask.Price != 100.0998 || ask.Amount != 1 {
+		t.Error("The best ask is wrong. ", ask)
+	}
+
+	// the coinbase 100.2 and the kraken 100.2 are merged with the sources.
+	manager.feeds[feedKey(COINBASE, TRADE_TYPE_SPOT)].(*RestBookFeed).Poll()
+	depth, err = book.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(depth.Missing) != 0 {
+		t.Fatal("All the books should be ready. ", depth.Missing)
+	}
+	var merged = false
+	for _, level := range depth.AskList {
+		if level.Price == 100.2 {
+			merged = level.Amount == 4 && len(level.Sources) == 2
+		}
+	}
+	if !merged {
+		t.Error("The level of the same price should be merged. ", depth.AskList)
+	}
+
+	book.mux.Lock()
+	delete(book.rates, "USDT")
+	book.mux.Unlock()
+	if _, err := book.Snapshot(); err == nil {
+		t.Error("The venue without the rate should be an error. ")
+	}
+}
+
+// go test -v ./ -count=1 -run=TestConsolidatedDepth_Query
+func TestConsolidatedDepth_Query(t *testing.T) {
+	var depth = &ConsolidatedDepth{
+		AskList: []*ConsolidatedLevel{{Price: 100, Amount: 1}, {Price: 101, Amount: 2}, {Price: 102, Amount: 3}},
+		BidList: []*ConsolidatedLevel{{Price: 99, Amount: 2}, {Price: 98, Amount: 2}},
+	}
+
+	if amount := depth.DepthAtPrice(BUY, 101); amount != 3 {
+		t.Error("The buy depth at 101 is wrong. ", amount)
+	}
+	if amount := depth.DepthAtPrice(SELL, 98.5); amount != 2 {
+		t.Error("The sell depth at 98.5 is wrong. ", amount)
+	}
+
+	var vwap, filled = depth.VWAP(BUY, 2)
+	if vwap != 100.5 || filled != 2 {
+		t.Error("The buy vwap is wrong. ", vwap, filled)
+	}
+	vwap, filled = depth.VWAP(SELL, 10)
+	if vwap != 98.5 || filled != 4 {
+		t.Error("The sell vwap should fill all the bids. ", vwap, filled)
+	}
+	if vwap, filled = (&ConsolidatedDepth{}).VWAP(BUY, 1); vwap != 0 || filled != 0 {
+		t.Error("The empty depth is wrong. ", vwap, filled)
+	}
+}
@@ -0,0 +1,317 @@
+package goghostex
+
+import (
+	"math"
+	"sort"
+)
+
+// LevelChange is the change of the price level between two snapshots.
+// The new level has the zero PrevAmount, the removed level has the zero Amount.
+type LevelChange struct {
+	Price      float64
+	Amount     float64
+	PrevAmount float64
+	Delta      float64
+}
+
+// The analytics of the DepthRecords suppose the records are ordered from the best price,
+// the asks are ascending and the bids are descending, like the AskList and BidList of the depth.
+
+// Amount the sum amount of the top levels, all the levels when the levels <= 0.
+func (dr DepthRecords) Amount(levels int) float64 {
+	if levels <= 0 || levels > len(dr) {
+		levels = len(dr)
+	}
+	var amount = 0.0
+	for i := 0; i < levels; i++ {
+		amount += dr[i].Amount
+	}
+	return amount
+}
+
+// AmountWithin the cumulative amount of the levels within the bps from the price.
+func (dr DepthRecords) AmountWithin(price, bps float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	var amount = 0.0
+	for _, record := range dr {
+		if math.Abs(record.Price-price)/price*10000 > bps+1e-9 {
+			break
+		}
+		amount += record.Amount
+	}
+	return amount
+}
+
+// VWAP the average price to take the amount, the filled is less than the amount when the depth is not enough.
+func (dr DepthRecords) VWAP(amount float64) (float64, float64) {
+	var filled, cost = 0.0, 0.0
+	for _, record := range dr {
+		if filled >= amount {
+			break
+		}
+		var take = math.Min(record.Amount, amount-filled)
+		filled += take
+		cost += take * record.Price
+	}
+	if filled == 0 {
+		return 0, 0
+	}
+	return cost / filled, filled
+}
+
+// Diff the changed levels from the prev records, it's ordered by the price ascending.
+func (dr DepthRecords) Diff(prev DepthRecords) []LevelChange {
+	var prevKV = make(map[int64]float64, len(prev))
+	for _, record := range prev {
+		prevKV[int64(math.Round(record.Price*100000000))] += record.Amount
+	}
+	var currKV = make(map[int64]float64, len(dr))
+	for _, record := range dr {
+		currKV[int64(math.Round(record.Price*100000000))] += record.Amount
+	}
+
+	var changes = make([]LevelChange, 0)
+	for stdPrice, amount := range currKV {
+		if prevAmount := prevKV[stdPrice]; prevAmount != amount {
+			changes = append(changes, LevelChange{
+				Price:      float64(stdPrice) / 100000000,
+				Amount:     amount,
+				PrevAmount: prevAmount,
+				Delta:      amount - prevAmount,
+			})
+		}
+	}
+	for stdPrice, prevAmount := range prevKV {
+		if _, exist := currKV[stdPrice]; !exist {
+			changes = append(changes, LevelChange{
+				Price:      float64(stdPrice) / 100000000,
+				PrevAmount: prevAmount,
+				Delta:      -prevAmount,
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Price < changes[j].Price })
+	return changes
+}
+
+// depthSides the analytics of the ask and bid list, the depth types share it.
+type depthSides struct {
+	asks DepthRecords
+	bids DepthRecords
+}
+
+func (s depthSides) mid() float64 {
+	if len(s.asks) == 0 || len(s.bids) == 0 {
+		return 0
+	}
+	return (s.asks[0].Price + s.bids[0].Price) / 2
+}
+
+// microPrice the mid weighted by the amount of the other side, it's close to the side with the less amount.
+func (s depthSides) microPrice() float64 {
+	if len(s.asks) == 0 || len(s.bids) == 0 {
+		return 0
+	}
+	var ask, bid = s.asks[0], s.bids[0]
+	if ask.Amount+bid.Amount == 0 {
+		return s.mid()
+	}
+	return (ask.Price*bid.Amount + bid.Price*ask.Amount) / (ask.Amount + bid.Amount)
+}
+
+func (s depthSides) spreadBps() float64 {
+	var mid = s.mid()
+	if mid == 0 {
+		return 0
+	}
+	return (s.asks[0].Price - s.bids[0].Price) / mid * 10000
+}
+
+func (s depthSides) amountWithinBps(bps float64) (float64, float64) {
+	var mid = s.mid()
+	return s.bids.AmountWithin(mid, bps), s.asks.AmountWithin(mid, bps)
+}
+
+// the buy side take the asks, the sell side take the bids.
+func (s depthSides) takeList(side TradeSide) DepthRecords {
+	if side == BUY || side == BUY_MARKET {
+		return s.asks
+	}
+	return s.bids
+}
+
+func (s depthSides) vwap(side TradeSide, amount float64) (float64, float64) {
+	return s.takeList(side).VWAP(amount)
+}
+
+// slippageBps the vwap is worse than the best price, it's positive.
+func (s depthSides) slippageBps(side TradeSide, amount float64) float64 {
+	var records = s.takeList(side)
+	var vwap, filled = records.VWAP(amount)
+	if filled == 0 {
+		return 0
+	}
+	var best = records[0].Price
+	return math.Abs(vwap-best) / best * 10000
+}
+
+// imbalance in [-1, 1], the positive means the bids are more than the asks.
+func (s depthSides) imbalance(levels int) float64 {
+	var bidAmount, askAmount = s.bids.Amount(levels), s.asks.Amount(levels)
+	if bidAmount+askAmount == 0 {
+		return 0
+	}
+	return (bidAmount - askAmount) / (bidAmount + askAmount)
+}
+
+func (s depthSides) diff(prev depthSides) ([]LevelChange, []LevelChange) {
+	return s.asks.Diff(prev.asks), s.bids.Diff(prev.bids)
+}
+
+func (depth *Depth) sides() depthSides {
+	return depthSides{asks: depth.AskList, bids: depth.BidList}
+}
+
+func (depth *Depth) Mid() float64 {
+	return depth.sides().mid()
+}
+
+func (depth *Depth) MicroPrice() float64 {
+	return depth.sides().microPrice()
+}
+
+func (depth *Depth) SpreadBps() float64 {
+	return depth.sides().spreadBps()
+}
+
+// AmountWithinBps the bid and ask amount within the bps from the mid.
+func (depth *Depth) AmountWithinBps(bps float64) (float64, float64) {
+	return depth.sides().amountWithinBps(bps)
+}
+
+func (depth *Depth) VWAP(side TradeSide, amount float64) (float64, float64) {
+	return depth.sides().vwap(side, amount)
+}
+
+func (depth *Depth) SlippageBps(side TradeSide, amount float64) float64 {
+	return depth.sides().slippageBps(side, amount)
+}
+
+func (depth *Depth) Imbalance(levels int) float64 {
+	return depth.sides().imbalance(levels)
+}
+
+// Diff the changed asks and bids from the prev snapshot.
+func (depth *Depth) Diff(prev *Depth) ([]LevelChange, []LevelChange) {
+	return depth.sides().diff(prev.sides())
+}
+
+func (depth *SwapDepth) sides() depthSides {
+	return depthSides{asks: depth.AskList, bids: depth.BidList}
+}
+
+func (depth *SwapDepth) Mid() float64 {
+	return depth.sides().mid()
+}
+
+func (depth *SwapDepth) MicroPrice() float64 {
+	return depth.sides().microPrice()
+}
+
+func (depth *SwapDepth) SpreadBps() float64 {
+	return depth.sides().spreadBps()
+}
+
+func (depth *SwapDepth) AmountWithinBps(bps float64) (float64, float64) {
+	return depth.sides().amountWithinBps(bps)
+}
+
+func (depth *SwapDepth) VWAP(side TradeSide, amount float64) (float64, float64) {
+	return depth.sides().vwap(side, amount)
+}
+
+func (depth *SwapDepth) SlippageBps(side TradeSide, amount float64) float64 {
+	return depth.sides().slippageBps(side, amount)
+}
+
+func (depth *SwapDepth) Imbalance(levels int) float64 {
+	return depth.sides().imbalance(levels)
+}
+
+func (depth *SwapDepth) Diff(prev *SwapDepth) ([]LevelChange, []LevelChange) {
+	return depth.sides().diff(prev.sides())
+}
+
+func (fd FutureDepth) sides() depthSides {
+	return depthSides{asks: fd.AskList, bids: fd.BidList}
+}
+
+func (fd FutureDepth) Mid() float64 {
+	return fd.sides().mid()
+}
+
+func (fd FutureDepth) MicroPrice() float64 {
+	return fd.sides().microPrice()
+}
+
+func (fd FutureDepth) SpreadBps() float64 {
+	return fd.sides().spreadBps()
+}
+
+func (fd FutureDepth) AmountWithinBps(bps float64) (float64, float64) {
+	return fd.sides().amountWithinBps(bps)
+}
+
+func (fd FutureDepth) VWAP(side TradeSide, amount float64) (float64, float64) {
+	return fd.sides().vwap(side, amount)
+}
+
+func (fd FutureDepth) SlippageBps(side TradeSide, amount float64) float64 {
+	return fd.sides().slippageBps(side, amount)
+}
+
+func (fd FutureDepth) Imbalance(levels int) float64 {
+	return fd.sides().imbalance(levels)
+}
+
+func (fd FutureDepth) Diff(prev FutureDepth) ([]LevelChange, []LevelChange) {
+	return fd.sides().diff(prev.sides())
+}
+
+func (depth *OneDepth) sides() depthSides {
+	return depthSides{asks: depth.AskList, bids: depth.BidList}
+}
+
+func (depth *OneDepth) Mid() float64 {
+	return depth.sides().mid()
+}
+
+func (depth *OneDepth) MicroPrice() float64 {
+	return depth.sides().microPrice()
+}
+
+func (depth *OneDepth) SpreadBps() float64 {
+	return depth.sides().spreadBps()
+}
+
+func (depth *OneDepth) AmountWithinBps(bps float64) (float64, float64) {
+	return depth.sides().amountWithinBps(bps)
+}
+
+func (depth *OneDepth) VWAP(side TradeSide, amount float64) (float64, float64) {
+	return depth.sides().vwap(side, amount)
+}
+
+func (depth *OneDepth) SlippageBps(side TradeSide, amount float64) float64 {
+	return depth.sides().slippageBps(side, amount)
+}
+
+func (depth *OneDepth) Imbalance(levels int) float64 {
+	return depth.sides().imbalance(levels)
+}
+
+func (depth *OneDepth) Diff(prev *OneDepth) ([]LevelChange, []LevelChange) {
+	return depth.sides().diff(prev.sides())
+}
@@ -0,0 +1,136 @@
+package goghostex
+
+import (
+	"math"
+	"testing"
+)
+
+func newTestDepth() *Depth {
+	return &Depth{
+		AskList: DepthRecords{{Price: 100.1, Amount: 1}, {Price: 100.2, Amount: 2}, {Price: 101, Amount: 4}},
+		BidList: DepthRecords{{Price: 99.9, Amount: 3}, {Price: 99.8, Amount: 1}, {Price: 99, Amount: 5}},
+	}
+}
+
+func nearly(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// go test -v ./ -count=1 -run=TestDepthRecords_Analytics
+func TestDepthRecords_Analytics(t *testing.T) {
+	var asks = newTestDepth().AskList
+	if amount := asks.Amount(2); amount != 3 {
+		t.Error("The amount of the top 2 levels is wrong. ", amount)
+	}
+	if amount := asks.Amount(0); amount != 7 {
+		t.Error("The amount of all the levels is wrong. ", amount)
+	}
+	// 100.2 is 20 bps from 100, 101 is 100 bps.
+	if amount := asks.AmountWithin(100, 20); amount != 3 {
+		t.Error("The amount within 20 bps is wrong. ", amount)
+	}
+
+	var vwap, filled = asks.VWAP(2)
+	if !nearly(vwap, 100.15) || filled != 2 {
+		t.Error("The vwap is wrong. ", vwap, filled)
+	}
+	vwap, filled = asks.VWAP(10)
+	if !nearly(vwap, (100.1+200.4+404)/7) || filled != 7 {
+		t.Error("The vwap should fill all the levels. ", vwap, filled)
+	}
+	if vwap, filled = (DepthRecords{}).VWAP(1); vwap != 0 || filled != 0 {
+		t.Error("The vwap of empty records is wrong. ", vwap, filled)
+	}
+}
+
+// go test -v ./ -count=1 -run=TestDepthRecords_Diff
+func TestDepthRecords_Diff(t *testing.T) {
+	var prev = DepthRecords{{Price: 100.1, Amount: 1}, {Price: 100.2, Amount: 2}, {Price: 101, Amount: 4}}
+	var curr = DepthRecords{{Price: 100, Amount: 0.5}, {Price: 100.1, Amount: 1}, {Price: 100.2, Amount: 1.5}}
+
+	var changes = curr.Diff(prev)
+	var expects = []LevelChange{
+		{Price: 100, Amount: 0.5, PrevAmount: 0, Delta: 0.5},
+		{Price: 100.2, Amount: 1.5, PrevAmount: 2, Delta: -0.5},
+		{Price: 101, Amount: 0, PrevAmount: 4, Delta: -4},
+	}
+	if len(changes) != len(expects) {
+		t.Fatal("The changes are wrong. ", changes)
+	}
+	for i, expect := range expects {
+		if changes[i] != expect {
+			t.Error("The change is wrong. ", i, changes[i])
+		}
+	}
+	if changes = prev.Diff(prev); len(changes) != 0 {
+		t.Error("The same records should not change. ", changes)
+	}
+}
+
+// go test -v ./ -count=1 -run=TestDepth_Analytics
+func TestDepth_Analytics(t *testing.T) {
+	var depth = newTestDepth()
+	if mid := depth.Mid(); !nearly(mid, 100) {
+		t.Error("The mid is wrong. ", mid)
+	}
+	// the bid amount is more, the micro price is close to the ask.
+	if micro := depth.MicroPrice(); !nearly(micro, (100.1*3+99.9*1)/4) {
+		t.Error("The micro price is wrong. ", micro)
+	}
+	if spread := depth.SpreadBps(); !nearly(spread, 20) {
+		t.Error("The spread is wrong. ", spread)
+	}
+	if bid, ask := depth.AmountWithinBps(20); bid != 4 || ask != 3 {
+		t.Error("The amount within 20 bps is wrong. ", bid, ask)
+	}
+
+	var vwap, filled = depth.VWAP(SELL, 4)
+	if !nearly(vwap, (99.9*3+99.8)/4) || filled != 4 {
+		t.Error("The sell vwap is wrong. ", vwap, filled)
+	}
+	if slippage := depth.SlippageBps(BUY, 3); !nearly(slippage, ((100.1+200.4)/3-100.1)/100.1*10000) {
+		t.Error("The buy slippage is wrong. ", slippage)
+	}
+	if slippage := depth.SlippageBps(SELL, 3); !nearly(slippage, 0) {
+		t.Error("The sell in the best level has no slippage. ", slippage)
+	}
+	if imbalance := depth.Imbalance(1); !nearly(imbalance, 0.5) {
+		t.Error("The imbalance of the top level is wrong. ", imbalance)
+	}
+	if imbalance := (&Depth{}).Imbalance(0); imbalance != 0 {
+		t.Error("The imbalance of the empty depth is wrong. ", imbalance)
+	}
+	if (&Depth{BidList: depth.BidList}).Mid() != 0 {
+		t.Error("The mid of the one side depth should be zero. ")
+	}
+
+	var prev = newTestDepth()
+	prev.BidList = prev.BidList[1:]
+	var askChanges, bidChanges = depth.Diff(prev)
+	if len(askChanges) != 0 || len(bidChanges) != 1 || bidChanges[0].Price != 99.9 || bidChanges[0].Delta != 3 {
+		t.Error("The diff is wrong. ", askChanges, bidChanges)
+	}
+}
+
+// go test -v ./ -count=1 -run=TestDepthTypes_Analytics
+func TestDepthTypes_Analytics(t *testing.T) {
+	var depth = newTestDepth()
+	var swapDepth = &SwapDepth{AskList: depth.AskList, BidList: depth.BidList}
+	var futureDepth = FutureDepth{AskList: depth.AskList, BidList: depth.BidList}
+	var oneDepth = &OneDepth{AskList: depth.AskList, BidList: depth.BidList}
+
+	for name, mid := range map[string]float64{
+		"swap": swapDepth.Mid(), "future": futureDepth.Mid(), "one": oneDepth.Mid(),
+	} {
+		if !nearly(mid, depth.Mid()) {
+			t.Error("The mid is wrong. ", name, mid)
+		}
+	}
+	if swapDepth.SpreadBps() != depth.SpreadBps() || futureDepth.MicroPrice() != depth.MicroPrice() ||
+		oneDepth.Imbalance(2) != depth.Imbalance(2) {
+		t.Error("The analytics of the depth types should be the same. ")
+	}
+	if asks, bids := oneDepth.Diff(oneDepth); len(asks) != 0 || len(bids) != 0 {
+		t.Error("The same depth should not change. ", asks, bids)
+	}
+}
@@ -1,15 +1,78 @@
 package goghostex
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
 
+// Error the code is one of the ERR_CODE_* of the category, or the code given in the NewError.
 type Error interface {
 	error
 	Code() int
 }
 
+// ExchangeError is the error from the exchange, the category is one of the Err* below, ErrUnknown when it's not
+// known, so the caller can branch by errors.Is(err, ErrInsufficientBalance) instead of matching the message.
+// The Code is the code of the category, the HttpStatus is the status of the response.
+type ExchangeError interface {
+	Error
+	Category() Error
+	Exchange() string
+	ExchangeCode() string // the error code of the exchange, eg: okex 51008 binance -2019 kraken EOrder:Insufficient funds
+	HttpStatus() int
+	Response() string // the raw response body
+}
+
+const (
+	ERR_CODE_UNKNOWN              = 1000
+	ERR_CODE_INSUFFICIENT_BALANCE = 1001
+	ERR_CODE_ORDER_NOT_FOUND      = 1002
+	ERR_CODE_RATE_LIMITED         = 1003
+	ERR_CODE_INVALID_PRICE        = 1004
+	ERR_CODE_POST_ONLY_REJECTED   = 1005
+	ERR_CODE_AUTH_FAILED          = 1006
+	ERR_CODE_EXCHANGE_UNAVAILABLE = 1007
+	ERR_CODE_INVALID_REQUEST      = 1008
+	ERR_CODE_ORDER_CLOSED         = 1009
+)
+
+// the categories of the exchange error, they're not the ExchangeError.
+var (
+	ErrUnknown             Error = &errorCategory{code: ERR_CODE_UNKNOWN, message: "unknown error"}
+	ErrInsufficientBalance Error = &errorCategory{code: ERR_CODE_INSUFFICIENT_BALANCE, message: "insufficient balance"}
+	ErrOrderNotFound       Error = &errorCategory{code: ERR_CODE_ORDER_NOT_FOUND, message: "order not found"}
+	ErrRateLimited         Error = &errorCategory{code: ERR_CODE_RATE_LIMITED, message: "rate limited"}
+	ErrInvalidPrice        Error = &errorCategory{code: ERR_CODE_INVALID_PRICE, message: "invalid price"}
+	ErrPostOnlyRejected    Error = &errorCategory{code: ERR_CODE_POST_ONLY_REJECTED, message: "post only rejected"}
+	ErrAuthFailed          Error = &errorCategory{code: ERR_CODE_AUTH_FAILED, message: "auth failed"}
+	ErrExchangeUnavailable Error = &errorCategory{code: ERR_CODE_EXCHANGE_UNAVAILABLE, message: "exchange unavailable"}
+	ErrInvalidRequest      Error = &errorCategory{code: ERR_CODE_INVALID_REQUEST, message: "invalid request"}
+	ErrOrderClosed         Error = &errorCategory{code: ERR_CODE_ORDER_CLOSED, message: "order closed"} // the order is filled or cancelled already
+)
+
+type errorCategory struct {
+	code    int
+	message string
+}
+
+func (this *errorCategory) Error() string {
+	return this.message
+}
+
+func (this *errorCategory) Code() int {
+	return this.code
+}
+
 type apiError struct {
 	code    int
 	message string
+
+	category     Error
+	exchange     string
+	exchangeCode string
+	httpStatus   int
+	response     string
 }
 
 func (this *apiError) Error() string {
@@ -20,10 +83,100 @@ func (this *apiError) Code() int {
 	return this.code
 }
 
+func (this *apiError) Category() Error {
+	if this.category == nil {
+		return ErrUnknown
+	}
+	return this.category
+}
+
+func (this *apiError) Exchange() string {
+	return this.exchange
+}
+
+func (this *apiError) ExchangeCode() string {
+	return this.exchangeCode
+}
+
+func (this *apiError) HttpStatus() int {
+	return this.httpStatus
+}
+
+func (this *apiError) Response() string {
+	return this.response
+}
+
+// Unwrap return the category, then errors.Is(err, ErrRateLimited) works, it's ErrUnknown without the category.
+func (this *apiError) Unwrap() error {
+	return this.Category()
+}
+
 // New creates a new API error with a code and a message
 func NewError(code int, message string, args ...interface{}) Error {
 	if len(args) > 0 {
-		return &apiError{code, fmt.Sprintf(message, args...)}
+		return &apiError{code: code, message: fmt.Sprintf(message, args...)}
+	}
+	return &apiError{code: code, message: message}
+}
+
+// NewHttpError the error of the non 2xx response, the category is decided by the http status.
+// The code is the code of the category, the http status is in the HttpStatus.
+func NewHttpError(httpStatus int, message, response string) Error {
+	var category Error
+	switch {
+	case httpStatus == http.StatusTooManyRequests || httpStatus == 418: // binance return 418 when the ip is banned
+		category = ErrRateLimited
+	case httpStatus == http.StatusUnauthorized || httpStatus == http.StatusForbidden:
+		category = ErrAuthFailed
+	case httpStatus >= 500:
+		category = ErrExchangeUnavailable
+	}
+	var code = ERR_CODE_UNKNOWN
+	if category != nil {
+		code = category.Code()
+	}
+	return &apiError{
+		code:       code,
+		message:    message,
+		category:   category,
+		httpStatus: httpStatus,
+		response:   response,
+	}
+}
+
+// NewExchangeError build the error by the error code table of the exchange.
+// The cause is the error from NewHttpRequest if exist, its http status, response and category are inherited.
+func NewExchangeError(exchange string, codes map[string]Error, exchangeCode, message string, cause error) Error {
+	var err = &apiError{
+		code:         ERR_CODE_UNKNOWN,
+		message:      message,
+		exchange:     exchange,
+		exchangeCode: exchangeCode,
+	}
+
+	var httpErr *apiError
+	if errors.As(cause, &httpErr) {
+		err.httpStatus = httpErr.httpStatus
+		err.response = httpErr.response
+		err.category = httpErr.category
+		if message == "" {
+			err.message = httpErr.message
+		}
+	}
+	if category, exist := codes[exchangeCode]; exist {
+		err.category = category
+	}
+	if err.category != nil {
+		err.code = err.category.Code()
+	}
+	return err
+}
+
+// HttpErrorResponse return the response body of the error from NewHttpRequest.
+func HttpErrorResponse(err error) (string, bool) {
+	var httpErr *apiError
+	if errors.As(err, &httpErr) && httpErr.response != "" {
+		return httpErr.response, true
 	}
-	return &apiError{code, message}
+	return "", false
 }
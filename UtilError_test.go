@@ -0,0 +1,90 @@
+package goghostex
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v ./ -count=1 -run=TestNewHttpError
+func TestNewHttpError(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"code":-1003,"msg":"Too many requests."}`))
+	}))
+	defer server.Close()
+
+	var _, err = NewHttpRequest(&http.Client{}, http.MethodGet, server.URL, "", nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatal("The 429 should be rate limited: ", err)
+	}
+	var exchangeErr ExchangeError
+	if !errors.As(err, &exchangeErr) || exchangeErr.HttpStatus() != http.StatusTooManyRequests {
+		t.Error("The http status is wrong: ", err)
+	}
+	if body, exist := HttpErrorResponse(err); !exist || body != `{"code":-1003,"msg":"Too many requests."}` {
+		t.Error("The response is wrong: ", body)
+	}
+
+	if exchangeErr.Code() != ERR_CODE_RATE_LIMITED {
+		t.Error("The code should be the code of the category: ", exchangeErr.Code())
+	}
+
+	// the 400 has no category without the exchange code, it's the unknown.
+	var badRequest = NewHttpError(http.StatusBadRequest, "bad request", "")
+	if !errors.Is(badRequest, ErrUnknown) || badRequest.Code() != ERR_CODE_UNKNOWN ||
+		badRequest.(ExchangeError).Category() != ErrUnknown || badRequest.(ExchangeError).HttpStatus() != http.StatusBadRequest {
+		t.Error("The 400 should be the unknown: ", badRequest)
+	}
+	if errors.Is(badRequest, ErrRateLimited) {
+		t.Error("The 400 should not be rate limited. ")
+	}
+}
+
+// go test -v ./ -count=1 -run=TestErrorCategory
+func TestErrorCategory(t *testing.T) {
+	var exchangeErr ExchangeError
+	if errors.As(ErrInsufficientBalance, &exchangeErr) {
+		t.Error("The category should not be the ExchangeError. ")
+	}
+	if _, exist := HttpErrorResponse(ErrRateLimited); exist {
+		t.Error("The category has no response. ")
+	}
+	if !errors.Is(NewError(400, "cancel fail"), ErrUnknown) {
+		t.Error("The error without the category should be the unknown. ")
+	}
+}
+
+// go test -v ./ -count=1 -run=TestNewExchangeError
+func TestNewExchangeError(t *testing.T) {
+	var codes = map[string]Error{"51008": ErrInsufficientBalance}
+
+	var err = NewExchangeError(OKEX, codes, "51008", "Insufficient balance", nil)
+	if !errors.Is(err, ErrInsufficientBalance) || errors.Is(err, ErrRateLimited) {
+		t.Error("The category is wrong: ", err)
+	}
+	if err.Code() != ERR_CODE_INSUFFICIENT_BALANCE || err.Error() != "Insufficient balance" {
+		t.Error("The code or message is wrong: ", err.Code(), err.Error())
+	}
+
+	// the code table override the category of the http status.
+	var cause = NewHttpError(http.StatusServiceUnavailable, "busy", `{"code":"51008"}`)
+	var exchangeErr ExchangeError
+	if !errors.As(NewExchangeError(OKEX, codes, "51008", "", cause), &exchangeErr) {
+		t.Fatal("The error should be the ExchangeError. ")
+	}
+	if exchangeErr.Category() != ErrInsufficientBalance || exchangeErr.Exchange() != OKEX ||
+		exchangeErr.ExchangeCode() != "51008" || exchangeErr.HttpStatus() != http.StatusServiceUnavailable ||
+		exchangeErr.Response() != `{"code":"51008"}` || exchangeErr.Error() != "busy" {
+		t.Error("The exchange error is wrong: ", exchangeErr)
+	}
+
+	// the unknown code inherit the category of the http status.
+	if !errors.Is(NewExchangeError(OKEX, codes, "99999", "", cause), ErrExchangeUnavailable) {
+		t.Error("The unknown code should inherit the http category. ")
+	}
+	if NewExchangeError(OKEX, codes, "99999", "", nil).(ExchangeError).Category() != ErrUnknown {
+		t.Error("The unknown code should be the unknown category. ")
+	}
+}
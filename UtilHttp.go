@@ -1,7 +1,6 @@
 package goghostex
 
 import (
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -15,6 +14,18 @@ func NewHttpRequest(
 	postData string,
 	reqHeaders map[string]string,
 ) ([]byte, error) {
+	var body, _, err = NewHttpRequestWithHeader(client, reqType, reqUrl, postData, reqHeaders)
+	return body, err
+}
+
+// NewHttpRequestWithHeader return the response header too, it's returned with the non 2xx error.
+func NewHttpRequestWithHeader(
+	client *http.Client,
+	reqType,
+	reqUrl,
+	postData string,
+	reqHeaders map[string]string,
+) ([]byte, http.Header, error) {
 
 	var req *http.Request
 	if strings.ToUpper(reqType) == http.MethodGet {
@@ -37,14 +48,14 @@ func NewHttpRequest(
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer resp.Body.Close()
 
 	bodyData, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, resp.Header, err
 	}
 
 	successCode := map[int]string{
@@ -54,15 +65,15 @@ func NewHttpRequest(
 	}
 
 	if _, exist := successCode[resp.StatusCode]; !exist {
-		return nil, errors.New(fmt.Sprintf(
+		return nil, resp.Header, NewHttpError(resp.StatusCode, fmt.Sprintf(
 			"HttpStatusCode: %d, HttpMethod: %s, Response: %s, Request: %s, Url: %s",
 			resp.StatusCode,
 			reqType,
 			string(bodyData),
 			postData,
 			reqUrl,
-		))
+		), string(bodyData))
 	}
 
-	return bodyData, nil
+	return bodyData, resp.Header, nil
 }
@@ -0,0 +1,345 @@
+package goghostex
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// the max size of the kline page in the exchange market, the exchange not in it use the DEFAULT_BACKFILL_PAGE_SIZE.
+var BackfillPageSize = map[string]int{
+	OKEX + ":" + TRADE_TYPE_SPOT:      300,
+	OKEX + ":" + TRADE_TYPE_SWAP:      100,
+	OKEX + ":" + TRADE_TYPE_FUTURE:    300,
+	BINANCE + ":" + TRADE_TYPE_SPOT:   1000,
+	BINANCE + ":" + TRADE_TYPE_SWAP:   1500,
+	BINANCE + ":" + TRADE_TYPE_FUTURE: 1500,
+	COINBASE + ":" + TRADE_TYPE_SPOT:  300,
+	GATE + ":" + TRADE_TYPE_SPOT:      1000,
+	GATE + ":" + TRADE_TYPE_SWAP:      1000,
+	KRAKEN + ":" + TRADE_TYPE_SWAP:    2000,
+	BITSTAMP + ":" + TRADE_TYPE_SPOT:  1000,
+}
+
+const DEFAULT_BACKFILL_PAGE_SIZE = 100
+
+// KlineGap is the missing klines in [Start, End), unit: ms.
+type KlineGap struct {
+	Start int64
+	End   int64
+}
+
+// KlineBackfillPage is the new klines of one page in ascending order, only the list of the market is filled.
+// The gaps are found between the klines of the page and the pages before, the gap left at last is in the
+// last page without klines.
+type KlineBackfillPage struct {
+	Klines       []*Kline
+	SwapKlines   []*SwapKline
+	FutureKlines []*FutureKline
+	Gaps         []KlineGap
+}
+
+// KlineBackfill page the kline history in [Start, End) by the since of the rest api, the since and size of
+// the exchanges are different, the klines of every page are filtered in the range, deduplicated and ordered.
+// Some exchange return the latest klines after the since, eg: okex, the api of them implement the
+// SpotKlineBeforeAPI, SwapKlineBeforeAPI or FutureKlineBeforeAPI, then the pages are from the End back to the Start.
+type KlineBackfill struct {
+	Spot         SpotRestAPI
+	Swap         SwapRestAPI
+	Future       FutureRestAPI
+	ContractType string // for future
+
+	Pair     Pair
+	Period   int
+	Start    int64         // unit: ms, included
+	End      int64         // unit: ms, excluded, the zero is now
+	PageSize int           // the zero is the size in BackfillPageSize
+	Interval time.Duration // the wait between the pages
+}
+
+func NewSpotKlineBackfill(spot SpotRestAPI, pair Pair, period int, start, end int64) *KlineBackfill {
+	return &KlineBackfill{Spot: spot, Pair: pair, Period: period, Start: start, End: end}
+}
+
+func NewSwapKlineBackfill(swap SwapRestAPI, pair Pair, period int, start, end int64) *KlineBackfill {
+	return &KlineBackfill{Swap: swap, Pair: pair, Period: period, Start: start, End: end}
+}
+
+func NewFutureKlineBackfill(future FutureRestAPI, contractType string, pair Pair, period int, start, end int64) *KlineBackfill {
+	return &KlineBackfill{Future: future, ContractType: contractType, Pair: pair, Period: period, Start: start, End: end}
+}
+
+func (b *KlineBackfill) market() (string, string, error) {
+	if b.Spot != nil {
+		return b.Spot.GetExchangeName(), TRADE_TYPE_SPOT, nil
+	}
+	if b.Swap != nil {
+		return b.Swap.GetExchangeName(), TRADE_TYPE_SWAP, nil
+	}
+	if b.Future != nil {
+		return b.Future.GetExchangeName(), TRADE_TYPE_FUTURE, nil
+	}
+	return "", "", errors.New("The spot, swap or future api is required in the backfill. ")
+}
+
+// backward is true when the api get the klines before the end.
+func (b *KlineBackfill) backward() bool {
+	if b.Spot != nil {
+		var _, ok = b.Spot.(SpotKlineBeforeAPI)
+		return ok
+	}
+	if b.Swap != nil {
+		var _, ok = b.Swap.(SwapKlineBeforeAPI)
+		return ok
+	}
+	var _, ok = b.Future.(FutureKlineBeforeAPI)
+	return ok
+}
+
+// fetchPage get the klines after the cursor, or before it in the backward,
+// the timestamps are in the same order of the page.
+func (b *KlineBackfill) fetchPage(cursor int64, size int, backward bool) (*KlineBackfillPage, []int64, error) {
+	var page = &KlineBackfillPage{}
+	var timestamps = make([]int64, 0, size)
+	if b.Spot != nil {
+		var klines []*Kline
+		var err error
+		if backward {
+			klines, _, err = b.Spot.(SpotKlineBeforeAPI).GetKlineRecordsBefore(b.Pair, b.Period, size, int(cursor))
+		} else {
+			klines, _, err = b.Spot.GetKlineRecords(b.Pair, b.Period, size, int(cursor))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		page.Klines = GetAscKline(klines)
+		for _, kline := range page.Klines {
+			timestamps = append(timestamps, kline.Timestamp)
+		}
+	} else if b.Swap != nil {
+		var klines []*SwapKline
+		var err error
+		if backward {
+			klines, _, err = b.Swap.(SwapKlineBeforeAPI).GetKlineBefore(b.Pair, b.Period, size, int(cursor))
+		} else {
+			klines, _, err = b.Swap.GetKline(b.Pair, b.Period, size, int(cursor))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		page.SwapKlines = GetAscSwapKline(klines)
+		for _, kline := range page.SwapKlines {
+			timestamps = append(timestamps, kline.Timestamp)
+		}
+	} else {
+		var klines []*FutureKline
+		var err error
+		if backward {
+			klines, _, err = b.Future.(FutureKlineBeforeAPI).GetKlineRecordsBefore(
+				b.ContractType, b.Pair, b.Period, size, int(cursor),
+			)
+		} else {
+			klines, _, err = b.Future.GetKlineRecords(b.ContractType, b.Pair, b.Period, size, int(cursor))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		page.FutureKlines = GetAscFutureKline(klines)
+		for _, kline := range page.FutureKlines {
+			timestamps = append(timestamps, kline.Timestamp)
+		}
+	}
+	return page, timestamps, nil
+}
+
+// keep the klines of the indexes in the page.
+func (page *KlineBackfillPage) keep(indexes []int) {
+	if page.Klines != nil {
+		var klines = make([]*Kline, 0, len(indexes))
+		for _, i := range indexes {
+			klines = append(klines, page.Klines[i])
+		}
+		page.Klines = klines
+	}
+	if page.SwapKlines != nil {
+		var klines = make([]*SwapKline, 0, len(indexes))
+		for _, i := range indexes {
+			klines = append(klines, page.SwapKlines[i])
+		}
+		page.SwapKlines = klines
+	}
+	if page.FutureKlines != nil {
+		var klines = make([]*FutureKline, 0, len(indexes))
+		for _, i := range indexes {
+			klines = append(klines, page.FutureKlines[i])
+		}
+		page.FutureKlines = klines
+	}
+}
+
+// Run call the handler with every page until the end, the error of the handler stop the backfill.
+func (b *KlineBackfill) Run(handler func(*KlineBackfillPage) error) error {
+	var exchange, market, err = b.market()
+	if err != nil {
+		return err
+	}
+	var periodMs, exist = PeriodMillisecond[b.Period]
+	if !exist {
+		return fmt.Errorf("The period %d is not supported in the backfill. ", b.Period)
+	}
+	var end = b.End
+	if end <= 0 {
+		end = time.Now().UnixMilli()
+	}
+	if b.Start >= end {
+		return fmt.Errorf("The start %d is not before the end %d. ", b.Start, end)
+	}
+	var pageSize = b.PageSize
+	if pageSize <= 0 {
+		if pageSize, exist = BackfillPageSize[exchange+":"+market]; !exist {
+			pageSize = DEFAULT_BACKFILL_PAGE_SIZE
+		}
+	}
+
+	// the kline in progress is not a gap.
+	var closed = end
+	if now := time.Now().UnixMilli(); closed > now-periodMs {
+		closed = now - periodMs
+	}
+	if b.backward() {
+		return b.runBackward(handler, end, closed, periodMs, pageSize)
+	}
+
+	// the next kline expected, the gap is found when the kline is after it.
+	var cursor, expected = b.Start, b.Start
+	for cursor < end {
+		var page, timestamps, err = b.fetchPage(cursor, pageSize, false)
+		if err != nil {
+			return err
+		}
+
+		var unique = uniqueInRange(timestamps, cursor, end)
+		if len(unique) == 0 {
+			// the exchange with the window of from and to return nothing in the missing window, eg: gate,
+			// the cursor goes on to the next window until the end, the gap is found by the next kline.
+			cursor += int64(pageSize) * periodMs
+			if b.Interval > 0 && cursor < end {
+				time.Sleep(b.Interval)
+			}
+			continue
+		}
+
+		page.keep(unique)
+		for _, i := range unique {
+			if timestamps[i] > expected {
+				page.Gaps = append(page.Gaps, KlineGap{Start: expected, End: timestamps[i]})
+			}
+			expected = timestamps[i] + periodMs
+		}
+		cursor = expected
+		// the last kline may be not closed, the missing klines after it are not the gap.
+		if cursor >= end || cursor+periodMs > time.Now().UnixMilli() {
+			return handler(page)
+		}
+		if err := handler(page); err != nil {
+			return err
+		}
+		if b.Interval > 0 {
+			time.Sleep(b.Interval)
+		}
+	}
+
+	if expected < closed {
+		return handler(&KlineBackfillPage{Gaps: []KlineGap{{Start: expected, End: end}}})
+	}
+	return nil
+}
+
+// runBackward page from the end back to the start, the klines of the page are still ascending.
+func (b *KlineBackfill) runBackward(
+	handler func(*KlineBackfillPage) error,
+	end, closed, periodMs int64,
+	pageSize int,
+) error {
+	// the first kline of the pages before, the gap is found when the kline is before it.
+	var cursor, upper = end, end
+	for cursor > b.Start {
+		var page, timestamps, err = b.fetchPage(cursor, pageSize, true)
+		if err != nil {
+			return err
+		}
+
+		var unique = uniqueInRange(timestamps, b.Start, cursor)
+		if len(unique) == 0 {
+			break
+		}
+
+		page.keep(unique)
+		for n := len(unique) - 1; n >= 0; n-- {
+			var next = timestamps[unique[n]] + periodMs
+			// the missing klines in progress after the last kline are not the gap.
+			if next < upper && (upper != end || next < closed) {
+				page.Gaps = append([]KlineGap{{Start: next, End: upper}}, page.Gaps...)
+			}
+			upper = timestamps[unique[n]]
+		}
+		cursor = upper
+		if cursor <= b.Start {
+			return handler(page)
+		}
+		if err := handler(page); err != nil {
+			return err
+		}
+		if b.Interval > 0 {
+			time.Sleep(b.Interval)
+		}
+	}
+
+	if upper > b.Start && (upper != end || b.Start < closed) {
+		return handler(&KlineBackfillPage{Gaps: []KlineGap{{Start: b.Start, End: upper}}})
+	}
+	return nil
+}
+
+// uniqueInRange the indexes of the timestamps in [start, end), sorted ascending and deduplicated.
+func uniqueInRange(timestamps []int64, start, end int64) []int {
+	var indexes = make([]int, 0, len(timestamps))
+	for i, ts := range timestamps {
+		if ts >= start && ts < end {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool { return timestamps[indexes[i]] < timestamps[indexes[j]] })
+	var unique = make([]int, 0, len(indexes))
+	for _, i := range indexes {
+		if len(unique) > 0 && timestamps[unique[len(unique)-1]] == timestamps[i] {
+			continue
+		}
+		unique = append(unique, i)
+	}
+	return unique
+}
+
+var ErrBackfillStopped = errors.New("The backfill is stopped. ")
+
+// Stream run the backfill in the goroutine, the pages channel is closed after the backfill finished,
+// then the error channel has the result, nil means success. Close the done to stop the backfill and the
+// goroutine, the result is ErrBackfillStopped then, the nil done never stops.
+func (b *KlineBackfill) Stream(done <-chan struct{}, size int) (<-chan *KlineBackfillPage, <-chan error) {
+	var pages = make(chan *KlineBackfillPage, size)
+	var result = make(chan error, 1)
+	go func() {
+		var err = b.Run(func(page *KlineBackfillPage) error {
+			select {
+			case pages <- page:
+				return nil
+			case <-done:
+				return ErrBackfillStopped
+			}
+		})
+		close(pages)
+		result <- err
+	}()
+	return pages, result
+}
@@ -0,0 +1,235 @@
+package goghostex
+
+import (
+	"testing"
+	"time"
+)
+
+const backfillStart = int64(1700000000000) // the multiple of the minute
+
+// fakeKlineSpot return the klines from the since in ascending order, the missing timestamps are not in the history.
+type fakeKlineSpot struct {
+	SpotRestAPI
+	missing map[int64]bool
+	last    int64
+	calls   int
+}
+
+func (this *fakeKlineSpot) GetExchangeName() string {
+	return BINANCE
+}
+
+func (this *fakeKlineSpot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline, []byte, error) {
+	this.calls++
+	var klines = make([]*Kline, 0, size)
+	// the first kline is duplicated with the last page.
+	for ts := int64(since) - 60*1000; ts <= this.last && len(klines) < size; ts += 60 * 1000 {
+		if ts < backfillStart || this.missing[ts] {
+			continue
+		}
+		klines = append(klines, &Kline{Pair: pair, Timestamp: ts, Close: float64(ts)})
+	}
+	return klines, nil, nil
+}
+
+// fakeWindowSpot return the klines in the window of [since, since+size*period), like gate with from and to.
+type fakeWindowSpot struct {
+	SpotRestAPI
+	missing map[int64]bool
+	last    int64
+}
+
+func (this *fakeWindowSpot) GetExchangeName() string {
+	return GATE
+}
+
+func (this *fakeWindowSpot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline, []byte, error) {
+	var klines = make([]*Kline, 0, size)
+	for ts := int64(since); ts < int64(since)+int64(size)*60*1000 && ts <= this.last; ts += 60 * 1000 {
+		if this.missing[ts] {
+			continue
+		}
+		klines = append(klines, &Kline{Pair: pair, Timestamp: ts})
+	}
+	return klines, nil, nil
+}
+
+// fakeKlineSwap return the latest klines after the since in descending order, like okex,
+// the history is paged backward by the klines before the end.
+type fakeKlineSwap struct {
+	SwapRestAPI
+	missing map[int64]bool
+	last    int64
+	calls   int
+}
+
+func (this *fakeKlineSwap) GetExchangeName() string {
+	return OKEX
+}
+
+func (this *fakeKlineSwap) GetKline(pair Pair, period, size, since int) ([]*SwapKline, []byte, error) {
+	var klines = make([]*SwapKline, 0, size)
+	for ts := this.last; ts >= int64(since) && len(klines) < size; ts -= 60 * 1000 {
+		klines = append(klines, &SwapKline{Pair: pair, Timestamp: ts})
+	}
+	return klines, nil, nil
+}
+
+func (this *fakeKlineSwap) GetKlineBefore(pair Pair, period, size, end int) ([]*SwapKline, []byte, error) {
+	this.calls++
+	var klines = make([]*SwapKline, 0, size)
+	// the history is longer than the backfill, the kline at the end is wrongly included.
+	for ts := int64(end); ts >= backfillStart-10*60*1000 && len(klines) < size; ts -= 60 * 1000 {
+		if ts > this.last || this.missing[ts] {
+			continue
+		}
+		klines = append(klines, &SwapKline{Pair: pair, Timestamp: ts})
+	}
+	return klines, nil, nil
+}
+
+// go test -v ./... -count=1 -run=TestKlineBackfill_Spot
+func TestKlineBackfill_Spot(t *testing.T) {
+	var minute = int64(60 * 1000)
+	var spot = &fakeKlineSpot{
+		missing: map[int64]bool{backfillStart + 4*minute: true, backfillStart + 5*minute: true},
+		last:    backfillStart + 9*minute,
+	}
+	var backfill = NewSpotKlineBackfill(spot, Pair{Basis: BTC, Counter: USDT}, KLINE_PERIOD_1MIN, backfillStart, backfillStart+12*minute)
+	backfill.PageSize = 3
+
+	var pages, result = backfill.Stream(nil, 4)
+	var timestamps = make([]int64, 0)
+	var gaps = make([]KlineGap, 0)
+	for page := range pages {
+		for _, kline := range page.Klines {
+			timestamps = append(timestamps, kline.Timestamp)
+		}
+		gaps = append(gaps, page.Gaps...)
+	}
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+
+	if len(timestamps) != 8 {
+		t.Fatal("The klines should be deduplicated: ", timestamps)
+	}
+	for i := 1; i < len(timestamps); i++ {
+		if timestamps[i] <= timestamps[i-1] {
+			t.Fatal("The klines should be ascending: ", timestamps)
+		}
+	}
+	if len(gaps) != 2 ||
+		gaps[0] != (KlineGap{Start: backfillStart + 4*minute, End: backfillStart + 6*minute}) ||
+		gaps[1] != (KlineGap{Start: backfillStart + 10*minute, End: backfillStart + 12*minute}) {
+		t.Error("The gaps are wrong: ", gaps)
+	}
+}
+
+// go test -v ./... -count=1 -run=TestKlineBackfill_Backward
+func TestKlineBackfill_Backward(t *testing.T) {
+	var minute = int64(60 * 1000)
+	var swap = &fakeKlineSwap{
+		missing: map[int64]bool{backfillStart + 40*minute: true, backfillStart + 41*minute: true},
+		last:    backfillStart + 99*minute,
+	}
+	var backfill = NewSwapKlineBackfill(swap, Pair{Basis: BTC, Counter: USDT}, KLINE_PERIOD_1MIN, backfillStart, backfillStart+102*minute)
+	backfill.PageSize = 10
+
+	var timestamps = make(map[int64]bool)
+	var gaps = make([]KlineGap, 0)
+	var prev = backfillStart + 102*minute
+	var err = backfill.Run(func(page *KlineBackfillPage) error {
+		for i, kline := range page.SwapKlines {
+			if i > 0 && kline.Timestamp <= page.SwapKlines[i-1].Timestamp {
+				t.Error("The swap klines should be ascending. ")
+			}
+			timestamps[kline.Timestamp] = true
+		}
+		if len(page.SwapKlines) > 0 {
+			if page.SwapKlines[len(page.SwapKlines)-1].Timestamp >= prev {
+				t.Error("The pages should be from the end to the start. ")
+			}
+			prev = page.SwapKlines[0].Timestamp
+		}
+		gaps = append(gaps, page.Gaps...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(timestamps) != 98 || !timestamps[backfillStart] || !timestamps[backfillStart+99*minute] {
+		t.Error("The full range should be returned: ", len(timestamps))
+	}
+	if swap.calls != 11 {
+		t.Error("The pages are wrong: ", swap.calls)
+	}
+	var expected = map[KlineGap]bool{
+		{Start: backfillStart + 40*minute, End: backfillStart + 42*minute}:   true,
+		{Start: backfillStart + 100*minute, End: backfillStart + 102*minute}: true,
+	}
+	if len(gaps) != 2 || !expected[gaps[0]] || !expected[gaps[1]] {
+		t.Error("The gaps are wrong: ", gaps)
+	}
+
+	backfill.Period = 0
+	if err := backfill.Run(func(*KlineBackfillPage) error { return nil }); err == nil {
+		t.Error("The unknown period should be an error. ")
+	}
+}
+
+// go test -v ./... -count=1 -run=TestKlineBackfill_EmptyWindow
+func TestKlineBackfill_EmptyWindow(t *testing.T) {
+	var minute = int64(60 * 1000)
+	var missing = make(map[int64]bool)
+	for ts := backfillStart + 3*minute; ts < backfillStart+10*minute; ts += minute {
+		missing[ts] = true
+	}
+	var spot = &fakeWindowSpot{missing: missing, last: backfillStart + 14*minute}
+	var backfill = NewSpotKlineBackfill(spot, Pair{Basis: BTC, Counter: USDT}, KLINE_PERIOD_1MIN, backfillStart, backfillStart+15*minute)
+	backfill.PageSize = 3
+
+	var count = 0
+	var gaps = make([]KlineGap, 0)
+	var err = backfill.Run(func(page *KlineBackfillPage) error {
+		count += len(page.Klines)
+		gaps = append(gaps, page.Gaps...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the empty windows in [3, 9) are skipped, the klines after them are still paged.
+	if count != 8 {
+		t.Error("The klines after the empty window are missing: ", count)
+	}
+	if len(gaps) != 1 || gaps[0] != (KlineGap{Start: backfillStart + 3*minute, End: backfillStart + 10*minute}) {
+		t.Error("The gaps are wrong: ", gaps)
+	}
+}
+
+// go test -v ./... -count=1 -run=TestKlineBackfill_StreamStop
+func TestKlineBackfill_StreamStop(t *testing.T) {
+	var minute = int64(60 * 1000)
+	var spot = &fakeKlineSpot{last: backfillStart + 99*minute}
+	var backfill = NewSpotKlineBackfill(spot, Pair{Basis: BTC, Counter: USDT}, KLINE_PERIOD_1MIN, backfillStart, backfillStart+100*minute)
+	backfill.PageSize = 3
+
+	var done = make(chan struct{})
+	var pages, result = backfill.Stream(done, 0)
+	<-pages
+	close(done)
+
+	select {
+	case err := <-result:
+		if err != ErrBackfillStopped {
+			t.Error("The result should be stopped: ", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The stream goroutine is not stopped. ")
+	}
+	if spot.calls >= 34 {
+		t.Error("The backfill should stop before the end: ", spot.calls)
+	}
+}
@@ -0,0 +1,423 @@
+package goghostex
+
+import (
+	"fmt"
+	"time"
+)
+
+// KlineSession is the boundary of the day and the week of the exchange kline.
+type KlineSession struct {
+	Location  *time.Location // the day start at the midnight of the location, the nil is UTC
+	WeekStart time.Weekday
+}
+
+// the session of the exchange kline, the exchange not in it use the DEFAULT_KLINE_SESSION.
+// The day kline of okex start at the midnight of UTC+8.
+var KlineSessions = map[string]KlineSession{
+	OKEX:     {Location: time.FixedZone("UTC+8", 8*60*60), WeekStart: time.Monday},
+	BINANCE:  {Location: time.UTC, WeekStart: time.Monday},
+	KRAKEN:   {Location: time.UTC, WeekStart: time.Monday},
+	GATE:     {Location: time.UTC, WeekStart: time.Monday},
+	COINBASE: {Location: time.UTC, WeekStart: time.Monday},
+	BITSTAMP: {Location: time.UTC, WeekStart: time.Monday},
+}
+
+var DEFAULT_KLINE_SESSION = KlineSession{Location: time.UTC, WeekStart: time.Monday}
+
+func GetKlineSession(exchange string) KlineSession {
+	if session, exist := KlineSessions[exchange]; exist {
+		return session
+	}
+	return DEFAULT_KLINE_SESSION
+}
+
+func (session KlineSession) location() *time.Location {
+	if session.Location == nil {
+		return time.UTC
+	}
+	return session.Location
+}
+
+// PeriodStart the start of the period which the ts in, unit: ms. The period not longer than the day is
+// aligned by the offset of the location, the week, the month and the year are aligned by the calendar.
+func (session KlineSession) PeriodStart(period int, ts int64) (int64, error) {
+	var loc = session.location()
+	var t = time.UnixMilli(ts).In(loc)
+	switch period {
+	case KLINE_PERIOD_1WEEK:
+		var days = (int(t.Weekday()) - int(session.WeekStart) + 7) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, loc).UnixMilli(), nil
+	case KLINE_PERIOD_1MONTH:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc).UnixMilli(), nil
+	case KLINE_PERIOD_1YEAR:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, loc).UnixMilli(), nil
+	}
+
+	var periodMs, exist = PeriodMillisecond[period]
+	if !exist {
+		return 0, fmt.Errorf("The period %d can not be resampled. ", period)
+	}
+	var _, offset = t.Zone()
+	var local = ts + int64(offset)*1000
+	var start = local - local%periodMs
+	if local%periodMs < 0 {
+		start -= periodMs
+	}
+	return start - int64(offset)*1000, nil
+}
+
+// the max klines of the source period in one kline of the period.
+func resampleRatio(source, period int) (int, bool) {
+	var sourceMs, exist = PeriodMillisecond[source]
+	switch period {
+	case KLINE_PERIOD_1MONTH:
+		if exist && (24*60*60*1000)%sourceMs == 0 {
+			return int(31 * 24 * 60 * 60 * 1000 / sourceMs), true
+		}
+		return 0, false
+	case KLINE_PERIOD_1YEAR:
+		if source == KLINE_PERIOD_1MONTH {
+			return 12, true
+		}
+		if exist && (24*60*60*1000)%sourceMs == 0 {
+			return int(366 * 24 * 60 * 60 * 1000 / sourceMs), true
+		}
+		return 0, false
+	}
+
+	var periodMs, periodExist = PeriodMillisecond[period]
+	if !exist || !periodExist || sourceMs > periodMs || periodMs%sourceMs != 0 {
+		return 0, false
+	}
+	return int(periodMs / sourceMs), true
+}
+
+// ResampleSource the largest supported period which the period can be built from, and the max number of
+// the source klines in one kline of the period.
+func ResampleSource(period int, supported func(period int) bool) (int, int, error) {
+	for source := KLINE_PERIOD_1YEAR; source >= KLINE_PERIOD_1MIN; source-- {
+		if source == period || !supported(source) {
+			continue
+		}
+		if ratio, ok := resampleRatio(source, period); ok {
+			return source, ratio, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("The period %d can not be built from the supported periods. ", period)
+}
+
+// KlineResampler build the klines of the period from the klines of the smaller period. The first kline is
+// dropped when the source klines start after its boundary, because it is not complete, the last kline is kept
+// even it's in progress, the same as the exchange.
+type KlineResampler struct {
+	Period   int
+	Session  KlineSession
+	Location *time.Location // the location of the date, the nil is the location of the session
+}
+
+func NewKlineResampler(exchange string, period int) *KlineResampler {
+	return &KlineResampler{Period: period, Session: GetKlineSession(exchange)}
+}
+
+type resampleBucket struct {
+	start    int64
+	from, to int // the source klines in [from, to)
+}
+
+// buckets group the ascending timestamps by the period.
+func (r *KlineResampler) buckets(timestamps []int64) ([]resampleBucket, error) {
+	var buckets = make([]resampleBucket, 0)
+	for i, ts := range timestamps {
+		var start, err = r.Session.PeriodStart(r.Period, ts)
+		if err != nil {
+			return nil, err
+		}
+		if len(buckets) > 0 && buckets[len(buckets)-1].start == start {
+			buckets[len(buckets)-1].to = i + 1
+			continue
+		}
+		buckets = append(buckets, resampleBucket{start: start, from: i, to: i + 1})
+	}
+	if len(buckets) > 0 && timestamps[0] > buckets[0].start {
+		buckets = buckets[1:]
+	}
+	return buckets, nil
+}
+
+func (r *KlineResampler) date(ts int64) string {
+	var loc = r.Location
+	if loc == nil {
+		loc = r.Session.location()
+	}
+	return time.UnixMilli(ts).In(loc).Format(GO_BIRTHDAY)
+}
+
+// ohlc the open, close, high and low of the prices in the bucket, the prices is [open, close, high, low].
+func ohlc(bucket resampleBucket, price func(i int) [4]float64) (float64, float64, float64, float64) {
+	var first, last = price(bucket.from), price(bucket.to - 1)
+	var high, low = first[2], first[3]
+	for i := bucket.from + 1; i < bucket.to; i++ {
+		var p = price(i)
+		if p[2] > high {
+			high = p[2]
+		}
+		if p[3] < low {
+			low = p[3]
+		}
+	}
+	return first[0], last[1], high, low
+}
+
+func (r *KlineResampler) Klines(klines []*Kline) ([]*Kline, error) {
+	klines = GetAscKline(klines)
+	var timestamps = make([]int64, 0, len(klines))
+	for _, kline := range klines {
+		timestamps = append(timestamps, kline.Timestamp)
+	}
+	var buckets, err = r.buckets(timestamps)
+	if err != nil {
+		return nil, err
+	}
+
+	var price = func(i int) [4]float64 {
+		return [4]float64{klines[i].Open, klines[i].Close, klines[i].High, klines[i].Low}
+	}
+	var result = make([]*Kline, 0, len(buckets))
+	for _, bucket := range buckets {
+		var kline = &Kline{
+			Pair:      klines[bucket.from].Pair,
+			Exchange:  klines[bucket.from].Exchange,
+			Timestamp: bucket.start,
+			Date:      r.date(bucket.start),
+		}
+		kline.Open, kline.Close, kline.High, kline.Low = ohlc(bucket, price)
+		for i := bucket.from; i < bucket.to; i++ {
+			kline.Vol += klines[i].Vol
+		}
+		result = append(result, kline)
+	}
+	return result, nil
+}
+
+func (r *KlineResampler) SwapKlines(klines []*SwapKline) ([]*SwapKline, error) {
+	klines = GetAscSwapKline(klines)
+	var timestamps = make([]int64, 0, len(klines))
+	for _, kline := range klines {
+		timestamps = append(timestamps, kline.Timestamp)
+	}
+	var buckets, err = r.buckets(timestamps)
+	if err != nil {
+		return nil, err
+	}
+
+	var price = func(i int) [4]float64 {
+		return [4]float64{klines[i].Open, klines[i].Close, klines[i].High, klines[i].Low}
+	}
+	var result = make([]*SwapKline, 0, len(buckets))
+	for _, bucket := range buckets {
+		var kline = &SwapKline{
+			Pair:      klines[bucket.from].Pair,
+			Exchange:  klines[bucket.from].Exchange,
+			Timestamp: bucket.start,
+			Date:      r.date(bucket.start),
+		}
+		kline.Open, kline.Close, kline.High, kline.Low = ohlc(bucket, price)
+		for i := bucket.from; i < bucket.to; i++ {
+			kline.Vol += klines[i].Vol
+		}
+		result = append(result, kline)
+	}
+	return result, nil
+}
+
+func (r *KlineResampler) FutureKlines(klines []*FutureKline) ([]*FutureKline, error) {
+	klines = GetAscFutureKline(klines)
+	var timestamps = make([]int64, 0, len(klines))
+	for _, kline := range klines {
+		timestamps = append(timestamps, kline.Timestamp)
+	}
+	var buckets, err = r.buckets(timestamps)
+	if err != nil {
+		return nil, err
+	}
+
+	var price = func(i int) [4]float64 {
+		return [4]float64{klines[i].Open, klines[i].Close, klines[i].High, klines[i].Low}
+	}
+	var result = make([]*FutureKline, 0, len(buckets))
+	for _, bucket := range buckets {
+		var first = klines[bucket.from]
+		var kline = &FutureKline{
+			Kline: Kline{
+				Pair:      first.Pair,
+				Exchange:  first.Exchange,
+				Timestamp: bucket.start,
+				Date:      r.date(bucket.start),
+			},
+			ContractType: first.ContractType,
+			DueTimestamp: first.DueTimestamp,
+			DueDate:      first.DueDate,
+		}
+		kline.Open, kline.Close, kline.High, kline.Low = ohlc(bucket, price)
+		for i := bucket.from; i < bucket.to; i++ {
+			kline.Vol += klines[i].Vol
+			kline.Vol2 += klines[i].Vol2
+		}
+		result = append(result, kline)
+	}
+	return result, nil
+}
+
+func (r *KlineResampler) FutureCandles(candles []*FutureCandle) ([]*FutureCandle, error) {
+	candles = GetAscFutureCandle(candles)
+	var timestamps = make([]int64, 0, len(candles))
+	for _, candle := range candles {
+		timestamps = append(timestamps, candle.Timestamp)
+	}
+	var buckets, err = r.buckets(timestamps)
+	if err != nil {
+		return nil, err
+	}
+
+	var price = func(i int) [4]float64 {
+		return [4]float64{candles[i].Open, candles[i].Close, candles[i].High, candles[i].Low}
+	}
+	var result = make([]*FutureCandle, 0, len(buckets))
+	for _, bucket := range buckets {
+		var first = candles[bucket.from]
+		var candle = &FutureCandle{
+			Symbol:       first.Symbol,
+			Exchange:     first.Exchange,
+			Timestamp:    bucket.start,
+			Date:         r.date(bucket.start),
+			Type:         first.Type,
+			DueTimestamp: first.DueTimestamp,
+			DueDate:      first.DueDate,
+		}
+		candle.Open, candle.Close, candle.High, candle.Low = ohlc(bucket, price)
+		for i := bucket.from; i < bucket.to; i++ {
+			candle.Vol += candles[i].Vol
+			candle.Vol2 += candles[i].Vol2
+		}
+		result = append(result, candle)
+	}
+	return result, nil
+}
+
+// KlineFallback is used by the rest kline api when the exchange has not the period, it request the klines of
+// the largest supported period which the period can be built from, then resample them.
+type KlineFallback struct {
+	Exchange  string
+	Supported func(period int) bool
+	Location  *time.Location // the location of the date
+	MaxSize   int            // the max size of the source request, the zero is no limit, the larger request is an error
+}
+
+// plan the resampler and the period, size and since of the source request. The since is moved to the start of
+// its period, so the first kline is complete.
+func (f *KlineFallback) plan(period, size int, since int64) (*KlineResampler, int, int, int64, error) {
+	var source, ratio, err = ResampleSource(period, f.Supported)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+	var resampler = &KlineResampler{Period: period, Session: GetKlineSession(f.Exchange), Location: f.Location}
+	if since > 0 {
+		if since < 1e12 {
+			since *= 1000
+		}
+		if since, err = resampler.Session.PeriodStart(period, since); err != nil {
+			return nil, 0, 0, 0, err
+		}
+	}
+	// one more period for the incomplete first kline.
+	var sourceSize = (size + 1) * ratio
+	if f.MaxSize > 0 && sourceSize > f.MaxSize {
+		return nil, 0, 0, 0, fmt.Errorf(
+			"The %d klines of the period %d need %d klines of the period %d, more than the max size %d of %s, "+
+				"the size must be at most %d. ",
+			size, period, sourceSize, source, f.MaxSize, f.Exchange, f.MaxSize/ratio-1,
+		)
+	}
+	return resampler, source, sourceSize, since, nil
+}
+
+// the latest size klines, the size <= 0 means all.
+func latest(length, size int) int {
+	if size <= 0 || length <= size {
+		return 0
+	}
+	return length - size
+}
+
+// Klines the klines of the period, it's an error if the source klines of the size are more than the MaxSize.
+func (f *KlineFallback) Klines(
+	period, size int, since int64,
+	get func(period, size int, since int64) ([]*Kline, []byte, error),
+) ([]*Kline, []byte, error) {
+	var resampler, source, sourceSize, sourceSince, err = f.plan(period, size, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	klines, resp, err := get(source, sourceSize, sourceSince)
+	if err != nil {
+		return nil, resp, err
+	}
+	if klines, err = resampler.Klines(klines); err != nil {
+		return nil, resp, err
+	}
+	return klines[latest(len(klines), size):], resp, nil
+}
+
+func (f *KlineFallback) SwapKlines(
+	period, size int, since int64,
+	get func(period, size int, since int64) ([]*SwapKline, []byte, error),
+) ([]*SwapKline, []byte, error) {
+	var resampler, source, sourceSize, sourceSince, err = f.plan(period, size, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	klines, resp, err := get(source, sourceSize, sourceSince)
+	if err != nil {
+		return nil, resp, err
+	}
+	if klines, err = resampler.SwapKlines(klines); err != nil {
+		return nil, resp, err
+	}
+	return klines[latest(len(klines), size):], resp, nil
+}
+
+func (f *KlineFallback) FutureKlines(
+	period, size int, since int64,
+	get func(period, size int, since int64) ([]*FutureKline, []byte, error),
+) ([]*FutureKline, []byte, error) {
+	var resampler, source, sourceSize, sourceSince, err = f.plan(period, size, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	klines, resp, err := get(source, sourceSize, sourceSince)
+	if err != nil {
+		return nil, resp, err
+	}
+	if klines, err = resampler.FutureKlines(klines); err != nil {
+		return nil, resp, err
+	}
+	return klines[latest(len(klines), size):], resp, nil
+}
+
+func (f *KlineFallback) FutureCandles(
+	period, size int, since int64,
+	get func(period, size int, since int64) ([]*FutureCandle, []byte, error),
+) ([]*FutureCandle, []byte, error) {
+	var resampler, source, sourceSize, sourceSince, err = f.plan(period, size, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	candles, resp, err := get(source, sourceSize, sourceSince)
+	if err != nil {
+		return nil, resp, err
+	}
+	if candles, err = resampler.FutureCandles(candles); err != nil {
+		return nil, resp, err
+	}
+	return candles[latest(len(candles), size):], resp, nil
+}
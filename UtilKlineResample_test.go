@@ -0,0 +1,172 @@
+package goghostex
+
+import (
+	"testing"
+	"time"
+)
+
+// go test -v ./... -count=1 -run=TestKlineSession_PeriodStart
+func TestKlineSession_PeriodStart(t *testing.T) {
+	// 2024-03-06 10:30:00 UTC, wednesday.
+	var ts = time.Date(2024, 3, 6, 10, 30, 0, 0, time.UTC).UnixMilli()
+	var cases = []struct {
+		exchange string
+		period   int
+		expected time.Time
+	}{
+		{BINANCE, KLINE_PERIOD_4H, time.Date(2024, 3, 6, 8, 0, 0, 0, time.UTC)},
+		{BINANCE, KLINE_PERIOD_1DAY, time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{BINANCE, KLINE_PERIOD_1WEEK, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{BINANCE, KLINE_PERIOD_1MONTH, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{BINANCE, KLINE_PERIOD_1YEAR, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// the day of okex start at 16:00 UTC.
+		{OKEX, KLINE_PERIOD_1DAY, time.Date(2024, 3, 5, 16, 0, 0, 0, time.UTC)},
+		{OKEX, KLINE_PERIOD_8H, time.Date(2024, 3, 6, 8, 0, 0, 0, time.UTC)},
+		{OKEX, KLINE_PERIOD_1MONTH, time.Date(2024, 2, 29, 16, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		var start, err = GetKlineSession(c.exchange).PeriodStart(c.period, ts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if start != c.expected.UnixMilli() {
+			t.Errorf("%s %d: expected %s, got %s", c.exchange, c.period, c.expected, time.UnixMilli(start).UTC())
+		}
+	}
+}
+
+// go test -v ./... -count=1 -run=TestResampleSource
+func TestResampleSource(t *testing.T) {
+	var okex = map[int]bool{
+		KLINE_PERIOD_1MIN: true, KLINE_PERIOD_5MIN: true, KLINE_PERIOD_1H: true,
+		KLINE_PERIOD_4H: true, KLINE_PERIOD_1DAY: true, KLINE_PERIOD_1WEEK: true,
+	}
+	var supported = func(period int) bool { return okex[period] }
+	var cases = []struct {
+		period int
+		source int
+		ratio  int
+	}{
+		{KLINE_PERIOD_8H, KLINE_PERIOD_4H, 2},
+		{KLINE_PERIOD_3DAY, KLINE_PERIOD_1DAY, 3},
+		{KLINE_PERIOD_1MONTH, KLINE_PERIOD_1DAY, 31},
+		{KLINE_PERIOD_1YEAR, KLINE_PERIOD_1DAY, 366},
+	}
+	for _, c := range cases {
+		var source, ratio, err = ResampleSource(c.period, supported)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if source != c.source || ratio != c.ratio {
+			t.Errorf("%d: expected %d x %d, got %d x %d", c.period, c.source, c.ratio, source, ratio)
+		}
+	}
+	if _, _, err := ResampleSource(KLINE_PERIOD_1MIN, func(period int) bool { return period != KLINE_PERIOD_1MIN }); err == nil {
+		t.Error("the 1min can not be built from the larger periods")
+	}
+}
+
+// go test -v ./... -count=1 -run=TestKlineResampler
+func TestKlineResampler(t *testing.T) {
+	var hour = int64(60 * 60 * 1000)
+	var start = time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC).UnixMilli()
+	var pair = Pair{Basis: BTC, Counter: USDT}
+
+	// the descending 1H klines from 03:00 to 12:00, the first 8H kline is incomplete.
+	var klines = make([]*SwapKline, 0)
+	for ts := start + 12*hour; ts >= start+3*hour; ts -= hour {
+		var price = float64(ts-start) / float64(hour)
+		klines = append(klines, &SwapKline{
+			Pair: pair, Exchange: BINANCE, Timestamp: ts,
+			Open: price, Close: price + 0.5, High: price + 1, Low: price - 1, Vol: 1,
+		})
+	}
+
+	var resampler = NewKlineResampler(BINANCE, KLINE_PERIOD_8H)
+	var result, err = resampler.SwapKlines(klines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 kline, got %d", len(result))
+	}
+	var kline = result[0]
+	if kline.Timestamp != start+8*hour || kline.Date != "2024-03-06 08:00:00" {
+		t.Errorf("the kline start at %d %s", kline.Timestamp, kline.Date)
+	}
+	if kline.Open != 8 || kline.Close != 12.5 || kline.High != 13 || kline.Low != 7 || kline.Vol != 5 {
+		t.Errorf("the kline is wrong: %+v", kline)
+	}
+
+	var candles = []*FutureCandle{
+		{Symbol: "btc_usd", Timestamp: start, Open: 1, Close: 2, High: 3, Low: 0.5, Vol: 1, Vol2: 10, DueTimestamp: 1},
+		{Symbol: "btc_usd", Timestamp: start + 24*hour, Open: 2, Close: 4, High: 5, Low: 1, Vol: 2, Vol2: 20, DueTimestamp: 1},
+	}
+	resampler = NewKlineResampler(BINANCE, KLINE_PERIOD_1MONTH)
+	merged, err := resampler.FutureCandles(candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the month start at 2024-03-01, the candles are after it.
+	if len(merged) != 0 {
+		t.Errorf("the incomplete month should be dropped, got %d", len(merged))
+	}
+	resampler.Period = KLINE_PERIOD_3DAY
+	if merged, err = resampler.FutureCandles(candles); err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 1 || merged[0].Open != 1 || merged[0].Close != 4 || merged[0].Vol2 != 30 || merged[0].DueTimestamp != 1 {
+		t.Errorf("the 3day candle is wrong: %+v", merged)
+	}
+}
+
+// go test -v ./... -count=1 -run=TestKlineFallback
+func TestKlineFallback(t *testing.T) {
+	var hour = int64(60 * 60 * 1000)
+	var last = time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC).UnixMilli()
+	var fallback = &KlineFallback{
+		Exchange:  BINANCE,
+		MaxSize:   20,
+		Supported: func(period int) bool { return period == KLINE_PERIOD_1H },
+	}
+
+	var request = [3]int64{}
+	var klines, _, err = fallback.Klines(KLINE_PERIOD_4H, 2, 0, func(period, size int, since int64) ([]*Kline, []byte, error) {
+		request = [3]int64{int64(period), int64(size), since}
+		var klines = make([]*Kline, 0, size)
+		for i := size - 1; i >= 0; i-- {
+			klines = append(klines, &Kline{Timestamp: last - int64(i)*hour, Close: float64(i), Vol: 1})
+		}
+		return klines, nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request != [3]int64{KLINE_PERIOD_1H, 12, 0} {
+		t.Errorf("the source request is %v", request)
+	}
+	if len(klines) != 2 || klines[0].Timestamp != last-4*hour || klines[1].Timestamp != last || klines[0].Vol != 4 {
+		t.Errorf("the klines are wrong: %+v %+v", klines[0], klines[len(klines)-1])
+	}
+
+	// the since is moved to the start of its period.
+	_, _, err = fallback.Klines(KLINE_PERIOD_4H, 4, last+hour, func(period, size int, since int64) ([]*Kline, []byte, error) {
+		request = [3]int64{int64(period), int64(size), since}
+		return nil, nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request != [3]int64{KLINE_PERIOD_1H, 20, last} {
+		t.Errorf("the source request is %v", request)
+	}
+
+	// the source klines are more than the max size, the short result is not returned.
+	_, _, err = fallback.Klines(KLINE_PERIOD_4H, 5, 0, func(period, size int, since int64) ([]*Kline, []byte, error) {
+		t.Error("the source should not be requested")
+		return nil, nil, nil
+	})
+	if err == nil {
+		t.Error("the size over the max size should be an error")
+	}
+}
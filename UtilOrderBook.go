@@ -0,0 +1,384 @@
+package goghostex
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BookUpdateRelay relay the "productId:timestamp" message in the UpdateChan of the local order books
+// to the typed handler. The adapter bind the productId with the pair when subscribing.
+type BookUpdateRelay struct {
+	Exchange string
+	Market   string
+
+	handler  func(*BookUpdate)
+	products map[string]Pair
+	stop     chan bool
+	mux      sync.RWMutex
+}
+
+func NewBookUpdateRelay(exchange, market string) *BookUpdateRelay {
+	return &BookUpdateRelay{
+		Exchange: exchange,
+		Market:   market,
+		products: make(map[string]Pair),
+	}
+}
+
+func (r *BookUpdateRelay) SetUpdateHandler(handler func(*BookUpdate)) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.handler = handler
+}
+
+func (r *BookUpdateRelay) Bind(productId string, pair Pair) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.products[productId] = pair
+}
+
+func (r *BookUpdateRelay) Unbind(productId string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	delete(r.products, productId)
+}
+
+// Start read the update messages until the Stop, the channel should not be read by others.
+func (r *BookUpdateRelay) Start(updates chan string) {
+	r.mux.Lock()
+	if r.stop != nil {
+		r.mux.Unlock()
+		return
+	}
+	var stop = make(chan bool, 1)
+	r.stop = stop
+	r.mux.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case msg := <-updates:
+				r.Relay(msg)
+			}
+		}
+	}()
+}
+
+func (r *BookUpdateRelay) Stop() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.stop != nil {
+		r.stop <- true
+		r.stop = nil
+	}
+}
+
+// Relay the message of the unbound productId is dropped.
+func (r *BookUpdateRelay) Relay(msg string) {
+	var split = strings.LastIndex(msg, ":")
+	if split < 0 {
+		return
+	}
+	var productId = msg[:split]
+	var timestamp, _ = strconv.ParseInt(msg[split+1:], 10, 64)
+
+	r.mux.RLock()
+	var pair, exist = r.products[productId]
+	var handler = r.handler
+	r.mux.RUnlock()
+	if !exist || handler == nil {
+		return
+	}
+
+	handler(&BookUpdate{
+		BookKey: BookKey{
+			Exchange: r.Exchange,
+			Market:   r.Market,
+			Pair:     pair,
+		},
+		ProductId: productId,
+		Timestamp: timestamp,
+	})
+}
+
+// OrderBookManager hold the books of many (exchange, market, pair) keys, there is one feed for every
+// (exchange, market). The updates of all the feeds are sent to the UpdateChan if it's not nil.
+// The subscribed books are keyed by the BookKey.String(), the desc of the currency is not compared.
+type OrderBookManager struct {
+	UpdateChan chan *BookUpdate
+
+	feeds      map[string]OrderBookFeed
+	subscribed map[string]BookKey
+	mux        sync.RWMutex
+}
+
+func NewOrderBookManager(size int) *OrderBookManager {
+	return &OrderBookManager{
+		UpdateChan: make(chan *BookUpdate, size),
+		feeds:      make(map[string]OrderBookFeed),
+		subscribed: make(map[string]BookKey),
+	}
+}
+
+func feedKey(exchange, market string) string {
+	return exchange + ":" + market
+}
+
+// AddFeed start the feed, the feed of the same (exchange, market) can only be added once.
+func (m *OrderBookManager) AddFeed(feed OrderBookFeed) error {
+	var key = feedKey(feed.GetExchangeName(), feed.GetMarket())
+
+	m.mux.Lock()
+	if _, exist := m.feeds[key]; exist {
+		m.mux.Unlock()
+		return fmt.Errorf("The feed of %s is added already. ", key)
+	}
+	m.feeds[key] = feed
+	m.mux.Unlock()
+
+	feed.SetUpdateHandler(func(update *BookUpdate) {
+		if m.UpdateChan != nil {
+			m.UpdateChan <- update
+		}
+	})
+	if err := feed.Start(); err != nil {
+		m.mux.Lock()
+		delete(m.feeds, key)
+		m.mux.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (m *OrderBookManager) getFeed(key BookKey) (OrderBookFeed, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	if feed, exist := m.feeds[feedKey(key.Exchange, key.Market)]; exist {
+		return feed, nil
+	}
+	return nil, fmt.Errorf("The feed of %s:%s is not added. ", key.Exchange, key.Market)
+}
+
+func (m *OrderBookManager) Subscribe(key BookKey) error {
+	var feed, err = m.getFeed(key)
+	if err != nil {
+		return err
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, exist := m.subscribed[key.String()]; exist {
+		return nil
+	}
+	feed.Subscribe(key.Pair)
+	m.subscribed[key.String()] = key
+	return nil
+}
+
+func (m *OrderBookManager) Unsubscribe(key BookKey) error {
+	var feed, err = m.getFeed(key)
+	if err != nil {
+		return err
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, exist := m.subscribed[key.String()]; !exist {
+		return nil
+	}
+	feed.Unsubscribe(key.Pair)
+	delete(m.subscribed, key.String())
+	return nil
+}
+
+// Snapshot the depth of the key, the pair of the depth is the key's. The depth of the feed is not changed,
+// the pair is set in the copy of it.
+func (m *OrderBookManager) Snapshot(key BookKey) (*Depth, error) {
+	var feed, err = m.getFeed(key)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mux.RLock()
+	var _, subscribed = m.subscribed[key.String()]
+	m.mux.RUnlock()
+	if !subscribed {
+		return nil, errors.New("The book is not subscribed. ")
+	}
+
+	depth, err := feed.Snapshot(key.Pair)
+	if err != nil {
+		return nil, err
+	}
+	var copied = *depth
+	copied.Pair = key.Pair
+	return &copied, nil
+}
+
+func (m *OrderBookManager) Keys() []BookKey {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	var keys = make([]BookKey, 0, len(m.subscribed))
+	for _, key := range m.subscribed {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Stop stop all the feeds, the manager can not be used after stopped.
+func (m *OrderBookManager) Stop() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	for _, feed := range m.feeds {
+		feed.Stop()
+	}
+	m.feeds = make(map[string]OrderBookFeed)
+	m.subscribed = make(map[string]BookKey)
+}
+
+// RestBookFeed poll the rest depth as the OrderBookFeed, it's for the exchange without the local order book.
+// The update is sent after every poll of the pair.
+type RestBookFeed struct {
+	Spot         SpotRestAPI
+	Interval     time.Duration
+	Size         int
+	ErrorHandler func(error)
+
+	handler func(*BookUpdate)
+	depths  map[string]*Depth
+	pairs   map[string]Pair
+	stop    chan bool
+	mux     sync.RWMutex
+}
+
+func NewRestBookFeed(spot SpotRestAPI, interval time.Duration, size int) *RestBookFeed {
+	return &RestBookFeed{
+		Spot:     spot,
+		Interval: interval,
+		Size:     size,
+		depths:   make(map[string]*Depth),
+		pairs:    make(map[string]Pair),
+	}
+}
+
+func (f *RestBookFeed) GetExchangeName() string {
+	return f.Spot.GetExchangeName()
+}
+
+func (f *RestBookFeed) GetMarket() string {
+	return TRADE_TYPE_SPOT
+}
+
+func (f *RestBookFeed) Start() error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.stop != nil {
+		return nil
+	}
+	f.stop = make(chan bool, 1)
+	go f.pollRoutine(f.stop)
+	return nil
+}
+
+func (f *RestBookFeed) Stop() {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.stop != nil {
+		f.stop <- true
+		f.stop = nil
+	}
+}
+
+func (f *RestBookFeed) Subscribe(pair Pair) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.pairs[pair.ToSymbol("_", false)] = pair
+}
+
+func (f *RestBookFeed) Unsubscribe(pair Pair) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	delete(f.pairs, pair.ToSymbol("_", false))
+	delete(f.depths, pair.ToSymbol("_", false))
+}
+
+// Snapshot the copy of the last polled depth, the caller can change it.
+func (f *RestBookFeed) Snapshot(pair Pair) (*Depth, error) {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	if depth, exist := f.depths[pair.ToSymbol("_", false)]; exist {
+		var copied = *depth
+		copied.AskList = append(DepthRecords{}, depth.AskList...)
+		copied.BidList = append(DepthRecords{}, depth.BidList...)
+		return &copied, nil
+	}
+	return nil, errors.New("The order book data is not ready or you need subscribe the pair. ")
+}
+
+func (f *RestBookFeed) SetUpdateHandler(handler func(*BookUpdate)) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.handler = handler
+}
+
+func (f *RestBookFeed) pollRoutine(stop chan bool) {
+	var ticker = time.NewTicker(f.Interval)
+	defer ticker.Stop()
+
+	for {
+		f.Poll()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll get the depth of all the subscribed pairs once.
+func (f *RestBookFeed) Poll() {
+	f.mux.RLock()
+	var pairs = make([]Pair, 0, len(f.pairs))
+	for _, pair := range f.pairs {
+		pairs = append(pairs, pair)
+	}
+	f.mux.RUnlock()
+
+	for _, pair := range pairs {
+		var depth, _, err = f.Spot.GetDepth(pair, f.Size)
+		if err != nil {
+			if f.ErrorHandler != nil {
+				f.ErrorHandler(err)
+			}
+			continue
+		}
+
+		f.mux.Lock()
+		if _, exist := f.pairs[pair.ToSymbol("_", false)]; !exist {
+			f.mux.Unlock()
+			continue
+		}
+		f.depths[pair.ToSymbol("_", false)] = depth
+		var handler = f.handler
+		f.mux.Unlock()
+
+		if handler != nil {
+			handler(&BookUpdate{
+				BookKey: BookKey{
+					Exchange: f.GetExchangeName(),
+					Market:   TRADE_TYPE_SPOT,
+					Pair:     pair,
+				},
+				ProductId: pair.ToSymbol("_", false),
+				Timestamp: depth.Timestamp,
+			})
+		}
+	}
+}
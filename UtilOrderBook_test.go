@@ -0,0 +1,170 @@
+package goghostex
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeBookFeed relay the update messages like the exchange adapters, the books are in the depths.
+type fakeBookFeed struct {
+	exchange string
+	market   string
+	relay    *BookUpdateRelay
+	updates  chan string
+	depths   map[string]*Depth
+	started  bool
+	stopped  bool
+}
+
+func newFakeBookFeed(exchange, market string) *fakeBookFeed {
+	return &fakeBookFeed{
+		exchange: exchange,
+		market:   market,
+		relay:    NewBookUpdateRelay(exchange, market),
+		updates:  make(chan string, 16),
+		depths:   make(map[string]*Depth),
+	}
+}
+
+func (this *fakeBookFeed) GetExchangeName() string { return this.exchange }
+func (this *fakeBookFeed) GetMarket() string       { return this.market }
+
+func (this *fakeBookFeed) Start() error {
+	this.started = true
+	this.relay.Start(this.updates)
+	return nil
+}
+
+func (this *fakeBookFeed) Stop() {
+	this.stopped = true
+	this.relay.Stop()
+}
+
+func (this *fakeBookFeed) Subscribe(pair Pair) {
+	var productId = pair.ToSymbol("", false)
+	this.relay.Bind(productId, pair)
+	this.depths[productId] = &Depth{
+		Sequence: 1,
+		BidList:  DepthRecords{{Price: 100, Amount: 1}},
+		AskList:  DepthRecords{{Price: 101, Amount: 1}},
+	}
+}
+
+func (this *fakeBookFeed) Unsubscribe(pair Pair) {
+	this.relay.Unbind(pair.ToSymbol("", false))
+	delete(this.depths, pair.ToSymbol("", false))
+}
+
+func (this *fakeBookFeed) Snapshot(pair Pair) (*Depth, error) {
+	if depth, exist := this.depths[pair.ToSymbol("", false)]; exist {
+		return depth, nil
+	}
+	return nil, errors.New("The order book data is not ready. ")
+}
+
+func (this *fakeBookFeed) SetUpdateHandler(handler func(*BookUpdate)) {
+	this.relay.SetUpdateHandler(handler)
+}
+
+// go test -v ./ -count=1 -run=TestBookUpdateRelay
+func TestBookUpdateRelay(t *testing.T) {
+	var relay = NewBookUpdateRelay(OKEX, TRADE_TYPE_SWAP)
+	var updates = make([]*BookUpdate, 0)
+	relay.SetUpdateHandler(func(update *BookUpdate) {
+		updates = append(updates, update)
+	})
+	relay.Bind("BTC-USDT-SWAP", Pair{Basis: BTC, Counter: USDT})
+
+	relay.Relay("BTC-USDT-SWAP:1700000000000")
+	relay.Relay("ETH-USDT-SWAP:1700000000001")
+	relay.Relay("broken message")
+	if len(updates) != 1 {
+		t.Fatal("Only the bound product should be relayed. ", len(updates))
+	}
+	if updates[0].String() != "okex:swap:btc_usdt" || updates[0].ProductId != "BTC-USDT-SWAP" ||
+		updates[0].Timestamp != 1700000000000 {
+		t.Error("The update is wrong. ", updates[0])
+	}
+
+	// the symbol of kraken spot has the slash, and it's the same in the message.
+	relay.Bind("BTC/USD", Pair{Basis: BTC, Counter: USD})
+	relay.Relay("BTC/USD:1700000000002")
+	if len(updates) != 2 || updates[1].Pair.ToSymbol("_", false) != "btc_usd" {
+		t.Error("The slash symbol is wrong. ", updates)
+	}
+
+	relay.Unbind("BTC-USDT-SWAP")
+	relay.Relay("BTC-USDT-SWAP:1700000000003")
+	if len(updates) != 2 {
+		t.Error("The unbound product should be dropped. ", len(updates))
+	}
+}
+
+// go test -v ./ -count=1 -run=TestOrderBookManager
+func TestOrderBookManager(t *testing.T) {
+	var manager = NewOrderBookManager(16)
+	var okexFeed, binanceFeed = newFakeBookFeed(OKEX, TRADE_TYPE_SWAP), newFakeBookFeed(BINANCE, TRADE_TYPE_SWAP)
+	if err := manager.AddFeed(okexFeed); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.AddFeed(binanceFeed); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.AddFeed(newFakeBookFeed(OKEX, TRADE_TYPE_SWAP)); err == nil {
+		t.Error("The feed of the same exchange and market should be added once. ")
+	}
+	if !okexFeed.started || !binanceFeed.started {
+		t.Fatal("The feed should be started when added. ")
+	}
+
+	var okexKey = BookKey{Exchange: OKEX, Market: TRADE_TYPE_SWAP, Pair: Pair{Basis: BTC, Counter: USDT}}
+	var binanceKey = BookKey{Exchange: BINANCE, Market: TRADE_TYPE_SWAP, Pair: NewPair("eth_usdt", "_")}
+	if err := manager.Subscribe(BookKey{Exchange: KRAKEN, Market: TRADE_TYPE_SPOT, Pair: okexKey.Pair}); err == nil {
+		t.Error("The key without the feed should not be subscribed. ")
+	}
+	if _, err := manager.Snapshot(okexKey); err == nil {
+		t.Error("The book is not subscribed yet. ")
+	}
+	for _, key := range []BookKey{okexKey, binanceKey, okexKey} {
+		if err := manager.Subscribe(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if keys := manager.Keys(); len(keys) != 2 {
+		t.Error("The keys are wrong. ", keys)
+	}
+
+	var depth, err = manager.Snapshot(okexKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth.Pair.ToSymbol("_", false) != "btc_usdt" || len(depth.BidList) != 1 {
+		t.Error("The depth is wrong. ", depth)
+	}
+	if okexFeed.depths["btcusdt"].Pair.Basis.Symbol != "" {
+		t.Error("The depth of the feed should not be changed. ")
+	}
+
+	binanceFeed.updates <- "ethusdt:1700000000000"
+	select {
+	case update := <-manager.UpdateChan:
+		if update.BookKey.String() != binanceKey.String() || update.Timestamp != 1700000000000 {
+			t.Error("The update is wrong. ", update)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The update is not delivered. ")
+	}
+
+	if err := manager.Unsubscribe(binanceKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Snapshot(binanceKey); err == nil {
+		t.Error("The unsubscribed book should not be snapshot. ")
+	}
+
+	manager.Stop()
+	if !okexFeed.stopped || !binanceFeed.stopped {
+		t.Error("The feeds should be stopped. ")
+	}
+}
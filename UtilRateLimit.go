@@ -0,0 +1,308 @@
+package goghostex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The mode of the rate limiter in APIConfig.RateLimit
+const (
+	RATE_LIMIT_BLOCK     = "block"     // wait until the request is allowed, it's the default mode
+	RATE_LIMIT_FAIL_FAST = "fail_fast" // return the ErrRateLimited immediately
+	RATE_LIMIT_OFF       = "off"       // no limit in client
+)
+
+// RateRule is the max weight in the interval.
+// The window rule reset the used weight every interval,
+// the decay rule (Decay > 0) decrease the used weight every second, eg: the counter of kraken.
+type RateRule struct {
+	Limit    float64
+	Interval time.Duration
+	Align    bool    // the window start at the multiple of the interval, eg: binance reset the weight every minute.
+	Decay    float64 // the weight decreased per second
+	Header   string  // the response header of the used weight, eg: binance X-MBX-USED-WEIGHT-1M
+}
+
+// RateCost is the weight of the request on the rule.
+type RateCost struct {
+	Rule          string
+	Weight        float64
+	PerEndpoint   bool // every endpoint has its own counter
+	PerInstrument bool // every instrument has its own counter, eg: okex limit the order by instId
+}
+
+type RateUsage struct {
+	Rule  string
+	Scope string // the endpoint or the instrument of the counter
+	Used  float64
+	Limit float64
+	Reset time.Duration // the duration until the counter is empty
+}
+
+// RateLimiter is shared by the goroutines of the exchange client, it's in front of the DoRequest.
+// The costs key is the "METHOD /path", the "/path" or the prefix of the path end with "/",
+// the "" key is the default cost of the requests, the empty costs means no limit.
+type RateLimiter struct {
+	Exchange string
+	Mode     string
+
+	rules   map[string]*RateRule
+	costs   map[string][]*RateCost
+	buckets map[string]*rateBucket
+	paused  time.Time
+	locker  sync.Mutex
+}
+
+type rateBucket struct {
+	rule  string
+	scope string
+	used  float64
+	since time.Time // the start of the window, or the last decay time
+}
+
+func NewRateLimiter(exchange, mode string, rules map[string]*RateRule, costs map[string][]*RateCost) *RateLimiter {
+	if mode == "" {
+		mode = RATE_LIMIT_BLOCK
+	}
+	return &RateLimiter{
+		Exchange: exchange,
+		Mode:     mode,
+		rules:    rules,
+		costs:    costs,
+		buckets:  make(map[string]*rateBucket),
+	}
+}
+
+// Acquire take the weight of the request, it blocks or fails fast by the mode when the limit is reached.
+func (this *RateLimiter) Acquire(method, uri, instrument string) error {
+	if this == nil || this.Mode == RATE_LIMIT_OFF {
+		return nil
+	}
+	var endpoint, costs = this.lookup(method, uri)
+	if len(costs) == 0 {
+		return nil
+	}
+
+	for {
+		this.locker.Lock()
+		var now = time.Now()
+		var wait = this.paused.Sub(now)
+		var buckets = make([]*rateBucket, 0, len(costs))
+		for _, cost := range costs {
+			var rule, exist = this.rules[cost.Rule]
+			if !exist {
+				continue
+			}
+			var bucket = this.bucket(cost, endpoint, instrument, now)
+			if w := bucket.wait(rule, cost.Weight, now); w > wait {
+				wait = w
+			}
+			buckets = append(buckets, bucket)
+		}
+		if wait <= 0 {
+			for i, bucket := range buckets {
+				bucket.used += costs[i].Weight
+			}
+			this.locker.Unlock()
+			return nil
+		}
+		this.locker.Unlock()
+
+		if this.Mode == RATE_LIMIT_FAIL_FAST {
+			return &apiError{
+				code:     ERR_CODE_RATE_LIMITED,
+				message:  fmt.Sprintf("The request %s %s is limited in client, retry after %s. ", method, endpoint, wait),
+				category: ErrRateLimited,
+				exchange: this.Exchange,
+			}
+		}
+		time.Sleep(wait)
+	}
+}
+
+// Feedback sync the used weight by the response header,
+// the rate limited error pause the requests by the Retry-After, or fill the counters of the request.
+func (this *RateLimiter) Feedback(method, uri, instrument string, header http.Header, err error) {
+	if this == nil || this.Mode == RATE_LIMIT_OFF {
+		return
+	}
+	var endpoint, costs = this.lookup(method, uri)
+
+	this.locker.Lock()
+	defer this.locker.Unlock()
+	var now = time.Now()
+	for _, cost := range costs {
+		var rule, exist = this.rules[cost.Rule]
+		if !exist || rule.Header == "" || header == nil {
+			continue
+		}
+		if used, parseErr := strconv.ParseFloat(header.Get(rule.Header), 64); parseErr == nil {
+			this.bucket(cost, endpoint, instrument, now).used = used
+		}
+	}
+
+	if !errors.Is(err, ErrRateLimited) {
+		return
+	}
+	if retryAfter := RetryAfter(header); retryAfter > 0 {
+		if until := now.Add(retryAfter); until.After(this.paused) {
+			this.paused = until
+		}
+		return
+	}
+	for _, cost := range costs {
+		if rule, exist := this.rules[cost.Rule]; exist {
+			var bucket = this.bucket(cost, endpoint, instrument, now)
+			if bucket.used < rule.Limit {
+				bucket.used = rule.Limit
+			}
+		}
+	}
+}
+
+// Sync set the used weight of the counter, the scope is the endpoint or the instrument, or "" for the whole rule.
+func (this *RateLimiter) Sync(rule, scope string, used float64) {
+	if this == nil {
+		return
+	}
+	this.locker.Lock()
+	defer this.locker.Unlock()
+	if _, exist := this.rules[rule]; !exist {
+		return
+	}
+	var now = time.Now()
+	var bucket, exist = this.buckets[rule+"|"+scope]
+	if !exist {
+		bucket = &rateBucket{rule: rule, scope: scope, since: now}
+		this.buckets[rule+"|"+scope] = bucket
+	}
+	bucket.refresh(this.rules[rule], now)
+	bucket.used = used
+}
+
+// Usage return the current usage of the counters, sorted by the rule and the scope.
+func (this *RateLimiter) Usage() []*RateUsage {
+	if this == nil {
+		return nil
+	}
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	var now = time.Now()
+	var usages = make([]*RateUsage, 0, len(this.buckets))
+	for _, bucket := range this.buckets {
+		var rule = this.rules[bucket.rule]
+		bucket.refresh(rule, now)
+		var usage = &RateUsage{Rule: bucket.rule, Scope: bucket.scope, Used: bucket.used, Limit: rule.Limit}
+		if rule.Decay > 0 {
+			usage.Reset = time.Duration(bucket.used / rule.Decay * float64(time.Second))
+		} else if bucket.used > 0 {
+			usage.Reset = bucket.since.Add(rule.Interval).Sub(now)
+		}
+		usages = append(usages, usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Rule != usages[j].Rule {
+			return usages[i].Rule < usages[j].Rule
+		}
+		return usages[i].Scope < usages[j].Scope
+	})
+	return usages
+}
+
+// lookup return the path and the costs of the request, the exact key first, then the longest prefix.
+func (this *RateLimiter) lookup(method, uri string) (string, []*RateCost) {
+	var path = uri
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	method = strings.ToUpper(method)
+
+	for _, key := range []string{method + " " + path, path} {
+		if costs, exist := this.costs[key]; exist {
+			return path, costs
+		}
+	}
+	for prefix := path; prefix != ""; {
+		var i = strings.LastIndex(strings.TrimSuffix(prefix, "/"), "/")
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i+1]
+		for _, key := range []string{method + " " + prefix, prefix} {
+			if costs, exist := this.costs[key]; exist {
+				return path, costs
+			}
+		}
+	}
+	return path, this.costs[""]
+}
+
+func (this *RateLimiter) bucket(cost *RateCost, endpoint, instrument string, now time.Time) *rateBucket {
+	var scopes = make([]string, 0, 2)
+	if cost.PerEndpoint {
+		scopes = append(scopes, endpoint)
+	}
+	if cost.PerInstrument {
+		scopes = append(scopes, instrument)
+	}
+	var scope = strings.Join(scopes, " ")
+	var bucket, exist = this.buckets[cost.Rule+"|"+scope]
+	if !exist {
+		bucket = &rateBucket{rule: cost.Rule, scope: scope, since: now}
+		if rule := this.rules[cost.Rule]; rule.Align && rule.Interval > 0 {
+			bucket.since = now.Truncate(rule.Interval)
+		}
+		this.buckets[cost.Rule+"|"+scope] = bucket
+	}
+	return bucket
+}
+
+func (this *rateBucket) refresh(rule *RateRule, now time.Time) {
+	if rule.Decay > 0 {
+		this.used -= now.Sub(this.since).Seconds() * rule.Decay
+		if this.used < 0 {
+			this.used = 0
+		}
+		this.since = now
+		return
+	}
+	if rule.Interval > 0 && !now.Before(this.since.Add(rule.Interval)) {
+		this.used = 0
+		if rule.Align {
+			this.since = now.Truncate(rule.Interval)
+		} else {
+			this.since = now
+		}
+	}
+}
+
+// wait return the duration until the weight is allowed, the weight larger than the limit is allowed on the empty counter.
+func (this *rateBucket) wait(rule *RateRule, weight float64, now time.Time) time.Duration {
+	this.refresh(rule, now)
+	if this.used+weight <= rule.Limit || this.used == 0 {
+		return 0
+	}
+	if rule.Decay > 0 {
+		return time.Duration((this.used + weight - rule.Limit) / rule.Decay * float64(time.Second))
+	}
+	return this.since.Add(rule.Interval).Sub(now)
+}
+
+// RetryAfter parse the Retry-After header in seconds.
+func RetryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+	var seconds, err = strconv.ParseFloat(header.Get("Retry-After"), 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,101 @@
+package goghostex
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// go test -v ./ -count=1 -run=TestRateLimiter_Acquire
+func TestRateLimiter_Acquire(t *testing.T) {
+	var rules = map[string]*RateRule{
+		"weight": {Limit: 3, Interval: 100 * time.Millisecond},
+		"order":  {Limit: 1, Decay: 10},
+	}
+	var costs = map[string][]*RateCost{
+		"/api/":           {{Rule: "weight", Weight: 1}},
+		"/api/v1/depth":   {{Rule: "weight", Weight: 2}},
+		"POST /api/order": {{Rule: "weight", Weight: 1}, {Rule: "order", Weight: 1, PerInstrument: true}},
+		"/api/free":       {},
+	}
+
+	var limiter = NewRateLimiter(BINANCE, RATE_LIMIT_FAIL_FAST, rules, costs)
+	if err := limiter.Acquire(http.MethodGet, "/api/v1/depth?symbol=BTCUSDT", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := limiter.Acquire(http.MethodPost, "/api/order", "BTCUSDT"); err != nil {
+		t.Fatal(err)
+	}
+	// the weight is 3 now, the prefix cost is limited, the empty cost is free.
+	if err := limiter.Acquire(http.MethodGet, "/api/v3/ticker", ""); !errors.Is(err, ErrRateLimited) {
+		t.Error("The weight should be limited: ", err)
+	}
+	if err := limiter.Acquire(http.MethodGet, "/api/free", ""); err != nil {
+		t.Error("The free endpoint should not be limited: ", err)
+	}
+
+	var usages = limiter.Usage()
+	if len(usages) != 2 || usages[0].Rule != "order" || usages[0].Scope != "BTCUSDT" ||
+		usages[1].Rule != "weight" || usages[1].Used != 3 || usages[1].Reset <= 0 {
+		t.Error("The usages are wrong: ", usages[0], usages[1])
+	}
+
+	// the window is reset after the interval, the order counter of the instrument decay.
+	time.Sleep(120 * time.Millisecond)
+	if err := limiter.Acquire(http.MethodPost, "/api/order", "BTCUSDT"); err != nil {
+		t.Error("The limit should be reset: ", err)
+	}
+	if err := limiter.Acquire(http.MethodPost, "/api/order", "BTCUSDT"); !errors.Is(err, ErrRateLimited) {
+		t.Error("The order of the instrument should be limited: ", err)
+	}
+	if err := limiter.Acquire(http.MethodPost, "/api/order", "ETHUSDT"); err != nil {
+		t.Error("The other instrument should not be limited: ", err)
+	}
+
+	// the block mode wait until the counter decay.
+	limiter.Mode = RATE_LIMIT_BLOCK
+	var start = time.Now()
+	if err := limiter.Acquire(http.MethodPost, "/api/order", "ETHUSDT"); err != nil {
+		t.Fatal(err)
+	}
+	if wait := time.Since(start); wait < 50*time.Millisecond {
+		t.Error("The block mode should wait the decay: ", wait)
+	}
+}
+
+// go test -v ./ -count=1 -run=TestRateLimiter_Feedback
+func TestRateLimiter_Feedback(t *testing.T) {
+	var rules = map[string]*RateRule{
+		"weight": {Limit: 10, Interval: time.Minute, Align: true, Header: "X-MBX-USED-WEIGHT-1M"},
+	}
+	var costs = map[string][]*RateCost{"": {{Rule: "weight", Weight: 1}}}
+	var limiter = NewRateLimiter(BINANCE, RATE_LIMIT_FAIL_FAST, rules, costs)
+
+	// the used weight is synced by the header.
+	var header = http.Header{}
+	header.Set("X-MBX-USED-WEIGHT-1M", "9")
+	limiter.Feedback(http.MethodGet, "/api/v3/time", "", header, nil)
+	if err := limiter.Acquire(http.MethodGet, "/api/v3/time", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := limiter.Acquire(http.MethodGet, "/api/v3/time", ""); !errors.Is(err, ErrRateLimited) {
+		t.Error("The synced weight should be limited: ", err)
+	}
+
+	// the retry after of the 429 pause all the requests.
+	limiter.Sync("weight", "", 0)
+	header = http.Header{}
+	header.Set("Retry-After", "60")
+	limiter.Feedback(http.MethodGet, "/api/v3/time", "", header, NewHttpError(http.StatusTooManyRequests, "", ""))
+	var err = limiter.Acquire(http.MethodGet, "/api/v3/time", "")
+	var exchangeErr ExchangeError
+	if !errors.As(err, &exchangeErr) || exchangeErr.Exchange() != BINANCE || exchangeErr.Category() != ErrRateLimited {
+		t.Error("The requests should be paused: ", err)
+	}
+
+	limiter.Mode = RATE_LIMIT_OFF
+	if err := limiter.Acquire(http.MethodGet, "/api/v3/time", ""); err != nil {
+		t.Error("The off mode should not limit: ", err)
+	}
+}
@@ -0,0 +1,301 @@
+package goghostex
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	RECORD_KIND_HTTP = "http"
+	RECORD_KIND_WS   = "ws"
+
+	// The env of the cassette mode, "record" record the live traffic into the cassette,
+	// "live" ignore the cassette, otherwise replay the cassette if it exists.
+	RECORD_MODE_ENV = "GOGHOSTEX_RECORD_MODE"
+	RECORD_MODE_REC = "record"
+	RECORD_MODE_LIV = "live"
+)
+
+// ErrCassetteNotFound the cassette is not found in the replay mode, the test should record it or skip.
+var ErrCassetteNotFound = errors.New("The cassette is not found, record it with GOGHOSTEX_RECORD_MODE=record. ")
+
+// The params changed in every request, they are ignored in matching the recorded request.
+var RECORD_IGNORE_PARAMS = []string{
+	"timestamp", "signature", "recvWindow", "sign", "nonce", "ts",
+}
+
+// Interaction is one line in the cassette, it's a http round trip or a websocket frame.
+type Interaction struct {
+	Kind string `json:"kind"`
+
+	// http
+	Method   string      `json:"method,omitempty"`
+	Url      string      `json:"url,omitempty"`
+	Body     string      `json:"body,omitempty"`
+	Status   int         `json:"status,omitempty"`
+	Header   http.Header `json:"header,omitempty"` // the response header
+	Response string      `json:"response,omitempty"`
+
+	// websocket, the stream is the name of the connection given by the recorder.
+	Stream string `json:"stream,omitempty"`
+	Frame  string `json:"frame,omitempty"`
+}
+
+// Recorder write the interactions into the cassette file line by line.
+type Recorder struct {
+	file   *os.File
+	writer *bufio.Writer
+	locker sync.Mutex
+}
+
+func NewRecorder(path string) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	var file, err = os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+func (this *Recorder) Write(interaction *Interaction) error {
+	var line, err = json.Marshal(interaction)
+	if err != nil {
+		return err
+	}
+
+	this.locker.Lock()
+	defer this.locker.Unlock()
+	if _, err := this.writer.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return this.writer.Flush()
+}
+
+func (this *Recorder) Close() error {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+	if err := this.writer.Flush(); err != nil {
+		return err
+	}
+	return this.file.Close()
+}
+
+// RecordFrames wrap the RecvHandler of the websocket, every frame is recorded before handled.
+func (this *Recorder) RecordFrames(stream string, handler func(string)) func(string) {
+	return func(frame string) {
+		_ = this.Write(&Interaction{Kind: RECORD_KIND_WS, Stream: stream, Frame: frame})
+		handler(frame)
+	}
+}
+
+// RecordTransport record the http round trip, the Transport is the real one, nil means http.DefaultTransport.
+type RecordTransport struct {
+	Transport http.RoundTripper
+	Recorder  *Recorder
+}
+
+func (this *RecordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var transport = this.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	var reqBody, err = readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	if err := this.Recorder.Write(&Interaction{
+		Kind:     RECORD_KIND_HTTP,
+		Method:   req.Method,
+		Url:      req.URL.String(),
+		Body:     reqBody,
+		Status:   resp.StatusCode,
+		Header:   resp.Header.Clone(),
+		Response: string(respBody),
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Cassette is the recorded interactions, it serve them back in the recorded order.
+type Cassette struct {
+	https  map[string][]*Interaction
+	frames map[string][]string
+	locker sync.Mutex
+}
+
+func LoadCassette(path string) (*Cassette, error) {
+	var file, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var cassette = &Cassette{
+		https:  make(map[string][]*Interaction),
+		frames: make(map[string][]string),
+	}
+	var scanner = bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var interaction = &Interaction{}
+		if err := json.Unmarshal(scanner.Bytes(), interaction); err != nil {
+			return nil, err
+		}
+		switch interaction.Kind {
+		case RECORD_KIND_HTTP:
+			var key = cassette.key(interaction.Method, interaction.Url, interaction.Body)
+			cassette.https[key] = append(cassette.https[key], interaction)
+		case RECORD_KIND_WS:
+			cassette.frames[interaction.Stream] = append(cassette.frames[interaction.Stream], interaction.Frame)
+		}
+	}
+	return cassette, scanner.Err()
+}
+
+// RoundTrip serve the recorded response, the same requests are served in the recorded order.
+func (this *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody, err = readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	this.locker.Lock()
+	defer this.locker.Unlock()
+	var key = this.key(req.Method, req.URL.String(), reqBody)
+	var interactions = this.https[key]
+	if len(interactions) == 0 {
+		return nil, errors.New(fmt.Sprintf("There is no recorded response of %s %s. ", req.Method, req.URL.String()))
+	}
+	var interaction = interactions[0]
+	this.https[key] = interactions[1:]
+
+	var header = interaction.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(interaction.Response)),
+		ContentLength: int64(len(interaction.Response)),
+		Request:       req,
+	}, nil
+}
+
+// ReplayFrames push the recorded frames of the stream into the handler, return the count of frames.
+func (this *Cassette) ReplayFrames(stream string, handler func(string)) int {
+	this.locker.Lock()
+	var frames = this.frames[stream]
+	this.locker.Unlock()
+
+	for _, frame := range frames {
+		handler(frame)
+	}
+	return len(frames)
+}
+
+// key is the request without RECORD_IGNORE_PARAMS, the params are sorted by the encoding.
+func (this *Cassette) key(method, rawUrl, body string) string {
+	var u, err = url.Parse(rawUrl)
+	if err != nil {
+		return strings.Join([]string{method, rawUrl, body}, " ")
+	}
+	u.RawQuery = this.strip(u.RawQuery)
+	// only the form body is stripped, the json body is matched as it is.
+	var trimmed = strings.TrimSpace(body)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		body = this.strip(body)
+	}
+	return strings.Join([]string{strings.ToUpper(method), u.String(), body}, " ")
+}
+
+func (this *Cassette) strip(query string) string {
+	var values, err = url.ParseQuery(query)
+	if err != nil {
+		return query
+	}
+	for _, param := range RECORD_IGNORE_PARAMS {
+		values.Del(param)
+	}
+	return values.Encode()
+}
+
+// NewCassetteClient return the http client by the RECORD_MODE_ENV, the close func must be called after the test.
+// In record mode, the traffic of the client is written into the path.
+// In replay mode (default), the client serve the recorded traffic, the ErrCassetteNotFound is returned when
+// the path not exist, the test should skip then, the live traffic is only in the live mode.
+func NewCassetteClient(path string, client *http.Client) (*http.Client, func() error, error) {
+	if client == nil {
+		client = &http.Client{}
+	}
+	var nothing = func() error { return nil }
+
+	switch os.Getenv(RECORD_MODE_ENV) {
+	case RECORD_MODE_REC:
+		var recorder, err = NewRecorder(path)
+		if err != nil {
+			return nil, nothing, err
+		}
+		return &http.Client{
+			Transport: &RecordTransport{Transport: client.Transport, Recorder: recorder},
+			Timeout:   client.Timeout,
+		}, recorder.Close, nil
+	case RECORD_MODE_LIV:
+		return client, nothing, nil
+	default:
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, nothing, fmt.Errorf("%w path: %s", ErrCassetteNotFound, path)
+		}
+		var cassette, err = LoadCassette(path)
+		if err != nil {
+			return nil, nothing, err
+		}
+		return &http.Client{Transport: cassette}, nothing, nil
+	}
+}
+
+func readBody(req *http.Request) (string, error) {
+	if req.Body == nil {
+		return "", nil
+	}
+	var body, err = ioutil.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+	_ = req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return string(body), nil
+}
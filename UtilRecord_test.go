@@ -0,0 +1,121 @@
+package goghostex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// go test -v ./ -count=1 -run=TestCassette_RecordReplay
+func TestCassette_RecordReplay(t *testing.T) {
+	var count = 0
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.Header().Set("X-Mbx-Used-Weight-1m", fmt.Sprintf("%d", count))
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		_, _ = fmt.Fprintf(w, `{"count":%d}`, count)
+	}))
+	defer server.Close()
+
+	var path = filepath.Join(t.TempDir(), "cassette.jsonl")
+	var recorder, err = NewRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var client = &http.Client{Transport: &RecordTransport{Recorder: recorder}}
+
+	// the same request is recorded twice, the signature is changed in every request.
+	for i := 0; i < 2; i++ {
+		var uri = fmt.Sprintf("%s/ticker?symbol=BTCUSDT&timestamp=%d&signature=s%d", server.URL, i, i)
+		if _, err := NewHttpRequest(client, http.MethodGet, uri, "", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := NewHttpRequest(client, http.MethodPost, server.URL+"/error", "b=2&a=1&nonce=1", nil); err == nil {
+		t.Fatal("The 400 should be an error. ")
+	}
+	var frames = recorder.RecordFrames("okex", func(string) {})
+	frames(`{"arg":{"channel":"tickers"}}`)
+	frames(`{"event":"pong"}`)
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var cassette *Cassette
+	if cassette, err = LoadCassette(path); err != nil {
+		t.Fatal(err)
+	}
+	var replay = &http.Client{Transport: cassette}
+	for i := 1; i <= 2; i++ {
+		var uri = fmt.Sprintf("%s/ticker?signature=x&timestamp=99&symbol=BTCUSDT", server.URL)
+		var body, header, err = NewHttpRequestWithHeader(replay, http.MethodGet, uri, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != fmt.Sprintf(`{"count":%d}`, i) {
+			t.Error("The replay response is wrong: ", string(body))
+		}
+		if header.Get("X-Mbx-Used-Weight-1m") != fmt.Sprintf("%d", i) {
+			t.Error("The replay header is wrong: ", header)
+		}
+	}
+	if _, err := NewHttpRequest(replay, http.MethodGet, server.URL+"/ticker?symbol=BTCUSDT", "", nil); err == nil {
+		t.Error("The recorded responses are exhausted, it should be an error. ")
+	}
+	if _, err := NewHttpRequest(replay, http.MethodPost, server.URL+"/error", "a=1&b=2&nonce=5", nil); err == nil {
+		t.Error("The recorded 400 should be an error. ")
+	}
+
+	var received = make([]string, 0)
+	if n := cassette.ReplayFrames("okex", func(frame string) { received = append(received, frame) }); n != 2 {
+		t.Error("The replay frames are wrong: ", n)
+	}
+	if received[1] != `{"event":"pong"}` {
+		t.Error("The frame order is wrong: ", received)
+	}
+	if count != 3 {
+		t.Error("The replay should not touch the server: ", count)
+	}
+}
+
+// go test -v ./ -count=1 -run=TestNewCassetteClient
+func TestNewCassetteClient(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "none.jsonl")
+	var live = &http.Client{}
+
+	_ = os.Setenv(RECORD_MODE_ENV, "")
+	defer os.Unsetenv(RECORD_MODE_ENV)
+	var client, closer, err = NewCassetteClient(path, live)
+	if !errors.Is(err, ErrCassetteNotFound) || client != nil {
+		t.Error("The replay without the cassette should fail. ", err)
+	}
+	_ = closer()
+
+	_ = os.Setenv(RECORD_MODE_ENV, RECORD_MODE_LIV)
+	if client, _, err = NewCassetteClient(path, live); err != nil || client != live {
+		t.Error("The live client should be used in live mode. ", err)
+	}
+
+	_ = os.Setenv(RECORD_MODE_ENV, RECORD_MODE_REC)
+	if client, closer, err = NewCassetteClient(path, live); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.Transport.(*RecordTransport); !ok {
+		t.Error("The client should record in record mode. ")
+	}
+	_ = closer()
+
+	_ = os.Setenv(RECORD_MODE_ENV, "")
+	if client, _, err = NewCassetteClient(path, live); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.Transport.(*Cassette); !ok {
+		t.Error("The client should replay when the cassette exists. ")
+	}
+}
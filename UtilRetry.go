@@ -0,0 +1,136 @@
+package goghostex
+
+import (
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+type RetryOption struct {
+	Times         int           // the max retry times, default 3
+	Backoff       time.Duration // the backoff of the first retry, it's doubled every retry, default 200ms
+	MaxBackoff    time.Duration // default 5s
+	LookupTimeout time.Duration // the deadline of looking up the order by cid after the ambiguous error, default 10s
+}
+
+func (this *RetryOption) init() *RetryOption {
+	var option = RetryOption{}
+	if this != nil {
+		option = *this
+	}
+	if option.Times <= 0 {
+		option.Times = 3
+	}
+	if option.Backoff <= 0 {
+		option.Backoff = 200 * time.Millisecond
+	}
+	if option.MaxBackoff <= 0 {
+		option.MaxBackoff = 5 * time.Second
+	}
+	if option.LookupTimeout <= 0 {
+		option.LookupTimeout = 10 * time.Second
+	}
+	return &option
+}
+
+func (this *RetryOption) backoff(attempt int) time.Duration {
+	var backoff = this.Backoff
+	for i := 0; i < attempt && backoff < this.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > this.MaxBackoff {
+		backoff = this.MaxBackoff
+	}
+	return backoff
+}
+
+// retry the read-only call when the error is retryable.
+func (this *RetryOption) retry(call func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = call(); err == nil || !IsRetryable(err) || attempt >= this.Times {
+			return err
+		}
+		time.Sleep(this.backoff(attempt))
+	}
+}
+
+// IsRetryable the request failed by the network, the rate limit or the unavailable exchange.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrExchangeUnavailable) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// IsAmbiguous the request may arrive at the exchange, the order may be placed or not.
+// The rate limited and the refused connection are not sent, or rejected by the exchange.
+func IsAmbiguous(err error) bool {
+	if !IsRetryable(err) || errors.Is(err, ErrRateLimited) || errors.Is(err, syscall.ECONNREFUSED) {
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return false
+	}
+	var dnsErr *net.DNSError
+	return !errors.As(err, &dnsErr)
+}
+
+// placeOrder place the order at most once. The ambiguous error is resolved by looking up the order by the cid
+// until it's found or the lookup timeout, the order not found may be still in flight, or finished and not in
+// the unfinished orders. So the order is placed again only when the exchange reject the duplicated cid for good,
+// otherwise the ambiguous error is returned. The order without cid is never placed again after the ambiguous error.
+func (this *RetryOption) placeOrder(cid string, dedupe bool, place func() error, lookup func() (bool, error)) error {
+	for attempt := 0; ; attempt++ {
+		var err = place()
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+		if !IsAmbiguous(err) {
+			if attempt >= this.Times {
+				return err
+			}
+			time.Sleep(this.backoff(attempt))
+			continue
+		}
+		if cid == "" {
+			return err
+		}
+		var found, lookupErr = this.lookup(lookup)
+		if found {
+			return nil
+		}
+		if !dedupe || lookupErr != nil || attempt >= this.Times {
+			return err
+		}
+	}
+}
+
+// lookup the order until it's found or the lookup timeout, the request may be still in flight, wait before every lookup.
+// The error of the last lookup is returned, the order is unknown when the last lookup failed.
+func (this *RetryOption) lookup(lookup func() (bool, error)) (bool, error) {
+	var deadline = time.Now().Add(this.LookupTimeout)
+	var err error
+	for i := 0; ; i++ {
+		var wait = time.Until(deadline)
+		if wait <= 0 {
+			return false, err
+		}
+		if backoff := this.backoff(i); backoff < wait {
+			wait = backoff
+		}
+		time.Sleep(wait)
+		var found bool
+		if found, err = lookup(); found {
+			return true, nil
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package goghostex
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// flakySwap fail the PlaceOrder in turn, the bool is whether the order is placed before the error.
+type flakySwap struct {
+	SwapRestAPI
+	placeErrs   []error
+	placeBefore []bool
+	lookupErr   error
+	arrive      int // the lookups before the placed order can be found
+	dedupe      bool
+	orders      map[string]*SwapOrder
+	places      int
+	lookups     int
+	tickers     int
+}
+
+func (this *flakySwap) DedupeCid() bool {
+	return this.dedupe
+}
+
+func (this *flakySwap) PlaceOrder(order *SwapOrder) ([]byte, error) {
+	var i = this.places
+	this.places++
+	if i < len(this.placeErrs) && !this.placeBefore[i] {
+		return nil, this.placeErrs[i]
+	}
+	order.OrderId = fmt.Sprint(this.places)
+	var placed = *order
+	this.orders[order.Cid] = &placed
+	if i < len(this.placeErrs) {
+		return nil, this.placeErrs[i]
+	}
+	return nil, nil
+}
+
+func (this *flakySwap) GetOrder(order *SwapOrder) ([]byte, error) {
+	if this.lookupErr != nil {
+		return nil, this.lookupErr
+	}
+	if this.lookups++; this.lookups <= this.arrive {
+		return nil, ErrOrderNotFound
+	}
+	if placed, exist := this.orders[order.Cid]; exist {
+		*order = *placed
+		return nil, nil
+	}
+	return nil, ErrOrderNotFound
+}
+
+func (this *flakySwap) GetUnFinishOrders(pair Pair) ([]*SwapOrder, []byte, error) {
+	return nil, nil, this.lookupErr
+}
+
+func (this *flakySwap) GetOrders(pair Pair) ([]*SwapOrder, []byte, error) {
+	return nil, nil, this.lookupErr
+}
+
+func (this *flakySwap) GetTicker(pair Pair) (*SwapTicker, []byte, error) {
+	this.tickers++
+	if this.tickers < 3 {
+		return nil, nil, NewHttpError(502, "bad gateway", "")
+	}
+	return &SwapTicker{Pair: pair}, nil, nil
+}
+
+// go test -v ./ -count=1 -run=TestRetrySwap_PlaceOrder
+func TestRetrySwap_PlaceOrder(t *testing.T) {
+	var option = &RetryOption{Backoff: time.Millisecond, LookupTimeout: 50 * time.Millisecond}
+	var newSwap = func(placeErrs []error, placeBefore []bool, lookupErr error) (*flakySwap, *RetrySwap) {
+		var swap = &flakySwap{
+			placeErrs:   placeErrs,
+			placeBefore: placeBefore,
+			lookupErr:   lookupErr,
+			orders:      make(map[string]*SwapOrder),
+		}
+		return swap, NewRetrySwap(swap, option)
+	}
+
+	// the order is placed before the timeout, it's found by the cid.
+	var swap, retry = newSwap([]error{timeoutError{}}, []bool{true}, nil)
+	var order = &SwapOrder{Cid: "cid1", Pair: BTC_USDT}
+	if _, err := retry.PlaceOrder(order); err != nil || swap.places != 1 || order.OrderId != "1" {
+		t.Error("The placed order should be found: ", err, swap.places, order.OrderId)
+	}
+
+	// the order is still in flight at the first lookups, keep looking up until it's found.
+	swap, retry = newSwap([]error{timeoutError{}}, []bool{true}, nil)
+	swap.arrive = 3
+	order = &SwapOrder{Cid: "cid2", Pair: BTC_USDT}
+	if _, err := retry.PlaceOrder(order); err != nil || swap.places != 1 || swap.lookups != 4 {
+		t.Error("The order in flight should be found: ", err, swap.places, swap.lookups)
+	}
+
+	// the order is not found, the exchange may not reject the duplicated cid, do not place it again.
+	swap, retry = newSwap([]error{timeoutError{}}, []bool{false}, nil)
+	order = &SwapOrder{Cid: "cid3", Pair: BTC_USDT}
+	if _, err := retry.PlaceOrder(order); !IsAmbiguous(err) || swap.places != 1 || swap.lookups < 2 {
+		t.Error("The order not found should not be placed again: ", err, swap.places, swap.lookups)
+	}
+
+	// the order is not found, the exchange reject the duplicated cid for good, place it again.
+	swap, retry = newSwap([]error{timeoutError{}}, []bool{false}, nil)
+	swap.dedupe = true
+	order = &SwapOrder{Cid: "cid4", Pair: BTC_USDT}
+	if _, err := retry.PlaceOrder(order); err != nil || swap.places != 2 || len(swap.orders) != 1 {
+		t.Error("The order should be placed again: ", err, swap.places)
+	}
+
+	// the order can not be looked up, do not place it again.
+	swap, retry = newSwap([]error{timeoutError{}}, []bool{false}, errors.New("The lookup failed. "))
+	swap.dedupe = true
+	order = &SwapOrder{Cid: "cid5", Pair: BTC_USDT}
+	if _, err := retry.PlaceOrder(order); !IsAmbiguous(err) || swap.places != 1 {
+		t.Error("The unknown order should not be placed again: ", err, swap.places)
+	}
+
+	// there is no cid, do not place it again.
+	swap, retry = newSwap([]error{timeoutError{}}, []bool{false}, nil)
+	swap.dedupe = true
+	if _, err := retry.PlaceOrder(&SwapOrder{Pair: BTC_USDT}); err == nil || swap.places != 1 {
+		t.Error("The order without cid should not be placed again: ", err, swap.places)
+	}
+
+	// the rate limit is not ambiguous, place it again without lookup.
+	swap, retry = newSwap([]error{NewHttpError(429, "too many requests", "")}, []bool{false}, errors.New("The lookup failed. "))
+	if _, err := retry.PlaceOrder(&SwapOrder{Cid: "cid6", Pair: BTC_USDT}); err != nil || swap.places != 2 {
+		t.Error("The rate limited order should be placed again: ", err, swap.places)
+	}
+
+	// the business error is returned directly.
+	swap, retry = newSwap([]error{ErrInsufficientBalance}, []bool{false}, nil)
+	if _, err := retry.PlaceOrder(&SwapOrder{Cid: "cid7", Pair: BTC_USDT}); err != ErrInsufficientBalance || swap.places != 1 {
+		t.Error("The business error should be returned: ", err, swap.places)
+	}
+}
+
+// go test -v ./ -count=1 -run=TestRetrySwap_Read
+func TestRetrySwap_Read(t *testing.T) {
+	var swap = &flakySwap{}
+	var retry = NewRetrySwap(swap, &RetryOption{Backoff: time.Millisecond})
+	var ticker, _, err = retry.GetTicker(BTC_USDT)
+	if err != nil || ticker == nil || swap.tickers != 3 {
+		t.Error("The ticker should be retried: ", err, swap.tickers)
+	}
+
+	swap = &flakySwap{}
+	retry = NewRetrySwap(swap, &RetryOption{Times: 1, Backoff: time.Millisecond})
+	if _, _, err = retry.GetTicker(BTC_USDT); !errors.Is(err, ErrExchangeUnavailable) || swap.tickers != 2 {
+		t.Error("The retry times is wrong: ", err, swap.tickers)
+	}
+}
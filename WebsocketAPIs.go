@@ -0,0 +1,174 @@
+package goghostex
+
+import (
+	"sync/atomic"
+)
+
+// MarketWebsocketAPI is the typed market data stream, the adapter in every exchange
+// decode the raw websocket message into the models and call the handlers in MarketHandler.
+type MarketWebsocketAPI interface {
+	GetExchangeName() string
+
+	SubscribeTicker(pair Pair) error
+	SubscribeTrade(pair Pair) error
+	SubscribeKline(pair Pair, period int) error
+	SubscribeFundingRate(pair Pair) error
+	SubscribeMarkPrice(pair Pair) error
+
+	Start() error
+
+	Stop()
+
+	Restart()
+}
+
+// MarketHandler is the callbacks of the typed market data. The spot stream call the
+// TickerHandler and KlineHandler, the swap stream call the SwapTickerHandler and
+// SwapKlineHandler. The nil handler means drop the data.
+type MarketHandler struct {
+	TickerHandler      func(*Ticker)
+	SwapTickerHandler  func(*SwapTicker)
+	TradeHandler       func(*Trade)
+	KlineHandler       func(*Kline)
+	SwapKlineHandler   func(*SwapKline)
+	FundingRateHandler func(*FundingRate)
+	MarkPriceHandler   func(*MarkPrice)
+}
+
+func (h *MarketHandler) OnTicker(ticker *Ticker) {
+	if h.TickerHandler != nil {
+		h.TickerHandler(ticker)
+	}
+}
+
+func (h *MarketHandler) OnSwapTicker(ticker *SwapTicker) {
+	if h.SwapTickerHandler != nil {
+		h.SwapTickerHandler(ticker)
+	}
+}
+
+func (h *MarketHandler) OnTrade(trade *Trade) {
+	if h.TradeHandler != nil {
+		h.TradeHandler(trade)
+	}
+}
+
+func (h *MarketHandler) OnKline(kline *Kline) {
+	if h.KlineHandler != nil {
+		h.KlineHandler(kline)
+	}
+}
+
+func (h *MarketHandler) OnSwapKline(kline *SwapKline) {
+	if h.SwapKlineHandler != nil {
+		h.SwapKlineHandler(kline)
+	}
+}
+
+func (h *MarketHandler) OnFundingRate(rate *FundingRate) {
+	if h.FundingRateHandler != nil {
+		h.FundingRateHandler(rate)
+	}
+}
+
+func (h *MarketHandler) OnMarkPrice(mark *MarkPrice) {
+	if h.MarkPriceHandler != nil {
+		h.MarkPriceHandler(mark)
+	}
+}
+
+// MarketChans build the MarketHandler which send the data to the channels,
+// if you prefer reading the channels in the loop. Every channel is buffered with the size.
+// The data is dropped when the channel is full, the websocket is not blocked by the slow reader.
+// The dropped data is counted, and the DropHandler is called with the name of the channel if it's not nil.
+type MarketChans struct {
+	Ticker      chan *Ticker
+	SwapTicker  chan *SwapTicker
+	Trade       chan *Trade
+	Kline       chan *Kline
+	SwapKline   chan *SwapKline
+	FundingRate chan *FundingRate
+	MarkPrice   chan *MarkPrice
+
+	DropHandler func(channel string)
+
+	dropped int64
+}
+
+func NewMarketChans(size int) *MarketChans {
+	return &MarketChans{
+		Ticker:      make(chan *Ticker, size),
+		SwapTicker:  make(chan *SwapTicker, size),
+		Trade:       make(chan *Trade, size),
+		Kline:       make(chan *Kline, size),
+		SwapKline:   make(chan *SwapKline, size),
+		FundingRate: make(chan *FundingRate, size),
+		MarkPrice:   make(chan *MarkPrice, size),
+	}
+}
+
+// Dropped the count of the data dropped by the full channels.
+func (c *MarketChans) Dropped() int64 {
+	return atomic.LoadInt64(&c.dropped)
+}
+
+func (c *MarketChans) drop(channel string) {
+	atomic.AddInt64(&c.dropped, 1)
+	if c.DropHandler != nil {
+		c.DropHandler(channel)
+	}
+}
+
+func (c *MarketChans) Handler() MarketHandler {
+	return MarketHandler{
+		TickerHandler: func(v *Ticker) {
+			select {
+			case c.Ticker <- v:
+			default:
+				c.drop("Ticker")
+			}
+		},
+		SwapTickerHandler: func(v *SwapTicker) {
+			select {
+			case c.SwapTicker <- v:
+			default:
+				c.drop("SwapTicker")
+			}
+		},
+		TradeHandler: func(v *Trade) {
+			select {
+			case c.Trade <- v:
+			default:
+				c.drop("Trade")
+			}
+		},
+		KlineHandler: func(v *Kline) {
+			select {
+			case c.Kline <- v:
+			default:
+				c.drop("Kline")
+			}
+		},
+		SwapKlineHandler: func(v *SwapKline) {
+			select {
+			case c.SwapKline <- v:
+			default:
+				c.drop("SwapKline")
+			}
+		},
+		FundingRateHandler: func(v *FundingRate) {
+			select {
+			case c.FundingRate <- v:
+			default:
+				c.drop("FundingRate")
+			}
+		},
+		MarkPriceHandler: func(v *MarkPrice) {
+			select {
+			case c.MarkPrice <- v:
+			default:
+				c.drop("MarkPrice")
+			}
+		},
+	}
+}
@@ -0,0 +1,24 @@
+package goghostex
+
+import (
+	"testing"
+)
+
+// go test -v ./ -count=1 -run=TestMarketChans_Drop
+func TestMarketChans_Drop(t *testing.T) {
+	var chans = NewMarketChans(1)
+	var drops = make([]string, 0)
+	chans.DropHandler = func(channel string) { drops = append(drops, channel) }
+
+	var handler = chans.Handler()
+	handler.OnTrade(&Trade{Tid: 1})
+	handler.OnTrade(&Trade{Tid: 2})
+	handler.OnSwapTicker(&SwapTicker{Last: 1})
+
+	if chans.Dropped() != 1 || len(drops) != 1 || drops[0] != "Trade" {
+		t.Error("The full channel should drop the data: ", chans.Dropped(), drops)
+	}
+	if trade := <-chans.Trade; trade.Tid != 1 || len(chans.SwapTicker) != 1 {
+		t.Error("The data in the channel is wrong: ", trade)
+	}
+}
@@ -49,6 +49,7 @@ var _INERNAL_KLINE_PERIOD_CONVERTER = map[int]string{
 	KLINE_PERIOD_15MIN:  "15m",
 	KLINE_PERIOD_30MIN:  "30m",
 	KLINE_PERIOD_60MIN:  "1h",
+	KLINE_PERIOD_1H:     "1h",
 	KLINE_PERIOD_2H:     "2h",
 	KLINE_PERIOD_4H:     "4h",
 	KLINE_PERIOD_6H:     "6h",
@@ -67,6 +68,7 @@ var _INERNAL_KLINE_SECOND_CONVERTER = map[int]int{
 	KLINE_PERIOD_15MIN:  15 * 60 * 1000,
 	KLINE_PERIOD_30MIN:  30 * 60 * 1000,
 	KLINE_PERIOD_60MIN:  60 * 60 * 1000,
+	KLINE_PERIOD_1H:     60 * 60 * 1000,
 	KLINE_PERIOD_2H:     2 * 60 * 60 * 1000,
 	KLINE_PERIOD_4H:     4 * 60 * 60 * 1000,
 	KLINE_PERIOD_6H:     6 * 60 * 60 * 1000,
@@ -78,8 +80,21 @@ var _INERNAL_KLINE_SECOND_CONVERTER = map[int]int{
 	KLINE_PERIOD_1MONTH: 30.5 * 24 * 60 * 60 * 1000,
 }
 
+// the period not in the converter is resampled from the smaller one, eg: 1YEAR.
+func newKlineFallback(location *time.Location) *KlineFallback {
+	return &KlineFallback{
+		Exchange: BINANCE,
+		Location: location,
+		MaxSize:  1000,
+		Supported: func(period int) bool {
+			var _, exist = _INERNAL_KLINE_PERIOD_CONVERTER[period]
+			return exist
+		},
+	}
+}
+
 func New(config *APIConfig) *Binance {
-	var binance = &Binance{config: config}
+	var binance = &Binance{config: config, Limiter: newRateLimiter(config)}
 	binance.Spot = &Spot{Binance: binance}
 	binance.Margin = &Margin{Binance: binance}
 	binance.Swap = &Swap{
@@ -100,12 +115,13 @@ func New(config *APIConfig) *Binance {
 }
 
 type Binance struct {
-	config *APIConfig
-	Spot   *Spot
-	Margin *Margin
-	Swap   *Swap
-	Future *Future
-	One    *One
+	config  *APIConfig
+	Spot    *Spot
+	Margin  *Margin
+	Swap    *Swap
+	Future  *Future
+	One     *One
+	Limiter *RateLimiter // shared by spot margin swap and future
 }
 
 func (this *Binance) GetExchangeName() string {
@@ -123,7 +139,10 @@ func (this *Binance) buildParamsSigned(postForm *url.Values) error {
 }
 
 func (this *Binance) DoRequest(httpMethod, uri, reqBody string, response interface{}) ([]byte, error) {
-	resp, err := NewHttpRequest(
+	if err := this.Limiter.Acquire(httpMethod, uri, ""); err != nil {
+		return nil, err
+	}
+	resp, header, err := NewHttpRequestWithHeader(
 		this.config.HttpClient,
 		httpMethod,
 		this.config.Endpoint+uri,
@@ -132,9 +151,10 @@ func (this *Binance) DoRequest(httpMethod, uri, reqBody string, response interfa
 			"X-MBX-APIKEY": this.config.ApiKey,
 		},
 	)
+	this.Limiter.Feedback(httpMethod, uri, "", header, err)
 
 	if err != nil {
-		return nil, err
+		return nil, binanceHttpError(err)
 	} else {
 		nowTimestamp := time.Now().Unix() * 1000
 		if this.config.LastTimestamp < nowTimestamp {
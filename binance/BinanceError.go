@@ -0,0 +1,49 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The error codes of binance api, https://binance-docs.github.io/apidocs/spot/en/#error-codes
+var _BINANCE_ERROR_CODES = map[string]Error{
+	"-1001": ErrExchangeUnavailable, // DISCONNECTED, internal error
+	"-1003": ErrRateLimited,         // TOO_MANY_REQUESTS
+	"-1015": ErrRateLimited,         // TOO_MANY_ORDERS
+	"-1022": ErrAuthFailed,          // INVALID_SIGNATURE
+	"-2014": ErrAuthFailed,          // BAD_API_KEY_FMT
+	"-2015": ErrAuthFailed,          // REJECTED_MBX_KEY
+	"-2010": ErrInsufficientBalance, // NEW_ORDER_REJECTED, mostly the balance is insufficient
+	"-2019": ErrInsufficientBalance, // MARGIN_NOT_SUFFICIEN
+	"-2011": ErrOrderNotFound,       // CANCEL_REJECTED, unknown order sent
+	"-2013": ErrOrderNotFound,       // NO_SUCH_ORDER
+	"-1013": ErrInvalidPrice,        // INVALID_MESSAGE, the filter failure of the price
+	"-1111": ErrInvalidPrice,        // BAD_PRECISION
+	"-4014": ErrInvalidPrice,        // PRICE_NOT_INCREASED_BY_TICK_SIZE
+	"-5022": ErrPostOnlyRejected,    // the post only order will be rejected in futures
+}
+
+// binanceHttpError the non 2xx response of binance has the code and msg.
+func binanceHttpError(err error) error {
+	var body, exist = HttpErrorResponse(err)
+	if !exist {
+		return err
+	}
+	var response = struct {
+		Code int64  `json:"code"`
+		Msg  string `json:"msg"`
+	}{}
+	if json.Unmarshal([]byte(body), &response) != nil || response.Code == 0 {
+		return NewExchangeError(BINANCE, _BINANCE_ERROR_CODES, "", "", err)
+	}
+
+	var binanceErr = NewExchangeError(BINANCE, _BINANCE_ERROR_CODES, fmt.Sprint(response.Code), "", err)
+	// the spot return -2010 when the LIMIT_MAKER order would immediately match and take.
+	if response.Code == -2010 && strings.Contains(response.Msg, "immediately match") {
+		binanceErr = NewExchangeError(BINANCE, map[string]Error{"-2010": ErrPostOnlyRejected}, "-2010", "", err)
+	}
+	return binanceErr
+}
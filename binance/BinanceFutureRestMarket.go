@@ -231,6 +231,14 @@ func (future *Future) GetKlineRecords(
 	if contractType == THIS_WEEK_CONTRACT || contractType == NEXT_WEEK_CONTRACT {
 		return nil, nil, errors.New("binance have not the this_week next_week contract. ")
 	}
+	if _, exist := _INERNAL_KLINE_PERIOD_CONVERTER[period]; !exist {
+		return newKlineFallback(future.config.Location).FutureKlines(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*FutureKline, []byte, error) {
+				return future.GetKlineRecords(contractType, pair, period, size, int(since))
+			},
+		)
+	}
 
 	var endTimestamp = since + size*_INERNAL_KLINE_SECOND_CONVERTER[period]
 	if endTimestamp > since+200*24*60*60*1000 {
@@ -300,6 +308,14 @@ func (future *Future) GetCandles(
 	size int,
 	since int64,
 ) ([]*FutureCandle, []byte, error) {
+	if _, exist := _INERNAL_KLINE_PERIOD_CONVERTER[period]; !exist {
+		return newKlineFallback(future.config.Location).FutureCandles(
+			period, size, since,
+			func(period, size int, since int64) ([]*FutureCandle, []byte, error) {
+				return future.GetCandles(dueTimestamp, symbol, period, size, since)
+			},
+		)
+	}
 
 	var contract, err = future.getContractByDueTimestamp(symbol, dueTimestamp)
 	if err != nil {
@@ -450,7 +466,10 @@ func (future *Future) KeepAlive() {
 }
 
 func (future *Future) DoRequest(httpMethod, endPoint, uri, reqBody string, response interface{}) ([]byte, error) {
-	resp, err := NewHttpRequest(
+	if err := future.Limiter.Acquire(httpMethod, uri, ""); err != nil {
+		return nil, err
+	}
+	resp, header, err := NewHttpRequestWithHeader(
 		future.config.HttpClient,
 		httpMethod,
 		endPoint+uri,
@@ -459,8 +478,9 @@ func (future *Future) DoRequest(httpMethod, endPoint, uri, reqBody string, respo
 			"X-MBX-APIKEY": future.config.ApiKey,
 		},
 	)
+	future.Limiter.Feedback(httpMethod, uri, "", header, err)
 	if err != nil {
-		return nil, err
+		return nil, binanceHttpError(err)
 	} else {
 		var nowTimestamp = time.Now().Unix() * 1000
 		if future.LastTimestamp < nowTimestamp {
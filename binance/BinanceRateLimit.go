@@ -0,0 +1,61 @@
+package binance
+
+import (
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The ip weight of binance is counted by the api (spot sapi fapi dapi), the weight reset every minute.
+// https://binance-docs.github.io/apidocs/spot/en/#limits
+var _BINANCE_RATE_RULES = map[string]*RateRule{
+	"spot_weight": {Limit: 6000, Interval: time.Minute, Align: true, Header: "X-MBX-USED-WEIGHT-1M"},
+	"spot_order":  {Limit: 100, Interval: 10 * time.Second, Align: true, Header: "X-MBX-ORDER-COUNT-10S"},
+	"sapi_weight": {Limit: 12000, Interval: time.Minute, Align: true, Header: "X-SAPI-USED-IP-WEIGHT-1M"},
+	"fapi_weight": {Limit: 2400, Interval: time.Minute, Align: true, Header: "X-MBX-USED-WEIGHT-1M"},
+	"fapi_order":  {Limit: 1200, Interval: time.Minute, Align: true, Header: "X-MBX-ORDER-COUNT-1M"},
+	"dapi_weight": {Limit: 2400, Interval: time.Minute, Align: true, Header: "X-MBX-USED-WEIGHT-1M"},
+	"dapi_order":  {Limit: 1200, Interval: time.Minute, Align: true, Header: "X-MBX-ORDER-COUNT-1M"},
+}
+
+// the weight of the endpoints, the others are 1.
+var _BINANCE_RATE_COSTS = map[string][]*RateCost{
+	"/api/":                      {{Rule: "spot_weight", Weight: 1}},
+	"/api/v1/ticker/24hr":        {{Rule: "spot_weight", Weight: 2}},
+	"/api/v1/depth":              {{Rule: "spot_weight", Weight: 5}},
+	"/api/v1/klines":             {{Rule: "spot_weight", Weight: 2}},
+	"/api/v3/exchangeInfo":       {{Rule: "spot_weight", Weight: 20}},
+	"/api/v3/account":            {{Rule: "spot_weight", Weight: 20}},
+	"/api/v3/openOrders":         {{Rule: "spot_weight", Weight: 6}},
+	"/api/v3/allOrders":          {{Rule: "spot_weight", Weight: 20}},
+	"GET /api/v3/order":          {{Rule: "spot_weight", Weight: 4}},
+	"POST /api/v3/order":         {{Rule: "spot_weight", Weight: 1}, {Rule: "spot_order", Weight: 1}},
+	"/sapi/":                     {{Rule: "sapi_weight", Weight: 1}},
+	"/sapi/v1/margin/account":    {{Rule: "sapi_weight", Weight: 10}},
+	"/sapi/v1/margin/openOrders": {{Rule: "sapi_weight", Weight: 10}},
+	"/sapi/v1/margin/allOrders":  {{Rule: "sapi_weight", Weight: 200}},
+	"/fapi/":                     {{Rule: "fapi_weight", Weight: 1}},
+	"/fapi/v1/depth":             {{Rule: "fapi_weight", Weight: 5}},
+	"/fapi/v1/klines":            {{Rule: "fapi_weight", Weight: 5}},
+	"/fapi/v1/continuousKlines":  {{Rule: "fapi_weight", Weight: 5}},
+	"/fapi/v1/allOrders":         {{Rule: "fapi_weight", Weight: 5}},
+	"/fapi/v1/account":           {{Rule: "fapi_weight", Weight: 5}},
+	"/fapi/v2/account":           {{Rule: "fapi_weight", Weight: 5}},
+	"/fapi/v1/positionRisk":      {{Rule: "fapi_weight", Weight: 5}},
+	"/fapi/v1/income":            {{Rule: "fapi_weight", Weight: 30}},
+	"POST /fapi/v1/order":        {{Rule: "fapi_weight", Weight: 0}, {Rule: "fapi_order", Weight: 1}},
+	"/dapi/":                     {{Rule: "dapi_weight", Weight: 1}},
+	"/dapi/v1/depth":             {{Rule: "dapi_weight", Weight: 5}},
+	"/dapi/v1/klines":            {{Rule: "dapi_weight", Weight: 5}},
+	"/dapi/v1/continuousKlines":  {{Rule: "dapi_weight", Weight: 5}},
+	"/dapi/v1/trades":            {{Rule: "dapi_weight", Weight: 5}},
+	"/dapi/v1/premiumIndex":      {{Rule: "dapi_weight", Weight: 10}},
+	"/dapi/v1/allOrders":         {{Rule: "dapi_weight", Weight: 20}},
+	"/dapi/v1/account":           {{Rule: "dapi_weight", Weight: 5}},
+	"/dapi/v1/income":            {{Rule: "dapi_weight", Weight: 20}},
+	"POST /dapi/v1/order":        {{Rule: "dapi_weight", Weight: 0}, {Rule: "dapi_order", Weight: 1}},
+}
+
+func newRateLimiter(config *APIConfig) *RateLimiter {
+	return NewRateLimiter(BINANCE, config.RateLimit, _BINANCE_RATE_RULES, _BINANCE_RATE_COSTS)
+}
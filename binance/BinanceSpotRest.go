@@ -125,6 +125,15 @@ func (spot *Spot) GetDepth(pair Pair, size int) (*Depth, []byte, error) {
 }
 
 func (spot *Spot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline, []byte, error) {
+	if _, exist := _INERNAL_KLINE_PERIOD_CONVERTER[period]; !exist {
+		return newKlineFallback(spot.config.Location).Klines(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*Kline, []byte, error) {
+				return spot.GetKlineRecords(pair, period, size, int(since))
+			},
+		)
+	}
+
 	startTimeFmt, endTimeFmt := fmt.Sprintf("%d", since), fmt.Sprintf("%d", time.Now().UnixNano())
 	if len(startTimeFmt) > 13 {
 		startTimeFmt = startTimeFmt[0:13]
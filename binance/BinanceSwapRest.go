@@ -46,6 +46,7 @@ type Swap struct {
 
 	nextUpdateContractTime time.Time // 下一次更新交易所contract信息
 	LastKeepLiveTime       time.Time // 上一次keep live时间。
+	keepLiveMux            sync.Mutex
 }
 
 func (swap *Swap) GetTicker(pair Pair) (*SwapTicker, []byte, error) {
@@ -332,6 +333,15 @@ func (swap *Swap) GetLimit(pair Pair) (float64, float64, error) {
 }
 
 func (swap *Swap) GetKline(pair Pair, period, size, since int) ([]*SwapKline, []byte, error) {
+	if _, exist := _INERNAL_KLINE_PERIOD_CONVERTER[period]; !exist {
+		return newKlineFallback(swap.config.Location).SwapKlines(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*SwapKline, []byte, error) {
+				return swap.GetKline(pair, period, size, int(since))
+			},
+		)
+	}
+
 	var contract = swap.GetContract(pair)
 
 	if size > 1500 {
@@ -981,28 +991,37 @@ func (swap *Swap) DoRequest(httpMethod, uri, reqBody string, response interface{
 	} else {
 		bnUrl = SWAP_BASIS_ENDPOINT + uri
 	}
-	resp, err := NewHttpRequest(
+	if err := swap.Limiter.Acquire(httpMethod, uri, ""); err != nil {
+		return nil, err
+	}
+	resp, respHeader, err := NewHttpRequestWithHeader(
 		swap.config.HttpClient,
 		httpMethod,
 		bnUrl,
 		reqBody,
 		header,
 	)
+	swap.Limiter.Feedback(httpMethod, uri, "", respHeader, err)
 
 	if err != nil {
-		return nil, err
+		return nil, binanceHttpError(err)
 	} else {
 		now := time.Now()
+		swap.keepLiveMux.Lock()
 		if swap.LastKeepLiveTime.Before(now) {
 			swap.LastKeepLiveTime = now
 		}
+		swap.keepLiveMux.Unlock()
 		return resp, json.Unmarshal(resp, &response)
 	}
 }
 
 func (swap *Swap) KeepAlive() {
 	// last timestamp in 5s, no need to keep alive
-	if (time.Now().Unix() - swap.LastKeepLiveTime.Unix()) < 5 {
+	swap.keepLiveMux.Lock()
+	var lastKeepLiveTime = swap.LastKeepLiveTime
+	swap.keepLiveMux.Unlock()
+	if (time.Now().Unix() - lastKeepLiveTime.Unix()) < 5 {
 		return
 	}
 	_, _ = swap.GetFundingFee(Pair{Basis: BTC, Counter: USDT})
@@ -26,6 +26,23 @@ type LocalOrderBooks struct {
 
 	// if the channel is not nil, send the update message to the channel. User should read the channel in the loop.
 	UpdateChan chan string
+
+	// ResyncHandler rebuild the book of the product when the sequence gap or the divergence found,
+	// the default get the snapshot in the goroutine.
+	ResyncHandler func(productId string)
+
+	// the maps above are written by the receiver, the snapshot and the verify goroutine, the lock of
+	// the product in OrderBookMuxs only guard the levels of the book.
+	booksMux sync.RWMutex
+
+	// the top levels are cross verified with the rest depth in every interval, it's off when the interval is 0.
+	VerifyInterval  time.Duration
+	VerifyLevels    int     // default 20
+	VerifyThreshold float64 // the divergence to force the resync, default 0.5
+
+	integrity      map[string]*BookIntegrity
+	integrityMux   sync.Mutex
+	stopVerifySign chan bool
 }
 
 type DeltaOrderBook struct {
@@ -59,23 +76,38 @@ func (this *LocalOrderBooks) Init() error {
 	if this.Cache == nil {
 		this.Cache = make(map[string][]*DeltaOrderBook)
 	}
+	if this.integrity == nil {
+		this.integrity = make(map[string]*BookIntegrity)
+	}
 	this.RecvHandler = func(s string) {
 		this.ReceiveDelta(s)
 	}
 	//this.WSTradeUMBN.ErrorHandler = func(err error) {
 	//
 	//}
-	return this.Start()
+	if err := this.Start(); err != nil {
+		return err
+	}
+	if this.VerifyInterval > 0 && this.stopVerifySign == nil {
+		this.stopVerifySign = make(chan bool, 1)
+		go this.verifyRoutine(this.stopVerifySign)
+	}
+	return nil
 }
 
 func (this *LocalOrderBooks) Restart() {
+	this.booksMux.Lock()
 	for productId, _ := range this.OrderBookMuxs {
 		var mux = this.OrderBookMuxs[productId]
+		if mux == nil {
+			continue
+		}
 		mux.Lock()
 		this.OrderBookMuxs[productId] = nil
 		this.Cache[productId] = nil
 		mux.Unlock()
 	}
+	this.booksMux.Unlock()
 
 	this.WSMarketUMBN.Restart()
 }
@@ -93,17 +125,32 @@ func (this *LocalOrderBooks) ReceiveDelta(msg string) {
 	}
 
 	var productId = strings.Split(delta.Stream, "@")[0]
+	var updated, gap = this.applyDelta(productId, delta.Data)
+	if gap {
+		// 有丢包现象，需要重新申请snapshot
+		this.resync(productId)
+	}
+	if updated && this.UpdateChan != nil {
+		this.UpdateChan <- fmt.Sprintf("%s:%d", productId, delta.Data.Timestamp)
+	}
+}
+
+// applyDelta update the book by the delta, return the book is updated or the sequence gap is found.
+func (this *LocalOrderBooks) applyDelta(productId string, data *DeltaOrderBook) (bool, bool) {
+	this.booksMux.Lock()
+	defer this.booksMux.Unlock()
+
 	// 如果还没有锁，说明还没有申请过snapshot，或者snapshot重置了。
 	if this.OrderBookMuxs[productId] == nil {
 		this.BidData[productId] = make(map[int64]float64)
 		this.AskData[productId] = make(map[int64]float64)
 		if this.Cache[productId] == nil {
-			this.Cache[productId] = []*DeltaOrderBook{delta.Data}
+			this.Cache[productId] = []*DeltaOrderBook{data}
 			go this.getSnapshot(productId, 0)
 		} else {
-			this.Cache[productId] = append(this.Cache[productId], delta.Data)
+			this.Cache[productId] = append(this.Cache[productId], data)
 		}
-		return
+		return false, false
 	}
 
 	//	已经有了snapshot，则直接处理delta
@@ -141,33 +188,28 @@ func (this *LocalOrderBooks) ReceiveDelta(msg string) {
 		this.Cache[productId] = make([]*DeltaOrderBook, 0)
 	}
 
-	if !withCache && delta.Data.PrevSeq != this.SeqData[productId] {
-		// 有丢包现象，需要重新申请snapshot
-		go this.getSnapshot(productId, 0)
-	} else {
-		for _, bid := range delta.Data.Bids {
-			var price, _ = strconv.ParseFloat(bid[0], 64)
-			var stdPrice = int64(price * 100000000)
-			var volume, _ = strconv.ParseFloat(bid[1], 64)
-
-			this.BidData[productId][stdPrice] = volume
-		}
-
-		for _, ask := range delta.Data.Asks {
-			var price, _ = strconv.ParseFloat(ask[0], 64)
-			var stdPrice = int64(price * 100000000)
-			var volume, _ = strconv.ParseFloat(ask[1], 64)
+	if !withCache && data.PrevSeq != this.SeqData[productId] {
+		return false, true
+	}
 
-			this.AskData[productId][stdPrice] = volume
-		}
-		this.SeqData[productId] = delta.Data.EndSeq
-		this.TsData[productId] = delta.Data.Timestamp
+	for _, bid := range data.Bids {
+		var price, _ = strconv.ParseFloat(bid[0], 64)
+		var stdPrice = int64(price * 100000000)
+		var volume, _ = strconv.ParseFloat(bid[1], 64)
 
-		if this.UpdateChan != nil {
-			this.UpdateChan <- fmt.Sprintf("%s:%d", productId, delta.Data.Timestamp)
-		}
+		this.BidData[productId][stdPrice] = volume
 	}
 
+	for _, ask := range data.Asks {
+		var price, _ = strconv.ParseFloat(ask[0], 64)
+		var stdPrice = int64(price * 100000000)
+		var volume, _ = strconv.ParseFloat(ask[1], 64)
+
+		this.AskData[productId][stdPrice] = volume
+	}
+	this.SeqData[productId] = data.EndSeq
+	this.TsData[productId] = data.Timestamp
+	return true, false
 }
 
 func (this *LocalOrderBooks) getPairByProductId(productId string) Pair {
@@ -182,12 +224,7 @@ func (this *LocalOrderBooks) getPairByProductId(productId string) Pair {
 }
 
 func (this *LocalOrderBooks) getSnapshot(productId string, times int) {
-	if this.OrderBookMuxs[productId] != nil {
-		var mux = this.OrderBookMuxs[productId]
-		mux.Lock()
-		this.OrderBookMuxs[productId] = nil
-		this.Cache[productId] = nil
-		mux.Unlock()
+	if this.resetBook(productId) {
 		return
 	}
 	if times > 5 {
@@ -206,9 +243,13 @@ func (this *LocalOrderBooks) getSnapshot(productId string, times int) {
 		return
 	}
 
+	this.booksMux.Lock()
+	defer this.booksMux.Unlock()
+	if this.BidData[productId] == nil || this.AskData[productId] == nil {
+		this.BidData[productId] = make(map[int64]float64)
+		this.AskData[productId] = make(map[int64]float64)
+	}
 	this.OrderBookMuxs[productId] = &sync.Mutex{}
-	var mux = this.OrderBookMuxs[productId]
-	mux.Lock()
 	this.SeqData[productId] = depth.Sequence
 
 	for _, bid := range depth.BidList {
@@ -220,7 +261,22 @@ func (this *LocalOrderBooks) getSnapshot(productId string, times int) {
 		var stdPrice = int64(ask.Price * 100000000)
 		this.AskData[productId][stdPrice] = ask.Amount
 	}
+}
+
+// resetBook drop the book of the product, the next delta get the new snapshot. Return false when there is no book.
+func (this *LocalOrderBooks) resetBook(productId string) bool {
+	this.booksMux.Lock()
+	defer this.booksMux.Unlock()
+
+	var mux = this.OrderBookMuxs[productId]
+	if mux == nil {
+		return false
+	}
+	mux.Lock()
+	this.OrderBookMuxs[productId] = nil
+	this.Cache[productId] = nil
 	mux.Unlock()
+	return true
 }
 
 func (this *LocalOrderBooks) Snapshot(pair Pair) (*Depth, error) {
@@ -231,6 +287,9 @@ func (this *LocalOrderBooks) Snapshot(pair Pair) (*Depth, error) {
 }
 
 func (this *LocalOrderBooks) SnapshotById(productId string) (*Depth, error) {
+	this.booksMux.RLock()
+	defer this.booksMux.RUnlock()
+
 	if this.BidData[productId] == nil || this.AskData[productId] == nil || this.OrderBookMuxs[productId] == nil {
 		return nil, fmt.Errorf("The order book data is not ready or you need subscribe the productid. ")
 	}
@@ -0,0 +1,63 @@
+package binance
+
+import (
+	. "github.com/deforceHK/goghostex"
+)
+
+// SwapBookFeed the LocalOrderBooks as the OrderBookFeed, the UpdateChan of the books is read by the feed.
+type SwapBookFeed struct {
+	*LocalOrderBooks
+	relay *BookUpdateRelay
+}
+
+func NewSwapBookFeed(books *LocalOrderBooks) *SwapBookFeed {
+	return &SwapBookFeed{
+		LocalOrderBooks: books,
+		relay:           NewBookUpdateRelay(BINANCE, TRADE_TYPE_SWAP),
+	}
+}
+
+func (this *SwapBookFeed) GetExchangeName() string {
+	return BINANCE
+}
+
+func (this *SwapBookFeed) GetMarket() string {
+	return TRADE_TYPE_SWAP
+}
+
+func (this *SwapBookFeed) Start() error {
+	if this.UpdateChan == nil {
+		this.UpdateChan = make(chan string, 1024)
+	}
+	this.relay.Start(this.UpdateChan)
+	return this.LocalOrderBooks.Init()
+}
+
+func (this *SwapBookFeed) Stop() {
+	this.relay.Stop()
+	this.LocalOrderBooks.Stop()
+}
+
+func (this *SwapBookFeed) Subscribe(pair Pair) {
+	this.relay.Bind(pair.ToSymbol("", false), pair)
+	this.LocalOrderBooks.Subscribe(pair)
+}
+
+func (this *SwapBookFeed) Unsubscribe(pair Pair) {
+	this.LocalOrderBooks.Unsubscribe(pair)
+	this.relay.Unbind(pair.ToSymbol("", false))
+}
+
+// Snapshot the pair of the binance book is parsed from the productId, it's replaced by the pair.
+func (this *SwapBookFeed) Snapshot(pair Pair) (*Depth, error) {
+	var depth, err = this.LocalOrderBooks.Snapshot(pair)
+	if err != nil {
+		return nil, err
+	}
+	depth.Pair = pair
+	return depth, nil
+}
+
+func (this *SwapBookFeed) SetUpdateHandler(handler func(*BookUpdate)) {
+	this.relay.SetUpdateHandler(handler)
+}
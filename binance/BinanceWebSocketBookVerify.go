@@ -0,0 +1,204 @@
+package binance
+
+import (
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+const (
+	DEFAULT_VERIFY_LEVELS    = 20
+	DEFAULT_VERIFY_THRESHOLD = 0.5
+)
+
+// BookIntegrity the statistics of the local order book, the divergence is the mismatched ratio of the top levels.
+type BookIntegrity struct {
+	Resyncs        int64         `json:"resyncs"`     // the snapshot rebuilt times, by the sequence gap or the divergence
+	Gaps           int64         `json:"gaps"`        // the sequence gap times
+	Verifies       int64         `json:"verifies"`    // the rest cross verified times
+	Divergences    int64         `json:"divergences"` // the times of the divergence exceed the threshold
+	LastDivergence float64       `json:"last_divergence"`
+	MaxDivergence  float64       `json:"max_divergence"`
+	LastSeqGap     int64         `json:"last_seq_gap"` // the rest sequence minus the local one at the last verify
+	LastVerifyTs   int64         `json:"last_verify_ts"`
+	LastUpdateTs   int64         `json:"last_update_ts"`
+	Stale          time.Duration `json:"stale"` // the duration since the last update
+}
+
+func (this *LocalOrderBooks) Stop() {
+	if this.stopVerifySign != nil {
+		this.stopVerifySign <- true
+		this.stopVerifySign = nil
+	}
+	this.WSMarketUMBN.Stop()
+}
+
+func (this *LocalOrderBooks) Integrity(pair Pair) BookIntegrity {
+	return this.IntegrityById(pair.ToSymbol("", false))
+}
+
+// IntegrityById the copy of the statistics, the stale is calculated when it's called.
+func (this *LocalOrderBooks) IntegrityById(productId string) BookIntegrity {
+	this.integrityMux.Lock()
+	defer this.integrityMux.Unlock()
+
+	var integrity = BookIntegrity{}
+	if stat := this.integrity[productId]; stat != nil {
+		integrity = *stat
+	}
+	this.booksMux.RLock()
+	var ts = this.TsData[productId]
+	this.booksMux.RUnlock()
+	if ts > 0 {
+		integrity.LastUpdateTs = ts
+		integrity.Stale = time.Since(time.UnixMilli(ts))
+	}
+	return integrity
+}
+
+// resync the getSnapshot reset the book, the next delta get the new snapshot.
+func (this *LocalOrderBooks) resync(productId string) {
+	this.updateIntegrity(productId, func(stat *BookIntegrity) {
+		stat.Gaps++
+		stat.Resyncs++
+	})
+	this.rebuild(productId)
+}
+
+// rebuild the book by the ResyncHandler, or get the snapshot in the goroutine.
+func (this *LocalOrderBooks) rebuild(productId string) {
+	if this.ResyncHandler != nil {
+		this.ResyncHandler(productId)
+		return
+	}
+	go this.getSnapshot(productId, 0)
+}
+
+func (this *LocalOrderBooks) updateIntegrity(productId string, update func(stat *BookIntegrity)) {
+	this.integrityMux.Lock()
+	defer this.integrityMux.Unlock()
+
+	if this.integrity == nil {
+		this.integrity = make(map[string]*BookIntegrity)
+	}
+	if this.integrity[productId] == nil {
+		this.integrity[productId] = &BookIntegrity{}
+	}
+	update(this.integrity[productId])
+}
+
+func (this *LocalOrderBooks) verifyRoutine(stopSign chan bool) {
+	var ticker = time.NewTicker(this.VerifyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopSign:
+			return
+		case <-ticker.C:
+			var productIds = make([]string, 0)
+			this.booksMux.RLock()
+			for productId, mux := range this.OrderBookMuxs {
+				if mux != nil {
+					productIds = append(productIds, productId)
+				}
+			}
+			this.booksMux.RUnlock()
+			for _, productId := range productIds {
+				if err := this.VerifyById(productId); err != nil {
+					this.ErrorHandler(err)
+				}
+			}
+		}
+	}
+}
+
+// VerifyById compare the top levels of the local book with the rest depth, force the resync when they diverged.
+func (this *LocalOrderBooks) VerifyById(productId string) error {
+	var levels, threshold = this.VerifyLevels, this.VerifyThreshold
+	if levels <= 0 {
+		levels = DEFAULT_VERIFY_LEVELS
+	}
+	if threshold <= 0 {
+		threshold = DEFAULT_VERIFY_THRESHOLD
+	}
+
+	var local, err = this.SnapshotById(productId)
+	if err != nil {
+		return err
+	}
+	remote, err := this.getDepthById(productId, levels)
+	if err != nil {
+		return err
+	}
+
+	var divergence = depthDivergence(local, remote, levels)
+	var diverged = divergence >= threshold
+	this.updateIntegrity(productId, func(stat *BookIntegrity) {
+		stat.Verifies++
+		stat.LastDivergence = divergence
+		if divergence > stat.MaxDivergence {
+			stat.MaxDivergence = divergence
+		}
+		stat.LastSeqGap = remote.Sequence - local.Sequence
+		stat.LastVerifyTs = time.Now().UnixMilli()
+		if diverged {
+			stat.Divergences++
+			stat.Resyncs++
+		}
+	})
+
+	if diverged {
+		this.rebuild(productId)
+	}
+	return nil
+}
+
+// depthDivergence the ratio of the mismatched levels in the top of the rest depth.
+// The level is mismatched when the amount is not the same, or the local level is not in the rest depth.
+func depthDivergence(local, remote *Depth, levels int) float64 {
+	var compared, mismatched = 0, 0
+	var compare = func(localList, remoteList DepthRecords, inRange func(price, edge int64) bool) {
+		if len(remoteList) > levels {
+			remoteList = remoteList[:levels]
+		}
+		if len(localList) > levels {
+			localList = localList[:levels]
+		}
+
+		var remoteKV = make(map[int64]float64, len(remoteList))
+		for _, record := range remoteList {
+			remoteKV[int64(record.Price*100000000)] = record.Amount
+		}
+		var localKV = make(map[int64]float64, len(localList))
+		for _, record := range localList {
+			localKV[int64(record.Price*100000000)] = record.Amount
+		}
+
+		for price, amount := range remoteKV {
+			compared++
+			if localAmount, exist := localKV[price]; !exist || localAmount != amount {
+				mismatched++
+			}
+		}
+
+		// the local level inside the rest range is missing in the rest depth, it's not removed locally.
+		if len(remoteList) == 0 {
+			return
+		}
+		var edge = int64(remoteList[len(remoteList)-1].Price * 100000000)
+		for price := range localKV {
+			if _, exist := remoteKV[price]; !exist && inRange(price, edge) {
+				compared++
+				mismatched++
+			}
+		}
+	}
+
+	compare(local.BidList, remote.BidList, func(price, edge int64) bool { return price >= edge })
+	compare(local.AskList, remote.AskList, func(price, edge int64) bool { return price <= edge })
+	if compared == 0 {
+		return 0
+	}
+	return float64(mismatched) / float64(compared)
+}
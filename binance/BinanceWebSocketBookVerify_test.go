@@ -0,0 +1,144 @@
+package binance
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// the swap endpoint is constant, the transport serve it by the handler.
+type handlerTransport struct {
+	handler http.HandlerFunc
+}
+
+func (this *handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var recorder = httptest.NewRecorder()
+	this.handler(recorder, req)
+	return recorder.Result(), nil
+}
+
+// go test -v ./binance/... -count=1 -run=TestDepthDivergence
+func TestDepthDivergence(t *testing.T) {
+	var remote = &Depth{
+		BidList: DepthRecords{{Price: 100, Amount: 1}, {Price: 99, Amount: 2}},
+		AskList: DepthRecords{{Price: 101, Amount: 1}, {Price: 102, Amount: 2}},
+	}
+	var local = &Depth{
+		BidList: DepthRecords{{Price: 100, Amount: 1}, {Price: 99, Amount: 2}, {Price: 98, Amount: 5}},
+		AskList: DepthRecords{{Price: 101, Amount: 1}, {Price: 102, Amount: 2}},
+	}
+	if divergence := depthDivergence(local, remote, 2); divergence != 0 {
+		t.Error("The same top levels should not diverge. ", divergence)
+	}
+
+	// the stale bid 100.5 is not removed, the amount of the ask 102 is changed.
+	local.BidList = DepthRecords{{Price: 100.5, Amount: 1}, {Price: 100, Amount: 1}, {Price: 99, Amount: 2}}
+	local.AskList = DepthRecords{{Price: 101, Amount: 1}, {Price: 102, Amount: 3}}
+	if divergence := depthDivergence(local, remote, 2); divergence != 0.6 {
+		t.Error("The divergence is wrong. ", divergence)
+	}
+}
+
+// go test -v ./binance/... -count=1 -run=TestLocalOrderBooks_VerifyById
+func TestLocalOrderBooks_VerifyById(t *testing.T) {
+	var requests = 0
+	var resyncs = make([]string, 0)
+	var books = &LocalOrderBooks{
+		WSMarketUMBN: &WSMarketUMBN{
+			Config: &APIConfig{
+				HttpClient: &http.Client{Transport: &handlerTransport{func(w http.ResponseWriter, r *http.Request) {
+					requests++
+					if r.URL.Path != "/fapi/v1/depth" || r.URL.Query().Get("symbol") != "btcusdt" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_, _ = w.Write([]byte(`{"lastUpdateId":110,"bids":[["100","1"],["99","2"]],"asks":[["101","1"],["102","2"]]}`))
+				}}},
+				Location:  time.UTC,
+				RateLimit: RATE_LIMIT_OFF,
+			},
+		},
+		VerifyLevels:  2,
+		ResyncHandler: func(productId string) { resyncs = append(resyncs, productId) },
+		BidData:       map[string]map[int64]float64{"btcusdt": {10000000000: 1, 9900000000: 2}},
+		AskData:       map[string]map[int64]float64{"btcusdt": {10100000000: 1, 10200000000: 2}},
+		SeqData:       map[string]int64{"btcusdt": 100},
+		TsData:        map[string]int64{"btcusdt": time.Now().UnixMilli()},
+		OrderBookMuxs: map[string]*sync.Mutex{"btcusdt": {}},
+		Cache:         map[string][]*DeltaOrderBook{},
+	}
+
+	if err := books.VerifyById("btcusdt"); err != nil {
+		t.Fatal(err)
+	}
+	var integrity = books.IntegrityById("btcusdt")
+	if integrity.Verifies != 1 || integrity.LastDivergence != 0 || integrity.Resyncs != 0 || integrity.LastSeqGap != 10 {
+		t.Fatal("The integrity is wrong. ", integrity)
+	}
+	if len(resyncs) != 0 {
+		t.Fatal("The same book should not be resynced. ", resyncs)
+	}
+
+	// the local book missed the removed bid 100.5 and the amount changes of the asks.
+	books.BidData["btcusdt"][10050000000] = 3
+	books.AskData["btcusdt"][10200000000] = 4
+	books.AskData["btcusdt"][10100000000] = 0.5
+	if err := books.VerifyById("btcusdt"); err != nil {
+		t.Fatal(err)
+	}
+	integrity = books.IntegrityById("btcusdt")
+	if integrity.Verifies != 2 || integrity.Divergences != 1 || integrity.Resyncs != 1 ||
+		integrity.MaxDivergence != integrity.LastDivergence || integrity.LastDivergence < 0.5 {
+		t.Error("The divergence should force the resync. ", integrity)
+	}
+	if len(resyncs) != 1 || resyncs[0] != "btcusdt" {
+		t.Error("The resync of the book is not triggered. ", resyncs)
+	}
+	if requests != 2 {
+		t.Error("The rest depth should be requested every verify. ", requests)
+	}
+}
+
+// go test -race -v ./binance/... -count=1 -run=TestLocalOrderBooks_ConcurrentRead
+func TestLocalOrderBooks_ConcurrentRead(t *testing.T) {
+	var resyncs = 0
+	var books = &LocalOrderBooks{
+		WSMarketUMBN:  &WSMarketUMBN{Config: &APIConfig{Location: time.UTC}},
+		ResyncHandler: func(productId string) { resyncs++ },
+		BidData:       map[string]map[int64]float64{"btcusdt": {10000000000: 1}},
+		AskData:       map[string]map[int64]float64{"btcusdt": {10100000000: 1}},
+		SeqData:       map[string]int64{"btcusdt": 100},
+		TsData:        map[string]int64{"btcusdt": time.Now().UnixMilli()},
+		OrderBookMuxs: map[string]*sync.Mutex{"btcusdt": {}},
+		Cache:         map[string][]*DeltaOrderBook{},
+	}
+
+	var done = make(chan bool)
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			_, _ = books.SnapshotById("btcusdt")
+			_ = books.IntegrityById("btcusdt")
+		}
+	}()
+	for seq := int64(100); seq < 300; seq++ {
+		books.ReceiveDelta(fmt.Sprintf(
+			`{"stream":"btcusdt@depth","data":{"T":%d,"U":%d,"u":%d,"pu":%d,"b":[["100","%d"]],"a":[]}}`,
+			time.Now().UnixMilli(), seq+1, seq+1, seq, seq,
+		))
+	}
+	<-done
+
+	var depth, err = books.SnapshotById("btcusdt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth.Sequence != 300 || depth.BidList[0].Amount != 299 || resyncs != 0 {
+		t.Error("The deltas are not applied in order. ", depth.Sequence, depth.BidList, resyncs)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	. "github.com/deforceHK/goghostex"
 )
 
+var _ OrderBookFeed = (*SwapBookFeed)(nil)
+
 // go test -v ./binance/... -count=1 -run=TestBinanceWebsocketBook
 func TestBinanceWebsocketBook(t *testing.T) {
 	var config = &APIConfig{
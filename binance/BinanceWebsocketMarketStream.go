@@ -0,0 +1,374 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+type bnTickerEvent struct {
+	EventType string  `json:"e"`
+	EventTime int64   `json:"E"`
+	Symbol    string  `json:"s"`
+	Last      float64 `json:"c,string"`
+	Bid       float64 `json:"b,string"`
+	Ask       float64 `json:"a,string"`
+	High      float64 `json:"h,string"`
+	Low       float64 `json:"l,string"`
+	Vol       float64 `json:"v,string"`
+
+	// the json decoder match the key case-insensitively, hold the upper keys here.
+	CloseTime   int64  `json:"C"`
+	BidQty      string `json:"B"`
+	AskQty      string `json:"A"`
+	LastTradeId int64  `json:"L"`
+	OpenTime    int64  `json:"O"`
+	Open        string `json:"o"`
+}
+
+type bnTradeEvent struct {
+	EventType    string  `json:"e"`
+	Symbol       string  `json:"s"`
+	TradeId      int64   `json:"t"`
+	AggTradeId   int64   `json:"a"`
+	Price        float64 `json:"p,string"`
+	Qty          float64 `json:"q,string"`
+	TradeTime    int64   `json:"T"`
+	IsBuyerMaker bool    `json:"m"`
+
+	EventTime int64 `json:"E"`
+	Ignore    bool  `json:"M"`
+}
+
+type bnKlineEvent struct {
+	EventType string `json:"e"`
+	EventTime int64  `json:"E"`
+	Symbol    string `json:"s"`
+	Kline     struct {
+		StartTime int64   `json:"t"`
+		CloseTime int64   `json:"T"`
+		Open      float64 `json:"o,string"`
+		Close     float64 `json:"c,string"`
+		High      float64 `json:"h,string"`
+		Low       float64 `json:"l,string"`
+		Vol       float64 `json:"v,string"`
+
+		LastTradeId int64  `json:"L"`
+		TakerVol    string `json:"V"`
+	} `json:"k"`
+}
+
+type bnMarkPriceEvent struct {
+	EventType       string  `json:"e"`
+	EventTime       int64   `json:"E"`
+	Symbol          string  `json:"s"`
+	MarkPrice       float64 `json:"p,string"`
+	IndexPrice      float64 `json:"i,string"`
+	FundingRate     float64 `json:"r,string"`
+	NextFundingTime int64   `json:"T"`
+	SettlePrice     string  `json:"P"`
+}
+
+type bnMarketPairs struct {
+	pairs    map[string]Pair
+	pairsMux sync.RWMutex
+}
+
+func (this *bnMarketPairs) put(pair Pair) string {
+	this.pairsMux.Lock()
+	defer this.pairsMux.Unlock()
+	if this.pairs == nil {
+		this.pairs = make(map[string]Pair)
+	}
+	this.pairs[pair.ToSymbol("", true)] = pair
+	return pair.ToSymbol("", false)
+}
+
+func (this *bnMarketPairs) get(symbol string) (Pair, bool) {
+	this.pairsMux.RLock()
+	defer this.pairsMux.RUnlock()
+	var pair, exist = this.pairs[strings.ToUpper(symbol)]
+	return pair, exist
+}
+
+func (this *bnMarketPairs) tradeSide(isBuyerMaker bool) TradeSide {
+	// the buyer is maker, so the taker sell.
+	if isBuyerMaker {
+		return SELL
+	}
+	return BUY
+}
+
+// SpotMarketStream decode the spot raw stream of binance into the typed models.
+type SpotMarketStream struct {
+	*WSMarketSpot
+	MarketHandler
+
+	bnMarketPairs
+}
+
+func (this *SpotMarketStream) Init() error {
+	this.WSMarketSpot.RecvHandler = func(s string) {
+		this.Receiver(s)
+	}
+	return this.Start()
+}
+
+func (this *SpotMarketStream) GetExchangeName() string {
+	return BINANCE
+}
+
+func (this *SpotMarketStream) SubscribeTicker(pair Pair) error {
+	this.Subscribe(this.put(pair) + "@ticker")
+	return nil
+}
+
+func (this *SpotMarketStream) SubscribeTrade(pair Pair) error {
+	this.Subscribe(this.put(pair) + "@trade")
+	return nil
+}
+
+func (this *SpotMarketStream) SubscribeKline(pair Pair, period int) error {
+	var bnPeriod, exist = _INERNAL_KLINE_PERIOD_CONVERTER[period]
+	if !exist {
+		return fmt.Errorf("The period %d is not supported by binance. ", period)
+	}
+	this.Subscribe(this.put(pair) + "@kline_" + bnPeriod)
+	return nil
+}
+
+func (this *SpotMarketStream) SubscribeFundingRate(pair Pair) error {
+	return fmt.Errorf("The funding rate is not in binance spot. ")
+}
+
+func (this *SpotMarketStream) SubscribeMarkPrice(pair Pair) error {
+	return fmt.Errorf("The mark price is not in binance spot. ")
+}
+
+func (this *SpotMarketStream) Receiver(msg string) {
+	var pre = struct {
+		EventType string `json:"e"`
+		EventTime int64  `json:"E"`
+		Symbol    string `json:"s"`
+	}{}
+	if err := json.Unmarshal([]byte(msg), &pre); err != nil {
+		this.ErrorHandler(err)
+		return
+	}
+	// the response of subscribe
+	if pre.EventType == "" {
+		return
+	}
+
+	var pair, exist = this.get(pre.Symbol)
+	if !exist {
+		return
+	}
+
+	switch pre.EventType {
+	case "24hrTicker":
+		var event = bnTickerEvent{}
+		if err := json.Unmarshal([]byte(msg), &event); err != nil {
+			this.ErrorHandler(err)
+			return
+		}
+		this.OnTicker(&Ticker{
+			Pair:      pair,
+			Last:      event.Last,
+			Buy:       event.Bid,
+			Sell:      event.Ask,
+			High:      event.High,
+			Low:       event.Low,
+			Vol:       event.Vol,
+			Timestamp: event.EventTime,
+			Date:      time.UnixMilli(event.EventTime).In(this.Config.Location).Format(GO_BIRTHDAY),
+		})
+	case "trade":
+		var event = bnTradeEvent{}
+		if err := json.Unmarshal([]byte(msg), &event); err != nil {
+			this.ErrorHandler(err)
+			return
+		}
+		this.OnTrade(&Trade{
+			Tid:       event.TradeId,
+			Type:      this.tradeSide(event.IsBuyerMaker),
+			Amount:    event.Qty,
+			Price:     event.Price,
+			Timestamp: event.TradeTime,
+			Pair:      pair,
+		})
+	case "kline":
+		var event = bnKlineEvent{}
+		if err := json.Unmarshal([]byte(msg), &event); err != nil {
+			this.ErrorHandler(err)
+			return
+		}
+		this.OnKline(&Kline{
+			Pair:      pair,
+			Exchange:  BINANCE,
+			Timestamp: event.Kline.StartTime,
+			Date:      time.UnixMilli(event.Kline.StartTime).In(this.Config.Location).Format(GO_BIRTHDAY),
+			Open:      event.Kline.Open,
+			Close:     event.Kline.Close,
+			High:      event.Kline.High,
+			Low:       event.Kline.Low,
+			Vol:       event.Kline.Vol,
+		})
+	}
+}
+
+// SwapMarketStream decode the usdt margined futures combined stream of binance into the typed models.
+// The funding rate and mark price are both in the markPrice stream.
+type SwapMarketStream struct {
+	*WSMarketUMBN
+	MarketHandler
+
+	bnMarketPairs
+}
+
+func (this *SwapMarketStream) Init() error {
+	this.WSMarketUMBN.RecvHandler = func(s string) {
+		this.Receiver(s)
+	}
+	return this.Start()
+}
+
+func (this *SwapMarketStream) GetExchangeName() string {
+	return BINANCE
+}
+
+func (this *SwapMarketStream) SubscribeTicker(pair Pair) error {
+	this.Subscribe(this.put(pair) + "@ticker")
+	return nil
+}
+
+func (this *SwapMarketStream) SubscribeTrade(pair Pair) error {
+	this.Subscribe(this.put(pair) + "@aggTrade")
+	return nil
+}
+
+func (this *SwapMarketStream) SubscribeKline(pair Pair, period int) error {
+	var bnPeriod, exist = _INERNAL_KLINE_PERIOD_CONVERTER[period]
+	if !exist {
+		return fmt.Errorf("The period %d is not supported by binance. ", period)
+	}
+	this.Subscribe(this.put(pair) + "@kline_" + bnPeriod)
+	return nil
+}
+
+func (this *SwapMarketStream) SubscribeFundingRate(pair Pair) error {
+	this.Subscribe(this.put(pair) + "@markPrice")
+	return nil
+}
+
+func (this *SwapMarketStream) SubscribeMarkPrice(pair Pair) error {
+	this.Subscribe(this.put(pair) + "@markPrice")
+	return nil
+}
+
+func (this *SwapMarketStream) Receiver(msg string) {
+	var combined = struct {
+		Stream string          `json:"stream"`
+		Data   json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal([]byte(msg), &combined); err != nil {
+		this.ErrorHandler(err)
+		return
+	}
+	// the response of subscribe
+	if combined.Stream == "" {
+		return
+	}
+
+	var pre = struct {
+		EventType string `json:"e"`
+		EventTime int64  `json:"E"`
+		Symbol    string `json:"s"`
+	}{}
+	if err := json.Unmarshal(combined.Data, &pre); err != nil {
+		this.ErrorHandler(err)
+		return
+	}
+	var pair, exist = this.get(pre.Symbol)
+	if !exist {
+		return
+	}
+
+	switch pre.EventType {
+	case "24hrTicker":
+		var event = bnTickerEvent{}
+		if err := json.Unmarshal(combined.Data, &event); err != nil {
+			this.ErrorHandler(err)
+			return
+		}
+		this.OnSwapTicker(&SwapTicker{
+			Pair:      pair,
+			Last:      event.Last,
+			Buy:       event.Bid,
+			Sell:      event.Ask,
+			High:      event.High,
+			Low:       event.Low,
+			Vol:       event.Vol,
+			Timestamp: event.EventTime,
+			Date:      time.UnixMilli(event.EventTime).In(this.Config.Location).Format(GO_BIRTHDAY),
+		})
+	case "aggTrade":
+		var event = bnTradeEvent{}
+		if err := json.Unmarshal(combined.Data, &event); err != nil {
+			this.ErrorHandler(err)
+			return
+		}
+		this.OnTrade(&Trade{
+			Tid:       event.AggTradeId,
+			Type:      this.tradeSide(event.IsBuyerMaker),
+			Amount:    event.Qty,
+			Price:     event.Price,
+			Timestamp: event.TradeTime,
+			Pair:      pair,
+		})
+	case "kline":
+		var event = bnKlineEvent{}
+		if err := json.Unmarshal(combined.Data, &event); err != nil {
+			this.ErrorHandler(err)
+			return
+		}
+		this.OnSwapKline(&SwapKline{
+			Pair:      pair,
+			Exchange:  BINANCE,
+			Timestamp: event.Kline.StartTime,
+			Date:      time.UnixMilli(event.Kline.StartTime).In(this.Config.Location).Format(GO_BIRTHDAY),
+			Open:      event.Kline.Open,
+			Close:     event.Kline.Close,
+			High:      event.Kline.High,
+			Low:       event.Kline.Low,
+			Vol:       event.Kline.Vol,
+		})
+	case "markPriceUpdate":
+		var event = bnMarkPriceEvent{}
+		if err := json.Unmarshal(combined.Data, &event); err != nil {
+			this.ErrorHandler(err)
+			return
+		}
+		var date = time.UnixMilli(event.EventTime).In(this.Config.Location).Format(GO_BIRTHDAY)
+		this.OnMarkPrice(&MarkPrice{
+			Pair:       pair,
+			Exchange:   BINANCE,
+			Price:      event.MarkPrice,
+			IndexPrice: event.IndexPrice,
+			Timestamp:  event.EventTime,
+			Date:       date,
+		})
+		this.OnFundingRate(&FundingRate{
+			Pair:             pair,
+			Exchange:         BINANCE,
+			Rate:             event.FundingRate,
+			FundingTimestamp: event.NextFundingTime,
+			Timestamp:        event.EventTime,
+			Date:             date,
+		})
+	}
+}
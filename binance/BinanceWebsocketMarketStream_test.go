@@ -0,0 +1,89 @@
+package binance
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// go test -v ./binance/... -count=1 -run=TestSpotMarketStream_Receiver
+func TestSpotMarketStream_Receiver(t *testing.T) {
+	var tickers, trades, klines = 0, 0, 0
+	var stream = &SpotMarketStream{
+		WSMarketSpot: &WSMarketSpot{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { t.Error(err) },
+		},
+		MarketHandler: MarketHandler{
+			TickerHandler: func(ticker *Ticker) {
+				tickers++
+				if ticker.Last != 0.0025 || ticker.Buy != 0.0024 || ticker.Sell != 0.0026 {
+					t.Error("The ticker is wrong: ", ticker)
+				}
+			},
+			TradeHandler: func(trade *Trade) {
+				trades++
+				if trade.Tid != 12345 || trade.Type != SELL || trade.Price != 0.001 {
+					t.Error("The trade is wrong: ", trade)
+				}
+			},
+			KlineHandler: func(kline *Kline) {
+				klines++
+				if kline.Timestamp != 123400000 || kline.Open != 0.001 || kline.Vol != 1000 {
+					t.Error("The kline is wrong: ", kline)
+				}
+			},
+		},
+	}
+	stream.put(BTC_USDT)
+
+	stream.Receiver(`{"result":null,"id":"1"}`)
+	stream.Receiver(`{"e":"24hrTicker","E":123456789,"s":"BTCUSDT","p":"0.0015","P":"250.00","w":"0.0018","x":"0.0009","c":"0.0025","Q":"10","b":"0.0024","B":"10","a":"0.0026","A":"100","o":"0.0010","h":"0.0025","l":"0.0010","v":"10000","q":"18","O":0,"C":86400000,"F":0,"L":18150,"n":18151}`)
+	stream.Receiver(`{"e":"trade","E":123456789,"s":"BTCUSDT","t":12345,"p":"0.001","q":"100","T":123456785,"m":true,"M":true}`)
+	stream.Receiver(`{"e":"kline","E":123456789,"s":"BTCUSDT","k":{"t":123400000,"T":123460000,"s":"BTCUSDT","i":"1m","f":100,"L":200,"o":"0.0010","c":"0.0020","h":"0.0025","l":"0.0015","v":"1000","n":100,"x":false,"q":"1.0000","V":"500","Q":"0.500","B":"123456"}}`)
+
+	if tickers != 1 || trades != 1 || klines != 1 {
+		t.Error("The handlers are not called as expected. ", tickers, trades, klines)
+	}
+}
+
+// go test -v ./binance/... -count=1 -run=TestSwapMarketStream_Receiver
+func TestSwapMarketStream_Receiver(t *testing.T) {
+	var trades, rates, marks = 0, 0, 0
+	var stream = &SwapMarketStream{
+		WSMarketUMBN: &WSMarketUMBN{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { t.Error(err) },
+		},
+		MarketHandler: MarketHandler{
+			TradeHandler: func(trade *Trade) {
+				trades++
+				if trade.Tid != 5933014 || trade.Type != BUY || trade.Amount != 100 {
+					t.Error("The trade is wrong: ", trade)
+				}
+			},
+			FundingRateHandler: func(rate *FundingRate) {
+				rates++
+				if rate.Rate != 0.00038167 || rate.FundingTimestamp != 1562306400000 {
+					t.Error("The funding rate is wrong: ", rate)
+				}
+			},
+			MarkPriceHandler: func(mark *MarkPrice) {
+				marks++
+				if mark.Price != 11794.15 || mark.IndexPrice != 11784.62659091 {
+					t.Error("The mark price is wrong: ", mark)
+				}
+			},
+		},
+	}
+	var _ MarketWebsocketAPI = stream
+	stream.put(BTC_USDT)
+
+	stream.Receiver(`{"stream":"btcusdt@aggTrade","data":{"e":"aggTrade","E":123456789,"s":"BTCUSDT","a":5933014,"p":"0.001","q":"100","f":100,"l":105,"T":123456785,"m":false}}`)
+	stream.Receiver(`{"stream":"btcusdt@markPrice","data":{"e":"markPriceUpdate","E":1562305380000,"s":"BTCUSDT","p":"11794.15000000","i":"11784.62659091","P":"11784.25641265","r":"0.00038167","T":1562306400000}}`)
+
+	if trades != 1 || rates != 1 || marks != 1 {
+		t.Error("The handlers are not called as expected. ", trades, rates, marks)
+	}
+}
@@ -1,9 +1,18 @@
 package bitstamp
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
 	. "github.com/deforceHK/goghostex"
 )
 
@@ -11,27 +20,36 @@ var (
 	ENDPOINT = "https://www.bitstamp.net"
 )
 
+const (
+	CONTENT_TYPE = "application/x-www-form-urlencoded"
+)
+
 type Bitstamp struct {
-	config *APIConfig
+	config  *APIConfig
+	Limiter *RateLimiter
 
 	Spot *Spot
 }
 
 func New(config *APIConfig) *Bitstamp {
-	bitstamp := &Bitstamp{config: config}
+	bitstamp := &Bitstamp{config: config, Limiter: newRateLimiter(config)}
 	bitstamp.Spot = &Spot{bitstamp}
 	return bitstamp
 }
 
 func (bitstamp *Bitstamp) DoRequest(httpMethod, uri, reqBody string, response interface{}) ([]byte, error) {
-	resp, err := NewHttpRequest(
+	if err := bitstamp.Limiter.Acquire(httpMethod, uri, ""); err != nil {
+		return nil, err
+	}
+	resp, header, err := NewHttpRequestWithHeader(
 		bitstamp.config.HttpClient,
 		httpMethod, bitstamp.config.Endpoint+uri, reqBody,
 		nil,
 	)
+	bitstamp.Limiter.Feedback(httpMethod, uri, "", header, err)
 
 	if err != nil {
-		return nil, err
+		return nil, bitstampHttpError(err)
 	} else {
 		nowTimestamp := time.Now().Unix() * 1000
 		if bitstamp.config.LastTimestamp < nowTimestamp {
@@ -40,3 +58,70 @@ func (bitstamp *Bitstamp) DoRequest(httpMethod, uri, reqBody string, response in
 		return resp, json.Unmarshal(resp, &response)
 	}
 }
+
+// DoSignRequest the private api is POST with the form body, it's authorized by the v2 headers.
+// The error of the private api is returned in 200 too, eg: {"status":"error","reason":...,"code":...}
+func (bitstamp *Bitstamp) DoSignRequest(uri string, params url.Values, response interface{}) ([]byte, error) {
+	if err := bitstamp.Limiter.Acquire(http.MethodPost, uri, ""); err != nil {
+		return nil, err
+	}
+	var reqBody = ""
+	if params != nil {
+		reqBody = params.Encode()
+	}
+	var headers, err = bitstamp.buildHeaders(http.MethodPost, uri, reqBody, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, header, err := NewHttpRequestWithHeader(
+		bitstamp.config.HttpClient,
+		http.MethodPost, bitstamp.config.Endpoint+uri, reqBody,
+		headers,
+	)
+	bitstamp.Limiter.Feedback(http.MethodPost, uri, "", header, err)
+
+	if err != nil {
+		return nil, bitstampHttpError(err)
+	}
+	nowTimestamp := time.Now().Unix() * 1000
+	if bitstamp.config.LastTimestamp < nowTimestamp {
+		bitstamp.config.LastTimestamp = nowTimestamp
+	}
+	if err := bitstampResponseError(resp); err != nil {
+		return resp, err
+	}
+	return resp, json.Unmarshal(resp, &response)
+}
+
+// buildHeaders the signature is the upper hex hmac-sha256 of the message, the content type is signed only with the body.
+// https://www.bitstamp.net/api/#section/Authentication
+func (bitstamp *Bitstamp) buildHeaders(httpMethod, uri, reqBody string, now time.Time) (map[string]string, error) {
+	var u, err = url.Parse(bitstamp.config.Endpoint + uri)
+	if err != nil {
+		return nil, err
+	}
+	var nonceStr = uuid.New().String()
+	var timestamp = fmt.Sprintf("%d", now.UnixNano()/int64(time.Millisecond))
+
+	var contentType = ""
+	if reqBody != "" {
+		contentType = CONTENT_TYPE
+	}
+	var message = "BITSTAMP " + bitstamp.config.ApiKey + strings.ToUpper(httpMethod) + u.Host + u.Path + u.RawQuery +
+		contentType + nonceStr + timestamp + "v2" + reqBody
+	var mac = hmac.New(sha256.New, []byte(bitstamp.config.ApiSecretKey))
+	mac.Write([]byte(message))
+
+	var headers = map[string]string{
+		"X-Auth":           "BITSTAMP " + bitstamp.config.ApiKey,
+		"X-Auth-Signature": strings.ToUpper(hex.EncodeToString(mac.Sum(nil))),
+		"X-Auth-Nonce":     nonceStr,
+		"X-Auth-Timestamp": timestamp,
+		"X-Auth-Version":   "v2",
+	}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	return headers, nil
+}
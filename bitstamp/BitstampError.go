@@ -0,0 +1,107 @@
+package bitstamp
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The error of bitstamp is the reason text, the code is not in every response.
+// The key is the lower fragment of the reason, the matched fragment is the exchange code of the error.
+var _BITSTAMP_ERROR_CODES = map[string]Error{
+	"order not found":            ErrOrderNotFound,
+	"invalid order id":           ErrOrderNotFound,
+	"check your account balance": ErrInsufficientBalance,
+	"you need":                   ErrInsufficientBalance,
+	"you have only":              ErrInsufficientBalance,
+	"maker or cancel":            ErrPostOnlyRejected,
+	"price is more than":         ErrInvalidPrice,
+	"price is less than":         ErrInvalidPrice,
+	"invalid limit price":        ErrInvalidPrice,
+	"ensure that there are no":   ErrInvalidPrice,
+	"invalid signature":          ErrAuthFailed,
+	"missing key":                ErrAuthFailed,
+	"api key not found":          ErrAuthFailed,
+	"invalid nonce":              ErrAuthFailed,
+	"authentication failed":      ErrAuthFailed,
+	"no permission":              ErrAuthFailed,
+	"too many requests":          ErrRateLimited,
+	"rate limit":                 ErrRateLimited,
+	"maintenance":                ErrExchangeUnavailable,
+}
+
+// the longer fragment is matched first.
+var _BITSTAMP_ERROR_FRAGMENTS = func() []string {
+	var fragments = make([]string, 0, len(_BITSTAMP_ERROR_CODES))
+	for fragment := range _BITSTAMP_ERROR_CODES {
+		fragments = append(fragments, fragment)
+	}
+	sort.Slice(fragments, func(i, j int) bool {
+		if len(fragments[i]) != len(fragments[j]) {
+			return len(fragments[i]) > len(fragments[j])
+		}
+		return fragments[i] < fragments[j]
+	})
+	return fragments
+}()
+
+type errorResponse struct {
+	Status string          `json:"status"`
+	Reason json.RawMessage `json:"reason"`
+	Code   string          `json:"code"`
+	Error  string          `json:"error"`
+}
+
+// reason the reason is the text, or the errors of the fields, eg: {"__all__": ["..."]}
+func (this *errorResponse) reason() string {
+	var text string
+	if json.Unmarshal(this.Reason, &text) == nil {
+		return text
+	}
+	var fields = map[string][]string{}
+	if json.Unmarshal(this.Reason, &fields) != nil {
+		return strings.TrimSpace(this.Error + " " + string(this.Reason))
+	}
+	var keys = make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var reasons = make([]string, 0, len(keys))
+	for _, key := range keys {
+		reasons = append(reasons, strings.Join(fields[key], " "))
+	}
+	return strings.Join(reasons, " ")
+}
+
+func bitstampError(code, reason string, cause error) error {
+	var lower = strings.ToLower(reason)
+	for _, fragment := range _BITSTAMP_ERROR_FRAGMENTS {
+		if strings.Contains(lower, fragment) {
+			code = fragment
+			break
+		}
+	}
+	return NewExchangeError(BITSTAMP, _BITSTAMP_ERROR_CODES, code, reason, cause)
+}
+
+// bitstampResponseError the error in the 200 response.
+func bitstampResponseError(resp []byte) error {
+	var response = errorResponse{}
+	if json.Unmarshal(resp, &response) != nil || response.Status != "error" {
+		return nil
+	}
+	return bitstampError(response.Code, response.reason(), nil)
+}
+
+func bitstampHttpError(err error) error {
+	var body, exist = HttpErrorResponse(err)
+	if !exist {
+		return err
+	}
+	var response = errorResponse{}
+	_ = json.Unmarshal([]byte(body), &response)
+	return bitstampError(response.Code, response.reason(), err)
+}
@@ -0,0 +1,22 @@
+package bitstamp
+
+import (
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The requests are 400 per second and 10000 per 10 minutes by default.
+// https://www.bitstamp.net/api/#section/Request-limits
+var _BITSTAMP_RATE_RULES = map[string]*RateRule{
+	"second": {Limit: 400, Interval: time.Second},
+	"window": {Limit: 10000, Interval: 10 * time.Minute},
+}
+
+var _BITSTAMP_RATE_COSTS = map[string][]*RateCost{
+	"": {{Rule: "second", Weight: 1}, {Rule: "window", Weight: 1}},
+}
+
+func newRateLimiter(config *APIConfig) *RateLimiter {
+	return NewRateLimiter(BITSTAMP, config.RateLimit, _BITSTAMP_RATE_RULES, _BITSTAMP_RATE_COSTS)
+}
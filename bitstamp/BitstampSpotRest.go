@@ -156,33 +156,8 @@ func (spot *Spot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline,
 	return GetAscKline(klines), resp, nil
 }
 
-// private api
-func (spot *Spot) GetAccount() (*Account, []byte, error) {
-	panic("implement me")
-}
-
-func (spot *Spot) PlaceOrder(*Order) ([]byte, error) {
-	panic("implement me")
-}
-
-func (spot *Spot) CancelOrder(*Order) ([]byte, error) {
-	panic("implement me")
-}
-
-func (spot *Spot) GetOrder(*Order) ([]byte, error) {
-	panic("implement me")
-}
-
-func (spot *Spot) GetOrders(pair Pair) ([]*Order, error) {
-	panic("implement me")
-}
-
-func (spot *Spot) GetUnFinishOrders(pair Pair) ([]*Order, []byte, error) {
-	panic("implement me")
-}
-
 func (spot *Spot) GetExchangeName() string {
-	panic("implement me")
+	return BITSTAMP
 }
 
 func (spot *Spot) GetExchangeRule(pair Pair) (*Rule, []byte, error) {
@@ -0,0 +1,37 @@
+package bitstamp
+
+import (
+	"strings"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+func (spot *Spot) GetAccount() (*Account, []byte, error) {
+	var response = make([]struct {
+		Currency  string  `json:"currency"`
+		Total     float64 `json:"total,string"`
+		Available float64 `json:"available,string"`
+		Reserved  float64 `json:"reserved,string"`
+	}, 0)
+	var resp, err = spot.DoSignRequest("/api/v2/account_balances/", nil, &response)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var account = &Account{
+		Exchange:    BITSTAMP,
+		SubAccounts: make(map[string]SubAccount),
+	}
+	for _, item := range response {
+		if item.Total == 0 && item.Available == 0 && item.Reserved == 0 {
+			continue
+		}
+		var symbol = strings.ToUpper(item.Currency)
+		account.SubAccounts[symbol] = SubAccount{
+			Currency:     NewCurrency(symbol, ""),
+			Amount:       item.Available,
+			AmountFrozen: item.Reserved,
+		}
+	}
+	return account, resp, nil
+}
@@ -0,0 +1,323 @@
+package bitstamp
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+var _INTERNAL_ORDER_STATUS_CONVERTER = map[string]TradeStatus{
+	"Open":     ORDER_UNFINISH,
+	"Finished": ORDER_FINISH,
+	"Canceled": ORDER_CANCEL,
+	"Expired":  ORDER_CANCEL,
+}
+
+// the flag param of the limit order, the NORMAL order is gtc.
+var _INTERNAL_PLACE_TYPE_CONVERTER = map[PlaceType]string{
+	ONLY_MAKER: "moc_order",
+	IOC:        "ioc_order",
+	FOK:        "fok_order",
+}
+
+// the datetime of bitstamp is utc, eg: 2022-01-31 14:43:15.796000
+const _DATETIME_LAYOUT = "2006-01-02 15:04:05"
+
+// flexString the id is the number or the string in the different api.
+type flexString string
+
+func (this *flexString) UnmarshalJSON(data []byte) error {
+	*this = flexString(strings.Trim(string(data), `"`))
+	return nil
+}
+
+type remoteOrder struct {
+	Id              flexString               `json:"id"`
+	ClientOrderId   string                   `json:"client_order_id"`
+	Datetime        string                   `json:"datetime"`
+	Type            flexString               `json:"type"`
+	Status          string                   `json:"status"`
+	Market          string                   `json:"market"`
+	CurrencyPair    string                   `json:"currency_pair"`
+	Price           float64                  `json:"price,string"`
+	Amount          float64                  `json:"amount,string"`
+	AmountAtCreate  float64                  `json:"amount_at_create,string"`
+	AmountRemaining float64                  `json:"amount_remaining,string"`
+	Transactions    []map[string]interface{} `json:"transactions"`
+}
+
+// merge the open order has no status, the order status has no price,
+// the deal amount and the avg price are summed by the transactions.
+func (this *remoteOrder) merge(order *Order, location *time.Location) {
+	order.OrderId = string(this.Id)
+	if this.ClientOrderId != "" {
+		order.Cid = this.ClientOrderId
+	}
+	if market := this.Market + this.CurrencyPair; market != "" {
+		order.Pair = NewPair(market, "/")
+	}
+	if this.Price > 0 {
+		order.Price = this.Price
+	}
+	if this.Type == "1" {
+		if order.Side != SELL_MARKET {
+			order.Side = SELL
+		}
+	} else if order.Side != BUY_MARKET {
+		order.Side = BUY
+	}
+
+	if t, err := time.ParseInLocation(_DATETIME_LAYOUT, this.Datetime, time.UTC); err == nil {
+		order.OrderTimestamp = t.UnixNano() / int64(time.Millisecond)
+		order.OrderDate = t.In(location).Format(GO_BIRTHDAY)
+	}
+
+	if this.Status == "" {
+		// the open order
+		if this.AmountAtCreate > 0 {
+			order.Amount = this.AmountAtCreate
+			order.DealAmount = this.AmountAtCreate - this.Amount
+		} else {
+			order.Amount = this.Amount
+		}
+		order.Status = ORDER_UNFINISH
+		if order.DealAmount > 0 {
+			order.Status = ORDER_PART_FINISH
+		}
+		return
+	}
+
+	var base = strings.ToLower(order.Pair.Basis.Symbol)
+	var counter = strings.ToLower(order.Pair.Counter.Symbol)
+	var dealAmount, dealValue, fee = 0.0, 0.0, 0.0
+	var lastDeal time.Time
+	for _, transaction := range this.Transactions {
+		dealAmount += ToFloat64(transaction[base])
+		dealValue += ToFloat64(transaction[counter])
+		fee += ToFloat64(transaction["fee"])
+		var datetime, _ = transaction["datetime"].(string)
+		var t, err = time.ParseInLocation(_DATETIME_LAYOUT, datetime, time.UTC)
+		if err == nil && t.After(lastDeal) {
+			lastDeal = t
+		}
+	}
+	order.DealAmount = dealAmount
+	order.Fee = fee
+	order.Amount = dealAmount + this.AmountRemaining
+	if dealAmount > 0 {
+		order.AvgPrice = dealValue / dealAmount
+	}
+	if !lastDeal.IsZero() {
+		order.DealTimestamp = lastDeal.UnixNano() / int64(time.Millisecond)
+		order.DealDatetime = lastDeal.In(location).Format(GO_BIRTHDAY)
+	}
+
+	var status, exist = _INTERNAL_ORDER_STATUS_CONVERTER[this.Status]
+	if !exist {
+		status = ORDER_FAIL
+	}
+	if status == ORDER_UNFINISH && dealAmount > 0 {
+		status = ORDER_PART_FINISH
+	}
+	order.Status = status
+}
+
+// PlaceOrder the market order is placed by the amount of the basis currency.
+func (spot *Spot) PlaceOrder(order *Order) ([]byte, error) {
+	var symbol = order.Pair.ToSymbol("", false)
+	var params = url.Values{}
+	params.Set("amount", strconv.FormatFloat(order.Amount, 'f', -1, 64))
+	if order.Cid != "" {
+		params.Set("client_order_id", order.Cid)
+	}
+
+	var uri string
+	switch order.Side {
+	case BUY:
+		uri = "/api/v2/buy/" + symbol + "/"
+	case SELL:
+		uri = "/api/v2/sell/" + symbol + "/"
+	case BUY_MARKET:
+		uri = "/api/v2/buy/market/" + symbol + "/"
+	case SELL_MARKET:
+		uri = "/api/v2/sell/market/" + symbol + "/"
+	default:
+		return nil, errors.New("The order side is not supported in bitstamp. ")
+	}
+	if order.Side == BUY || order.Side == SELL {
+		params.Set("price", strconv.FormatFloat(order.Price, 'f', -1, 64))
+		if order.OrderType != NORMAL {
+			var flag, exist = _INTERNAL_PLACE_TYPE_CONVERTER[order.OrderType]
+			if !exist {
+				return nil, errors.New("The place type is not supported in bitstamp. ")
+			}
+			params.Set(flag, "True")
+		}
+	}
+
+	var response = remoteOrder{}
+	var now = time.Now()
+	order.PlaceTimestamp = now.UnixNano() / int64(time.Millisecond)
+	order.PlaceDatetime = now.In(spot.config.Location).Format(GO_BIRTHDAY)
+	var resp, err = spot.DoSignRequest(uri, params, &response)
+	if err != nil {
+		return resp, err
+	}
+	if response.Id == "" {
+		return resp, errors.New(string(resp))
+	}
+
+	order.OrderId = string(response.Id)
+	order.Status = ORDER_UNFINISH
+	return resp, nil
+}
+
+func (spot *Spot) CancelOrder(order *Order) ([]byte, error) {
+	if order.OrderId == "" {
+		return nil, errors.New("The order id is empty. ")
+	}
+	var params = url.Values{}
+	params.Set("id", order.OrderId)
+	var response = remoteOrder{}
+	var resp, err = spot.DoSignRequest("/api/v2/cancel_order/", params, &response)
+	if err != nil {
+		return resp, err
+	}
+	if string(response.Id) != order.OrderId {
+		return resp, errors.New(string(resp))
+	}
+
+	// the order may be dealed before the cancel, the real state is in the order status.
+	if _, err := spot.GetOrder(order); err != nil {
+		return resp, err
+	}
+	if order.Status == ORDER_UNFINISH || order.Status == ORDER_PART_FINISH {
+		order.Status = ORDER_CANCEL
+	}
+	return resp, nil
+}
+
+// GetOrder the order is found by the order id, or the cid when the order id is empty.
+func (spot *Spot) GetOrder(order *Order) ([]byte, error) {
+	var params = url.Values{}
+	if order.OrderId != "" {
+		params.Set("id", order.OrderId)
+	} else if order.Cid != "" {
+		params.Set("client_order_id", order.Cid)
+	} else {
+		return nil, errors.New("The order id and cid is empty. ")
+	}
+
+	var response = remoteOrder{}
+	var resp, err = spot.DoSignRequest("/api/v2/order_status/", params, &response)
+	if err != nil {
+		return resp, err
+	}
+	if response.Status == "" {
+		return resp, errors.New(string(resp))
+	}
+	response.merge(order, spot.config.Location)
+	return resp, nil
+}
+
+// GetOrders the recent dealed orders are summed by the last 100 transactions of the pair,
+// the open orders are fetched once to find the part finished ones. the order not open is finish,
+// also the order canceled after a part deal, use GetOrder for the exact status.
+func (spot *Spot) GetOrders(pair Pair) ([]*Order, error) {
+	var params = url.Values{}
+	params.Set("limit", "100")
+	params.Set("sort", "desc")
+	var transactions = make([]map[string]flexString, 0)
+	var _, err = spot.DoSignRequest("/api/v2/user_transactions/"+pair.ToSymbol("", false)+"/", params, &transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	var unfinish, _, unfinishErr = spot.GetUnFinishOrders(pair)
+	if unfinishErr != nil {
+		return nil, unfinishErr
+	}
+	var open = make(map[string]*Order, len(unfinish))
+	for _, order := range unfinish {
+		open[order.OrderId] = order
+	}
+	return transactionOrders(pair, transactions, open, spot.config.Location), nil
+}
+
+// transactionOrders the base amount is positive in the buy trade and negative in the sell trade.
+func transactionOrders(
+	pair Pair,
+	transactions []map[string]flexString,
+	open map[string]*Order,
+	location *time.Location,
+) []*Order {
+	var base = strings.ToLower(pair.Basis.Symbol)
+	var counter = strings.ToLower(pair.Counter.Symbol)
+	var orders = make([]*Order, 0)
+	var values = make(map[string]float64)
+	var index = make(map[string]*Order)
+	for _, transaction := range transactions {
+		// the type 2 is the market trade, the others are deposit, withdraw and transfer.
+		var orderId = string(transaction["order_id"])
+		if transaction["type"] != "2" || orderId == "" || orderId == "null" {
+			continue
+		}
+
+		var amount = ToFloat64(string(transaction[base]))
+		var order, exist = index[orderId]
+		if !exist {
+			order = &Order{OrderId: orderId, Pair: pair, Side: BUY, Status: ORDER_FINISH}
+			if amount < 0 {
+				order.Side = SELL
+			}
+			index[orderId] = order
+			orders = append(orders, order)
+		}
+		order.DealAmount += math.Abs(amount)
+		order.Fee += ToFloat64(string(transaction["fee"]))
+		values[orderId] += math.Abs(ToFloat64(string(transaction[counter])))
+
+		var t, err = time.ParseInLocation(_DATETIME_LAYOUT, string(transaction["datetime"]), time.UTC)
+		if err == nil && t.UnixNano()/int64(time.Millisecond) > order.DealTimestamp {
+			order.DealTimestamp = t.UnixNano() / int64(time.Millisecond)
+			order.DealDatetime = t.In(location).Format(GO_BIRTHDAY)
+		}
+	}
+
+	for _, order := range orders {
+		order.Amount = order.DealAmount
+		if order.DealAmount > 0 {
+			order.AvgPrice = values[order.OrderId] / order.DealAmount
+		}
+		if remote, exist := open[order.OrderId]; exist {
+			order.Cid = remote.Cid
+			order.Price = remote.Price
+			order.Amount = remote.Amount
+			order.OrderTimestamp = remote.OrderTimestamp
+			order.OrderDate = remote.OrderDate
+			order.Status = ORDER_PART_FINISH
+		}
+	}
+	return orders
+}
+
+func (spot *Spot) GetUnFinishOrders(pair Pair) ([]*Order, []byte, error) {
+	var response = make([]*remoteOrder, 0)
+	var resp, err = spot.DoSignRequest("/api/v2/open_orders/"+pair.ToSymbol("", false)+"/", nil, &response)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var orders = make([]*Order, 0, len(response))
+	for _, remote := range response {
+		var order = &Order{Pair: pair}
+		remote.merge(order, spot.config.Location)
+		orders = append(orders, order)
+	}
+	return orders, resp, nil
+}
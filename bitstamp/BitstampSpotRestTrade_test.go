@@ -0,0 +1,133 @@
+package bitstamp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// go test -v ./bitstamp/... -count=1 -run=TestBitstamp_BuildHeaders
+func TestBitstamp_BuildHeaders(t *testing.T) {
+	var bitstamp = New(&APIConfig{
+		Endpoint:     ENDPOINT,
+		ApiKey:       "key",
+		ApiSecretKey: "secret",
+		Location:     time.UTC,
+	})
+	var now = time.Unix(1700000000, 0)
+	var body = "amount=0.1&price=20000"
+	var headers, err = bitstamp.buildHeaders("POST", "/api/v2/buy/btcusd/", body, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var message = "BITSTAMP key" + "POST" + "www.bitstamp.net" + "/api/v2/buy/btcusd/" + "" +
+		CONTENT_TYPE + headers["X-Auth-Nonce"] + "1700000000000" + "v2" + body
+	var mac = hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(message))
+	if headers["X-Auth-Signature"] != strings.ToUpper(hex.EncodeToString(mac.Sum(nil))) {
+		t.Error("The signature is wrong. ")
+	}
+	if headers["X-Auth"] != "BITSTAMP key" || headers["X-Auth-Timestamp"] != "1700000000000" ||
+		headers["Content-Type"] != CONTENT_TYPE || len(headers["X-Auth-Nonce"]) != 36 {
+		t.Error("The headers are wrong. ", headers)
+	}
+
+	// the content type is not signed without the body.
+	if headers, _ = bitstamp.buildHeaders("POST", "/api/v2/account_balances/", "", now); headers["Content-Type"] != "" {
+		t.Error("The content type should be empty. ")
+	}
+}
+
+// go test -v ./bitstamp/... -count=1 -run=TestRemoteOrder_Merge
+func TestRemoteOrder_Merge(t *testing.T) {
+	var status = `{"id": 1458532827766784, "datetime": "2022-01-31 14:43:15", "type": "0", "status": "Open",
+		"market": "BTC/USD", "amount_remaining": "0.6", "client_order_id": "cid",
+		"transactions": [
+			{"tid": 1, "price": "20000.00", "fee": "0.5", "btc": "0.3", "usd": "6000.00", "datetime": "2022-01-31 14:43:16.100000", "type": 2},
+			{"tid": 2, "price": "20100.00", "fee": "0.5", "btc": "0.1", "usd": "2010.00", "datetime": "2022-01-31 14:43:17.200000", "type": 2}
+		]}`
+	var remote = remoteOrder{}
+	if err := json.Unmarshal([]byte(status), &remote); err != nil {
+		t.Fatal(err)
+	}
+	var order = &Order{}
+	remote.merge(order, time.UTC)
+	if order.OrderId != "1458532827766784" || order.Cid != "cid" || order.Side != BUY ||
+		order.Pair.ToSymbol("", false) != "btcusd" || order.Status != ORDER_PART_FINISH {
+		t.Error("The order is wrong. ", order)
+	}
+	if order.DealAmount != 0.4 || order.Amount != 1 || order.Fee != 1 || order.AvgPrice != 8010/0.4 {
+		t.Error("The deal is wrong. ", order)
+	}
+	if order.DealTimestamp != 1643640197200 || order.OrderTimestamp != 1643640195000 {
+		t.Error("The timestamp is wrong. ", order)
+	}
+
+	var open = `{"id": "2", "datetime": "2022-01-31 14:43:15", "type": "1", "price": "21000",
+		"amount": "0.5", "amount_at_create": "1.0", "currency_pair": "BTC/USD"}`
+	remote = remoteOrder{}
+	if err := json.Unmarshal([]byte(open), &remote); err != nil {
+		t.Fatal(err)
+	}
+	order = &Order{}
+	remote.merge(order, time.UTC)
+	if order.Side != SELL || order.Price != 21000 || order.Amount != 1 || order.DealAmount != 0.5 ||
+		order.Status != ORDER_PART_FINISH {
+		t.Error("The open order is wrong. ", order)
+	}
+}
+
+// go test -v ./bitstamp/... -count=1 -run=TestBitstampResponseError
+func TestBitstampResponseError(t *testing.T) {
+	var err = bitstampResponseError([]byte(
+		`{"status": "error", "reason": {"__all__": ["You have only 1.00 USD available. Check your account balance for details."]}}`,
+	))
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Error("The error should be insufficient balance. ", err)
+	}
+	if err = bitstampResponseError([]byte(`{"status": "error", "reason": "Order not found"}`)); !errors.Is(err, ErrOrderNotFound) {
+		t.Error("The error should be order not found. ", err)
+	}
+	if err = bitstampResponseError([]byte(`[]`)); err != nil {
+		t.Error("The array response is not error. ", err)
+	}
+}
+
+// go test -v ./bitstamp/... -count=1 -run=TestTransactionOrders
+func TestTransactionOrders(t *testing.T) {
+	var data = `[
+		{"id": 3, "order_id": 11, "type": "2", "datetime": "2022-01-31 14:43:17.200000", "fee": "0.5", "btc": "-0.1", "usd": "2010.00", "btc_usd": 20100},
+		{"id": 2, "order_id": 10, "type": "2", "datetime": "2022-01-31 14:43:16.100000", "fee": "0.5", "btc": "0.3", "usd": "-6000.00", "btc_usd": 20000},
+		{"id": 1, "order_id": 10, "type": "2", "datetime": "2022-01-31 14:43:15.000000", "fee": "0.5", "btc": "0.1", "usd": "-2010.00", "btc_usd": 20100},
+		{"id": 0, "order_id": null, "type": "0", "datetime": "2022-01-31 14:40:00.000000", "fee": "0", "btc": "1.0", "usd": "0"}
+	]`
+	var transactions = make([]map[string]flexString, 0)
+	if err := json.Unmarshal([]byte(data), &transactions); err != nil {
+		t.Fatal(err)
+	}
+	var open = map[string]*Order{
+		"11": {OrderId: "11", Cid: "cid", Price: 20100, Amount: 0.5, Status: ORDER_PART_FINISH},
+	}
+	var orders = transactionOrders(NewPair("btc_usd", "_"), transactions, open, time.UTC)
+	if len(orders) != 2 {
+		t.Fatal("The orders are wrong. ", orders)
+	}
+
+	var sell, buy = orders[0], orders[1]
+	if sell.OrderId != "11" || sell.Side != SELL || sell.Status != ORDER_PART_FINISH ||
+		sell.Amount != 0.5 || sell.DealAmount != 0.1 || sell.Cid != "cid" || sell.Price != 20100 {
+		t.Error("The open order is wrong. ", sell)
+	}
+	if buy.OrderId != "10" || buy.Side != BUY || buy.Status != ORDER_FINISH || buy.Fee != 1 ||
+		buy.Amount != buy.DealAmount || buy.AvgPrice != 8010/buy.DealAmount || buy.DealTimestamp != 1643640196100 {
+		t.Error("The dealed order is wrong. ", buy)
+	}
+}
@@ -1,7 +1,19 @@
 package coinbase
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	. "github.com/deforceHK/goghostex"
@@ -16,11 +28,16 @@ const (
 	APPLICATION_JSON_UTF8 = "application/json; charset=UTF-8"
 
 	ENDPOINT = "https://api.exchange.coinbase.com"
+
+	// the trade api is advanced trade, the ApiKey is the key name, the ApiSecretKey is the private key.
+	ADVANCED_ENDPOINT = "https://api.coinbase.com"
+	ADVANCED_API      = "/api/v3/brokerage"
 )
 
 type Coinbase struct {
-	config *APIConfig
-	Spot   *Spot
+	config  *APIConfig
+	Spot    *Spot
+	Limiter *RateLimiter
 	//Future *Future
 	//Margin *Margin
 	//Wallet *Wallet
@@ -34,8 +51,21 @@ var _INERNAL_KLINE_PERIOD_CONVERTER = map[int]int{
 	KLINE_PERIOD_1DAY:  86400,
 }
 
+// the period not in the converter is resampled from the smaller one, eg: 1H 4H 1WEEK.
+func newKlineFallback(location *time.Location) *KlineFallback {
+	return &KlineFallback{
+		Exchange: COINBASE,
+		Location: location,
+		MaxSize:  300,
+		Supported: func(period int) bool {
+			var _, exist = _INERNAL_KLINE_PERIOD_CONVERTER[period]
+			return exist
+		},
+	}
+}
+
 func New(config *APIConfig) *Coinbase {
-	cb := &Coinbase{config: config}
+	cb := &Coinbase{config: config, Limiter: newRateLimiter(config)}
 	cb.Spot = &Spot{cb}
 
 	return cb
@@ -49,7 +79,10 @@ func (coinbase *Coinbase) DoRequest(
 ) ([]byte, error) {
 
 	url := coinbase.config.Endpoint + uri
-	resp, err := NewHttpRequest(
+	if err := coinbase.Limiter.Acquire(httpMethod, uri, ""); err != nil {
+		return nil, err
+	}
+	resp, header, err := NewHttpRequestWithHeader(
 		coinbase.config.HttpClient,
 		httpMethod,
 		url,
@@ -60,6 +93,7 @@ func (coinbase *Coinbase) DoRequest(
 			CACHE_CONTROL: "no-store", // test to not use cached for coinbase
 		},
 	)
+	coinbase.Limiter.Feedback(httpMethod, uri, "", header, err)
 
 	if err != nil {
 		return nil, err
@@ -82,7 +116,10 @@ func (coinbase *Coinbase) DoRequestHistorical(
 ) ([]byte, error) {
 
 	url := "https://api.pro.coinbase.com" + uri
-	resp, err := NewHttpRequest(
+	if err := coinbase.Limiter.Acquire(httpMethod, uri, ""); err != nil {
+		return nil, err
+	}
+	resp, header, err := NewHttpRequestWithHeader(
 		coinbase.config.HttpClient,
 		httpMethod,
 		url,
@@ -93,6 +130,7 @@ func (coinbase *Coinbase) DoRequestHistorical(
 			CACHE_CONTROL: "no-store", // test to not use cached for coinbase
 		},
 	)
+	coinbase.Limiter.Feedback(httpMethod, uri, "", header, err)
 
 	if err != nil {
 		return nil, err
@@ -104,3 +142,108 @@ func (coinbase *Coinbase) DoRequestHistorical(
 		return resp, json.Unmarshal(resp, &response)
 	}
 }
+
+// DoSignRequest the advanced trade api, it's authorized by the jwt of the cdp api key.
+func (coinbase *Coinbase) DoSignRequest(
+	httpMethod,
+	uri,
+	reqBody string,
+	response interface{},
+) ([]byte, error) {
+	if err := coinbase.Limiter.Acquire(httpMethod, uri, ""); err != nil {
+		return nil, err
+	}
+	var token, err = coinbase.buildJwt(httpMethod, uri, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, header, err := NewHttpRequestWithHeader(
+		coinbase.config.HttpClient,
+		httpMethod,
+		ADVANCED_ENDPOINT+uri,
+		reqBody,
+		map[string]string{
+			CONTENT_TYPE:    APPLICATION_JSON_UTF8,
+			ACCEPT:          APPLICATION_JSON,
+			"Authorization": "Bearer " + token,
+		},
+	)
+	coinbase.Limiter.Feedback(httpMethod, uri, "", header, err)
+
+	if err != nil {
+		return nil, coinbaseHttpError(err)
+	} else {
+		nowTimestamp := time.Now().Unix() * 1000
+		if nowTimestamp > coinbase.config.LastTimestamp {
+			coinbase.config.LastTimestamp = nowTimestamp
+		}
+		return resp, json.Unmarshal(resp, &response)
+	}
+}
+
+// buildJwt the ecdsa key in pem is signed by ES256, the ed25519 key in base64 is signed by EdDSA.
+// https://docs.cdp.coinbase.com/advanced-trade/docs/rest-api-auth
+func (coinbase *Coinbase) buildJwt(httpMethod, uri string, now time.Time) (string, error) {
+	var nonce = make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	var u, err = url.Parse(ADVANCED_ENDPOINT + uri)
+	if err != nil {
+		return "", err
+	}
+
+	var alg = "ES256"
+	var ecKey *ecdsa.PrivateKey
+	var edKey ed25519.PrivateKey
+	if block, _ := pem.Decode([]byte(strings.ReplaceAll(coinbase.config.ApiSecretKey, `\n`, "\n"))); block != nil {
+		if ecKey, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+			var key, pkcs8Err = x509.ParsePKCS8PrivateKey(block.Bytes)
+			if pkcs8Err != nil {
+				return "", err
+			}
+			var isEc bool
+			if ecKey, isEc = key.(*ecdsa.PrivateKey); !isEc {
+				return "", errors.New("The pem key of coinbase must be the ecdsa key. ")
+			}
+		}
+	} else {
+		var seed, decodeErr = base64.StdEncoding.DecodeString(coinbase.config.ApiSecretKey)
+		if decodeErr != nil || (len(seed) != ed25519.SeedSize && len(seed) != ed25519.PrivateKeySize) {
+			return "", errors.New("The secret key of coinbase must be the ecdsa pem or the ed25519 base64. ")
+		}
+		alg, edKey = "EdDSA", ed25519.NewKeyFromSeed(seed[:ed25519.SeedSize])
+	}
+
+	var header, _ = json.Marshal(map[string]string{
+		"alg":   alg,
+		"typ":   "JWT",
+		"kid":   coinbase.config.ApiKey,
+		"nonce": hex.EncodeToString(nonce),
+	})
+	var claims, _ = json.Marshal(map[string]interface{}{
+		"iss": "cdp",
+		"sub": coinbase.config.ApiKey,
+		"nbf": now.Unix(),
+		"exp": now.Unix() + 120,
+		"uri": fmt.Sprintf("%s %s%s", strings.ToUpper(httpMethod), u.Host, u.Path),
+	})
+	var signing = base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	var signature []byte
+	if edKey != nil {
+		signature = ed25519.Sign(edKey, []byte(signing))
+	} else {
+		var digest = sha256.Sum256([]byte(signing))
+		var r, s, signErr = ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if signErr != nil {
+			return "", signErr
+		}
+		// the signature of jwt is r and s in 32 bytes, not the asn1.
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
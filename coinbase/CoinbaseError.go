@@ -0,0 +1,41 @@
+package coinbase
+
+import (
+	"encoding/json"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The error of the advanced trade api, the failure reason of the order is the code too.
+var _COINBASE_ERROR_CODES = map[string]Error{
+	"NOT_FOUND":                     ErrOrderNotFound,
+	"UNKNOWN_CANCEL_ORDER":          ErrOrderNotFound,
+	"INSUFFICIENT_FUND":             ErrInsufficientBalance,
+	"INSUFFICIENT_FUNDS":            ErrInsufficientBalance,
+	"INVALID_LIMIT_PRICE_POST_ONLY": ErrPostOnlyRejected,
+	"INVALID_LIMIT_PRICE":           ErrInvalidPrice,
+	"INVALID_PRICE_PRECISION":       ErrInvalidPrice,
+	"UNAUTHENTICATED":               ErrAuthFailed,
+	"PERMISSION_DENIED":             ErrAuthFailed,
+	"RESOURCE_EXHAUSTED":            ErrRateLimited,
+	"UNAVAILABLE":                   ErrExchangeUnavailable,
+	"INTERNAL":                      ErrExchangeUnavailable,
+}
+
+func coinbaseError(code, message string) error {
+	return NewExchangeError(COINBASE, _COINBASE_ERROR_CODES, code, message, nil)
+}
+
+// coinbaseHttpError the non 2xx response has the error and message.
+func coinbaseHttpError(err error) error {
+	var body, exist = HttpErrorResponse(err)
+	if !exist {
+		return err
+	}
+	var response = struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}{}
+	_ = json.Unmarshal([]byte(body), &response)
+	return NewExchangeError(COINBASE, _COINBASE_ERROR_CODES, response.Error, "", err)
+}
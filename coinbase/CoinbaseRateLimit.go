@@ -0,0 +1,24 @@
+package coinbase
+
+import (
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The public endpoints are 10 requests per second, the advanced trade endpoints are 30 requests per second.
+// https://docs.cdp.coinbase.com/exchange/docs/rest-rate-limits
+// https://docs.cdp.coinbase.com/advanced-trade/docs/rest-api-rate-limits
+var _COINBASE_RATE_RULES = map[string]*RateRule{
+	"public":  {Limit: 10, Interval: time.Second},
+	"private": {Limit: 30, Interval: time.Second},
+}
+
+var _COINBASE_RATE_COSTS = map[string][]*RateCost{
+	"":                 {{Rule: "public", Weight: 1}},
+	ADVANCED_API + "/": {{Rule: "private", Weight: 1}},
+}
+
+func newRateLimiter(config *APIConfig) *RateLimiter {
+	return NewRateLimiter(COINBASE, config.RateLimit, _COINBASE_RATE_RULES, _COINBASE_RATE_COSTS)
+}
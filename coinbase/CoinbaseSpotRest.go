@@ -72,8 +72,46 @@ func (spot *Spot) GetTicker(pair Pair) (*Ticker, []byte, error) {
 	return ticker, tickerResp, nil
 }
 
-func (*Spot) GetDepth(pair Pair, size int) (*Depth, []byte, error) {
-	panic("implement me")
+func (spot *Spot) GetDepth(pair Pair, size int) (*Depth, []byte, error) {
+	var response = struct {
+		Bids     [][]interface{} `json:"bids"`
+		Asks     [][]interface{} `json:"asks"`
+		Sequence int64           `json:"sequence"`
+		Time     string          `json:"time"`
+	}{}
+
+	// the level 2 is the aggregated book, the size is cut here.
+	var uri = fmt.Sprintf("/products/%s/book?level=2", pair.ToSymbol("-", true))
+	var resp, err = spot.DoRequest("GET", uri, "", &response)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var now = time.Now()
+	if bookTime, err := time.Parse(time.RFC3339, response.Time); err == nil {
+		now = bookTime
+	}
+	var depth = &Depth{
+		Pair:      pair,
+		Timestamp: now.UnixNano() / int64(time.Millisecond),
+		Sequence:  response.Sequence,
+		Date:      now.In(spot.config.Location).Format(GO_BIRTHDAY),
+		AskList:   make(DepthRecords, 0),
+		BidList:   make(DepthRecords, 0),
+	}
+	for i := 0; i < len(response.Bids) && i < size; i++ {
+		depth.BidList = append(depth.BidList, DepthRecord{
+			Price:  ToFloat64(response.Bids[i][0]),
+			Amount: ToFloat64(response.Bids[i][1]),
+		})
+	}
+	for i := 0; i < len(response.Asks) && i < size; i++ {
+		depth.AskList = append(depth.AskList, DepthRecord{
+			Price:  ToFloat64(response.Asks[i][0]),
+			Amount: ToFloat64(response.Asks[i][1]),
+		})
+	}
+	return depth, resp, nil
 }
 
 func (spot *Spot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline, []byte, error) {
@@ -83,7 +121,12 @@ func (spot *Spot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline,
 
 	granularity, exist := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if !exist {
-		return nil, nil, errors.New("The coinbase just support 1min 5min 15min 6h 1day. ")
+		return newKlineFallback(spot.config.Location).Klines(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*Kline, []byte, error) {
+				return spot.GetKlineRecords(pair, period, size, int(since))
+			},
+		)
 	}
 
 	uri := fmt.Sprintf(
@@ -242,31 +285,6 @@ func (spot *Spot) GetExchangeRule(pair Pair) (*Rule, []byte, error) {
 	return &rule, resp, nil
 }
 
-// private api
-func (*Spot) PlaceOrder(*Order) ([]byte, error) {
-	panic("implement me")
-}
-
-func (*Spot) CancelOrder(*Order) ([]byte, error) {
-	panic("implement me")
-}
-
-func (*Spot) GetOrder(*Order) ([]byte, error) {
-	panic("implement me")
-}
-
-func (*Spot) GetOrders(pair Pair) ([]*Order, error) {
-	panic("implement me")
-}
-
-func (*Spot) GetUnFinishOrders(pair Pair) ([]*Order, []byte, error) {
-	panic("implement me")
-}
-
-func (*Spot) GetAccount() (*Account, []byte, error) {
-	panic("implement me")
-}
-
 // util api
 func (spot *Spot) KeepAlive() {
 	nowTimestamp := time.Now().Unix() * 1000
@@ -0,0 +1,54 @@
+package coinbase
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+func (spot *Spot) GetAccount() (*Account, []byte, error) {
+	var account = &Account{
+		Exchange:    COINBASE,
+		SubAccounts: make(map[string]SubAccount),
+	}
+
+	var params = url.Values{}
+	params.Set("limit", "250")
+	var lastResp []byte
+	for {
+		var response = struct {
+			Accounts []struct {
+				Currency         string `json:"currency"`
+				AvailableBalance struct {
+					Value float64 `json:"value,string"`
+				} `json:"available_balance"`
+				Hold struct {
+					Value float64 `json:"value,string"`
+				} `json:"hold"`
+			} `json:"accounts"`
+			HasNext bool   `json:"has_next"`
+			Cursor  string `json:"cursor"`
+		}{}
+		var resp, err = spot.DoSignRequest(http.MethodGet, ADVANCED_API+"/accounts?"+params.Encode(), "", &response)
+		if err != nil {
+			return nil, resp, err
+		}
+		lastResp = resp
+
+		for _, item := range response.Accounts {
+			var symbol = strings.ToUpper(item.Currency)
+			var sub = account.SubAccounts[symbol]
+			sub.Currency = NewCurrency(symbol, "")
+			sub.Amount += item.AvailableBalance.Value
+			sub.AmountFrozen += item.Hold.Value
+			account.SubAccounts[symbol] = sub
+		}
+		if !response.HasNext || response.Cursor == "" {
+			break
+		}
+		params.Set("cursor", response.Cursor)
+	}
+	return account, lastResp, nil
+}
@@ -0,0 +1,293 @@
+package coinbase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+var _INTERNAL_ORDER_STATUS_CONVERTER = map[string]TradeStatus{
+	"PENDING":       ORDER_UNFINISH,
+	"QUEUED":        ORDER_UNFINISH,
+	"OPEN":          ORDER_UNFINISH,
+	"FILLED":        ORDER_FINISH,
+	"CANCEL_QUEUED": ORDER_CANCEL_ING,
+	"CANCELLED":     ORDER_CANCEL,
+	"EXPIRED":       ORDER_CANCEL,
+	"FAILED":        ORDER_FAIL,
+}
+
+// the order configuration of the place type, the market order is ioc in coinbase.
+var _INTERNAL_PLACE_TYPE_CONVERTER = map[PlaceType]string{
+	NORMAL:     "limit_limit_gtc",
+	ONLY_MAKER: "limit_limit_gtc",
+	IOC:        "sor_limit_ioc",
+	FOK:        "limit_limit_fok",
+	MARKET:     "market_market_ioc",
+}
+
+var _INTERNAL_PLACE_TYPE_REVERTER = map[string]PlaceType{
+	"limit_limit_gtc":   NORMAL,
+	"limit_limit_gtd":   NORMAL,
+	"sor_limit_ioc":     IOC,
+	"limit_limit_fok":   FOK,
+	"market_market_ioc": MARKET,
+}
+
+type orderConfiguration struct {
+	BaseSize   string `json:"base_size,omitempty"`
+	QuoteSize  string `json:"quote_size,omitempty"`
+	LimitPrice string `json:"limit_price,omitempty"`
+	PostOnly   bool   `json:"post_only,omitempty"`
+}
+
+type remoteOrder struct {
+	OrderId            string                         `json:"order_id"`
+	ProductId          string                         `json:"product_id"`
+	ClientOrderId      string                         `json:"client_order_id"`
+	Side               string                         `json:"side"`
+	Status             string                         `json:"status"`
+	OrderConfiguration map[string]*orderConfiguration `json:"order_configuration"`
+	FilledSize         float64                        `json:"filled_size,string"`
+	AverageFilledPrice float64                        `json:"average_filled_price,string"`
+	TotalFees          float64                        `json:"total_fees,string"`
+	CreatedTime        time.Time                      `json:"created_time"`
+	LastFillTime       *time.Time                     `json:"last_fill_time"`
+}
+
+func (this *remoteOrder) merge(order *Order, location *time.Location) {
+	order.OrderId = this.OrderId
+	order.Cid = this.ClientOrderId
+	order.Pair = NewPair(this.ProductId, "-")
+	order.AvgPrice = this.AverageFilledPrice
+	order.DealAmount = this.FilledSize
+	order.Fee = this.TotalFees
+
+	var marketSide = false
+	for name, config := range this.OrderConfiguration {
+		order.OrderType = _INTERNAL_PLACE_TYPE_REVERTER[name]
+		if config.PostOnly {
+			order.OrderType = ONLY_MAKER
+		}
+		order.Price, _ = strconv.ParseFloat(config.LimitPrice, 64)
+		order.Amount, _ = strconv.ParseFloat(config.BaseSize, 64)
+		marketSide = name == "market_market_ioc"
+	}
+	if this.Side == "BUY" {
+		order.Side = BUY
+		if marketSide {
+			order.Side = BUY_MARKET
+		}
+	} else {
+		order.Side = SELL
+		if marketSide {
+			order.Side = SELL_MARKET
+		}
+	}
+
+	var status, exist = _INTERNAL_ORDER_STATUS_CONVERTER[this.Status]
+	if !exist {
+		status = ORDER_FAIL
+	}
+	if status == ORDER_UNFINISH && this.FilledSize > 0 {
+		status = ORDER_PART_FINISH
+	}
+	order.Status = status
+
+	if !this.CreatedTime.IsZero() {
+		order.OrderTimestamp = this.CreatedTime.UnixNano() / int64(time.Millisecond)
+		order.OrderDate = this.CreatedTime.In(location).Format(GO_BIRTHDAY)
+	}
+	if this.LastFillTime != nil && !this.LastFillTime.IsZero() {
+		order.DealTimestamp = this.LastFillTime.UnixNano() / int64(time.Millisecond)
+		order.DealDatetime = this.LastFillTime.In(location).Format(GO_BIRTHDAY)
+	}
+}
+
+// PlaceOrder the client_order_id is required in coinbase, it's generated when the cid is empty.
+func (spot *Spot) PlaceOrder(order *Order) ([]byte, error) {
+	var placeType = order.OrderType
+	if order.Side == BUY_MARKET || order.Side == SELL_MARKET {
+		placeType = MARKET
+	}
+	var configName, exist = _INTERNAL_PLACE_TYPE_CONVERTER[placeType]
+	if !exist {
+		return nil, errors.New("The place type is not supported in coinbase. ")
+	}
+	var side = "BUY"
+	if order.Side == SELL || order.Side == SELL_MARKET {
+		side = "SELL"
+	}
+	if order.Cid == "" {
+		order.Cid = newClientOrderId()
+	}
+
+	var config = &orderConfiguration{
+		BaseSize: strconv.FormatFloat(order.Amount, 'f', -1, 64),
+		PostOnly: placeType == ONLY_MAKER,
+	}
+	if placeType != MARKET {
+		config.LimitPrice = strconv.FormatFloat(order.Price, 'f', -1, 64)
+	}
+	var request = struct {
+		ClientOrderId      string                         `json:"client_order_id"`
+		ProductId          string                         `json:"product_id"`
+		Side               string                         `json:"side"`
+		OrderConfiguration map[string]*orderConfiguration `json:"order_configuration"`
+	}{
+		ClientOrderId:      order.Cid,
+		ProductId:          order.Pair.ToSymbol("-", true),
+		Side:               side,
+		OrderConfiguration: map[string]*orderConfiguration{configName: config},
+	}
+	var reqBody, _ = json.Marshal(request)
+
+	var response = struct {
+		Success         bool `json:"success"`
+		SuccessResponse struct {
+			OrderId string `json:"order_id"`
+		} `json:"success_response"`
+		ErrorResponse struct {
+			Error                string `json:"error"`
+			Message              string `json:"message"`
+			PreviewFailureReason string `json:"preview_failure_reason"`
+		} `json:"error_response"`
+	}{}
+
+	var now = time.Now()
+	order.PlaceTimestamp = now.UnixNano() / int64(time.Millisecond)
+	order.PlaceDatetime = now.In(spot.config.Location).Format(GO_BIRTHDAY)
+	var resp, err = spot.DoSignRequest(http.MethodPost, ADVANCED_API+"/orders", string(reqBody), &response)
+	if err != nil {
+		return resp, err
+	}
+	if !response.Success {
+		var code = response.ErrorResponse.Error
+		if response.ErrorResponse.PreviewFailureReason != "" {
+			code = strings.TrimPrefix(response.ErrorResponse.PreviewFailureReason, "PREVIEW_")
+		}
+		return resp, coinbaseError(code, string(resp))
+	}
+
+	order.OrderId = response.SuccessResponse.OrderId
+	order.Status = ORDER_UNFINISH
+	return resp, nil
+}
+
+func (spot *Spot) CancelOrder(order *Order) ([]byte, error) {
+	if order.OrderId == "" {
+		return nil, errors.New("The order id is empty. ")
+	}
+	var reqBody, _ = json.Marshal(map[string][]string{"order_ids": {order.OrderId}})
+	var response = struct {
+		Results []struct {
+			Success       bool   `json:"success"`
+			FailureReason string `json:"failure_reason"`
+			OrderId       string `json:"order_id"`
+		} `json:"results"`
+	}{}
+
+	var resp, err = spot.DoSignRequest(http.MethodPost, ADVANCED_API+"/orders/batch_cancel", string(reqBody), &response)
+	if err != nil {
+		return resp, err
+	}
+	if len(response.Results) == 0 {
+		return resp, errors.New(string(resp))
+	}
+	if !response.Results[0].Success {
+		return resp, coinbaseError(response.Results[0].FailureReason, string(resp))
+	}
+	order.Status = ORDER_CANCEL_ING
+	return resp, nil
+}
+
+// GetOrder the order without the order id is found by the cid in the recent orders.
+func (spot *Spot) GetOrder(order *Order) ([]byte, error) {
+	if order.OrderId == "" {
+		if order.Cid == "" {
+			return nil, errors.New("The order id and cid is empty. ")
+		}
+		var orders, resp, err = spot.getOrders(order.Pair, nil)
+		if err != nil {
+			return resp, err
+		}
+		for _, o := range orders {
+			if o.Cid == order.Cid {
+				*order = *o
+				return resp, nil
+			}
+		}
+		return resp, coinbaseError("NOT_FOUND", fmt.Sprintf("The order %s is not found. ", order.Cid))
+	}
+
+	var response = struct {
+		Order *remoteOrder `json:"order"`
+	}{}
+	var resp, err = spot.DoSignRequest(
+		http.MethodGet,
+		ADVANCED_API+"/orders/historical/"+url.PathEscape(order.OrderId),
+		"",
+		&response,
+	)
+	if err != nil {
+		return resp, err
+	}
+	if response.Order == nil {
+		return resp, coinbaseError("NOT_FOUND", string(resp))
+	}
+	response.Order.merge(order, spot.config.Location)
+	return resp, nil
+}
+
+// GetOrders the recent dealed orders.
+func (spot *Spot) GetOrders(pair Pair) ([]*Order, error) {
+	var orders, _, err = spot.getOrders(pair, []string{"FILLED", "CANCELLED", "EXPIRED", "FAILED"})
+	return orders, err
+}
+
+func (spot *Spot) GetUnFinishOrders(pair Pair) ([]*Order, []byte, error) {
+	return spot.getOrders(pair, []string{"OPEN"})
+}
+
+func (spot *Spot) getOrders(pair Pair, status []string) ([]*Order, []byte, error) {
+	var params = url.Values{}
+	params.Set("product_ids", pair.ToSymbol("-", true))
+	params.Set("limit", "100")
+	for _, s := range status {
+		params.Add("order_status", s)
+	}
+	var response = struct {
+		Orders []*remoteOrder `json:"orders"`
+	}{}
+	var resp, err = spot.DoSignRequest(
+		http.MethodGet,
+		ADVANCED_API+"/orders/historical/batch?"+params.Encode(),
+		"",
+		&response,
+	)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var orders = make([]*Order, 0, len(response.Orders))
+	for _, remote := range response.Orders {
+		var order = &Order{}
+		remote.merge(order, spot.config.Location)
+		orders = append(orders, order)
+	}
+	return orders, resp, nil
+}
+
+// newClientOrderId the uuid v4.
+func newClientOrderId() string {
+	return uuid.New().String()
+}
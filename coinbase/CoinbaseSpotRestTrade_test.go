@@ -0,0 +1,88 @@
+package coinbase
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// go test -v ./coinbase/... -count=1 -run=TestCoinbase_BuildJwt
+func TestCoinbase_BuildJwt(t *testing.T) {
+	var key, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	var der, _ = x509.MarshalECPrivateKey(key)
+	var secret = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+
+	var cb = New(&APIConfig{
+		ApiKey:       "organizations/org/apiKeys/key",
+		ApiSecretKey: strings.ReplaceAll(secret, "\n", `\n`), // the key in env has the escaped newline
+		Location:     time.UTC,
+	})
+	var token, err = cb.buildJwt("get", ADVANCED_API+"/orders/historical/batch?limit=1", time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parts = strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatal("The jwt is wrong: ", token)
+	}
+	var claims = struct {
+		Sub string `json:"sub"`
+		Nbf int64  `json:"nbf"`
+		Exp int64  `json:"exp"`
+		Uri string `json:"uri"`
+	}{}
+	var payload, _ = base64.RawURLEncoding.DecodeString(parts[1])
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Sub != "organizations/org/apiKeys/key" || claims.Exp-claims.Nbf != 120 ||
+		claims.Uri != "GET api.coinbase.com/api/v3/brokerage/orders/historical/batch" {
+		t.Error("The claims are wrong: ", claims)
+	}
+
+	var signature, _ = base64.RawURLEncoding.DecodeString(parts[2])
+	var digest = sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	var r, s = new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	if len(signature) != 64 || !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+		t.Error("The signature is wrong. ")
+	}
+}
+
+// go test -v ./coinbase/... -count=1 -run=TestRemoteOrder_Merge
+func TestRemoteOrder_Merge(t *testing.T) {
+	var raw = `{
+		"order_id": "0000-000000-000000",
+		"product_id": "BTC-USD",
+		"client_order_id": "11111-000000-000000",
+		"side": "SELL",
+		"status": "OPEN",
+		"order_configuration": {"limit_limit_gtc": {"base_size": "0.5", "limit_price": "30000", "post_only": true}},
+		"filled_size": "0.2",
+		"average_filled_price": "30000",
+		"total_fees": "1.2",
+		"created_time": "2023-11-14T22:13:20Z"
+	}`
+	var remote = &remoteOrder{}
+	if err := json.Unmarshal([]byte(raw), remote); err != nil {
+		t.Fatal(err)
+	}
+	var order = &Order{}
+	remote.merge(order, time.UTC)
+	if order.Side != SELL || order.OrderType != ONLY_MAKER || order.Status != ORDER_PART_FINISH ||
+		order.Price != 30000 || order.Amount != 0.5 || order.DealAmount != 0.2 || order.Fee != 1.2 ||
+		!order.Pair.Eq(Pair{Basis: BTC, Counter: USD}) || order.OrderTimestamp != 1700000000000 {
+		t.Error("The order is wrong: ", order)
+	}
+}
@@ -3,6 +3,7 @@ package coinbase
 import (
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 	"time"
@@ -125,3 +126,32 @@ func TestSpot_GetHistoricalCandles(t *testing.T) {
 	//}
 
 }
+
+// go test -v ./coinbase/... -count=1 -run=TestSpot_GetDepth
+func TestSpot_GetDepth(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/products/BTC-USD/book" || r.URL.Query().Get("level") != "2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"bids":[["30000.5","1.5",3],["30000","2",1],["29999","1",1]],
+			"asks":[["30001","0.5",1],["30002","1",2]],"sequence":123456,"time":"2023-11-14T22:13:20.000Z"}`))
+	}))
+	defer server.Close()
+
+	var cb = New(&APIConfig{
+		Endpoint:   server.URL,
+		HttpClient: server.Client(),
+		Location:   time.UTC,
+		RateLimit:  RATE_LIMIT_OFF,
+	})
+	var depth, _, err = cb.Spot.GetDepth(Pair{Basis: BTC, Counter: USD}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(depth.BidList) != 2 || len(depth.AskList) != 2 || depth.Sequence != 123456 ||
+		depth.Timestamp != 1700000000000 || depth.BidList[0].Price != 30000.5 || depth.BidList[0].Amount != 1.5 ||
+		depth.AskList[1].Price != 30002 {
+		t.Error("The depth is wrong. ", depth)
+	}
+}
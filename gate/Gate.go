@@ -5,8 +5,10 @@ import (
 	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	. "github.com/deforceHK/goghostex"
@@ -28,21 +30,37 @@ var _INERNAL_KLINE_PERIOD_CONVERTER = map[int]string{
 	KLINE_PERIOD_15MIN: "15m",
 	KLINE_PERIOD_30MIN: "30m",
 	KLINE_PERIOD_60MIN: "1h",
+	KLINE_PERIOD_1H:    "1h",
 	KLINE_PERIOD_4H:    "4h",
 	KLINE_PERIOD_8H:    "8h",
 	KLINE_PERIOD_1DAY:  "1d",
 }
 
+// the period not in the converter is resampled from the smaller one, eg: 2H 1WEEK.
+func newKlineFallback(location *time.Location) *KlineFallback {
+	return &KlineFallback{
+		Exchange: GATE,
+		Location: location,
+		MaxSize:  1000,
+		Supported: func(period int) bool {
+			var _, exist = _INERNAL_KLINE_PERIOD_CONVERTER[period]
+			return exist
+		},
+	}
+}
+
 var GATE_PLACE_TYPE_CONVERTER = map[PlaceType]string{
 	NORMAL:     "gtc",
 	IOC:        "ioc",
 	ONLY_MAKER: "poc",
+	FOK:        "fok",
 }
 
 var GATE_PLACE_TYPE_REVERTER = map[string]PlaceType{
 	"gtc": NORMAL,
 	"ioc": IOC,
 	"poc": ONLY_MAKER,
+	"fok": FOK,
 }
 
 type SwapOrderGate struct {
@@ -145,10 +163,123 @@ func (sog *SwapOrderGate) New(loc *time.Location) *SwapOrder {
 	return &sOrder
 }
 
+// SpotOrderGate the amount of the market buy order is the counter currency in gate.
+type SpotOrderGate struct {
+	Id           string      `json:"id,omitempty"`
+	Text         string      `json:"text,omitempty"`
+	CurrencyPair string      `json:"currency_pair"`
+	Type         string      `json:"type"`
+	Account      string      `json:"account"`
+	Side         string      `json:"side"`
+	Amount       float64     `json:"amount,string"`
+	Price        float64     `json:"price,string,omitempty"`
+	TimeInForce  string      `json:"time_in_force"`
+	Status       string      `json:"status,omitempty"`
+	FinishAs     string      `json:"finish_as,omitempty"`
+	Left         float64     `json:"left,string,omitempty"`
+	FilledTotal  float64     `json:"filled_total,string,omitempty"`
+	AvgDealPrice float64     `json:"avg_deal_price,string,omitempty"`
+	Fee          float64     `json:"fee,string,omitempty"`
+	CreateTimeMs json.Number `json:"create_time_ms,omitempty"`
+	UpdateTimeMs json.Number `json:"update_time_ms,omitempty"`
+}
+
+// Merge the market buy order is placed by the amount * price in counter currency, the price is the estimated price.
+func (sog *SpotOrderGate) Merge(order *Order) error {
+	sog.Text = order.Cid
+	sog.CurrencyPair = order.Pair.ToSymbol("_", true)
+	sog.Account = "spot"
+	sog.Amount = order.Amount
+
+	switch order.Side {
+	case BUY, SELL:
+		placeType, exist := GATE_PLACE_TYPE_CONVERTER[order.OrderType]
+		if !exist {
+			return errors.New("The place type is not supported in gate. ")
+		}
+		sog.Type, sog.Price, sog.TimeInForce = "limit", order.Price, placeType
+	case BUY_MARKET:
+		if order.Price <= 0 {
+			return errors.New("The market buy order of gate need the estimated price. ")
+		}
+		sog.Type, sog.TimeInForce = "market", "ioc"
+		sog.Amount = order.Amount * order.Price
+	case SELL_MARKET:
+		sog.Type, sog.TimeInForce = "market", "ioc"
+	default:
+		return errors.New("The order side is not supported in gate. ")
+	}
+
+	sog.Side = "buy"
+	if order.Side == SELL || order.Side == SELL_MARKET {
+		sog.Side = "sell"
+	}
+	return nil
+}
+
+func (sog *SpotOrderGate) New(loc *time.Location) *Order {
+	var order = &Order{
+		Cid:       sog.Text,
+		OrderId:   sog.Id,
+		Price:     sog.Price,
+		Amount:    sog.Amount,
+		AvgPrice:  sog.AvgDealPrice,
+		Fee:       sog.Fee,
+		Pair:      NewPair(sog.CurrencyPair, "_"),
+		OrderType: GATE_PLACE_TYPE_REVERTER[sog.TimeInForce],
+	}
+
+	order.DealAmount = sog.Amount - sog.Left
+	if sog.Type == "market" {
+		order.OrderType = IOC
+		order.Side = SELL_MARKET
+		if sog.Side == "buy" {
+			order.Side = BUY_MARKET
+			order.DealAmount = 0
+			if sog.AvgDealPrice > 0 {
+				order.DealAmount = sog.FilledTotal / sog.AvgDealPrice
+			}
+			order.Amount = order.DealAmount
+		}
+	} else {
+		order.Side = SELL
+		if sog.Side == "buy" {
+			order.Side = BUY
+		}
+	}
+
+	switch sog.Status {
+	case "closed":
+		order.Status = ORDER_FINISH
+	case "cancelled":
+		order.Status = ORDER_CANCEL
+		if sog.Left == 0 && sog.FinishAs == "filled" {
+			order.Status = ORDER_FINISH
+		}
+	default:
+		order.Status = ORDER_UNFINISH
+		if order.DealAmount > 0 {
+			order.Status = ORDER_PART_FINISH
+		}
+	}
+
+	// the time in ms may have the decimal part.
+	if createTime, err := sog.CreateTimeMs.Float64(); err == nil {
+		order.OrderTimestamp = int64(createTime)
+		order.OrderDate = time.Unix(0, order.OrderTimestamp*int64(time.Millisecond)).In(loc).Format(GO_BIRTHDAY)
+	}
+	if updateTime, err := sog.UpdateTimeMs.Float64(); err == nil && order.DealAmount > 0 {
+		order.DealTimestamp = int64(updateTime)
+		order.DealDatetime = time.Unix(0, order.DealTimestamp*int64(time.Millisecond)).In(loc).Format(GO_BIRTHDAY)
+	}
+	return order
+}
+
 type Gate struct {
-	config *APIConfig
-	Spot   *Spot
-	Swap   *Swap
+	config  *APIConfig
+	Spot    *Spot
+	Swap    *Swap
+	Limiter *RateLimiter
 	//Future *Future
 	//Margin *Margin
 	//Wallet *Wallet
@@ -159,9 +290,13 @@ func (*Gate) GetExchangeName() string {
 }
 
 func New(config *APIConfig) *Gate {
-	gate := &Gate{config: config}
+	gate := &Gate{config: config, Limiter: newRateLimiter(config)}
 	gate.Spot = &Spot{gate}
-	gate.Swap = &Swap{gate}
+	gate.Swap = &Swap{
+		Gate:          gate,
+		Locker:        new(sync.Mutex),
+		swapContracts: SwapContracts{},
+	}
 	return gate
 }
 
@@ -177,7 +312,11 @@ func (gate *Gate) DoRequest(
 		url += fmt.Sprintf("?%s", rawQuery)
 	}
 
-	resp, err := NewHttpRequest(
+	var instrument = gateInstrument(rawQuery, reqBody)
+	if err := gate.Limiter.Acquire(httpMethod, uri, instrument); err != nil {
+		return nil, err
+	}
+	resp, header, err := NewHttpRequestWithHeader(
 		gate.config.HttpClient,
 		httpMethod,
 		url,
@@ -187,9 +326,10 @@ func (gate *Gate) DoRequest(
 			ACCEPT:       APPLICATION_JSON,
 		},
 	)
+	gate.Limiter.Feedback(httpMethod, uri, instrument, header, err)
 
 	if err != nil {
-		return nil, err
+		return nil, gateHttpError(err)
 	} else {
 		nowTimestamp := time.Now().Unix() * 1000
 		if nowTimestamp > gate.config.LastTimestamp {
@@ -206,6 +346,12 @@ func (gate *Gate) DoSignRequest(
 	reqBody string,
 	response interface{},
 ) ([]byte, error) {
+	// wait before the sign, the timestamp of the sign is checked by gate.
+	var instrument = gateInstrument(rawQuery, reqBody)
+	if err := gate.Limiter.Acquire(httpMethod, uri, instrument); err != nil {
+		return nil, err
+	}
+
 	h := sha512.New()
 	if reqBody != "" {
 		h.Write([]byte(reqBody))
@@ -223,7 +369,7 @@ func (gate *Gate) DoSignRequest(
 		url += fmt.Sprintf("?%s", rawQuery)
 	}
 
-	resp, err := NewHttpRequest(
+	resp, header, err := NewHttpRequestWithHeader(
 		gate.config.HttpClient,
 		httpMethod,
 		url,
@@ -236,9 +382,10 @@ func (gate *Gate) DoSignRequest(
 			ACCEPT:       APPLICATION_JSON,
 		},
 	)
+	gate.Limiter.Feedback(httpMethod, uri, instrument, header, err)
 
 	if err != nil {
-		return resp, err
+		return resp, gateHttpError(err)
 	} else {
 		nowTimestamp := time.Now().Unix() * 1000
 		if nowTimestamp > gate.config.LastTimestamp {
@@ -0,0 +1,46 @@
+package gate
+
+import (
+	"encoding/json"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The error of gate is the label in the non 2xx response, eg: {"label":"BALANCE_NOT_ENOUGH","message":"..."}
+// https://www.gate.io/docs/developers/apiv4/#label-list
+var _GATE_ERROR_CODES = map[string]Error{
+	"ORDER_NOT_FOUND":           ErrOrderNotFound,
+	"ORDER_CLOSED":              ErrOrderClosed,
+	"ORDER_CANCELLED":           ErrOrderClosed,
+	"BALANCE_NOT_ENOUGH":        ErrInsufficientBalance,
+	"MARGIN_BALANCE_NOT_ENOUGH": ErrInsufficientBalance,
+	"INSUFFICIENT_AVAILABLE":    ErrInsufficientBalance,
+	"POC_FILL_IMMEDIATELY":      ErrPostOnlyRejected,
+	"ORDER_POC_IMMEDIATE":       ErrPostOnlyRejected,
+	"INVALID_PRECISION":         ErrInvalidPrice,
+	"PRICE_TOO_DEVIATED":        ErrInvalidPrice,
+	"INVALID_PARAM_VALUE":       ErrInvalidRequest,
+	"INVALID_KEY":               ErrAuthFailed,
+	"INVALID_SIGNATURE":         ErrAuthFailed,
+	"REQUEST_EXPIRED":           ErrAuthFailed,
+	"MISSING_REQUIRED_HEADER":   ErrAuthFailed,
+	"FORBIDDEN":                 ErrAuthFailed,
+	"READ_ONLY":                 ErrAuthFailed,
+	"IP_FORBIDDEN":              ErrAuthFailed,
+	"TOO_MANY_REQUESTS":         ErrRateLimited,
+	"SERVER_ERROR":              ErrExchangeUnavailable,
+	"TOO_BUSY":                  ErrExchangeUnavailable,
+}
+
+func gateHttpError(err error) error {
+	var body, exist = HttpErrorResponse(err)
+	if !exist {
+		return err
+	}
+	var response = struct {
+		Label   string `json:"label"`
+		Message string `json:"message"`
+	}{}
+	_ = json.Unmarshal([]byte(body), &response)
+	return NewExchangeError(GATE, _GATE_ERROR_CODES, response.Label, response.Message, err)
+}
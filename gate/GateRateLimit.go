@@ -0,0 +1,82 @@
+package gate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The public endpoints are limited per endpoint, the order placement of spot is limited per pair.
+// https://www.gate.io/docs/developers/apiv4/#frequency-limit-rule
+var _GATE_RATE_RULES = map[string]*RateRule{
+	"public":          {Limit: 200, Interval: 10 * time.Second},
+	"spot_order":      {Limit: 10, Interval: time.Second},
+	"spot_cancel":     {Limit: 200, Interval: time.Second},
+	"spot_private":    {Limit: 200, Interval: 10 * time.Second},
+	"futures_order":   {Limit: 100, Interval: time.Second},
+	"futures_cancel":  {Limit: 200, Interval: time.Second},
+	"futures_private": {Limit: 200, Interval: 10 * time.Second},
+}
+
+var _GATE_RATE_COSTS = gateRateCosts()
+
+func gateRateCosts() map[string][]*RateCost {
+	var costs = map[string][]*RateCost{
+		"/api/v4/":                    {{Rule: "public", Weight: 1, PerEndpoint: true}},
+		"POST /api/v4/spot/orders":    {{Rule: "spot_order", Weight: 1, PerInstrument: true}},
+		"DELETE /api/v4/spot/orders":  {{Rule: "spot_cancel", Weight: 1}},
+		"DELETE /api/v4/spot/orders/": {{Rule: "spot_cancel", Weight: 1}},
+	}
+	for _, uri := range []string{
+		"GET /api/v4/spot/orders", "GET /api/v4/spot/orders/",
+		"/api/v4/spot/accounts", "/api/v4/spot/open_orders", "/api/v4/spot/my_trades",
+	} {
+		costs[uri] = []*RateCost{{Rule: "spot_private", Weight: 1, PerEndpoint: true}}
+	}
+
+	for _, settle := range []string{"usdt", "btc"} {
+		var prefix = fmt.Sprintf("/api/v4/futures/%s/", settle)
+		costs["POST "+prefix+"orders"] = []*RateCost{{Rule: "futures_order", Weight: 1}}
+		costs["DELETE "+prefix+"orders"] = []*RateCost{{Rule: "futures_cancel", Weight: 1}}
+		costs["DELETE "+prefix+"orders/"] = []*RateCost{{Rule: "futures_cancel", Weight: 1}}
+		for _, uri := range []string{
+			"GET " + prefix + "orders", "GET " + prefix + "orders/",
+			prefix + "accounts", prefix + "positions", prefix + "positions/",
+			prefix + "account_book", prefix + "my_trades",
+		} {
+			costs[uri] = []*RateCost{{Rule: "futures_private", Weight: 1, PerEndpoint: true}}
+		}
+	}
+	return costs
+}
+
+func newRateLimiter(config *APIConfig) *RateLimiter {
+	return NewRateLimiter(GATE, config.RateLimit, _GATE_RATE_RULES, _GATE_RATE_COSTS)
+}
+
+// gateInstrument the currency_pair or contract in the query or the json body.
+func gateInstrument(rawQuery, reqBody string) string {
+	if values, err := url.ParseQuery(rawQuery); err == nil {
+		if pair := values.Get("currency_pair"); pair != "" {
+			return pair
+		}
+		if contract := values.Get("contract"); contract != "" {
+			return contract
+		}
+	}
+	var body = struct {
+		CurrencyPair string `json:"currency_pair"`
+		Contract     string `json:"contract"`
+	}{}
+	if strings.HasPrefix(strings.TrimSpace(reqBody), "{") && json.Unmarshal([]byte(reqBody), &body) == nil {
+		if body.CurrencyPair != "" {
+			return body.CurrencyPair
+		}
+		return body.Contract
+	}
+	return ""
+}
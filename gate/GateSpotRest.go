@@ -1,6 +1,15 @@
 package gate
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
 	. "github.com/deforceHK/goghostex"
 )
 
@@ -9,53 +18,409 @@ type Spot struct {
 }
 
 func (spot *Spot) GetExchangeRule(pair Pair) (*Rule, []byte, error) {
-	panic("implement me")
+	uri := "/api/v4/spot/currency_pairs/" + pair.ToSymbol("_", true)
+	r := struct {
+		Base            string  `json:"base"`
+		Quote           string  `json:"quote"`
+		MinBaseAmount   float64 `json:"min_base_amount,string"`
+		AmountPrecision int     `json:"amount_precision"`
+		Precision       int     `json:"precision"`
+	}{}
+
+	resp, err := spot.DoRequest(http.MethodGet, uri, "", "", &r)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &Rule{
+		Pair:             pair,
+		Base:             NewCurrency(r.Base, ""),
+		Counter:          NewCurrency(r.Quote, ""),
+		BaseMinSize:      r.MinBaseAmount,
+		BasePrecision:    r.AmountPrecision,
+		CounterPrecision: r.Precision,
+	}, resp, nil
 }
 
 func (spot *Spot) GetTicker(pair Pair) (*Ticker, []byte, error) {
-	panic("implement me")
+	params := url.Values{}
+	params.Add("currency_pair", pair.ToSymbol("_", true))
+
+	r := make([]*struct {
+		Last       float64 `json:"last,string"`
+		LowestAsk  float64 `json:"lowest_ask,string"`
+		HighestBid float64 `json:"highest_bid,string"`
+		BaseVolume float64 `json:"base_volume,string"`
+		High24H    float64 `json:"high_24h,string"`
+		Low24H     float64 `json:"low_24h,string"`
+	}, 0)
+
+	resp, err := spot.DoRequest(http.MethodGet, "/api/v4/spot/tickers", params.Encode(), "", &r)
+	if err != nil {
+		return nil, resp, err
+	}
+	if len(r) == 0 {
+		return nil, resp, errors.New(string(resp))
+	}
+
+	now := time.Now()
+	return &Ticker{
+		Pair:      pair,
+		Last:      r[0].Last,
+		Buy:       r[0].HighestBid,
+		Sell:      r[0].LowestAsk,
+		High:      r[0].High24H,
+		Low:       r[0].Low24H,
+		Vol:       r[0].BaseVolume,
+		Timestamp: now.UnixNano() / int64(time.Millisecond),
+		Date:      now.In(spot.config.Location).Format(GO_BIRTHDAY),
+	}, resp, nil
 }
 
 func (spot *Spot) GetDepth(pair Pair, size int) (*Depth, []byte, error) {
-	panic("implement me")
+	params := url.Values{}
+	params.Add("currency_pair", pair.ToSymbol("_", true))
+	params.Add("limit", fmt.Sprintf("%d", size))
+	params.Add("with_id", "true")
+
+	r := struct {
+		Id      int64       `json:"id"`
+		Current int64       `json:"current"`
+		Asks    [][2]string `json:"asks"`
+		Bids    [][2]string `json:"bids"`
+	}{}
+
+	resp, err := spot.DoRequest(http.MethodGet, "/api/v4/spot/order_book", params.Encode(), "", &r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	depth := new(Depth)
+	depth.Pair = pair
+	depth.Timestamp = r.Current
+	depth.Date = time.Unix(0, r.Current*int64(time.Millisecond)).In(spot.config.Location).Format(GO_BIRTHDAY)
+	depth.Sequence = r.Id
+	for _, bid := range r.Bids {
+		depth.BidList = append(depth.BidList, DepthRecord{Price: ToFloat64(bid[0]), Amount: ToFloat64(bid[1])})
+	}
+	for _, ask := range r.Asks {
+		depth.AskList = append(depth.AskList, DepthRecord{Price: ToFloat64(ask[0]), Amount: ToFloat64(ask[1])})
+	}
+	return depth, resp, nil
 }
 
+// GetKlineRecords the since is the timestamp in second or millisecond.
 func (spot *Spot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline, []byte, error) {
-	panic("implement me")
+	interval, exist := _INERNAL_KLINE_PERIOD_CONVERTER[period]
+	if !exist {
+		return newKlineFallback(spot.config.Location).Klines(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*Kline, []byte, error) {
+				return spot.GetKlineRecords(pair, period, size, int(since))
+			},
+		)
+	}
+
+	params := url.Values{}
+	params.Add("currency_pair", pair.ToSymbol("_", true))
+	params.Add("interval", interval)
+	// the limit is conflicted with the from and the to in gate.
+	if since > 0 {
+		from := int64(since)
+		if from > 1e12 {
+			from /= 1000
+		}
+		params.Add("from", fmt.Sprintf("%d", from))
+		params.Add("to", fmt.Sprintf("%d", from+int64(size)*PeriodMillisecond[period]/1000))
+	} else {
+		params.Add("limit", fmt.Sprintf("%d", size))
+	}
+
+	// [timestamp, quote volume, close, high, low, open, base volume, closed]
+	r := make([][]interface{}, 0)
+	resp, err := spot.DoRequest(http.MethodGet, "/api/v4/spot/candlesticks", params.Encode(), "", &r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	klines := make([]*Kline, 0, len(r))
+	for _, item := range r {
+		if len(item) < 6 {
+			continue
+		}
+		vol := ToFloat64(item[1])
+		if len(item) > 6 {
+			vol = ToFloat64(item[6])
+		}
+		t := time.Unix(ToInt64(item[0]), 0)
+		klines = append(klines, &Kline{
+			Exchange:  GATE,
+			Timestamp: t.UnixNano() / int64(time.Millisecond),
+			Date:      t.In(spot.config.Location).Format(GO_BIRTHDAY),
+			Pair:      pair,
+			Open:      ToFloat64(item[5]),
+			High:      ToFloat64(item[3]),
+			Low:       ToFloat64(item[4]),
+			Close:     ToFloat64(item[2]),
+			Vol:       vol,
+		})
+	}
+	return GetAscKline(klines), resp, nil
 }
 
+// GetTrades the since is the timestamp in millisecond.
 func (spot *Spot) GetTrades(pair Pair, since int64) ([]*Trade, error) {
-	panic("implement me")
+	params := url.Values{}
+	params.Add("currency_pair", pair.ToSymbol("_", true))
+	params.Add("limit", "1000")
+	if since > 0 {
+		params.Add("from", fmt.Sprintf("%d", since/1000))
+		params.Add("to", fmt.Sprintf("%d", time.Now().Unix()))
+	}
+
+	r := make([]*struct {
+		Id           string      `json:"id"`
+		CreateTimeMs json.Number `json:"create_time_ms"`
+		Side         string      `json:"side"`
+		Amount       float64     `json:"amount,string"`
+		Price        float64     `json:"price,string"`
+	}, 0)
+	if _, err := spot.DoRequest(http.MethodGet, "/api/v4/spot/trades", params.Encode(), "", &r); err != nil {
+		return nil, err
+	}
+
+	trades := make([]*Trade, 0, len(r))
+	for _, item := range r {
+		tid, _ := strconv.ParseInt(item.Id, 10, 64)
+		timestamp, _ := item.CreateTimeMs.Float64()
+		if int64(timestamp) < since {
+			continue
+		}
+		side := BUY
+		if item.Side == "sell" {
+			side = SELL
+		}
+		trades = append(trades, &Trade{
+			Tid:       tid,
+			Type:      side,
+			Amount:    item.Amount,
+			Price:     item.Price,
+			Timestamp: int64(timestamp),
+			Pair:      pair,
+		})
+	}
+	return trades, nil
 }
 
 func (spot *Spot) GetAccount() (*Account, []byte, error) {
-	panic("implement me")
+	r := make([]*struct {
+		Currency  string  `json:"currency"`
+		Available float64 `json:"available,string"`
+		Locked    float64 `json:"locked,string"`
+	}, 0)
+
+	resp, err := spot.DoSignRequest(http.MethodGet, "/api/v4/spot/accounts", "", "", &r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	account := &Account{
+		Exchange:    GATE,
+		SubAccounts: make(map[string]SubAccount),
+	}
+	for _, item := range r {
+		symbol := strings.ToUpper(item.Currency)
+		account.SubAccounts[symbol] = SubAccount{
+			Currency:     NewCurrency(symbol, ""),
+			Amount:       item.Available,
+			AmountFrozen: item.Locked,
+		}
+	}
+	return account, resp, nil
+}
+
+// gateText the text of gate start with t-, and at most 28 bytes of letters, digits, _, - or . after it.
+// The cid is prefixed when it's not.
+func gateText(cid string) (string, error) {
+	var text = cid
+	if !strings.HasPrefix(text, "t-") {
+		text = "t-" + text
+	}
+	var body = text[2:]
+	if len(body) == 0 || len(body) > 28 {
+		return "", fmt.Errorf("The cid %s of gate must be 1 to 28 bytes after the t- prefix. ", cid)
+	}
+	for _, c := range body {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '-' || c == '.') {
+			return "", fmt.Errorf("The cid %s of gate can only have letters, digits, _, - or . after the t- prefix. ", cid)
+		}
+	}
+	return text, nil
 }
 
+// PlaceOrder the text of gate must start with t-, the cid is prefixed when it's not.
+// The cid is checked before sending, the invalid cid is an error and the order is not changed.
 func (spot *Spot) PlaceOrder(order *Order) ([]byte, error) {
-	panic("implement me")
+	if order.Cid != "" {
+		text, err := gateText(order.Cid)
+		if err != nil {
+			return nil, err
+		}
+		order.Cid = text
+	}
+	sog := &SpotOrderGate{}
+	if err := sog.Merge(order); err != nil {
+		return nil, err
+	}
+	reqBody, err := json.Marshal(sog)
+	if err != nil {
+		return nil, err
+	}
+
+	response := SpotOrderGate{}
+	now := time.Now()
+	order.PlaceTimestamp = now.UnixNano() / int64(time.Millisecond)
+	order.PlaceDatetime = now.In(spot.config.Location).Format(GO_BIRTHDAY)
+	resp, err := spot.DoSignRequest(http.MethodPost, "/api/v4/spot/orders", "", string(reqBody), &response)
+	if err != nil {
+		return resp, err
+	}
+	if response.Id == "" {
+		return resp, errors.New(string(resp))
+	}
+
+	order.OrderId = response.Id
+	spot.merge(order, &response)
+	return resp, nil
 }
 
 func (spot *Spot) CancelOrder(order *Order) ([]byte, error) {
-	panic("implement me")
+	if order.OrderId == "" {
+		return nil, errors.New("The order id is empty. ")
+	}
+	params := url.Values{}
+	params.Add("currency_pair", order.Pair.ToSymbol("_", true))
+
+	response := SpotOrderGate{}
+	resp, err := spot.DoSignRequest(
+		http.MethodDelete,
+		"/api/v4/spot/orders/"+url.PathEscape(order.OrderId),
+		params.Encode(),
+		"",
+		&response,
+	)
+	if err != nil {
+		return resp, err
+	}
+	spot.merge(order, &response)
+	return resp, nil
 }
 
+// GetOrder the order is found by the order id, or the cid when the order id is empty.
+// The cid of gate is found only in the order book, or in 60 seconds after the order is finished.
 func (spot *Spot) GetOrder(order *Order) ([]byte, error) {
-	panic("implement me")
+	id := order.OrderId
+	if id == "" && order.Cid != "" {
+		text, err := gateText(order.Cid)
+		if err != nil {
+			return nil, err
+		}
+		id = text
+	}
+	if id == "" {
+		return nil, errors.New("The order id and cid is empty. ")
+	}
+	params := url.Values{}
+	params.Add("currency_pair", order.Pair.ToSymbol("_", true))
+
+	response := SpotOrderGate{}
+	resp, err := spot.DoSignRequest(
+		http.MethodGet,
+		"/api/v4/spot/orders/"+url.PathEscape(id),
+		params.Encode(),
+		"",
+		&response,
+	)
+	if err != nil {
+		return resp, err
+	}
+	order.OrderId = response.Id
+	spot.merge(order, &response)
+	return resp, nil
 }
 
+// GetOrders the recent finished orders.
 func (spot *Spot) GetOrders(pair Pair) ([]*Order, error) {
-	panic("implement me")
+	orders, _, err := spot.getOrders(pair, "finished")
+	return orders, err
 }
 
 func (spot *Spot) GetUnFinishOrders(pair Pair) ([]*Order, []byte, error) {
-	panic("implement me")
+	return spot.getOrders(pair, "open")
+}
+
+func (spot *Spot) getOrders(pair Pair, status string) ([]*Order, []byte, error) {
+	params := url.Values{}
+	params.Add("currency_pair", pair.ToSymbol("_", true))
+	params.Add("status", status)
+	params.Add("limit", "100")
+
+	response := make([]*SpotOrderGate, 0)
+	resp, err := spot.DoSignRequest(http.MethodGet, "/api/v4/spot/orders", params.Encode(), "", &response)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	orders := make([]*Order, 0, len(response))
+	for _, o := range response {
+		orders = append(orders, o.New(spot.config.Location))
+	}
+	return orders, resp, nil
+}
+
+// merge the deal and the status of the remote order, the request fields of the local order are kept.
+func (spot *Spot) merge(order *Order, sog *SpotOrderGate) {
+	remote := sog.New(spot.config.Location)
+	order.Status = remote.Status
+	order.AvgPrice = remote.AvgPrice
+	order.DealAmount = remote.DealAmount
+	order.Fee = remote.Fee
+	order.OrderTimestamp, order.OrderDate = remote.OrderTimestamp, remote.OrderDate
+	order.DealTimestamp, order.DealDatetime = remote.DealTimestamp, remote.DealDatetime
+	if order.Cid == "" {
+		order.Cid = remote.Cid
+	}
+	if order.Amount == 0 {
+		order.Amount, order.Price, order.Side = remote.Amount, remote.Price, remote.Side
+	}
 }
 
 func (spot *Spot) KeepAlive() {
-	panic("implement me")
+	nowTimestamp := time.Now().Unix() * 1000
+	if (nowTimestamp - spot.config.LastTimestamp) < 5*1000 {
+		return
+	}
+	_, _, _ = spot.GetTicker(Pair{Basis: BTC, Counter: USDT})
 }
 
+// GetOHLCs the symbol is the pair string, eg: btc_usdt
 func (spot *Spot) GetOHLCs(symbol string, period, size, since int) ([]*OHLC, []byte, error) {
-	panic("implement me")
+	klines, resp, err := spot.GetKlineRecords(NewPair(symbol, "_"), period, size, since)
+	if err != nil {
+		return nil, resp, err
+	}
+	ohlcs := make([]*OHLC, 0, len(klines))
+	for _, k := range klines {
+		ohlcs = append(ohlcs, &OHLC{
+			Symbol:    symbol,
+			Exchange:  GATE,
+			Timestamp: k.Timestamp,
+			Date:      k.Date,
+			Open:      k.Open,
+			Close:     k.Close,
+			High:      k.High,
+			Low:       k.Low,
+			Vol:       k.Vol,
+		})
+	}
+	return ohlcs, resp, nil
 }
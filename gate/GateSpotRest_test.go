@@ -0,0 +1,114 @@
+package gate
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+var _ SpotRestAPI = (*Spot)(nil)
+
+// go test -v ./gate/... -count=1 -run=TestSpotOrderGate_Merge
+func TestSpotOrderGate_Merge(t *testing.T) {
+	var sog = &SpotOrderGate{}
+	var err = sog.Merge(&Order{
+		Cid:       "t-abc",
+		Pair:      Pair{Basis: BTC, Counter: USDT},
+		Side:      SELL,
+		OrderType: ONLY_MAKER,
+		Price:     30000,
+		Amount:    0.01,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body, _ = json.Marshal(sog)
+	var expect = `{"text":"t-abc","currency_pair":"BTC_USDT","type":"limit","account":"spot","side":"sell",` +
+		`"amount":"0.01","price":"30000","time_in_force":"poc"}`
+	if string(body) != expect {
+		t.Error("The limit order body is wrong. ", string(body))
+	}
+
+	// the market buy order is placed in the counter currency.
+	sog = &SpotOrderGate{}
+	if err = sog.Merge(&Order{Pair: Pair{Basis: BTC, Counter: USDT}, Side: BUY_MARKET, Amount: 0.5, Price: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if sog.Type != "market" || sog.Amount != 50 || sog.Price != 0 || sog.TimeInForce != "ioc" {
+		t.Error("The market order is wrong. ", sog)
+	}
+	if err = (&SpotOrderGate{}).Merge(&Order{Side: BUY_MARKET, Amount: 0.5}); err == nil {
+		t.Error("The market buy order without price should be failed. ")
+	}
+}
+
+// go test -v ./gate/... -count=1 -run=TestSpotOrderGate_New
+func TestSpotOrderGate_New(t *testing.T) {
+	var raw = `{"id":"12332324","text":"t-123456","create_time_ms":1548000000123.456,"update_time_ms":1548000100123,
+		"currency_pair":"ETH_BTC","status":"open","type":"limit","account":"spot","side":"buy","amount":"1",
+		"price":"5.00032","time_in_force":"gtc","left":"0.5","filled_total":"2.50016","avg_deal_price":"5.00032",
+		"fee":"0.005","fee_currency":"ETH","finish_as":"open"}`
+	var sog = &SpotOrderGate{}
+	if err := json.Unmarshal([]byte(raw), sog); err != nil {
+		t.Fatal(err)
+	}
+	var order = sog.New(time.UTC)
+	if order.OrderId != "12332324" || order.Cid != "t-123456" || order.Side != BUY || order.OrderType != NORMAL ||
+		order.Status != ORDER_PART_FINISH || order.DealAmount != 0.5 || order.AvgPrice != 5.00032 ||
+		order.Pair.ToSymbol("_", false) != "eth_btc" {
+		t.Error("The order is wrong. ", order)
+	}
+	if order.OrderTimestamp != 1548000000123 || order.DealTimestamp != 1548000100123 {
+		t.Error("The timestamp is wrong. ", order)
+	}
+
+	sog = &SpotOrderGate{
+		Type: "market", Side: "buy", Status: "closed", Amount: 100, FilledTotal: 100, AvgDealPrice: 50,
+	}
+	if order = sog.New(time.UTC); order.Side != BUY_MARKET || order.DealAmount != 2 || order.Status != ORDER_FINISH {
+		t.Error("The market order is wrong. ", order)
+	}
+}
+
+// go test -v ./gate/... -count=1 -run=TestGateHttpError
+func TestGateHttpError(t *testing.T) {
+	var err = gateHttpError(NewHttpError(400, "400 Bad Request", `{"label":"BALANCE_NOT_ENOUGH","message":"balance not enough"}`))
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Error("The error should be insufficient balance. ", err)
+	}
+	err = gateHttpError(NewHttpError(400, "400 Bad Request", `{"label":"INVALID_PARAM_VALUE","message":"invalid text"}`))
+	if !errors.Is(err, ErrInvalidRequest) || errors.Is(err, ErrInvalidPrice) {
+		t.Error("The invalid param should be the invalid request. ", err)
+	}
+	err = gateHttpError(NewHttpError(400, "400 Bad Request", `{"label":"ORDER_CLOSED","message":"order closed"}`))
+	if !errors.Is(err, ErrOrderClosed) || errors.Is(err, ErrOrderNotFound) {
+		t.Error("The closed order should not be the not found. ", err)
+	}
+}
+
+// go test -v ./gate/... -count=1 -run=TestGateText
+func TestGateText(t *testing.T) {
+	if text, err := gateText("abc_1.2-3"); err != nil || text != "t-abc_1.2-3" {
+		t.Error("The cid should be prefixed: ", text, err)
+	}
+	if text, err := gateText("t-abc"); err != nil || text != "t-abc" {
+		t.Error("The prefixed cid should be kept: ", text, err)
+	}
+	for _, cid := range []string{UUID(), "t-", "abc def", "t-abc#1"} {
+		if _, err := gateText(cid); err == nil {
+			t.Error("The invalid cid should be an error: ", cid)
+		}
+	}
+	if text, err := gateText(UUID()[:28]); err != nil || len(text) != 30 {
+		t.Error("The 28 bytes cid should be valid: ", text, err)
+	}
+
+	var order = &Order{Cid: UUID(), Pair: BTC_USDT}
+	var cid = order.Cid
+	if _, err := (&Spot{}).PlaceOrder(order); err == nil || order.Cid != cid {
+		t.Error("The order with the invalid cid should not be sent or changed: ", err, order.Cid)
+	}
+}
@@ -2,11 +2,11 @@ package gate
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	. "github.com/deforceHK/goghostex"
@@ -14,6 +14,10 @@ import (
 
 type Swap struct {
 	*Gate
+	sync.Locker
+	swapContracts SwapContracts
+
+	nextUpdateContractTime time.Time // the next time to update the contracts
 }
 
 //func (swap *Swap) GetExchangeRule(pair Pair) (*SwapRule, []byte, error) {
@@ -156,14 +160,6 @@ func (swap *Swap) GetDepth(pair Pair, size int) (*SwapDepth, []byte, error) {
 	}
 }
 
-func (swap *Swap) GetContract(pair Pair) *SwapContract {
-	panic("implement me")
-}
-
-func (swap *Swap) GetLimit(pair Pair) (float64, float64, error) {
-	panic("implement me")
-}
-
 func (swap *Swap) GetKline(pair Pair, period, size, since int) ([]*SwapKline, []byte, error) {
 	uri := "/api/v4/futures/%s/candlesticks"
 	symbol := pair.ToSymbol("_", true)
@@ -175,7 +171,12 @@ func (swap *Swap) GetKline(pair Pair, period, size, since int) ([]*SwapKline, []
 	}
 
 	if _, exist := _INERNAL_KLINE_PERIOD_CONVERTER[period]; !exist {
-		return nil, nil, errors.New("Can not get the period kline data. ")
+		return newKlineFallback(swap.config.Location).SwapKlines(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*SwapKline, []byte, error) {
+				return swap.GetKline(pair, period, size, int(since))
+			},
+		)
 	}
 
 	params := url.Values{}
@@ -221,10 +222,6 @@ func (swap *Swap) GetKline(pair Pair, period, size, since int) ([]*SwapKline, []
 	}
 }
 
-func (swap *Swap) GetOpenAmount(pair Pair) (float64, int64, []byte, error) {
-	panic("implement me")
-}
-
 func (swap *Swap) GetFundingFees(pair Pair) ([][]interface{}, []byte, error) {
 	uri := "/api/v4/futures/%s/funding_rate"
 	symbol, settle := pair.ToSymbol("_", true), ""
@@ -513,14 +510,6 @@ func (swap *Swap) GetUnFinishOrders(pair Pair) ([]*SwapOrder, []byte, error) {
 	return orders, resp, nil
 }
 
-func (swap *Swap) GetPosition(pair Pair, openType FutureType) (*SwapPosition, []byte, error) {
-	panic("implement me")
-}
-
-func (swap *Swap) GetAccountFlow() ([]*SwapAccountItem, []byte, error) {
-	panic("implement me")
-}
-
 func (swap *Swap) KeepAlive() {
 	_, _ = swap.GetFundingFee(Pair{Basis: BTC, Counter: USDT})
 }
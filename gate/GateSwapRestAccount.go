@@ -0,0 +1,163 @@
+package gate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+var _INERNAL_FLOW_TYPE_CONVERTER = map[string]string{
+	"pnl":  SUBJECT_SETTLE,
+	"fee":  SUBJECT_COMMISSION,
+	"fund": SUBJECT_FUNDING_FEE,
+}
+
+// GetPosition the empty position is returned when there is no position of the type.
+// The position in single mode is long or short by the sign of the size, the leverage 0 means cross margin.
+func (swap *Swap) GetPosition(pair Pair, openType FutureType) (*SwapPosition, []byte, error) {
+	var params = url.Values{}
+	params.Set("holding", "true")
+	var response = make([]*struct {
+		Contract           string  `json:"contract"`
+		Size               int64   `json:"size"`
+		Leverage           float64 `json:"leverage,string"`
+		CrossLeverageLimit float64 `json:"cross_leverage_limit,string"`
+		Margin             float64 `json:"margin,string"`
+		EntryPrice         float64 `json:"entry_price,string"`
+		LiqPrice           float64 `json:"liq_price,string"`
+		MarkPrice          float64 `json:"mark_price,string"`
+		Mode               string  `json:"mode"`
+	}, 0)
+	resp, err := swap.DoSignRequest(
+		http.MethodGet,
+		fmt.Sprintf("/api/v4/futures/%s/positions", swapSettle(pair)),
+		params.Encode(),
+		"",
+		&response,
+	)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	for _, p := range response {
+		if p.Contract != pair.ToSymbol("_", true) || p.Size == 0 {
+			continue
+		}
+		var positionType = OPEN_LONG
+		if p.Mode == "dual_short" || (p.Mode != "dual_long" && p.Size < 0) {
+			positionType = OPEN_SHORT
+		}
+		if positionType != openType {
+			continue
+		}
+
+		var amount = p.Size
+		if amount < 0 {
+			amount = -amount
+		}
+		var marginType, leverage = ISOLATED, p.Leverage
+		if p.Leverage == 0 {
+			marginType, leverage = CROSS, p.CrossLeverageLimit
+		}
+		return &SwapPosition{
+			Pair:           pair,
+			Type:           positionType,
+			Amount:         float64(amount),
+			Price:          p.EntryPrice,
+			MarkPrice:      p.MarkPrice,
+			LiquidatePrice: p.LiqPrice,
+			MarginType:     marginType,
+			MarginAmount:   p.Margin,
+			Leverage:       int64(leverage),
+		}, resp, nil
+	}
+	return &SwapPosition{Pair: pair, Type: openType}, resp, nil
+}
+
+// GetAccountFlow the recent settle, commission and funding fee of the usdt and the btc settled contracts.
+func (swap *Swap) GetAccountFlow() ([]*SwapAccountItem, []byte, error) {
+	var items = make([]*SwapAccountItem, 0)
+	var resps = make([]string, 0, len(_GATE_SWAP_SETTLES))
+	for _, settle := range _GATE_SWAP_SETTLES {
+		var settleItems, resp, err = swap.accountFlow(settle, "")
+		if err != nil {
+			return nil, resp, err
+		}
+		items = append(items, settleItems...)
+		resps = append(resps, string(resp))
+	}
+	var resp, _ = json.Marshal(resps)
+	return items, resp, nil
+}
+
+func (swap *Swap) GetPairFlow(pair Pair) ([]*SwapAccountItem, []byte, error) {
+	return swap.accountFlow(swapSettle(pair), pair.ToSymbol("_", true))
+}
+
+func (swap *Swap) accountFlow(settle, contract string) ([]*SwapAccountItem, []byte, error) {
+	var params = url.Values{}
+	params.Set("limit", "100")
+	if contract != "" {
+		params.Set("contract", contract)
+	}
+	var response = make([]*struct {
+		Id       string  `json:"id"`
+		Time     float64 `json:"time"`
+		Change   float64 `json:"change,string"`
+		Balance  float64 `json:"balance,string"`
+		Type     string  `json:"type"`
+		Text     string  `json:"text"`
+		Contract string  `json:"contract"`
+	}, 0)
+	resp, err := swap.DoSignRequest(
+		http.MethodGet,
+		fmt.Sprintf("/api/v4/futures/%s/account_book", settle),
+		params.Encode(),
+		"",
+		&response,
+	)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var settleMode = SETTLE_MODE_COUNTER
+	if settle != "usdt" {
+		settleMode = SETTLE_MODE_BASIS
+	}
+	var items = make([]*SwapAccountItem, 0)
+	for _, r := range response {
+		var subject, exist = _INERNAL_FLOW_TYPE_CONVERTER[r.Type]
+		if !exist {
+			continue
+		}
+		// the contract is in the text of the old records, eg: BTC_USDT:1234567
+		var name = r.Contract
+		if name == "" {
+			name = strings.Split(r.Text, ":")[0]
+		}
+		if name == "" || (contract != "" && name != contract) {
+			continue
+		}
+
+		var timestamp = int64(r.Time * 1000)
+		var info, _ = json.Marshal(r)
+		items = append(items, &SwapAccountItem{
+			Pair:           NewPair(name, "_"),
+			Exchange:       GATE,
+			Subject:        subject,
+			Id:             r.Id,
+			SettleMode:     settleMode,
+			SettleCurrency: NewCurrency(settle, ""),
+			Amount:         r.Change,
+			Timestamp:      timestamp,
+			DateTime:       time.Unix(0, timestamp*int64(time.Millisecond)).In(swap.config.Location).Format(GO_BIRTHDAY),
+			Info:           string(info),
+		})
+	}
+	return items, resp, nil
+}
@@ -0,0 +1,179 @@
+package gate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// the settle currencies of gate futures, the usdt is direct, the btc is inverse.
+var _GATE_SWAP_SETTLES = []string{"usdt", "btc"}
+
+type swapContractGate struct {
+	Name              string  `json:"name"`
+	Type              string  `json:"type"`
+	QuantoMultiplier  float64 `json:"quanto_multiplier,string"`
+	OrderPriceRound   float64 `json:"order_price_round,string"`
+	OrderSizeMin      int64   `json:"order_size_min"`
+	OrderPriceDeviate float64 `json:"order_price_deviate,string"`
+	MarkPrice         float64 `json:"mark_price,string"`
+	InDelisting       bool    `json:"in_delisting"`
+}
+
+// New the unit amount of the direct contract is the basis currency, the inverse contract is 1 counter currency.
+func (scg *swapContractGate) New() *SwapContract {
+	var pair = NewPair(scg.Name, "_")
+	var contract = &SwapContract{
+		Pair:         pair,
+		Symbol:       pair.ToSymbol("_", false),
+		Exchange:     GATE,
+		ContractName: pair.ToSwapContractName(),
+		SettleMode:   SETTLE_MODE_COUNTER,
+
+		UnitAmount:      scg.QuantoMultiplier,
+		TickSize:        scg.OrderPriceRound,
+		PricePrecision:  GetPrecisionInt64(scg.OrderPriceRound),
+		AmountPrecision: 0, // the size of gate futures is integer.
+	}
+	if scg.Type == "inverse" {
+		contract.SettleMode = SETTLE_MODE_BASIS
+		contract.UnitAmount = 1
+	}
+	return contract
+}
+
+// swapSettle the settle currency in the uri, eg: BTC_USDT is usdt, BTC_USD is btc.
+func swapSettle(pair Pair) string {
+	if strings.Index(pair.ToSymbol("_", true), "_USDT") > 0 {
+		return strings.ToLower(pair.Counter.Symbol)
+	}
+	return strings.ToLower(pair.Basis.Symbol)
+}
+
+func (swap *Swap) GetContract(pair Pair) *SwapContract {
+	return swap.getContract(pair)
+}
+
+// GetLimit the order price must be in the deviation of the mark price, return the highest and the lowest price.
+func (swap *Swap) GetLimit(pair Pair) (float64, float64, error) {
+	var uri = fmt.Sprintf("/api/v4/futures/%s/contracts/%s", swapSettle(pair), pair.ToSymbol("_", true))
+	var response = swapContractGate{}
+	if _, err := swap.DoRequest(http.MethodGet, uri, "", "", &response); err != nil {
+		return 0, 0, err
+	}
+	if response.MarkPrice <= 0 {
+		return 0, 0, errors.New("The mark price is not ready. ")
+	}
+	return response.MarkPrice * (1 + response.OrderPriceDeviate), response.MarkPrice * (1 - response.OrderPriceDeviate), nil
+}
+
+// GetOpenAmount the open interest in the basis currency and the timestamp of the stat.
+func (swap *Swap) GetOpenAmount(pair Pair) (float64, int64, []byte, error) {
+	var contract = swap.GetContract(pair)
+	if contract == nil {
+		return 0, 0, nil, errors.New("Can not find the contract. ")
+	}
+
+	var params = url.Values{}
+	params.Set("contract", pair.ToSymbol("_", true))
+	params.Set("interval", "5m")
+	params.Set("limit", "1")
+	var response = make([]*struct {
+		Time         int64   `json:"time"`
+		OpenInterest float64 `json:"open_interest"`
+		MarkPrice    float64 `json:"mark_price"`
+	}, 0)
+	resp, err := swap.DoRequest(
+		http.MethodGet,
+		fmt.Sprintf("/api/v4/futures/%s/contract_stats", swapSettle(pair)),
+		params.Encode(),
+		"",
+		&response,
+	)
+	if err != nil {
+		return 0, 0, resp, err
+	}
+	if len(response) == 0 {
+		return 0, 0, resp, errors.New("lack response data. ")
+	}
+
+	var stat = response[len(response)-1]
+	var amount = stat.OpenInterest * contract.UnitAmount
+	if contract.SettleMode == SETTLE_MODE_BASIS {
+		if stat.MarkPrice <= 0 {
+			return 0, 0, resp, errors.New("The mark price is not ready. ")
+		}
+		amount = amount / stat.MarkPrice
+	}
+	return amount, stat.Time * 1000, resp, nil
+}
+
+func (swap *Swap) getContract(pair Pair) *SwapContract {
+	defer swap.Unlock()
+	swap.Lock()
+
+	var now = time.Now().In(swap.config.Location)
+	if now.After(swap.nextUpdateContractTime) {
+		_, err := swap.updateContracts()
+		// retry 3 times
+		for i := 0; err != nil && i < 3; i++ {
+			time.Sleep(time.Second)
+			_, err = swap.updateContracts()
+		}
+		// the old contracts are used when the update failed, retry in 10 minutes.
+		if err != nil {
+			swap.nextUpdateContractTime = now.Add(10 * time.Minute)
+		}
+	}
+	return swap.swapContracts.ContractNameKV[pair.ToSwapContractName()]
+}
+
+func (swap *Swap) updateContracts() ([]byte, error) {
+	var swapContracts = SwapContracts{
+		ContractNameKV: make(map[string]*SwapContract, 0),
+	}
+	var lastResp []byte
+	for _, settle := range _GATE_SWAP_SETTLES {
+		var response = make([]*swapContractGate, 0)
+		resp, err := swap.DoRequest(
+			http.MethodGet,
+			fmt.Sprintf("/api/v4/futures/%s/contracts", settle),
+			"",
+			"",
+			&response,
+		)
+		if err != nil {
+			return resp, err
+		}
+		lastResp = resp
+		for _, item := range response {
+			if item.InDelisting {
+				continue
+			}
+			var contract = item.New()
+			swapContracts.ContractNameKV[contract.ContractName] = contract
+		}
+	}
+	if len(swapContracts.ContractNameKV) == 0 {
+		return lastResp, errors.New("The api data not ready. ")
+	}
+
+	// setting next update time.
+	var nowTime = time.Now().In(swap.config.Location)
+	var nextUpdateTime = time.Date(
+		nowTime.Year(), nowTime.Month(), nowTime.Day(),
+		16, 0, 0, 0, swap.config.Location,
+	)
+	if nowTime.Hour() >= 16 {
+		nextUpdateTime = nextUpdateTime.AddDate(0, 0, 1)
+	}
+
+	swap.nextUpdateContractTime = nextUpdateTime
+	swap.swapContracts = swapContracts
+	return lastResp, nil
+}
@@ -0,0 +1,115 @@
+package gate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+var _ SwapRestAPI = (*Swap)(nil)
+
+// the gate client always request the ENDPOINT, the transport serve it by the handler.
+type handlerTransport struct {
+	handler http.HandlerFunc
+}
+
+func (this *handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var recorder = httptest.NewRecorder()
+	this.handler(recorder, req)
+	return recorder.Result(), nil
+}
+
+func newTestGate(handler http.HandlerFunc) *Gate {
+	return New(&APIConfig{
+		HttpClient: &http.Client{Transport: &handlerTransport{handler}},
+		Location:   time.UTC,
+		RateLimit:  RATE_LIMIT_OFF,
+	})
+}
+
+// go test -v ./gate/... -count=1 -run=TestSwap_GetContract
+func TestSwap_GetContract(t *testing.T) {
+	var gate = newTestGate(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/futures/usdt/contracts":
+			_, _ = w.Write([]byte(`[{"name":"BTC_USDT","type":"direct","quanto_multiplier":"0.0001",
+				"order_price_round":"0.1","order_size_min":1,"order_price_deviate":"0.5","mark_price":"30000"}]`))
+		case "/api/v4/futures/btc/contracts":
+			_, _ = w.Write([]byte(`[{"name":"BTC_USD","type":"inverse","quanto_multiplier":"0",
+				"order_price_round":"0.5","order_size_min":1,"order_price_deviate":"0.5","mark_price":"30000"}]`))
+		case "/api/v4/futures/usdt/contracts/BTC_USDT":
+			_, _ = w.Write([]byte(`{"name":"BTC_USDT","type":"direct","order_price_deviate":"0.1","mark_price":"30000"}`))
+		case "/api/v4/futures/btc/contract_stats":
+			_, _ = w.Write([]byte(`[{"time":1700000000,"open_interest":600000,"mark_price":30000}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	var contract = gate.Swap.GetContract(Pair{Basis: BTC, Counter: USDT})
+	if contract == nil || contract.SettleMode != SETTLE_MODE_COUNTER || contract.UnitAmount != 0.0001 ||
+		contract.TickSize != 0.1 || contract.PricePrecision != 1 || contract.AmountPrecision != 0 {
+		t.Fatal("The usdt contract is wrong. ", contract)
+	}
+	contract = gate.Swap.GetContract(Pair{Basis: BTC, Counter: USD})
+	if contract == nil || contract.SettleMode != SETTLE_MODE_BASIS || contract.UnitAmount != 1 || contract.TickSize != 0.5 {
+		t.Fatal("The btc contract is wrong. ", contract)
+	}
+
+	var high, low, err = gate.Swap.GetLimit(Pair{Basis: BTC, Counter: USDT})
+	if err != nil || high != 33000 || low != 27000 {
+		t.Error("The limit is wrong. ", high, low, err)
+	}
+
+	var amount, timestamp, _, amountErr = gate.Swap.GetOpenAmount(Pair{Basis: BTC, Counter: USD})
+	if amountErr != nil || amount != 20 || timestamp != 1700000000000 {
+		t.Error("The open amount is wrong. ", amount, timestamp, amountErr)
+	}
+}
+
+// go test -v ./gate/... -count=1 -run=TestSwap_GetPosition
+func TestSwap_GetPosition(t *testing.T) {
+	var gate = newTestGate(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/futures/usdt/positions":
+			_, _ = w.Write([]byte(`[
+				{"contract":"BTC_USDT","size":-20,"leverage":"0","cross_leverage_limit":"10","margin":"60",
+				 "entry_price":"30000","liq_price":"40000","mark_price":"30100","mode":"single"},
+				{"contract":"ETH_USDT","size":5,"leverage":"3","margin":"10","entry_price":"2000","mode":"single"}]`))
+		case "/api/v4/futures/usdt/account_book":
+			_, _ = w.Write([]byte(`[
+				{"id":"1","time":1700000000.123,"change":"-0.5","balance":"100","type":"fee","text":"BTC_USDT:1"},
+				{"id":"2","time":1700000001,"change":"1.2","balance":"101","type":"fund","contract":"BTC_USDT"},
+				{"id":"3","time":1700000002,"change":"50","balance":"151","type":"dnw","text":""}]`))
+		case "/api/v4/futures/btc/account_book":
+			_, _ = w.Write([]byte(`[{"id":"4","time":1700000003,"change":"0.001","balance":"1","type":"pnl","contract":"BTC_USD"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	var position, _, err = gate.Swap.GetPosition(Pair{Basis: BTC, Counter: USDT}, OPEN_SHORT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position.Amount != 20 || position.Price != 30000 || position.LiquidatePrice != 40000 ||
+		position.MarginType != CROSS || position.Leverage != 10 || position.MarginAmount != 60 {
+		t.Error("The short position is wrong. ", position)
+	}
+	if position, _, _ = gate.Swap.GetPosition(Pair{Basis: BTC, Counter: USDT}, OPEN_LONG); position.Amount != 0 {
+		t.Error("The long position should be empty. ", position)
+	}
+
+	var items, _, flowErr = gate.Swap.GetAccountFlow()
+	if flowErr != nil {
+		t.Fatal(flowErr)
+	}
+	if len(items) != 3 || items[0].Subject != SUBJECT_COMMISSION || items[0].Timestamp != 1700000000123 ||
+		items[1].Subject != SUBJECT_FUNDING_FEE || items[2].Subject != SUBJECT_SETTLE ||
+		items[2].SettleMode != SETTLE_MODE_BASIS || items[2].Pair.ToSymbol("_", false) != "btc_usd" {
+		t.Error("The account flow is wrong. ", items)
+	}
+}
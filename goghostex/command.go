@@ -3,20 +3,28 @@ package main
 import (
 	"flag"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/deforceHK/goghostex/binance"
 	"github.com/deforceHK/goghostex/coinbase"
+	"github.com/deforceHK/goghostex/gate"
+	"github.com/deforceHK/goghostex/kraken"
 	"github.com/deforceHK/goghostex/okex"
 
 	. "github.com/deforceHK/goghostex"
 )
 
+// T2O_PRICE_RATIO the t2o buy order is placed at the ratio of the bid price.
+const T2O_PRICE_RATIO = 0.8
+
 var okexClient *okex.OKEx
 var coinbaseClient *coinbase.Coinbase
 var binanceClient *binance.Binance
+var krakenClient *kraken.Kraken
+var gateClient *gate.Gate
 
 var spotClients = map[string]SpotRestAPI{}
 var swapClients = map[string]SwapRestAPI{}
@@ -83,8 +91,21 @@ type Command struct {
 	Exchange     string
 	Pair         string
 	ContractType string
-
-	Proxy string
+	Amount       float64 // the order amount of t2o, the min amount of the pair if it's less
+	Times        int
+
+	Side      string // buy sell in spot, open_long open_short liquidate_long liquidate_short in swap and future
+	Price     float64
+	PlaceType string
+	LeverRate int64
+	OrderId   string
+	Cid       string
+	Confirm   bool   // the order-mutating command must be confirmed
+	Output    string // table or json
+
+	Proxy   string
+	Config  string // the profiles file, see the LoadProfiles
+	Account string // the sub-account in the profiles
 
 	APIKey        string
 	APISecret     string
@@ -99,7 +120,19 @@ func (c *Command) Init(subCommand string, args []string) {
 	fs.StringVar(&c.Type, "type", "spot", "Input the type. Default is spot. ")
 	fs.StringVar(&c.Pair, "pair", "btc_usd", "Input the pair. Default is btc_usd. ")
 	fs.StringVar(&c.ContractType, "contract-type", "", "Input the contract-type. It's nessary in future. ")
+	fs.Float64Var(&c.Amount, "amount", 0, "Input the order amount. Default is the min amount in t2o. ")
+	fs.IntVar(&c.Times, "times", 1, "Input the request times of t2o. Default is 1. ")
+	fs.StringVar(&c.Side, "side", "", "Input the side. buy/sell in spot, open_long/open_short/liquidate_long/liquidate_short in swap and future. ")
+	fs.Float64Var(&c.Price, "price", 0, "Input the order price. ")
+	fs.StringVar(&c.PlaceType, "place-type", "normal", "Input the place type, normal/only_maker/fok/ioc/market. Default is normal. ")
+	fs.Int64Var(&c.LeverRate, "lever-rate", 1, "Input the lever rate of swap and future. Default is 1. ")
+	fs.StringVar(&c.OrderId, "order-id", "", "Input the order id. ")
+	fs.StringVar(&c.Cid, "cid", "", "Input the client order id. ")
+	fs.BoolVar(&c.Confirm, "confirm", false, "Confirm the order-mutating command: place, cancel, cancel-all and t2o. ")
+	fs.StringVar(&c.Output, "output", "table", "Input the output format, table/json. Default is table. ")
 	fs.StringVar(&c.Proxy, "proxy", "", "Input the proxy. ")
+	fs.StringVar(&c.Config, "config", "", "Input the profiles file, json or .env. Default is the GOGHOSTEX_CONFIG environment. ")
+	fs.StringVar(&c.Account, "account", "", "Input the sub-account in the profiles. Default is the main account. ")
 	fs.StringVar(&c.APIKey, "api-key", "", "Input the api-key. ")
 	fs.StringVar(&c.APISecret, "api-secret", "", "Input the api-secret. ")
 	fs.StringVar(&c.APIPassphrase, "api-passphrase", "", "Input the api-passphrase. ")
@@ -121,8 +154,21 @@ func (c *Command) Init(subCommand string, args []string) {
 	} else if subCommand == "info" {
 		c.Info()
 	} else if subCommand == "t2o" {
-		//}else if subCommand == ""{
-		//}else if subCommand == ""{
+		c.t2o()
+	} else if subCommand == "account" {
+		c.account()
+	} else if subCommand == "positions" {
+		c.positions()
+	} else if subCommand == "orders" {
+		c.orders()
+	} else if subCommand == "place" {
+		c.place()
+	} else if subCommand == "cancel" {
+		c.cancel()
+	} else if subCommand == "cancel-all" {
+		c.cancelAll()
+	} else if subCommand == "flow" {
+		c.flow()
 	}
 }
 
@@ -332,91 +378,283 @@ func (c *Command) coDepth() {
 
 }
 
+// spotRuleAPI the exchange rule of the spot is not in the SpotRestAPI, not every exchange has it.
+type spotRuleAPI interface {
+	GetExchangeRule(pair Pair) (*Rule, []byte, error)
+}
+
 func (c *Command) Info() {
+	p := NewPair(c.Pair, "_")
 
-	//p := NewPair(c.Pair, "_")
-	//var rule *Rule
-	//var response []byte
-	//
-	//switch c.Type {
-	//case "future":
-	//	fr, resp, err := swapClients[c.Exchange].GetExchangeRule(p)
-	//	if err != nil {
-	//		fmt.Println(err)
-	//		return
-	//	}
-	//	rule = &fr.Rule
-	//	response = resp
-	//case "spot":
-	//	r, resp, err := spotClients[c.Exchange].GetExchangeRule(p)
-	//	if err != nil {
-	//		fmt.Println(err)
-	//		return
-	//	}
-	//	rule = r
-	//	response = resp
-	//case "swap":
-	//	sr, resp, err := swapClients[c.Exchange].GetExchangeRule(p)
-	//	if err != nil {
-	//		fmt.Println(err)
-	//		return
-	//	}
-	//	rule = &sr.Rule
-	//	response = resp
-	//default:
-	//	panic("imp it!")
-	//}
-
-	//fmt.Printf("%s %s @%s rule: \n", c.Pair, c.Type, c.Exchange)
-	//fmt.Printf("The min order amount: %f \n", rule.BaseMinSize)
-	//fmt.Printf("The order amount precision: %d \n", rule.BasePrecision)
-	//fmt.Printf("The order price precision: %d \n", rule.CounterPrecision)
-	fmt.Println("------------------- Raw Response From Exchange -------------------")
-	//fmt.Println(string(response))
+	fmt.Printf("%s %s @%s rule: \n", c.Pair, c.Type, c.Exchange)
+	switch c.Type {
+	case "future":
+		client, exist := futureClients[c.Exchange]
+		if !exist {
+			fmt.Printf("The command not support %s in %s. \n", c.Type, c.Exchange)
+			return
+		}
+		contract, err := client.GetContract(p, c.ContractType)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("Contract name:", contract.ContractName)
+		fmt.Println("Contract type:", contract.ContractType)
+		fmt.Println("Settle mode:", contract.SettleMode)
+		fmt.Println("Due date:", contract.DueDate)
+		fmt.Println("Unit amount:", contract.UnitAmount)
+		fmt.Println("Tick size:", contract.TickSize)
+		fmt.Println("Price precision:", contract.PricePrecision)
+		fmt.Println("Amount precision:", contract.AmountPrecision)
+		fmt.Println("------------------- Raw Response From Exchange -------------------")
+		fmt.Println(contract.RawData)
+	case "spot":
+		client, exist := spotClients[c.Exchange].(spotRuleAPI)
+		if !exist {
+			fmt.Printf("The command not support %s in %s. \n", c.Type, c.Exchange)
+			return
+		}
+		rule, response, err := client.GetExchangeRule(p)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("Tick size:", math.Pow10(-rule.CounterPrecision))
+		fmt.Println("Price precision:", rule.CounterPrecision)
+		fmt.Println("Amount precision:", rule.BasePrecision)
+		fmt.Println("Min order amount:", rule.BaseMinSize)
+		fmt.Println("------------------- Raw Response From Exchange -------------------")
+		fmt.Println(string(response))
+	case "swap":
+		client, exist := swapClients[c.Exchange]
+		if !exist {
+			fmt.Printf("The command not support %s in %s. \n", c.Type, c.Exchange)
+			return
+		}
+		contract := client.GetContract(p)
+		if contract == nil {
+			fmt.Printf("Can not find the contract of %s in %s. \n", c.Pair, c.Exchange)
+			return
+		}
+		fmt.Println("Contract name:", contract.ContractName)
+		fmt.Println("Settle mode:", contract.SettleMode)
+		fmt.Println("Unit amount:", contract.UnitAmount)
+		fmt.Println("Tick size:", contract.TickSize)
+		fmt.Println("Price precision:", contract.PricePrecision)
+		fmt.Println("Amount precision:", contract.AmountPrecision)
+		if high, low, err := client.GetLimit(p); err == nil {
+			fmt.Println("Price limit:", low, "-", high)
+		}
+	default:
+		fmt.Printf("The command not support %s in %s. \n", c.Type, c.Exchange)
+	}
 }
 
-func (c *Command) initClients() {
-	loc := time.Now().Location()
-	okexClient = okex.New(
-		&APIConfig{
-			Endpoint:      okex.ENDPOINT,
-			HttpClient:    getHttpClient(c.Proxy),
-			ApiKey:        c.APIKey,
-			ApiSecretKey:  c.APISecret,
-			ApiPassphrase: c.APIPassphrase,
-			Location:      loc,
-		},
-	)
-	coinbaseClient = coinbase.New(
-		&APIConfig{
-			Endpoint:      coinbase.ENDPOINT,
-			HttpClient:    getHttpClient(c.Proxy),
-			ApiKey:        c.APIKey,
-			ApiSecretKey:  c.APISecret,
-			ApiPassphrase: c.APIPassphrase,
-			Location:      loc,
-		},
-	)
-	binanceClient = binance.New(
-		&APIConfig{
-			Endpoint:      binance.ENDPOINT,
-			HttpClient:    getHttpClient(c.Proxy),
-			ApiKey:        c.APIKey,
-			ApiSecretKey:  c.APISecret,
-			ApiPassphrase: c.APIPassphrase,
-			Location:      loc,
-		},
+// t2o place the ONLY_MAKER buy order far from the market after the ticker observed, then cancel it at once.
+// The delay from the ticker observed to the order acknowledged is the t2o.
+func (c *Command) t2o() {
+	if !c.confirmed("t2o") {
+		return
+	}
+	receiveDelays := make([]int64, 0)
+	totalDelays := int64(0)
+	errorNum := 0
+	for i := 0; i < c.Times; i++ {
+		tickerDelay, t2oDelay, cancelDelay, err := c.getT2O()
+		if err != nil {
+			fmt.Println(err)
+			errorNum += 1
+			continue
+		}
+		fmt.Printf(
+			"The %d sequence. The ticker delay is %d ns, the t2o delay is %d ns, the cancel delay is %d ns. \n",
+			i+1, tickerDelay, t2oDelay, cancelDelay,
+		)
+		receiveDelays = append(receiveDelays, t2oDelay)
+		totalDelays += t2oDelay
+	}
+
+	fmt.Printf("%s %s @%s t2o: \n", c.Pair, c.Type, c.Exchange)
+	if len(receiveDelays) == 0 {
+		fmt.Printf("Request %d times, errored %d times. \n", c.Times, errorNum)
+		return
+	}
+	fmt.Printf(
+		"Request %d times, acknowledged %d times, errored %d times, avg t2o delay is %.2f ns(nanosecond). \n",
+		c.Times, len(receiveDelays), errorNum, float64(totalDelays)/float64(len(receiveDelays)),
 	)
+}
+
+// newCid the uuid without -, the text of gate is at most 28 bytes after the t- prefix.
+func newCid(exchange string) string {
+	var cid = UUID()
+	if exchange == GATE {
+		return cid[:28]
+	}
+	return cid
+}
+
+// getT2O return the ticker delay, the ticker to order delay and the cancel delay.
+// The rule or the contract is got before the ticker observed, its request is not in the t2o.
+func (c *Command) getT2O() (int64, int64, int64, error) {
+	p := NewPair(c.Pair, "_")
+
+	var place func(price float64) (func() ([]byte, error), error)
+	switch c.Type {
+	case "future":
+		contract, err := futureClients[c.Exchange].GetContract(p, c.ContractType)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		place = func(price float64) (func() ([]byte, error), error) {
+			order := &FutureOrder{
+				Cid:          newCid(c.Exchange),
+				Price:        ToFloat64(FloatToPrice(price, contract.PricePrecision, contract.TickSize)),
+				Amount:       int64(math.Max(c.Amount, 1)),
+				PlaceType:    ONLY_MAKER,
+				Type:         OPEN_LONG,
+				LeverRate:    1,
+				Pair:         p,
+				ContractType: c.ContractType,
+				Exchange:     c.Exchange,
+			}
+			if _, err := futureClients[c.Exchange].PlaceOrder(order); err != nil {
+				return nil, err
+			}
+			return func() ([]byte, error) { return futureClients[c.Exchange].CancelOrder(order) }, nil
+		}
+	case "spot":
+		client, exist := spotClients[c.Exchange].(spotRuleAPI)
+		if !exist {
+			return 0, 0, 0, fmt.Errorf("The command not support %s in %s. ", c.Type, c.Exchange)
+		}
+		rule, _, err := client.GetExchangeRule(p)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		place = func(price float64) (func() ([]byte, error), error) {
+			order := &Order{
+				Cid:       newCid(c.Exchange),
+				Price:     ToFloat64(FloatToString(price, int64(rule.CounterPrecision))),
+				Amount:    math.Max(c.Amount, rule.BaseMinSize),
+				Pair:      p,
+				Side:      BUY,
+				OrderType: ONLY_MAKER,
+			}
+			if _, err := spotClients[c.Exchange].PlaceOrder(order); err != nil {
+				return nil, err
+			}
+			return func() ([]byte, error) { return spotClients[c.Exchange].CancelOrder(order) }, nil
+		}
+	case "swap":
+		contract := swapClients[c.Exchange].GetContract(p)
+		if contract == nil {
+			return 0, 0, 0, fmt.Errorf("Can not find the contract of %s in %s. ", c.Pair, c.Exchange)
+		}
+		place = func(price float64) (func() ([]byte, error), error) {
+			order := &SwapOrder{
+				Cid:       newCid(c.Exchange),
+				Price:     ToFloat64(FloatToPrice(price, contract.PricePrecision, contract.TickSize)),
+				Amount:    math.Max(c.Amount, math.Pow10(-int(contract.AmountPrecision))),
+				PlaceType: ONLY_MAKER,
+				Type:      OPEN_LONG,
+				LeverRate: 1,
+				Pair:      p,
+				Exchange:  c.Exchange,
+			}
+			if _, err := swapClients[c.Exchange].PlaceOrder(order); err != nil {
+				return nil, err
+			}
+			return func() ([]byte, error) { return swapClients[c.Exchange].CancelOrder(order) }, nil
+		}
+	default:
+		return 0, 0, 0, fmt.Errorf("The command not support %s in %s. ", c.Type, c.Exchange)
+	}
+
+	ticker, _, tickerDelay, err := c.getTicker()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	observedTS := time.Now().UnixNano()
+
+	// the price is far from the market, the order will never be dealt.
+	cancel, err := place(ticker.Buy * T2O_PRICE_RATIO)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	ackTS := time.Now().UnixNano()
+
+	if _, err := cancel(); err != nil {
+		return 0, 0, 0, fmt.Errorf("The order is placed but not canceled, please cancel it by hand. %s ", err)
+	}
+	cancelTS := time.Now().UnixNano()
+	return tickerDelay, ackTS - observedTS, cancelTS - ackTS, nil
+}
+
+// apiConfig the config of the exchange in the profiles, the flags overwrite the profile.
+// The account flag is only for the exchange of the command.
+func (c *Command) apiConfig(profiles *Profiles, exchange, endpoint string) *APIConfig {
+	account := ""
+	if exchange == c.Exchange {
+		account = c.Account
+	}
+	profile, err := profiles.Profile(exchange, account)
+	if err != nil {
+		fmt.Println(err)
+		profile = &ProfileConfig{Timeout: DEFAULT_CONFIG_TIMEOUT}
+	}
+	if account != "" && profile.ApiKey == "" {
+		fmt.Printf("The account %s of %s is not found in the profiles. \n", account, exchange)
+	}
+	if c.APIKey != "" {
+		profile.ApiKey = c.APIKey
+		profile.ApiSecretKey = c.APISecret
+		profile.ApiPassphrase = c.APIPassphrase
+	}
+	if c.Proxy != "" {
+		profile.Proxy = c.Proxy
+	}
+
+	config, err := profile.APIConfig(endpoint)
+	if err != nil {
+		fmt.Println(err)
+		return &APIConfig{
+			Endpoint:   endpoint,
+			HttpClient: getHttpClient(c.Proxy),
+			Location:   time.Now().Location(),
+		}
+	}
+	return config
+}
+
+func (c *Command) initClients() {
+	profiles, err := LoadProfiles(c.Config)
+	if err != nil {
+		fmt.Println(err)
+		profiles = &Profiles{}
+	}
+
+	okexClient = okex.New(c.apiConfig(profiles, OKEX, okex.ENDPOINT))
+	coinbaseClient = coinbase.New(c.apiConfig(profiles, COINBASE, coinbase.ENDPOINT))
+	binanceClient = binance.New(c.apiConfig(profiles, BINANCE, binance.ENDPOINT))
+	krakenClient = kraken.New(c.apiConfig(profiles, KRAKEN, kraken.ENDPOINT))
+	gateClient = gate.New(c.apiConfig(profiles, GATE, gate.ENDPOINT))
 
 	if c.Type == "spot" {
 		spotClients[OKEX] = okexClient.Spot
 		spotClients[COINBASE] = coinbaseClient.Spot
 		spotClients[BINANCE] = binanceClient.Spot
+		spotClients[KRAKEN] = krakenClient.Spot
+		spotClients[GATE] = gateClient.Spot
 	} else if c.Type == "future" {
 		futureClients[OKEX] = okexClient.Future
+		futureClients[BINANCE] = binanceClient.Future
 	} else if c.Type == "swap" {
 		swapClients[OKEX] = okexClient.Swap
 		swapClients[BINANCE] = binanceClient.Swap
+		swapClients[KRAKEN] = krakenClient.Swap
+		swapClients[GATE] = gateClient.Swap
 	} else {
 		fmt.Printf("The command not support %s in %s. \n", c.Type, c.Exchange)
 	}
@@ -9,12 +9,19 @@ var cliPair = flag.String("pair", "btc_usd", "Input the pair. ")
 var cliType = flag.String("type", "spot", "Input the type. ")
 
 var sCommand = map[string]string{
-	"ticker":    "exchange ticker api",
-	"co-ticker": "co-location info of exchange ticker api",
-	"depth":     "exchange depth api",
-	"co-depth":  "co-location info of exchange depth api",
-	"info":      "the exchange rule. ",
-	"t2o":       "ticker to order time stat",
+	"ticker":     "exchange ticker api",
+	"co-ticker":  "co-location info of exchange ticker api",
+	"depth":      "exchange depth api",
+	"co-depth":   "co-location info of exchange depth api",
+	"info":       "the exchange rule. ",
+	"t2o":        "ticker to order time stat",
+	"account":    "the account balance",
+	"positions":  "the swap positions of the pair",
+	"orders":     "the unfinished orders of the pair",
+	"place":      "place the order, -confirm is required",
+	"cancel":     "cancel the order, -confirm is required",
+	"cancel-all": "cancel all the unfinished orders of the pair, -confirm is required",
+	"flow":       "the account flow of the pair",
 }
 
 func main() {
@@ -0,0 +1,552 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+var tradeSides = map[string]TradeSide{
+	"buy":         BUY,
+	"sell":        SELL,
+	"buy_market":  BUY_MARKET,
+	"sell_market": SELL_MARKET,
+}
+
+var futureTypes = map[string]FutureType{
+	"open_long":       OPEN_LONG,
+	"open_short":      OPEN_SHORT,
+	"liquidate_long":  LIQUIDATE_LONG,
+	"liquidate_short": LIQUIDATE_SHORT,
+}
+
+var placeTypes = map[string]PlaceType{
+	"normal":     NORMAL,
+	"only_maker": ONLY_MAKER,
+	"fok":        FOK,
+	"ioc":        IOC,
+	"market":     MARKET,
+}
+
+// the orders are cancelable in these status.
+var cancelableStatus = map[TradeStatus]bool{
+	ORDER_UNFINISH:    true,
+	ORDER_PART_FINISH: true,
+}
+
+// output print the v in json, or print the rows in table.
+func (c *Command) output(v interface{}, header []string, rows [][]string) {
+	if c.Output == "json" {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	_ = w.Flush()
+}
+
+// confirmed the order-mutating command must run with the -confirm flag.
+func (c *Command) confirmed(action string) bool {
+	if c.Confirm {
+		return true
+	}
+	fmt.Printf(
+		"The %s of %s %s @%s will change the orders, please run it again with -confirm. \n",
+		action, c.Pair, c.Type, c.Exchange,
+	)
+	return false
+}
+
+func (c *Command) unsupported() {
+	fmt.Printf("The command not support %s in %s. \n", c.Type, c.Exchange)
+}
+
+func (c *Command) spotClient() (SpotRestAPI, bool) {
+	client, exist := spotClients[c.Exchange]
+	if !exist {
+		c.unsupported()
+	}
+	return client, exist
+}
+
+func (c *Command) swapClient() (SwapRestAPI, bool) {
+	client, exist := swapClients[c.Exchange]
+	if !exist {
+		c.unsupported()
+	}
+	return client, exist
+}
+
+func (c *Command) futureClient() (FutureRestAPI, bool) {
+	client, exist := futureClients[c.Exchange]
+	if !exist {
+		c.unsupported()
+	}
+	return client, exist
+}
+
+func placeTypeString(placeType PlaceType) string {
+	for name, t := range placeTypes {
+		if t == placeType {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+func floatText(v float64) string {
+	return FloatToString(v, 8)
+}
+
+func (c *Command) account() {
+	switch c.Type {
+	case "spot":
+		client, exist := c.spotClient()
+		if !exist {
+			return
+		}
+		account, _, err := client.GetAccount()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		rows := make([][]string, 0, len(account.SubAccounts))
+		for _, sub := range account.SubAccounts {
+			if sub.Amount == 0 && sub.AmountFrozen == 0 {
+				continue
+			}
+			rows = append(rows, []string{sub.Currency.Symbol, floatText(sub.Amount), floatText(sub.AmountFrozen)})
+		}
+		c.output(account, []string{"CURRENCY", "AMOUNT", "FROZEN"}, rows)
+	case "swap":
+		client, exist := c.swapClient()
+		if !exist {
+			return
+		}
+		account, _, err := client.GetAccount()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		c.output(
+			account,
+			[]string{"CURRENCY", "BALANCE_TOTAL", "BALANCE_NET", "BALANCE_AVAIL", "MARGIN", "PROFIT_REAL", "PROFIT_UNREAL"},
+			[][]string{{
+				account.Currency.Symbol, floatText(account.BalanceTotal), floatText(account.BalanceNet), floatText(account.BalanceAvail),
+				floatText(account.Margin), floatText(account.ProfitReal), floatText(account.ProfitUnreal),
+			}},
+		)
+	case "future":
+		client, exist := c.futureClient()
+		if !exist {
+			return
+		}
+		account, _, err := client.GetAccount()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		rows := make([][]string, 0, len(account.SubAccount))
+		subs := make(map[string]FutureSubAccount, len(account.SubAccount))
+		for currency, sub := range account.SubAccount {
+			subs[currency.Symbol] = sub
+			rows = append(rows, []string{
+				currency.Symbol, floatText(sub.BalanceTotal), floatText(sub.BalanceNet), floatText(sub.BalanceAvail),
+				floatText(sub.Margin), floatText(sub.ProfitReal), floatText(sub.ProfitUnreal),
+			})
+		}
+		c.output(
+			subs,
+			[]string{"CURRENCY", "BALANCE_TOTAL", "BALANCE_NET", "BALANCE_AVAIL", "MARGIN", "PROFIT_REAL", "PROFIT_UNREAL"},
+			rows,
+		)
+	default:
+		c.unsupported()
+	}
+}
+
+// positions only the swap has the position api.
+func (c *Command) positions() {
+	if c.Type != "swap" {
+		c.unsupported()
+		return
+	}
+	client, exist := c.swapClient()
+	if !exist {
+		return
+	}
+
+	p := NewPair(c.Pair, "_")
+	positions := make([]*SwapPosition, 0, 2)
+	rows := make([][]string, 0, 2)
+	for _, openType := range []FutureType{OPEN_LONG, OPEN_SHORT} {
+		position, _, err := client.GetPosition(p, openType)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		positions = append(positions, position)
+		rows = append(rows, []string{
+			c.Pair, position.Type.String(), floatText(position.Amount), floatText(position.Price), floatText(position.MarkPrice),
+			floatText(position.LiquidatePrice), position.MarginType, floatText(position.MarginAmount), fmt.Sprint(position.Leverage),
+		})
+	}
+	c.output(
+		positions,
+		[]string{"PAIR", "TYPE", "AMOUNT", "PRICE", "MARK_PRICE", "LIQUIDATE_PRICE", "MARGIN_TYPE", "MARGIN", "LEVERAGE"},
+		rows,
+	)
+}
+
+func (c *Command) printSpotOrders(orders []*Order) {
+	rows := make([][]string, 0, len(orders))
+	for _, order := range orders {
+		rows = append(rows, []string{
+			order.OrderId, order.Cid, order.Pair.ToSymbol("_", false), order.Side.String(),
+			placeTypeString(order.OrderType), floatText(order.Price), floatText(order.Amount), floatText(order.DealAmount), order.Status.String(),
+		})
+	}
+	c.output(orders, []string{"ORDER_ID", "CID", "PAIR", "SIDE", "PLACE_TYPE", "PRICE", "AMOUNT", "DEAL", "STATUS"}, rows)
+}
+
+func (c *Command) printSwapOrders(orders []*SwapOrder) {
+	rows := make([][]string, 0, len(orders))
+	for _, order := range orders {
+		rows = append(rows, []string{
+			order.OrderId, order.Cid, order.Pair.ToSymbol("_", false), order.Type.String(),
+			placeTypeString(order.PlaceType), floatText(order.Price), floatText(order.Amount), floatText(order.DealAmount), order.Status.String(),
+		})
+	}
+	c.output(orders, []string{"ORDER_ID", "CID", "PAIR", "TYPE", "PLACE_TYPE", "PRICE", "AMOUNT", "DEAL", "STATUS"}, rows)
+}
+
+func (c *Command) printFutureOrders(orders []*FutureOrder) {
+	rows := make([][]string, 0, len(orders))
+	for _, order := range orders {
+		rows = append(rows, []string{
+			order.OrderId, order.Cid, order.ContractName, order.Type.String(), placeTypeString(order.PlaceType),
+			floatText(order.Price), fmt.Sprint(order.Amount), fmt.Sprint(order.DealAmount), order.Status.String(),
+		})
+	}
+	c.output(orders, []string{"ORDER_ID", "CID", "CONTRACT", "TYPE", "PLACE_TYPE", "PRICE", "AMOUNT", "DEAL", "STATUS"}, rows)
+}
+
+// unfinishedFutureOrders the FutureRestAPI has no unfinished orders api, filter them by the status.
+func (c *Command) unfinishedFutureOrders(client FutureRestAPI) ([]*FutureOrder, error) {
+	orders, _, err := client.GetOrders(NewPair(c.Pair, "_"), c.ContractType)
+	if err != nil {
+		return nil, err
+	}
+	unfinished := make([]*FutureOrder, 0, len(orders))
+	for _, order := range orders {
+		if cancelableStatus[order.Status] {
+			unfinished = append(unfinished, order)
+		}
+	}
+	return unfinished, nil
+}
+
+// orders the unfinished orders of the pair.
+func (c *Command) orders() {
+	p := NewPair(c.Pair, "_")
+	switch c.Type {
+	case "spot":
+		client, exist := c.spotClient()
+		if !exist {
+			return
+		}
+		orders, _, err := client.GetUnFinishOrders(p)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		c.printSpotOrders(orders)
+	case "swap":
+		client, exist := c.swapClient()
+		if !exist {
+			return
+		}
+		orders, _, err := client.GetUnFinishOrders(p)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		c.printSwapOrders(orders)
+	case "future":
+		client, exist := c.futureClient()
+		if !exist {
+			return
+		}
+		orders, err := c.unfinishedFutureOrders(client)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		c.printFutureOrders(orders)
+	default:
+		c.unsupported()
+	}
+}
+
+func (c *Command) place() {
+	placeType, exist := placeTypes[strings.ToLower(c.PlaceType)]
+	if !exist {
+		fmt.Printf("The place-type %s is not in normal/only_maker/fok/ioc/market. \n", c.PlaceType)
+		return
+	}
+	if c.Amount <= 0 || (c.Price <= 0 && placeType != MARKET) {
+		fmt.Println("The amount and the price must be positive. ")
+		return
+	}
+	if !c.confirmed("place") {
+		return
+	}
+
+	p := NewPair(c.Pair, "_")
+	switch c.Type {
+	case "spot":
+		client, exist := c.spotClient()
+		if !exist {
+			return
+		}
+		side, exist := tradeSides[strings.ToLower(c.Side)]
+		if !exist {
+			fmt.Printf("The side %s is not in buy/sell/buy_market/sell_market. \n", c.Side)
+			return
+		}
+		order := &Order{
+			Cid:       c.Cid,
+			Price:     c.Price,
+			Amount:    c.Amount,
+			Pair:      p,
+			Side:      side,
+			OrderType: placeType,
+		}
+		if _, err := client.PlaceOrder(order); err != nil {
+			fmt.Println(err)
+			return
+		}
+		c.printSpotOrders([]*Order{order})
+	case "swap":
+		client, exist := c.swapClient()
+		if !exist {
+			return
+		}
+		futureType, exist := futureTypes[strings.ToLower(c.Side)]
+		if !exist {
+			fmt.Printf("The side %s is not in open_long/open_short/liquidate_long/liquidate_short. \n", c.Side)
+			return
+		}
+		order := &SwapOrder{
+			Cid:        c.Cid,
+			Price:      c.Price,
+			Amount:     c.Amount,
+			PlaceType:  placeType,
+			Type:       futureType,
+			MarginType: CROSS,
+			LeverRate:  c.LeverRate,
+			Pair:       p,
+			Exchange:   c.Exchange,
+		}
+		if _, err := client.PlaceOrder(order); err != nil {
+			fmt.Println(err)
+			return
+		}
+		c.printSwapOrders([]*SwapOrder{order})
+	case "future":
+		client, exist := c.futureClient()
+		if !exist {
+			return
+		}
+		futureType, exist := futureTypes[strings.ToLower(c.Side)]
+		if !exist {
+			fmt.Printf("The side %s is not in open_long/open_short/liquidate_long/liquidate_short. \n", c.Side)
+			return
+		}
+		order := &FutureOrder{
+			Cid:          c.Cid,
+			Price:        c.Price,
+			Amount:       int64(c.Amount),
+			PlaceType:    placeType,
+			Type:         futureType,
+			LeverRate:    c.LeverRate,
+			Pair:         p,
+			ContractType: c.ContractType,
+			Exchange:     c.Exchange,
+		}
+		if _, err := client.PlaceOrder(order); err != nil {
+			fmt.Println(err)
+			return
+		}
+		c.printFutureOrders([]*FutureOrder{order})
+	default:
+		c.unsupported()
+	}
+}
+
+func (c *Command) cancel() {
+	if c.OrderId == "" && c.Cid == "" {
+		fmt.Println("The order-id or the cid is required. ")
+		return
+	}
+	if !c.confirmed("cancel") {
+		return
+	}
+
+	p := NewPair(c.Pair, "_")
+	var err error
+	switch c.Type {
+	case "spot":
+		client, exist := c.spotClient()
+		if !exist {
+			return
+		}
+		_, err = client.CancelOrder(&Order{OrderId: c.OrderId, Cid: c.Cid, Pair: p})
+	case "swap":
+		client, exist := c.swapClient()
+		if !exist {
+			return
+		}
+		_, err = client.CancelOrder(&SwapOrder{OrderId: c.OrderId, Cid: c.Cid, Pair: p, Exchange: c.Exchange})
+	case "future":
+		client, exist := c.futureClient()
+		if !exist {
+			return
+		}
+		_, err = client.CancelOrder(&FutureOrder{
+			OrderId: c.OrderId, Cid: c.Cid, Pair: p, ContractType: c.ContractType, Exchange: c.Exchange,
+		})
+	default:
+		c.unsupported()
+		return
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("The order %s%s of %s %s @%s is canceled. \n", c.OrderId, c.Cid, c.Pair, c.Type, c.Exchange)
+}
+
+// cancelAll cancel the unfinished orders of the pair one by one, the failed ones are printed.
+func (c *Command) cancelAll() {
+	if !c.confirmed("cancel-all") {
+		return
+	}
+
+	p := NewPair(c.Pair, "_")
+	var cancels = make(map[string]func() error)
+	switch c.Type {
+	case "spot":
+		client, exist := c.spotClient()
+		if !exist {
+			return
+		}
+		orders, _, err := client.GetUnFinishOrders(p)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, order := range orders {
+			order := order
+			cancels[order.OrderId] = func() error { _, err := client.CancelOrder(order); return err }
+		}
+	case "swap":
+		client, exist := c.swapClient()
+		if !exist {
+			return
+		}
+		orders, _, err := client.GetUnFinishOrders(p)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, order := range orders {
+			order := order
+			cancels[order.OrderId] = func() error { _, err := client.CancelOrder(order); return err }
+		}
+	case "future":
+		client, exist := c.futureClient()
+		if !exist {
+			return
+		}
+		orders, err := c.unfinishedFutureOrders(client)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, order := range orders {
+			order := order
+			cancels[order.OrderId] = func() error { _, err := client.CancelOrder(order); return err }
+		}
+	default:
+		c.unsupported()
+		return
+	}
+
+	rows := make([][]string, 0, len(cancels))
+	results := make(map[string]string, len(cancels))
+	for orderId, cancel := range cancels {
+		result := "canceled"
+		if err := cancel(); err != nil {
+			result = err.Error()
+		}
+		results[orderId] = result
+		rows = append(rows, []string{orderId, result})
+	}
+	c.output(results, []string{"ORDER_ID", "RESULT"}, rows)
+}
+
+func (c *Command) flow() {
+	p := NewPair(c.Pair, "_")
+	switch c.Type {
+	case "swap":
+		client, exist := c.swapClient()
+		if !exist {
+			return
+		}
+		items, _, err := client.GetPairFlow(p)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		rows := make([][]string, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, []string{
+				item.Id, item.Pair.ToSymbol("_", false), item.Subject, item.SettleCurrency.Symbol, floatText(item.Amount), item.DateTime,
+			})
+		}
+		c.output(items, []string{"ID", "PAIR", "SUBJECT", "CURRENCY", "AMOUNT", "DATETIME"}, rows)
+	case "future":
+		client, exist := c.futureClient()
+		if !exist {
+			return
+		}
+		items, _, err := client.GetPairFlow(p)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		rows := make([][]string, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, []string{
+				item.ContractName, item.Pair.ToSymbol("_", false), item.Subject, item.SettleCurrency.Symbol, floatText(item.Amount), item.DateTime,
+			})
+		}
+		c.output(items, []string{"CONTRACT", "PAIR", "SUBJECT", "CURRENCY", "AMOUNT", "DATETIME"}, rows)
+	default:
+		c.unsupported()
+	}
+}
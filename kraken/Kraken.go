@@ -32,8 +32,20 @@ var _INERNAL_KLINE_PERIOD_CONVERTER = map[int]string{
 	KLINE_PERIOD_1DAY:  "1440",
 }
 
+// the period not in the converter is resampled from the smaller one, eg: 2H 1WEEK.
+func newKlineFallback(location *time.Location, converter map[int]string) *KlineFallback {
+	return &KlineFallback{
+		Exchange: KRAKEN,
+		Location: location,
+		Supported: func(period int) bool {
+			var _, exist = converter[period]
+			return exist
+		},
+	}
+}
+
 func New(config *APIConfig) *Kraken {
-	var k = &Kraken{config: config}
+	var k = &Kraken{config: config, Limiter: newRateLimiter(config)}
 	k.Spot = &Spot{k}
 	k.Swap = &Swap{
 		Kraken:        k,
@@ -44,9 +56,10 @@ func New(config *APIConfig) *Kraken {
 }
 
 type Kraken struct {
-	config *APIConfig
-	Spot   *Spot
-	Swap   *Swap
+	config  *APIConfig
+	Spot    *Spot
+	Swap    *Swap
+	Limiter *RateLimiter
 	//Margin *Margin
 	//Future *Future
 }
@@ -56,7 +69,10 @@ func (k *Kraken) GetExchangeName() string {
 }
 
 func (k *Kraken) DoRequest(httpMethod, uri, reqBody string, response interface{}) ([]byte, error) {
-	resp, err := NewHttpRequest(
+	if err := k.Limiter.Acquire(httpMethod, uri, ""); err != nil {
+		return nil, err
+	}
+	resp, header, err := NewHttpRequestWithHeader(
 		k.config.HttpClient,
 		httpMethod,
 		k.config.Endpoint+uri,
@@ -65,9 +81,10 @@ func (k *Kraken) DoRequest(httpMethod, uri, reqBody string, response interface{}
 			"Content-Type": "application/x-www-form-urlencoded",
 		},
 	)
+	k.Limiter.Feedback(httpMethod, uri, "", header, err)
 
 	if err != nil {
-		return nil, err
+		return nil, krakenHttpError(err)
 	} else {
 		nowTimestamp := time.Now().Unix() * 1000
 		if k.config.LastTimestamp < nowTimestamp {
@@ -78,13 +95,17 @@ func (k *Kraken) DoRequest(httpMethod, uri, reqBody string, response interface{}
 }
 
 func (k *Kraken) DoSignRequest(httpMethod, uri string, data interface{}, response interface{}) ([]byte, error) {
+	var pair = krakenPair(data)
+	if err := k.Limiter.Acquire(httpMethod, uri, pair); err != nil {
+		return nil, err
+	}
 	var sign, signErr = k.GetKrakenSign(uri, data)
 	if signErr != nil {
 		return nil, signErr
 	}
 
 	var postData, _ = json.Marshal(data)
-	resp, err := NewHttpRequest(
+	resp, header, err := NewHttpRequestWithHeader(
 		k.config.HttpClient,
 		httpMethod,
 		k.config.Endpoint+uri,
@@ -96,9 +117,10 @@ func (k *Kraken) DoSignRequest(httpMethod, uri string, data interface{}, respons
 			"API-Sign":     sign,
 		},
 	)
+	k.Limiter.Feedback(httpMethod, uri, pair, header, err)
 
 	if err != nil {
-		return nil, err
+		return nil, krakenHttpError(err)
 	} else {
 		nowTimestamp := time.Now().Unix() * 1000
 		if k.config.LastTimestamp < nowTimestamp {
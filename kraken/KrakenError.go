@@ -0,0 +1,72 @@
+package kraken
+
+import (
+	"strings"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The error codes of kraken spot api, https://docs.kraken.com/api/docs/guides/spot-errors
+var _KRAKEN_ERROR_CODES = map[string]Error{
+	"EOrder:Insufficient funds":           ErrInsufficientBalance,
+	"EOrder:Insufficient margin":          ErrInsufficientBalance,
+	"EOrder:Unknown order":                ErrOrderNotFound,
+	"EOrder:Post only order":              ErrPostOnlyRejected,
+	"EOrder:Invalid price":                ErrInvalidPrice,
+	"EOrder:Rate limit exceeded":          ErrRateLimited,
+	"EAPI:Rate limit exceeded":            ErrRateLimited,
+	"EAPI:Invalid key":                    ErrAuthFailed,
+	"EAPI:Invalid signature":              ErrAuthFailed,
+	"EAPI:Invalid nonce":                  ErrAuthFailed,
+	"EGeneral:Permission denied":          ErrAuthFailed,
+	"EService:Unavailable":                ErrExchangeUnavailable,
+	"EService:Busy":                       ErrExchangeUnavailable,
+	"EService:Deadline elapsed":           ErrExchangeUnavailable,
+	"EService:Market in cancel_only mode": ErrExchangeUnavailable,
+
+	// the error or status of the futures api
+	"insufficientAvailableFunds": ErrInsufficientBalance,
+	"postWouldExecute":           ErrPostOnlyRejected,
+	"notFound":                   ErrOrderNotFound,
+	"invalidPrice":               ErrInvalidPrice,
+	"apiLimitExceeded":           ErrRateLimited,
+	"authenticationError":        ErrAuthFailed,
+	"nonceBelowThreshold":        ErrAuthFailed,
+	"nonceDuplicate":             ErrAuthFailed,
+}
+
+// krakenError the spot response has the error list, the first known one is the code.
+func krakenError(errs []string) error {
+	var code = ""
+	for _, e := range errs {
+		if _, exist := _KRAKEN_ERROR_CODES[e]; exist {
+			code = e
+			break
+		}
+	}
+	if code == "" && len(errs) > 0 {
+		code = errs[0]
+	}
+	return NewExchangeError(KRAKEN, _KRAKEN_ERROR_CODES, code, strings.Join(errs, ","), nil)
+}
+
+// krakenSwapError the status is the error or the order status of the futures response.
+func krakenSwapError(status string, resp []byte) error {
+	return NewExchangeError(KRAKEN, _KRAKEN_ERROR_CODES, status, string(resp), nil)
+}
+
+// krakenHttpError the non 2xx response is categorized by the http status.
+func krakenHttpError(err error) error {
+	if _, exist := HttpErrorResponse(err); !exist {
+		return err
+	}
+	return NewExchangeError(KRAKEN, _KRAKEN_ERROR_CODES, "", "", err)
+}
+
+// errorStatus the futures response has the error when the request failed, otherwise the status tell the reason.
+func errorStatus(err, status string) string {
+	if err != "" {
+		return err
+	}
+	return status
+}
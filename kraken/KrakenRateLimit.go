@@ -0,0 +1,52 @@
+package kraken
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The spot counter of kraken decay every second, the max and decay rate is of the starter tier.
+// The AddOrder and CancelOrder are not counted in the api counter, the order counter is per pair.
+// The futures cost 500 in every 10 seconds, the history endpoints have their own 100 tokens in every 10 minutes.
+// https://docs.kraken.com/api/docs/guides/spot-rest-ratelimits
+// https://docs.kraken.com/api/docs/guides/futures-rate-limits
+var _KRAKEN_RATE_RULES = map[string]*RateRule{
+	"public":  {Limit: 1, Decay: 1},
+	"private": {Limit: 15, Decay: 0.33},
+	"order":   {Limit: 60, Decay: 1},
+	"futures": {Limit: 500, Interval: 10 * time.Second},
+	"history": {Limit: 100, Decay: 0.16},
+}
+
+var _KRAKEN_RATE_COSTS = map[string][]*RateCost{
+	"/0/public/":               {{Rule: "public", Weight: 1}},
+	"/0/private/":              {{Rule: "private", Weight: 1}},
+	"/0/private/Ledgers":       {{Rule: "private", Weight: 2}},
+	"/0/private/QueryLedgers":  {{Rule: "private", Weight: 2}},
+	"/0/private/TradesHistory": {{Rule: "private", Weight: 2}},
+	"/0/private/AddOrder":      {{Rule: "order", Weight: 1, PerInstrument: true}},
+	"/0/private/CancelOrder":   {},
+	"/api/v3/":                 {{Rule: "futures", Weight: 1}},
+	"/api/v3/sendorder":        {{Rule: "futures", Weight: 10}},
+	"/api/v3/cancelorder":      {{Rule: "futures", Weight: 10}},
+	"/api/v3/fills":            {{Rule: "futures", Weight: 2}},
+	"/api/v3/openpositions":    {{Rule: "futures", Weight: 2}},
+	"/api/v3/openorders":       {{Rule: "futures", Weight: 2}},
+	"/api/v3/accounts":         {{Rule: "futures", Weight: 2}},
+	"/api/v3/cancelallorders":  {{Rule: "futures", Weight: 25}},
+	"/api/history/v3/":         {{Rule: "history", Weight: 3}},
+}
+
+func newRateLimiter(config *APIConfig) *RateLimiter {
+	return NewRateLimiter(KRAKEN, config.RateLimit, _KRAKEN_RATE_RULES, _KRAKEN_RATE_COSTS)
+}
+
+// krakenPair the pair of the private request, the order counter is per pair.
+func krakenPair(data interface{}) string {
+	if params, ok := data.(map[string]interface{}); ok && params["pair"] != nil {
+		return fmt.Sprint(params["pair"])
+	}
+	return ""
+}
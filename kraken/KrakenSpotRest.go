@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
 	. "github.com/deforceHK/goghostex"
@@ -27,25 +26,18 @@ type Spot struct {
 	*Kraken
 }
 
-func (s *Spot) GetTicker(pair Pair) (*Ticker, []byte, error) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (s *Spot) GetDepth(pair Pair, size int) (*Depth, []byte, error) {
-	//TODO implement me
-	panic("implement me")
-}
-
 func (s *Spot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline, []byte, error) {
+	if _, exist := _INERNAL_KLINE_PERIOD_CONVERTER[period]; !exist {
+		return newKlineFallback(s.config.Location, _INERNAL_KLINE_PERIOD_CONVERTER).Klines(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*Kline, []byte, error) {
+				return s.GetKlineRecords(pair, period, size, int(since))
+			},
+		)
+	}
 
 	var startTimeFmt = fmt.Sprintf("%d", since)
-	var pairStd = strings.ToUpper(pair.ToSymbol("", true))
-	if pairStd == "BTCUSD" {
-		pairStd = "XXBTZUSD"
-	} else if pairStd == "ETHUSD" {
-		pairStd = "XETHZUSD"
-	}
+	var pairStd = krakenSymbol(pair)
 
 	if len(startTimeFmt) > 13 {
 		startTimeFmt = startTimeFmt[0:13]
@@ -68,11 +60,11 @@ func (s *Spot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline, []
 	}
 
 	if len(result.Error) != 0 {
-		return nil, nil, errors.New(strings.Join(result.Error, ","))
+		return nil, nil, krakenError(result.Error)
 	}
 
 	var records = make([][]interface{}, 0)
-	err = json.Unmarshal(result.Result[pairStd], &records)
+	err = krakenResult(result.Result, &records)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -106,19 +98,9 @@ func (s *Spot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline, []
 	return GetAscKline(klineRecords), resp, nil
 }
 
-func (s *Spot) GetTrades(pair Pair, since int64) ([]*Trade, error) {
-	//TODO implement me
-	panic("implement me")
-}
-
 func (s *Spot) PlaceOrder(order *Order) ([]byte, error) {
 	// Convert pair to Kraken format
-	var pairStd = strings.ToUpper(order.Pair.ToSymbol("", true))
-	if pairStd == "BTCUSD" {
-		pairStd = "XXBTZUSD"
-	} else if pairStd == "ETHUSD" {
-		pairStd = "XETHZUSD"
-	}
+	var pairStd = krakenSymbol(order.Pair)
 
 	// Map order type
 	var orderType, exist = _INERNAL_ORDER_PLACE_TYPE_CONVERTER[order.OrderType]
@@ -164,7 +146,7 @@ func (s *Spot) PlaceOrder(order *Order) ([]byte, error) {
 	}
 
 	if len(result.Error) != 0 {
-		return resp, errors.New(strings.Join(result.Error, ","))
+		return resp, krakenError(result.Error)
 	}
 
 	if len(result.Result.Txid) == 0 {
@@ -200,7 +182,7 @@ func (s *Spot) CancelOrder(order *Order) ([]byte, error) {
 	}
 
 	if len(result.Error) > 0 {
-		return resp, errors.New(strings.Join(result.Error, ","))
+		return resp, krakenError(result.Error)
 	}
 
 	return resp, nil
@@ -227,7 +209,7 @@ func (s *Spot) GetOrder(order *Order) ([]byte, error) {
 	}
 
 	if len(result.Error) > 0 {
-		return resp, errors.New(strings.Join(result.Error, ","))
+		return resp, krakenError(result.Error)
 	}
 
 	if orderInfo, exist := result.Result[order.OrderId]; exist {
@@ -254,7 +236,7 @@ func (s *Spot) GetOrder(order *Order) ([]byte, error) {
 		return resp, nil
 	}
 
-	return resp, errors.New("order not found")
+	return resp, NewExchangeError(KRAKEN, _KRAKEN_ERROR_CODES, "EOrder:Unknown order", "order not found", nil)
 }
 
 // 假设这个方法需要实现，用于将 Kraken 的订单状态转换为系统内部状态
@@ -295,11 +277,9 @@ func (s *Spot) GetUnFinishOrders(pair Pair) ([]*Order, []byte, error) {
 }
 
 func (s *Spot) KeepAlive() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (s *Spot) GetOHLCs(symbol string, period, size, since int) ([]*OHLC, []byte, error) {
-	//TODO implement me
-	panic("implement me")
+	nowTimestamp := time.Now().Unix() * 1000
+	if (nowTimestamp - s.config.LastTimestamp) < 5*1000 {
+		return
+	}
+	_, _, _ = s.GetTicker(Pair{Basis: BTC, Counter: USD})
 }
@@ -2,21 +2,58 @@ package kraken
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	. "github.com/deforceHK/goghostex"
 )
 
+type balanceEx struct {
+	Balance   float64 `json:"balance,string"`
+	HoldTrade float64 `json:"hold_trade,string"`
+}
+
+// GetAccount the asset of kraken is normalized, eg: XXBT -> BTC, the auto earn balance (.F) is merged.
 func (s *Spot) GetAccount() (*Account, []byte, error) {
 	var nowTS = fmt.Sprintf("%d", time.Now().UnixNano())
 	var data = map[string]interface{}{
 		"nonce": nowTS,
 	}
 
-	resp, err := s.DoSignRequest("POST", "/0/private/Balance", data, nil)
+	var result = struct {
+		Error  []string             `json:"error"`
+		Result map[string]balanceEx `json:"result"`
+	}{}
+	resp, err := s.DoSignRequest("POST", API_PRIVATE+"/BalanceEx", data, &result)
 	if err != nil {
-		return nil, nil, err
-	} else {
-		return nil, resp, nil
+		return nil, resp, err
+	}
+	if len(result.Error) != 0 {
+		return nil, resp, krakenError(result.Error)
+	}
+	return balanceAccount(result.Result), resp, nil
+}
+
+// balanceAccount the hold trade is the amount frozen by the open orders.
+func balanceAccount(balances map[string]balanceEx) *Account {
+	var account = &Account{
+		Exchange:    KRAKEN,
+		SubAccounts: make(map[string]SubAccount),
+	}
+	for asset, balance := range balances {
+		// the staking and the other earn balances are not tradable.
+		if i := strings.Index(asset, "."); i >= 0 {
+			if asset[i:] != ".F" {
+				continue
+			}
+			asset = asset[:i]
+		}
+		var currency = krakenCurrency(asset)
+		var sub = account.SubAccounts[currency.Symbol]
+		sub.Currency = currency
+		sub.Amount += balance.Balance - balance.HoldTrade
+		sub.AmountFrozen += balance.HoldTrade
+		account.SubAccounts[currency.Symbol] = sub
 	}
+	return account
 }
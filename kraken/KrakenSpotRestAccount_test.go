@@ -1,6 +1,7 @@
 package kraken
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 	"time"
@@ -38,3 +39,28 @@ func TestSpot_GetAccount(t *testing.T) {
 		t.Logf(string(resp))
 	}
 }
+
+// go test -v ./kraken/... -count=1 -run=TestBalanceAccount
+func TestBalanceAccount(t *testing.T) {
+	var data = `{
+		"XXBT": {"balance": "1.5", "hold_trade": "0.5"},
+		"XBT.F": {"balance": "0.25", "hold_trade": "0"},
+		"ZUSD": {"balance": "1000", "hold_trade": "100"},
+		"DOT.S": {"balance": "10", "hold_trade": "0"}
+	}`
+	var balances = make(map[string]balanceEx)
+	if err := json.Unmarshal([]byte(data), &balances); err != nil {
+		t.Fatal(err)
+	}
+
+	var account = balanceAccount(balances)
+	if len(account.SubAccounts) != 2 {
+		t.Fatal("The staking balance should be skipped. ", account.SubAccounts)
+	}
+	if btc := account.SubAccounts["BTC"]; btc.Amount != 1.25 || btc.AmountFrozen != 0.5 {
+		t.Error("The btc balance is wrong. ", btc)
+	}
+	if usd := account.SubAccounts["USD"]; usd.Amount != 900 || usd.AmountFrozen != 100 {
+		t.Error("The usd balance is wrong. ", usd)
+	}
+}
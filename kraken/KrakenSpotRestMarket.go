@@ -0,0 +1,247 @@
+package kraken
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// the currency in the pair name of kraken, the others are same with the standard.
+var _INERNAL_CURRENCY_CONVERTER = map[string]string{
+	"BTC":  "XBT",
+	"DOGE": "XDG",
+}
+
+// the legacy asset code of kraken with the X or Z prefix, the balances and the result keys use them.
+var _INERNAL_CURRENCY_REVERTER = map[string]string{
+	"XBT":  "BTC",
+	"XXBT": "BTC",
+	"XDG":  "DOGE",
+	"XXDG": "DOGE",
+	"XETH": "ETH",
+	"XETC": "ETC",
+	"XLTC": "LTC",
+	"XMLN": "MLN",
+	"XREP": "REP",
+	"XXLM": "XLM",
+	"XXMR": "XMR",
+	"XXRP": "XRP",
+	"XZEC": "ZEC",
+	"ZAUD": "AUD",
+	"ZCAD": "CAD",
+	"ZEUR": "EUR",
+	"ZGBP": "GBP",
+	"ZJPY": "JPY",
+	"ZUSD": "USD",
+}
+
+// krakenSymbol the pair name in the request, eg: XBTUSD, kraken accept the altname in all the spot api.
+func krakenSymbol(pair Pair) string {
+	var basis, counter = strings.ToUpper(pair.Basis.Symbol), strings.ToUpper(pair.Counter.Symbol)
+	if symbol, exist := _INERNAL_CURRENCY_CONVERTER[basis]; exist {
+		basis = symbol
+	}
+	if symbol, exist := _INERNAL_CURRENCY_CONVERTER[counter]; exist {
+		counter = symbol
+	}
+	return basis + counter
+}
+
+// krakenCurrency the standard currency of the kraken asset, eg: XXBT -> BTC, ZUSD -> USD
+func krakenCurrency(asset string) Currency {
+	var symbol = strings.ToUpper(asset)
+	if standard, exist := _INERNAL_CURRENCY_REVERTER[symbol]; exist {
+		symbol = standard
+	}
+	return NewCurrency(symbol, "")
+}
+
+// krakenResult the result of the pair, the result key is the pair name of kraken, eg: XXBTZUSD, not the request one.
+func krakenResult(result map[string]json.RawMessage, response interface{}) error {
+	for key, raw := range result {
+		if key == "last" {
+			continue
+		}
+		return json.Unmarshal(raw, response)
+	}
+	return errors.New("The result of the pair is empty. ")
+}
+
+func (s *Spot) GetTicker(pair Pair) (*Ticker, []byte, error) {
+	var params = url.Values{}
+	params.Set("pair", krakenSymbol(pair))
+
+	var result = struct {
+		Error  []string                   `json:"error"`
+		Result map[string]json.RawMessage `json:"result"`
+	}{}
+	resp, err := s.DoRequest(http.MethodGet, API_V1+"Ticker?"+params.Encode(), "", &result)
+	if err != nil {
+		return nil, resp, err
+	}
+	if len(result.Error) != 0 {
+		return nil, resp, krakenError(result.Error)
+	}
+
+	// the a b c are [price, lot volume], the v h l are [today, last 24 hours].
+	var ticker = struct {
+		A []string `json:"a"`
+		B []string `json:"b"`
+		C []string `json:"c"`
+		V []string `json:"v"`
+		L []string `json:"l"`
+		H []string `json:"h"`
+	}{}
+	if err = krakenResult(result.Result, &ticker); err != nil {
+		return nil, resp, err
+	}
+	if len(ticker.A) == 0 || len(ticker.B) == 0 || len(ticker.C) == 0 ||
+		len(ticker.V) < 2 || len(ticker.L) < 2 || len(ticker.H) < 2 {
+		return nil, resp, errors.New(string(resp))
+	}
+
+	var now = time.Now()
+	return &Ticker{
+		Pair:      pair,
+		Last:      ToFloat64(ticker.C[0]),
+		Buy:       ToFloat64(ticker.B[0]),
+		Sell:      ToFloat64(ticker.A[0]),
+		High:      ToFloat64(ticker.H[1]),
+		Low:       ToFloat64(ticker.L[1]),
+		Vol:       ToFloat64(ticker.V[1]),
+		Timestamp: now.UnixNano() / int64(time.Millisecond),
+		Date:      now.In(s.config.Location).Format(GO_BIRTHDAY),
+	}, resp, nil
+}
+
+func (s *Spot) GetDepth(pair Pair, size int) (*Depth, []byte, error) {
+	var params = url.Values{}
+	params.Set("pair", krakenSymbol(pair))
+	params.Set("count", fmt.Sprintf("%d", size))
+
+	var result = struct {
+		Error  []string                   `json:"error"`
+		Result map[string]json.RawMessage `json:"result"`
+	}{}
+	resp, err := s.DoRequest(http.MethodGet, API_V1+"Depth?"+params.Encode(), "", &result)
+	if err != nil {
+		return nil, resp, err
+	}
+	if len(result.Error) != 0 {
+		return nil, resp, krakenError(result.Error)
+	}
+
+	// [price, volume, timestamp]
+	var book = struct {
+		Asks [][]interface{} `json:"asks"`
+		Bids [][]interface{} `json:"bids"`
+	}{}
+	if err = krakenResult(result.Result, &book); err != nil {
+		return nil, resp, err
+	}
+
+	var depth = &Depth{Pair: pair}
+	var lastTimestamp int64 = 0
+	for _, list := range []*struct {
+		records *DepthRecords
+		items   [][]interface{}
+	}{{&depth.AskList, book.Asks}, {&depth.BidList, book.Bids}} {
+		for _, item := range list.items {
+			if len(item) < 3 {
+				continue
+			}
+			*list.records = append(*list.records, DepthRecord{Price: ToFloat64(item[0]), Amount: ToFloat64(item[1])})
+			if ts := ToInt64(item[2]); ts > lastTimestamp {
+				lastTimestamp = ts
+			}
+		}
+	}
+
+	var now = time.Now()
+	depth.Timestamp = now.UnixNano() / int64(time.Millisecond)
+	depth.Date = now.In(s.config.Location).Format(GO_BIRTHDAY)
+	depth.Sequence = depth.Timestamp
+	if lastTimestamp > 0 {
+		depth.Sequence = lastTimestamp * 1000
+	}
+	return depth, resp, nil
+}
+
+// GetTrades the since is the timestamp in millisecond, the recent 1000 trades are returned when it's 0.
+func (s *Spot) GetTrades(pair Pair, since int64) ([]*Trade, error) {
+	var params = url.Values{}
+	params.Set("pair", krakenSymbol(pair))
+	if since > 0 {
+		params.Set("since", fmt.Sprintf("%d", since/1000))
+	}
+
+	var result = struct {
+		Error  []string                   `json:"error"`
+		Result map[string]json.RawMessage `json:"result"`
+	}{}
+	resp, err := s.DoRequest(http.MethodGet, API_V1+"Trades?"+params.Encode(), "", &result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Error) != 0 {
+		return nil, krakenError(result.Error)
+	}
+
+	// [price, volume, time, buy/sell, market/limit, miscellaneous, trade_id]
+	var records = make([][]interface{}, 0)
+	if err = krakenResult(result.Result, &records); err != nil {
+		return nil, errors.New(string(resp))
+	}
+
+	var trades = make([]*Trade, 0, len(records))
+	for _, record := range records {
+		if len(record) < 4 {
+			continue
+		}
+		var side = BUY
+		if record[3] == "s" {
+			side = SELL
+		}
+		var trade = &Trade{
+			Type:      side,
+			Amount:    ToFloat64(record[1]),
+			Price:     ToFloat64(record[0]),
+			Timestamp: int64(ToFloat64(record[2]) * 1000),
+			Pair:      pair,
+		}
+		if len(record) > 6 {
+			trade.Tid = ToInt64(record[6])
+		}
+		trades = append(trades, trade)
+	}
+	return trades, nil
+}
+
+// GetOHLCs the symbol is the pair string, eg: btc_usd
+func (s *Spot) GetOHLCs(symbol string, period, size, since int) ([]*OHLC, []byte, error) {
+	var klines, resp, err = s.GetKlineRecords(NewPair(symbol, "_"), period, size, since)
+	if err != nil {
+		return nil, resp, err
+	}
+	var ohlcs = make([]*OHLC, 0, len(klines))
+	for _, k := range klines {
+		ohlcs = append(ohlcs, &OHLC{
+			Symbol:    symbol,
+			Exchange:  KRAKEN,
+			Timestamp: k.Timestamp,
+			Date:      k.Date,
+			Open:      k.Open,
+			Close:     k.Close,
+			High:      k.High,
+			Low:       k.Low,
+			Vol:       k.Vol,
+		})
+	}
+	return ohlcs, resp, nil
+}
@@ -0,0 +1,91 @@
+package kraken
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+var _ SpotRestAPI = (*Spot)(nil)
+
+// go test -v ./kraken/... -count=1 -run=TestKrakenSymbol
+func TestKrakenSymbol(t *testing.T) {
+	if symbol := krakenSymbol(Pair{Basis: BTC, Counter: USD}); symbol != "XBTUSD" {
+		t.Error("The symbol of btc_usd is wrong. ", symbol)
+	}
+	if symbol := krakenSymbol(NewPair("doge_usdt", "_")); symbol != "XDGUSDT" {
+		t.Error("The symbol of doge_usdt is wrong. ", symbol)
+	}
+	for asset, symbol := range map[string]string{"XXBT": "BTC", "XBT": "BTC", "ZUSD": "USD", "XETH": "ETH", "DOT": "DOT"} {
+		if currency := krakenCurrency(asset); currency.Symbol != symbol {
+			t.Error("The currency of the asset is wrong. ", asset, currency.Symbol)
+		}
+	}
+}
+
+// go test -v ./kraken/... -count=1 -run=TestSpot_PublicMarket
+func TestSpot_PublicMarket(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("pair") != "XBTUSD" {
+			_, _ = w.Write([]byte(`{"error":["EQuery:Unknown asset pair"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case API_V1 + "Ticker":
+			_, _ = w.Write([]byte(`{"error":[],"result":{"XXBTZUSD":{"a":["30001.0","1","1.000"],
+				"b":["30000.0","2","2.000"],"c":["30000.5","0.1"],"v":["100.1","2000.2"],
+				"l":["29000.0","28000.0"],"h":["31000.0","32000.0"],"o":"29500.0"}}}`))
+		case API_V1 + "Depth":
+			_, _ = w.Write([]byte(`{"error":[],"result":{"XXBTZUSD":{
+				"asks":[["30001.0","1.5",1688671834],["30002.0","2.5",1688671835]],
+				"bids":[["30000.0","0.5",1688671830],["29999.0","3.0",1688671836]]}}}`))
+		case API_V1 + "Trades":
+			_, _ = w.Write([]byte(`{"error":[],"result":{"XXBTZUSD":[
+				["30000.1","0.01",1688671834.1234,"b","l","",65001],
+				["30000.0","0.02",1688671835.5,"s","m","",65002]],"last":"1688671835500000000"}}`))
+		}
+	}))
+	defer server.Close()
+
+	var k = New(&APIConfig{
+		Endpoint:   server.URL,
+		HttpClient: server.Client(),
+		Location:   time.UTC,
+		RateLimit:  RATE_LIMIT_OFF,
+	})
+	var pair = Pair{Basis: BTC, Counter: USD}
+
+	var ticker, _, err = k.Spot.GetTicker(pair)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticker.Last != 30000.5 || ticker.Buy != 30000 || ticker.Sell != 30001 || ticker.High != 32000 ||
+		ticker.Low != 28000 || ticker.Vol != 2000.2 {
+		t.Error("The ticker is wrong. ", ticker)
+	}
+
+	var depth *Depth
+	if depth, _, err = k.Spot.GetDepth(pair, 2); err != nil {
+		t.Fatal(err)
+	}
+	if len(depth.AskList) != 2 || depth.AskList[0].Price != 30001 || depth.BidList[1].Amount != 3 ||
+		depth.Sequence != 1688671836000 {
+		t.Error("The depth is wrong. ", depth)
+	}
+
+	var trades []*Trade
+	if trades, err = k.Spot.GetTrades(pair, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 2 || trades[0].Tid != 65001 || trades[0].Type != BUY || trades[1].Type != SELL ||
+		trades[0].Timestamp != 1688671834123 || trades[1].Amount != 0.02 {
+		t.Error("The trades are wrong. ", trades)
+	}
+
+	if _, _, err = k.Spot.GetTicker(Pair{Basis: ETH, Counter: USD}); err == nil {
+		t.Error("The unknown pair should be failed. ")
+	}
+}
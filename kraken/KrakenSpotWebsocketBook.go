@@ -3,7 +3,10 @@ package kraken
 import (
 	"encoding/json"
 	"fmt"
+	"hash/crc32"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,6 +23,28 @@ type SpotOrderBooks struct {
 
 	// if the channel is not nil, send the update message to the channel. User should read the channel in the loop.
 	UpdateChan chan string
+	// ResubscribeHandler rebuild the book of the symbol when the checksum mismatch found, the default is the Resubscribe.
+	ResubscribeHandler func(productId string)
+
+	// the price and qty precision of the symbol, from the instrument channel. They're necessary in checksum.
+	precisions   map[string][2]int
+	precisionMux sync.RWMutex
+	// the book is invalid after the checksum mismatch, until the next snapshot.
+	checksumFails map[string]int64
+	invalid       map[string]bool
+	checksumMux   sync.Mutex
+}
+
+type KKInstrument struct {
+	Channel string `json:"channel"`
+	Type    string `json:"type"`
+	Data    struct {
+		Pairs []struct {
+			Symbol         string `json:"symbol"`
+			PricePrecision int    `json:"price_precision"`
+			QtyPrecision   int    `json:"qty_precision"`
+		} `json:"pairs"`
+	} `json:"data"`
 }
 
 type KKBookSnapshot struct {
@@ -73,11 +98,33 @@ func (this *SpotOrderBooks) Init() error {
 	if this.TsData == nil {
 		this.TsData = make(map[string]int64)
 	}
+	if this.precisions == nil {
+		this.precisions = make(map[string][2]int)
+	}
+	if this.checksumFails == nil {
+		this.checksumFails = make(map[string]int64)
+	}
+	if this.invalid == nil {
+		this.invalid = make(map[string]bool)
+	}
 
 	this.WSSpotMarketKK.RecvHandler = func(s string) {
 		this.Receiver(s)
 	}
-	return this.Start()
+	if err := this.Start(); err != nil {
+		return err
+	}
+
+	// the precisions of the symbols are in the instrument channel.
+	var sub = struct {
+		Method string `json:"method"`
+		Params struct {
+			Channel string `json:"channel"`
+		} `json:"params"`
+	}{Method: "subscribe"}
+	sub.Params.Channel = "instrument"
+	this.WSSpotMarketKK.Subscribe(sub)
+	return nil
 }
 
 func (this *SpotOrderBooks) Subscribe(pair Pair) {
@@ -138,6 +185,16 @@ func (this *SpotOrderBooks) Receiver(msg string) {
 	}{}
 
 	_ = json.Unmarshal(rawData, &pre)
+	if pre.Channel == "instrument" {
+		var instrument = KKInstrument{}
+		_ = json.Unmarshal(rawData, &instrument)
+		this.precisionMux.Lock()
+		for _, pair := range instrument.Data.Pairs {
+			this.precisions[pair.Symbol] = [2]int{pair.PricePrecision, pair.QtyPrecision}
+		}
+		this.precisionMux.Unlock()
+		return
+	}
 	if pre.Channel != "book" {
 		fmt.Println("The feed must in book_snapshot book")
 		return
@@ -157,8 +214,9 @@ func (this *SpotOrderBooks) Receiver(msg string) {
 func (this *SpotOrderBooks) recvBook(book KKBookUpdate) {
 	for _, data := range book.Data {
 		var mux, exist = this.OrderBookMuxs[data.Symbol]
-		if !exist {
-			return
+		// the book is resubscribing, wait for the snapshot.
+		if !exist || this.isInvalid(data.Symbol) {
+			continue
 		}
 
 		mux.Lock()
@@ -180,8 +238,14 @@ func (this *SpotOrderBooks) recvBook(book KKBookUpdate) {
 		var updateTime, _ = time.ParseInLocation(time.RFC3339, data.Timestamp, this.Config.Location)
 		this.SeqData[data.Symbol] = data.Checksum
 		this.TsData[data.Symbol] = updateTime.UnixMilli()
+		var actual, verifiable = this.checksum(data.Symbol)
 		mux.Unlock()
 
+		if verifiable && actual != data.Checksum {
+			this.checksumFail(data.Symbol, data.Checksum, actual)
+			continue
+		}
+
 		if this.UpdateChan != nil {
 			this.UpdateChan <- fmt.Sprintf("%s:%d", data.Symbol, updateTime.UnixMilli())
 		}
@@ -215,16 +279,111 @@ func (this *SpotOrderBooks) recvSnapshot(snapshot KKBookSnapshot) {
 		this.AskData[data.Symbol] = askData
 		this.SeqData[data.Symbol] = data.Checksum
 		this.TsData[data.Symbol] = 0
+		var actual, verifiable = this.checksum(data.Symbol)
+		this.setInvalid(data.Symbol, false)
 		mux.Unlock()
+
+		if verifiable && actual != data.Checksum {
+			this.checksumFail(data.Symbol, data.Checksum, actual)
+			continue
+		}
 	}
 }
 
+// The checksum of kraken: the top 10 asks ascending and the top 10 bids descending,
+// price and qty are formatted in the symbol precision without the point and the leading zeros,
+// then crc32 in unsigned int32. It's not verifiable until the precision received.
+// Must be called with the symbol mux locked.
+func (this *SpotOrderBooks) checksum(symbol string) (int64, bool) {
+	this.precisionMux.RLock()
+	var precision, exist = this.precisions[symbol]
+	this.precisionMux.RUnlock()
+	if !exist {
+		return 0, false
+	}
+
+	var askPrices = make([]int64, 0, len(this.AskData[symbol]))
+	for stdPrice, amount := range this.AskData[symbol] {
+		if amount > 0 {
+			askPrices = append(askPrices, stdPrice)
+		}
+	}
+	var bidPrices = make([]int64, 0, len(this.BidData[symbol]))
+	for stdPrice, amount := range this.BidData[symbol] {
+		if amount > 0 {
+			bidPrices = append(bidPrices, stdPrice)
+		}
+	}
+	sort.Slice(askPrices, func(i, j int) bool { return askPrices[i] < askPrices[j] })
+	sort.Slice(bidPrices, func(i, j int) bool { return bidPrices[i] > bidPrices[j] })
+
+	var format = func(v float64, n int) string {
+		var text = strconv.FormatFloat(v, 'f', n, 64)
+		return strings.TrimLeft(strings.Replace(text, ".", "", 1), "0")
+	}
+
+	var builder strings.Builder
+	for i := 0; i < 10 && i < len(askPrices); i++ {
+		builder.WriteString(format(float64(askPrices[i])/100000000, precision[0]))
+		builder.WriteString(format(this.AskData[symbol][askPrices[i]], precision[1]))
+	}
+	for i := 0; i < 10 && i < len(bidPrices); i++ {
+		builder.WriteString(format(float64(bidPrices[i])/100000000, precision[0]))
+		builder.WriteString(format(this.BidData[symbol][bidPrices[i]], precision[1]))
+	}
+	return int64(crc32.ChecksumIEEE([]byte(builder.String()))), true
+}
+
+// checksumFail the book is invalid before the resubscribe, the Snapshot of it is an error until the next snapshot.
+func (this *SpotOrderBooks) checksumFail(symbol string, expect, actual int64) {
+	this.checksumMux.Lock()
+	this.checksumFails[symbol]++
+	this.invalid[symbol] = true
+	var failCount = this.checksumFails[symbol]
+	this.checksumMux.Unlock()
+
+	this.ErrorHandler(&BookChecksumError{
+		Exchange:  KRAKEN,
+		ProductId: symbol,
+		Expect:    expect,
+		Actual:    actual,
+		FailCount: failCount,
+	})
+	if this.ResubscribeHandler != nil {
+		this.ResubscribeHandler(symbol)
+		return
+	}
+	this.Resubscribe(symbol)
+}
+
+func (this *SpotOrderBooks) setInvalid(symbol string, invalid bool) {
+	this.checksumMux.Lock()
+	defer this.checksumMux.Unlock()
+	this.invalid[symbol] = invalid
+}
+
+func (this *SpotOrderBooks) isInvalid(symbol string) bool {
+	this.checksumMux.Lock()
+	defer this.checksumMux.Unlock()
+	return this.invalid[symbol]
+}
+
+// ChecksumFails return the checksum fail times of the symbol, eg: BTC/USD
+func (this *SpotOrderBooks) ChecksumFails(symbol string) int64 {
+	this.checksumMux.Lock()
+	defer this.checksumMux.Unlock()
+	return this.checksumFails[symbol]
+}
+
 func (this *SpotOrderBooks) Snapshot(pair Pair) (*Depth, error) {
 	var productId = pair.ToSymbol("/", true)
 
 	if this.BidData[productId] == nil || this.AskData[productId] == nil || this.OrderBookMuxs[productId] == nil {
 		return nil, fmt.Errorf("The order book data is not ready or you need subscribe the productid. ")
 	}
+	if this.isInvalid(productId) {
+		return nil, fmt.Errorf("The order book of %s is invalid after the checksum mismatch, it's resubscribing. ", productId)
+	}
 
 	var mux = this.OrderBookMuxs[productId]
 	mux.Lock()
@@ -0,0 +1,57 @@
+package kraken
+
+import (
+	. "github.com/deforceHK/goghostex"
+)
+
+// SpotBookFeed the SpotOrderBooks as the OrderBookFeed, the UpdateChan of the books is read by the feed.
+type SpotBookFeed struct {
+	*SpotOrderBooks
+	relay *BookUpdateRelay
+}
+
+func NewSpotBookFeed(books *SpotOrderBooks) *SpotBookFeed {
+	return &SpotBookFeed{
+		SpotOrderBooks: books,
+		relay:          NewBookUpdateRelay(KRAKEN, TRADE_TYPE_SPOT),
+	}
+}
+
+func (this *SpotBookFeed) GetExchangeName() string {
+	return KRAKEN
+}
+
+func (this *SpotBookFeed) GetMarket() string {
+	return TRADE_TYPE_SPOT
+}
+
+func (this *SpotBookFeed) Start() error {
+	if this.UpdateChan == nil {
+		this.UpdateChan = make(chan string, 1024)
+	}
+	this.relay.Start(this.UpdateChan)
+	return this.SpotOrderBooks.Init()
+}
+
+func (this *SpotBookFeed) Stop() {
+	this.relay.Stop()
+	this.SpotOrderBooks.Stop()
+}
+
+func (this *SpotBookFeed) Subscribe(pair Pair) {
+	this.relay.Bind(pair.ToSymbol("/", true), pair)
+	this.SpotOrderBooks.Subscribe(pair)
+}
+
+func (this *SpotBookFeed) Unsubscribe(pair Pair) {
+	this.SpotOrderBooks.Unsubscribe(pair)
+	this.relay.Unbind(pair.ToSymbol("/", true))
+}
+
+func (this *SpotBookFeed) Snapshot(pair Pair) (*Depth, error) {
+	return this.SpotOrderBooks.Snapshot(pair)
+}
+
+func (this *SpotBookFeed) SetUpdateHandler(handler func(*BookUpdate)) {
+	this.relay.SetUpdateHandler(handler)
+}
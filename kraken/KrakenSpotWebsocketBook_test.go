@@ -2,12 +2,15 @@ package kraken
 
 import (
 	"net/http"
+	"sync"
 	"testing"
 	"time"
 
 	. "github.com/deforceHK/goghostex"
 )
 
+var _ OrderBookFeed = (*SpotBookFeed)(nil)
+
 /**
 * unit test cmd
 * go test -v ./kraken/... -count=1 -run=TestWSSpotWebsocketBook_Start
@@ -74,3 +77,99 @@ func TestWSSpotWebsocketBook_Start(t *testing.T) {
 		//t.Log(string(depthData))
 	}
 }
+
+// go test -v ./kraken/... -count=1 -run=TestSpotOrderBooks_Checksum
+func TestSpotOrderBooks_Checksum(t *testing.T) {
+	var wsKK = &SpotOrderBooks{
+		WSSpotMarketKK: &WSSpotMarketKK{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { t.Error(err) },
+		},
+		OrderBookMuxs: make(map[string]*sync.Mutex),
+		BidData:       make(map[string]map[int64]float64),
+		AskData:       make(map[string]map[int64]float64),
+		SeqData:       make(map[string]int64),
+		TsData:        make(map[string]int64),
+		precisions:    make(map[string][2]int),
+		checksumFails: make(map[string]int64),
+		invalid:       make(map[string]bool),
+	}
+
+	wsKK.Receiver(`{"channel":"instrument","type":"snapshot","data":{"assets":[],"pairs":[{"symbol":"BTC/USD","base":"BTC","quote":"USD","status":"online","qty_precision":8,"qty_increment":0.00000001,"price_precision":1,"price_increment":0.1}]}}`)
+	wsKK.Receiver(`{"channel":"book","type":"snapshot","data":[{"symbol":"BTC/USD","bids":[{"price":26500.1,"qty":0.3},{"price":26499.5,"qty":2.0}],"asks":[{"price":26500.2,"qty":0.5},{"price":26501.0,"qty":1.25}],"checksum":1969654995}]}`)
+	wsKK.Receiver(`{"channel":"book","type":"update","data":[{"symbol":"BTC/USD","bids":[{"price":26500.0,"qty":0.1}],"asks":[],"checksum":2102669604,"timestamp":"2023-10-06T17:35:55.440295Z"}]}`)
+
+	if wsKK.ChecksumFails("BTC/USD") != 0 {
+		t.Error("The checksum should be passed. ")
+	}
+	var depth, _ = wsKK.Snapshot(BTC_USD)
+	if len(depth.BidList) != 3 || depth.BidList[1].Price != 26500 {
+		t.Error("The bid list is wrong: ", depth.BidList)
+	}
+}
+
+// go test -v ./kraken/... -count=1 -run=TestSpotOrderBooks_ChecksumMismatch
+func TestSpotOrderBooks_ChecksumMismatch(t *testing.T) {
+	var errs = make([]error, 0)
+	var resubscribes = make([]string, 0)
+	var wsKK = &SpotOrderBooks{
+		WSSpotMarketKK: &WSSpotMarketKK{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { errs = append(errs, err) },
+		},
+		ResubscribeHandler: func(productId string) { resubscribes = append(resubscribes, productId) },
+		OrderBookMuxs:      make(map[string]*sync.Mutex),
+		BidData:            make(map[string]map[int64]float64),
+		AskData:            make(map[string]map[int64]float64),
+		SeqData:            make(map[string]int64),
+		TsData:             make(map[string]int64),
+		precisions:         make(map[string][2]int),
+		checksumFails:      make(map[string]int64),
+		invalid:            make(map[string]bool),
+	}
+
+	wsKK.Receiver(`{"channel":"instrument","type":"snapshot","data":{"assets":[],"pairs":[{"symbol":"BTC/USD","base":"BTC","quote":"USD","status":"online","qty_precision":8,"qty_increment":0.00000001,"price_precision":1,"price_increment":0.1}]}}`)
+	wsKK.Receiver(`{"channel":"book","type":"snapshot","data":[{"symbol":"BTC/USD","bids":[{"price":26500.1,"qty":0.3},{"price":26499.5,"qty":2.0}],"asks":[{"price":26500.2,"qty":0.5},{"price":26501.0,"qty":1.25}],"checksum":1969654995}]}`)
+	if len(errs) != 0 || len(resubscribes) != 0 {
+		t.Fatal("The snapshot checksum should be passed. ", errs, resubscribes)
+	}
+	wsKK.Receiver(`{"channel":"book","type":"update","data":[{"symbol":"BTC/USD","bids":[{"price":26500.0,"qty":0.1}],"asks":[],"checksum":12345,"timestamp":"2023-10-06T17:35:55.440295Z"}]}`)
+
+	if wsKK.ChecksumFails("BTC/USD") != 1 {
+		t.Error("The checksum fails should be 1: ", wsKK.ChecksumFails("BTC/USD"))
+	}
+	if len(errs) != 1 {
+		t.Fatal("The checksum error should be handled once: ", errs)
+	}
+	var checksumErr, ok = errs[0].(*BookChecksumError)
+	if !ok || checksumErr.Exchange != KRAKEN || checksumErr.ProductId != "BTC/USD" ||
+		checksumErr.Expect != 12345 || checksumErr.Actual != 2102669604 || checksumErr.FailCount != 1 {
+		t.Error("The checksum error is wrong: ", errs[0])
+	}
+	if len(resubscribes) != 1 || resubscribes[0] != "BTC/USD" {
+		t.Error("The book should be resubscribed: ", resubscribes)
+	}
+
+	// the invalid book is not served, the updates are ignored until the snapshot.
+	if _, err := wsKK.Snapshot(BTC_USD); err == nil {
+		t.Error("The invalid book should be an error. ")
+	}
+	wsKK.Receiver(`{"channel":"book","type":"update","data":[{"symbol":"BTC/USD","bids":[{"price":26499.0,"qty":0.1}],"asks":[],"checksum":12345,"timestamp":"2023-10-06T17:35:56.440295Z"}]}`)
+	if len(errs) != 1 || len(resubscribes) != 1 {
+		t.Error("The update of the invalid book should be ignored: ", errs, resubscribes)
+	}
+
+	// the mismatch of the first symbol does not skip the others in the message.
+	wsKK.Receiver(`{"channel":"book","type":"snapshot","data":[{"symbol":"BTC/USD","bids":[{"price":26500.1,"qty":0.3}],"asks":[{"price":26500.2,"qty":0.5}],"checksum":12345},{"symbol":"ETH/USD","bids":[{"price":1600.1,"qty":1}],"asks":[{"price":1600.2,"qty":2}],"checksum":0}]}`)
+	if depth, err := wsKK.Snapshot(ETH_USD); err != nil || len(depth.AskList) != 1 {
+		t.Error("The book of the second symbol should be received: ", err)
+	}
+	if _, err := wsKK.Snapshot(BTC_USD); err == nil || len(resubscribes) != 2 {
+		t.Error("The book with the mismatch snapshot should be invalid: ", err, resubscribes)
+	}
+
+	wsKK.Receiver(`{"channel":"book","type":"snapshot","data":[{"symbol":"BTC/USD","bids":[{"price":26500.1,"qty":0.3},{"price":26499.5,"qty":2.0}],"asks":[{"price":26500.2,"qty":0.5},{"price":26501.0,"qty":1.25}],"checksum":1969654995}]}`)
+	if _, err := wsKK.Snapshot(BTC_USD); err != nil {
+		t.Error("The book should be valid after the snapshot: ", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -17,6 +18,8 @@ const (
 	SWAP_KRAKEN_ENDPOINT = "https://futures.kraken.com/derivatives"
 
 	SWAP_BASE_MODE_CHART = "https://futures.kraken.com"
+
+	SWAP_HISTORY_ENDPOINT = "https://futures.kraken.com"
 )
 
 type Swap struct {
@@ -41,7 +44,7 @@ func (swap *Swap) DoRequest(baseUrl, httpMethod, uri, reqBody string, response i
 		},
 	)
 	if err != nil {
-		return resp, err
+		return resp, krakenHttpError(err)
 	} else {
 		swap.lastRequestTS = time.Now().UnixMilli()
 		return resp, json.Unmarshal(resp, &response)
@@ -49,6 +52,15 @@ func (swap *Swap) DoRequest(baseUrl, httpMethod, uri, reqBody string, response i
 }
 
 func (swap *Swap) DoAuthRequest(httpMethod, uri, reqBody string, response interface{}) ([]byte, error) {
+	return swap.doAuthRequest(SWAP_KRAKEN_ENDPOINT, httpMethod, uri, reqBody, response)
+}
+
+// doAuthRequest the reqBody is the query of the GET request, it's signed as the post data too.
+func (swap *Swap) doAuthRequest(baseUrl, httpMethod, uri, reqBody string, response interface{}) ([]byte, error) {
+	// wait before the nonce, the nonce must be increasing.
+	if err := swap.Limiter.Acquire(httpMethod, uri, ""); err != nil {
+		return nil, err
+	}
 	var aut = ""
 	var nonce = fmt.Sprintf("%d", time.Now().UnixNano())
 	aut = reqBody + nonce + uri
@@ -64,10 +76,15 @@ func (swap *Swap) DoAuthRequest(httpMethod, uri, reqBody string, response interf
 		aut = base64.StdEncoding.EncodeToString(hmacAUT)
 	}
 
-	resp, err := NewHttpRequest(
+	var reqUrl = baseUrl + uri
+	if httpMethod == http.MethodGet && reqBody != "" {
+		reqUrl += "?" + reqBody
+	}
+
+	resp, header, err := NewHttpRequestWithHeader(
 		swap.config.HttpClient,
 		httpMethod,
-		SWAP_KRAKEN_ENDPOINT+uri,
+		reqUrl,
 		reqBody,
 		map[string]string{
 			"Content-Type": "application/x-www-form-urlencoded",
@@ -77,9 +94,10 @@ func (swap *Swap) DoAuthRequest(httpMethod, uri, reqBody string, response interf
 			"Nonce":        nonce,
 		},
 	)
+	swap.Limiter.Feedback(httpMethod, uri, "", header, err)
 
 	if err != nil {
-		return nil, err
+		return nil, krakenHttpError(err)
 	} else {
 		swap.lastRequestTS = time.Now().UnixMilli()
 		return resp, json.Unmarshal(resp, &response)
@@ -1,15 +1,150 @@
 package kraken
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
 	. "github.com/deforceHK/goghostex"
 )
 
+const (
+	SWAP_ACCOUNT_LOG_URI = "/api/history/v3/account-log"
+)
+
+// the transfer entries of the account log, the other entries are split by the pnl, fee and funding.
+var _INERNAL_TRANSFER_INFO = map[string]bool{
+	"transfer":                true,
+	"cross-exchange transfer": true,
+	"subaccount transfer":     true,
+	"admin transfer":          true,
+}
+
+type accountLogKK struct {
+	Id              int64   `json:"id"`
+	Date            string  `json:"date"`
+	Asset           string  `json:"asset"`
+	Info            string  `json:"info"`
+	BookingUid      string  `json:"booking_uid"`
+	MarginAccount   string  `json:"margin_account"`
+	OldBalance      float64 `json:"old_balance"`
+	NewBalance      float64 `json:"new_balance"`
+	Contract        string  `json:"contract"`
+	TradePrice      float64 `json:"trade_price"`
+	MarkPrice       float64 `json:"mark_price"`
+	FundingRate     float64 `json:"funding_rate"`
+	RealizedPnl     float64 `json:"realized_pnl"`
+	RealizedFunding float64 `json:"realized_funding"`
+	Fee             float64 `json:"fee"`
+	Execution       string  `json:"execution"`
+}
+
 func (swap *Swap) GetAccountFlow() ([]*SwapAccountItem, []byte, error) {
-	//TODO implement me
-	panic("implement me")
+	return swap.accountFlow("")
 }
 
 func (swap *Swap) GetPairFlow(pair Pair) ([]*SwapAccountItem, []byte, error) {
-	// todo sync fee
-	return make([]*SwapAccountItem, 0), []byte(""), nil
+	var contract = swap.getContract(pair)
+	if contract == nil {
+		return nil, nil, fmt.Errorf("The contract of %s not found. ", pair.ToSymbol("_", false))
+	}
+	return swap.accountFlow(contract.ContractName)
+}
+
+// accountFlow the account log is not filtered by the contract remote, all the entries are filtered here.
+func (swap *Swap) accountFlow(contractName string) ([]*SwapAccountItem, []byte, error) {
+	var params = url.Values{}
+	params.Set("count", "500")
+
+	var response = struct {
+		AccountUid string          `json:"accountUid"`
+		Logs       []*accountLogKK `json:"logs"`
+	}{}
+
+	var resp, err = swap.doAuthRequest(
+		SWAP_HISTORY_ENDPOINT,
+		http.MethodGet,
+		SWAP_ACCOUNT_LOG_URI,
+		params.Encode(),
+		&response,
+	)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var items = make([]*SwapAccountItem, 0)
+	for _, log := range response.Logs {
+		var contract = strings.ToUpper(log.Contract)
+		if contractName != "" && contract != contractName {
+			continue
+		}
+		items = append(items, swap.accountItems(log)...)
+	}
+	return items, resp, nil
+}
+
+// accountItems the trade entry has the pnl and the fee, it's split to the settle and the commission like okex.
+func (swap *Swap) accountItems(log *accountLogKK) []*SwapAccountItem {
+	var contract = strings.ToUpper(log.Contract)
+	// only the PF contracts are supported, the PI and FI are the inverse ones.
+	if contract != "" && !strings.HasPrefix(contract, "PF_") {
+		return nil
+	}
+
+	var pair = Pair{}
+	if contract != "" {
+		pair = krakenSwapPair(contract)
+	}
+
+	var settleMode = SETTLE_MODE_COUNTER
+	if contract == "" && log.MarginAccount != "flex" {
+		settleMode = SETTLE_MODE_BASIS
+	}
+
+	var logTime, _ = time.Parse(time.RFC3339, log.Date)
+	var info, _ = json.Marshal(log)
+	var newItem = func(subject string, amount float64) *SwapAccountItem {
+		return &SwapAccountItem{
+			Pair:     pair,
+			Exchange: KRAKEN,
+			Subject:  subject,
+			Id:       fmt.Sprintf("%d", log.Id),
+
+			SettleMode:     settleMode,
+			SettleCurrency: krakenCurrency(log.Asset),
+			Amount:         amount,
+			Timestamp:      logTime.UnixMilli(),
+			DateTime:       logTime.In(swap.config.Location).Format(GO_BIRTHDAY),
+			Info:           string(info),
+		}
+	}
+
+	var items = make([]*SwapAccountItem, 0)
+	if _INERNAL_TRANSFER_INFO[log.Info] {
+		var amount = log.NewBalance - log.OldBalance
+		if amount > 0 {
+			items = append(items, newItem(SUBJECT_TRANSFER_IN, amount))
+		} else if amount < 0 {
+			items = append(items, newItem(SUBJECT_TRANSFER_OUT, amount))
+		}
+		return items
+	}
+
+	if contract == "" {
+		return items
+	}
+	if log.RealizedPnl != 0 {
+		items = append(items, newItem(SUBJECT_SETTLE, log.RealizedPnl))
+	}
+	// the fee is the cost, it's negative in the flow.
+	if log.Fee != 0 {
+		items = append(items, newItem(SUBJECT_COMMISSION, -log.Fee))
+	}
+	if log.RealizedFunding != 0 {
+		items = append(items, newItem(SUBJECT_FUNDING_FEE, log.RealizedFunding))
+	}
+	return items
 }
@@ -1 +1,91 @@
 package kraken
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// the futures endpoints are constant, the transport serve them by the handler.
+type handlerTransport struct {
+	handler http.HandlerFunc
+}
+
+func (this *handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var recorder = httptest.NewRecorder()
+	this.handler(recorder, req)
+	return recorder.Result(), nil
+}
+
+// go test -v ./kraken/... -count=1 -run=TestSwap_GetAccountFlow
+func TestSwap_GetAccountFlow(t *testing.T) {
+	var kr = New(&APIConfig{
+		HttpClient: &http.Client{Transport: &handlerTransport{func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/derivatives" + SWAP_CONTRACT_URI:
+				_, _ = w.Write([]byte(`{"result":"success","instruments":[
+					{"symbol":"PF_XBTUSD","type":"flexible_futures","tickSize":1,"contractSize":1,"contractValueTradePrecision":4},
+					{"symbol":"PF_ETHUSD","type":"flexible_futures","tickSize":0.1,"contractSize":1,"contractValueTradePrecision":3}]}`))
+			case SWAP_ACCOUNT_LOG_URI:
+				if r.URL.Query().Get("count") != "500" || r.Header.Get("Authent") == "" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				_, _ = w.Write([]byte(`{"accountUid":"uid","logs":[
+					{"id":5,"date":"2023-11-14T22:13:20.000Z","asset":"usd","info":"futures trade","margin_account":"flex",
+					 "old_balance":100,"new_balance":101.5,"contract":"pf_xbtusd","realized_pnl":2,"fee":0.5,"realized_funding":null},
+					{"id":4,"date":"2023-11-14T22:00:00.000Z","asset":"usd","info":"funding rate change","margin_account":"flex",
+					 "old_balance":100.1,"new_balance":100,"contract":"pf_ethusd","realized_funding":-0.1},
+					{"id":3,"date":"2023-11-14T21:00:00.000Z","asset":"usd","info":"transfer","margin_account":"flex",
+					 "old_balance":0,"new_balance":100.1,"contract":null},
+					{"id":2,"date":"2023-11-14T20:00:00.000Z","asset":"xbt","info":"futures trade","margin_account":"fi_xbtusd",
+					 "old_balance":1,"new_balance":1.1,"contract":"pi_xbtusd","realized_pnl":0.1}]}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}}},
+		ApiSecretKey: base64.StdEncoding.EncodeToString([]byte("secret")),
+		Location:     time.UTC,
+		RateLimit:    RATE_LIMIT_OFF,
+	})
+
+	var items, _, err = kr.Swap.GetAccountFlow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 {
+		t.Fatal("The account flow is wrong. ", len(items))
+	}
+	var expects = []struct {
+		subject string
+		amount  float64
+		symbol  string
+	}{
+		{SUBJECT_SETTLE, 2, "btc_usd"},
+		{SUBJECT_COMMISSION, -0.5, "btc_usd"},
+		{SUBJECT_FUNDING_FEE, -0.1, "eth_usd"},
+		{SUBJECT_TRANSFER_IN, 100.1, "_"},
+	}
+	for i, expect := range expects {
+		var item = items[i]
+		if item.Subject != expect.subject || item.Amount != expect.amount ||
+			item.Pair.ToSymbol("_", false) != expect.symbol || item.SettleCurrency.Symbol != "USD" {
+			t.Error("The flow item is wrong. ", i, item.Subject, item.Amount, item.Pair)
+		}
+	}
+	if items[0].Id != "5" || items[0].Timestamp != 1700000000000 || items[0].SettleMode != SETTLE_MODE_COUNTER {
+		t.Error("The flow item is wrong. ", items[0])
+	}
+
+	items, _, err = kr.Swap.GetPairFlow(Pair{Basis: BTC, Counter: USD})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Subject != SUBJECT_SETTLE || items[1].Subject != SUBJECT_COMMISSION {
+		t.Error("The pair flow is wrong. ", items)
+	}
+}
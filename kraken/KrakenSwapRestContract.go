@@ -164,14 +164,7 @@ func (swap *Swap) GetContracts() ([]*SwapContract, []byte, error) {
 			//}else{
 			//	openTime = t
 			//}
-			var coin = inst.Symbol[3 : len(inst.Symbol)-3]
-			if coin == "XBT" {
-				coin = "BTC"
-			}
-			var pair = Pair{
-				NewCurrency(coin, ""),
-				USD,
-			}
+			var pair = krakenSwapPair(inst.Symbol)
 
 			contracts = append(contracts, &SwapContract{
 				Pair:            pair,
@@ -189,3 +182,15 @@ func (swap *Swap) GetContracts() ([]*SwapContract, []byte, error) {
 	}
 
 }
+
+// krakenSwapPair the pair of the PF contract, eg: PF_XBTUSD -> btc_usd
+func krakenSwapPair(symbol string) Pair {
+	var coin = symbol[3 : len(symbol)-3]
+	if coin == "XBT" {
+		coin = "BTC"
+	}
+	return Pair{
+		NewCurrency(coin, ""),
+		USD,
+	}
+}
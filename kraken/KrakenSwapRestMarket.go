@@ -136,6 +136,14 @@ func (swap *Swap) GetLimit(pair Pair) (float64, float64, error) {
 }
 
 func (swap *Swap) GetKline(pair Pair, period, size, since int) ([]*SwapKline, []byte, error) {
+	if _, exist := SWAP_KRAKEN_PERIOD_TRANS[period]; !exist {
+		return newKlineFallback(swap.config.Location, SWAP_KRAKEN_PERIOD_TRANS).SwapKlines(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*SwapKline, []byte, error) {
+				return swap.GetKline(pair, period, size, int(since))
+			},
+		)
+	}
 	var symbol = pair.ToSymbol("", true)
 	if symbol == "BTCUSD" {
 		symbol = "XBTUSD"
@@ -72,6 +72,7 @@ func (swap *Swap) PlaceOrder(order *SwapOrder) ([]byte, error) {
 	var response struct {
 		ServerTime string `json:"serverTime"`
 		Result     string `json:"result"`
+		Error      string `json:"error"`
 		SendStatus struct {
 			CliOrdId     string `json:"cliOrdId"`
 			Status       string `json:"status"`
@@ -106,11 +107,11 @@ func (swap *Swap) PlaceOrder(order *SwapOrder) ([]byte, error) {
 		return resp, err
 	} else {
 		if response.Result != "success" || len(response.SendStatus.OrderEvents) == 0 {
-			return resp, errors.New(string(resp))
+			return resp, krakenSwapError(errorStatus(response.Error, response.SendStatus.Status), resp)
 		}
 		if orderStatus, exist := statusRelation[response.SendStatus.Status]; !exist {
 			order.Status = ORDER_FAIL
-			return resp, errors.New(string(resp))
+			return resp, krakenSwapError(response.SendStatus.Status, resp)
 		} else {
 			order.Status = orderStatus
 		}
@@ -135,6 +136,7 @@ func (swap *Swap) CancelOrder(order *SwapOrder) ([]byte, error) {
 	var uri = "/api/v3/cancelorder"
 	var response struct {
 		Result       string `json:"result"`
+		Error        string `json:"error"`
 		CancelStatus struct {
 			Status       string `json:"status"`
 			CliOrdId     string `json:"cliOrdId"`
@@ -148,10 +150,10 @@ func (swap *Swap) CancelOrder(order *SwapOrder) ([]byte, error) {
 		return resp, err
 	} else {
 		if response.Result != "success" {
-			return resp, errors.New(string(resp))
+			return resp, krakenSwapError(errorStatus(response.Error, response.CancelStatus.Status), resp)
 		}
 		if orderStatus, exist := statusRelation[response.CancelStatus.Status]; !exist {
-			return resp, errors.New(string(resp))
+			return resp, krakenSwapError(response.CancelStatus.Status, resp)
 		} else {
 			order.Status = orderStatus
 		}
@@ -200,7 +202,7 @@ func (swap *Swap) GetOrder(order *SwapOrder) ([]byte, error) {
 		return resp, err
 	} else {
 		if orderStatus, exist := getOrderStatusRelation[response.Orders[0].Status]; !exist {
-			return resp, errors.New(string(resp))
+			return resp, krakenSwapError(response.Orders[0].Status, resp)
 		} else {
 			order.Status = orderStatus
 		}
@@ -242,6 +244,7 @@ func (swap *Swap) GetOrders(pair Pair) ([]*SwapOrder, []byte, error) {
 	var response struct {
 		ServerTime string `json:"serverTime"`
 		Result     string `json:"result"`
+		Error      string `json:"error"`
 		Fills      []struct {
 			CliOrdId string  `json:"cliOrdId"`
 			FillTime string  `json:"fillTime"`
@@ -264,7 +267,7 @@ func (swap *Swap) GetOrders(pair Pair) ([]*SwapOrder, []byte, error) {
 		return nil, resp, err
 	} else {
 		if response.Result != "success" {
-			return nil, resp, errors.New(string(resp))
+			return nil, resp, krakenSwapError(response.Error, resp)
 		}
 		var orders = make([]*SwapOrder, 0)
 		for _, fill := range response.Fills {
@@ -0,0 +1,79 @@
+package kraken
+
+import (
+	"fmt"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// SwapBookFeed the LocalOrderBooks as the OrderBookFeed, the UpdateChan of the books is read by the feed.
+type SwapBookFeed struct {
+	*LocalOrderBooks
+	relay *BookUpdateRelay
+}
+
+func NewSwapBookFeed(books *LocalOrderBooks) *SwapBookFeed {
+	return &SwapBookFeed{
+		LocalOrderBooks: books,
+		relay:           NewBookUpdateRelay(KRAKEN, TRADE_TYPE_SWAP),
+	}
+}
+
+// the product id of the PF contract, eg: PF_XBTUSD
+func (this *SwapBookFeed) productId(pair Pair) string {
+	var symbol = pair.ToSymbol("", true)
+	if symbol == "BTCUSD" {
+		symbol = "XBTUSD"
+	}
+	return fmt.Sprintf("PF_%s", symbol)
+}
+
+func (this *SwapBookFeed) GetExchangeName() string {
+	return KRAKEN
+}
+
+func (this *SwapBookFeed) GetMarket() string {
+	return TRADE_TYPE_SWAP
+}
+
+func (this *SwapBookFeed) Start() error {
+	if this.UpdateChan == nil {
+		this.UpdateChan = make(chan string, 1024)
+	}
+	this.relay.Start(this.UpdateChan)
+	return this.LocalOrderBooks.Init()
+}
+
+func (this *SwapBookFeed) Stop() {
+	this.relay.Stop()
+	this.LocalOrderBooks.Stop()
+}
+
+func (this *SwapBookFeed) Subscribe(pair Pair) {
+	this.relay.Bind(this.productId(pair), pair)
+	this.LocalOrderBooks.Subscribe(pair)
+}
+
+func (this *SwapBookFeed) Unsubscribe(pair Pair) {
+	this.LocalOrderBooks.Unsubscribe(pair)
+	this.relay.Unbind(this.productId(pair))
+}
+
+func (this *SwapBookFeed) Snapshot(pair Pair) (*Depth, error) {
+	var depth, err = this.LocalOrderBooks.Snapshot(pair)
+	if err != nil {
+		return nil, err
+	}
+	return &Depth{
+		Pair:      depth.Pair,
+		Timestamp: depth.Timestamp,
+		Sequence:  depth.Sequence,
+		Date:      depth.Date,
+		AskList:   depth.AskList,
+		BidList:   depth.BidList,
+	}, nil
+}
+
+func (this *SwapBookFeed) SetUpdateHandler(handler func(*BookUpdate)) {
+	this.relay.SetUpdateHandler(handler)
+}
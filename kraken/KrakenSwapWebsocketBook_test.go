@@ -9,6 +9,8 @@ import (
 	. "github.com/deforceHK/goghostex"
 )
 
+var _ OrderBookFeed = (*SwapBookFeed)(nil)
+
 // go test -v ./kraken/... -count=1 -run=TestLocalOrderBooks_Init
 func TestLocalOrderBooks_Init(t *testing.T) {
 	var config = &APIConfig{
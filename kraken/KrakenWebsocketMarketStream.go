@@ -0,0 +1,407 @@
+package kraken
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+type kkMarketPairs struct {
+	pairs    map[string]Pair
+	pairsMux sync.RWMutex
+}
+
+func (this *kkMarketPairs) put(symbol string, pair Pair) {
+	this.pairsMux.Lock()
+	defer this.pairsMux.Unlock()
+	if this.pairs == nil {
+		this.pairs = make(map[string]Pair)
+	}
+	this.pairs[symbol] = pair
+}
+
+func (this *kkMarketPairs) get(symbol string) (Pair, bool) {
+	this.pairsMux.RLock()
+	defer this.pairsMux.RUnlock()
+	var pair, exist = this.pairs[symbol]
+	return pair, exist
+}
+
+// SpotMarketStream decode the spot v2 channels of kraken into the typed models.
+type SpotMarketStream struct {
+	*WSSpotMarketKK
+	MarketHandler
+
+	kkMarketPairs
+}
+
+type kkSpotSub struct {
+	Method string `json:"method"`
+	Params struct {
+		Channel  string   `json:"channel"`
+		Symbol   []string `json:"symbol"`
+		Interval int      `json:"interval,omitempty"`
+	} `json:"params"`
+}
+
+func (this *SpotMarketStream) Init() error {
+	this.WSSpotMarketKK.RecvHandler = func(s string) {
+		this.Receiver(s)
+	}
+	return this.Start()
+}
+
+func (this *SpotMarketStream) GetExchangeName() string {
+	return KRAKEN
+}
+
+func (this *SpotMarketStream) SubscribeTicker(pair Pair) error {
+	this.subscribe("ticker", pair, 0)
+	return nil
+}
+
+func (this *SpotMarketStream) SubscribeTrade(pair Pair) error {
+	this.subscribe("trade", pair, 0)
+	return nil
+}
+
+func (this *SpotMarketStream) SubscribeKline(pair Pair, period int) error {
+	var kkPeriod, exist = _INERNAL_KLINE_PERIOD_CONVERTER[period]
+	if !exist {
+		return fmt.Errorf("The period %d is not supported by kraken. ", period)
+	}
+	var interval, _ = strconv.Atoi(kkPeriod)
+	this.subscribe("ohlc", pair, interval)
+	return nil
+}
+
+func (this *SpotMarketStream) SubscribeFundingRate(pair Pair) error {
+	return fmt.Errorf("The funding rate is not in kraken spot. ")
+}
+
+func (this *SpotMarketStream) SubscribeMarkPrice(pair Pair) error {
+	return fmt.Errorf("The mark price is not in kraken spot. ")
+}
+
+func (this *SpotMarketStream) subscribe(channel string, pair Pair, interval int) {
+	var symbol = pair.ToSymbol("/", true)
+	this.put(symbol, pair)
+
+	var sub = kkSpotSub{Method: "subscribe"}
+	sub.Params.Channel = channel
+	sub.Params.Symbol = []string{symbol}
+	sub.Params.Interval = interval
+	this.WSSpotMarketKK.Subscribe(sub)
+}
+
+func (this *SpotMarketStream) Receiver(msg string) {
+	var push = struct {
+		Channel string          `json:"channel"`
+		Type    string          `json:"type"`
+		Data    json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal([]byte(msg), &push); err != nil {
+		this.ErrorHandler(err)
+		return
+	}
+	if push.Type != "snapshot" && push.Type != "update" {
+		return
+	}
+
+	var err error
+	switch push.Channel {
+	case "ticker":
+		err = this.recvTicker(push.Data)
+	case "trade":
+		// the snapshot is the history trades, skip it.
+		if push.Type == "update" {
+			err = this.recvTrade(push.Data)
+		}
+	case "ohlc":
+		err = this.recvOHLC(push.Data)
+	}
+	if err != nil {
+		this.ErrorHandler(err)
+	}
+}
+
+func (this *SpotMarketStream) recvTicker(raw json.RawMessage) error {
+	var data = make([]struct {
+		Symbol string  `json:"symbol"`
+		Bid    float64 `json:"bid"`
+		Ask    float64 `json:"ask"`
+		Last   float64 `json:"last"`
+		Volume float64 `json:"volume"`
+		Low    float64 `json:"low"`
+		High   float64 `json:"high"`
+	}, 0)
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	// the ticker of kraken has no timestamp, use the local time.
+	var now = time.Now().In(this.Config.Location)
+	for _, d := range data {
+		var pair, exist = this.get(d.Symbol)
+		if !exist {
+			continue
+		}
+		this.OnTicker(&Ticker{
+			Pair:      pair,
+			Last:      d.Last,
+			Buy:       d.Bid,
+			Sell:      d.Ask,
+			High:      d.High,
+			Low:       d.Low,
+			Vol:       d.Volume,
+			Timestamp: now.UnixMilli(),
+			Date:      now.Format(GO_BIRTHDAY),
+		})
+	}
+	return nil
+}
+
+func (this *SpotMarketStream) recvTrade(raw json.RawMessage) error {
+	var data = make([]struct {
+		Symbol    string  `json:"symbol"`
+		Side      string  `json:"side"`
+		Price     float64 `json:"price"`
+		Qty       float64 `json:"qty"`
+		TradeId   int64   `json:"trade_id"`
+		Timestamp string  `json:"timestamp"`
+	}, 0)
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		var pair, exist = this.get(d.Symbol)
+		if !exist {
+			continue
+		}
+		var tradeTime, _ = time.Parse(time.RFC3339, d.Timestamp)
+		var side = BUY
+		if d.Side == "sell" {
+			side = SELL
+		}
+		this.OnTrade(&Trade{
+			Tid:       d.TradeId,
+			Type:      side,
+			Amount:    d.Qty,
+			Price:     d.Price,
+			Timestamp: tradeTime.UnixMilli(),
+			Pair:      pair,
+		})
+	}
+	return nil
+}
+
+func (this *SpotMarketStream) recvOHLC(raw json.RawMessage) error {
+	var data = make([]struct {
+		Symbol        string  `json:"symbol"`
+		Open          float64 `json:"open"`
+		High          float64 `json:"high"`
+		Low           float64 `json:"low"`
+		Close         float64 `json:"close"`
+		Volume        float64 `json:"volume"`
+		IntervalBegin string  `json:"interval_begin"`
+	}, 0)
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		var pair, exist = this.get(d.Symbol)
+		if !exist {
+			continue
+		}
+		var beginTime, _ = time.Parse(time.RFC3339, d.IntervalBegin)
+		this.OnKline(&Kline{
+			Pair:      pair,
+			Exchange:  KRAKEN,
+			Timestamp: beginTime.UnixMilli(),
+			Date:      beginTime.In(this.Config.Location).Format(GO_BIRTHDAY),
+			Open:      d.Open,
+			Close:     d.Close,
+			High:      d.High,
+			Low:       d.Low,
+			Vol:       d.Volume,
+		})
+	}
+	return nil
+}
+
+// SwapMarketStream decode the futures v1 feeds of kraken into the typed models.
+// The funding rate and mark price are both in the ticker feed, and there is no kline feed.
+type SwapMarketStream struct {
+	*WSSwapMarketKK
+	MarketHandler
+
+	kkMarketPairs
+	feeds    map[string]bool
+	feedsMux sync.Mutex
+}
+
+type kkSwapTicker struct {
+	Time                 int64   `json:"time"`
+	ProductId            string  `json:"product_id"`
+	Bid                  float64 `json:"bid"`
+	Ask                  float64 `json:"ask"`
+	Last                 float64 `json:"last"`
+	High                 float64 `json:"high"`
+	Low                  float64 `json:"low"`
+	Volume               float64 `json:"volume"`
+	Index                float64 `json:"index"`
+	MarkPrice            float64 `json:"markPrice"`
+	RelativeFundingRate  float64 `json:"relative_funding_rate"`
+	RelativeFundingRateP float64 `json:"relative_funding_rate_prediction"`
+	NextFundingRateTime  int64   `json:"next_funding_rate_time"`
+}
+
+func (this *SwapMarketStream) Init() error {
+	this.WSSwapMarketKK.RecvHandler = func(s string) {
+		this.Receiver(s)
+	}
+	return this.Start()
+}
+
+func (this *SwapMarketStream) GetExchangeName() string {
+	return KRAKEN
+}
+
+func (this *SwapMarketStream) SubscribeTicker(pair Pair) error {
+	this.subscribe("ticker", pair)
+	return nil
+}
+
+func (this *SwapMarketStream) SubscribeTrade(pair Pair) error {
+	this.subscribe("trade", pair)
+	return nil
+}
+
+func (this *SwapMarketStream) SubscribeKline(pair Pair, period int) error {
+	return fmt.Errorf("The kline is not in kraken futures websocket. ")
+}
+
+func (this *SwapMarketStream) SubscribeFundingRate(pair Pair) error {
+	this.subscribe("ticker", pair)
+	return nil
+}
+
+func (this *SwapMarketStream) SubscribeMarkPrice(pair Pair) error {
+	this.subscribe("ticker", pair)
+	return nil
+}
+
+func (this *SwapMarketStream) subscribe(feed string, pair Pair) {
+	var symbol = pair.ToSymbol("", true)
+	if symbol == "BTCUSD" {
+		symbol = "XBTUSD"
+	}
+	var productId = fmt.Sprintf("PF_%s", symbol)
+	this.put(productId, pair)
+
+	// the ticker feed is shared by ticker, funding rate and mark price.
+	this.feedsMux.Lock()
+	if this.feeds == nil {
+		this.feeds = make(map[string]bool)
+	}
+	var key = feed + ":" + productId
+	if this.feeds[key] {
+		this.feedsMux.Unlock()
+		return
+	}
+	this.feeds[key] = true
+	this.feedsMux.Unlock()
+
+	var sub = struct {
+		Event      string   `json:"event"`
+		Feed       string   `json:"feed"`
+		ProductIds []string `json:"product_ids"`
+	}{
+		"subscribe", feed, []string{productId},
+	}
+	this.WSSwapMarketKK.Subscribe(sub)
+}
+
+func (this *SwapMarketStream) Receiver(msg string) {
+	var pre = struct {
+		Feed      string `json:"feed"`
+		ProductId string `json:"product_id"`
+	}{}
+	if err := json.Unmarshal([]byte(msg), &pre); err != nil {
+		this.ErrorHandler(err)
+		return
+	}
+
+	var pair, exist = this.get(pre.ProductId)
+	if !exist {
+		return
+	}
+
+	switch pre.Feed {
+	case "ticker":
+		var ticker = kkSwapTicker{}
+		if err := json.Unmarshal([]byte(msg), &ticker); err != nil {
+			this.ErrorHandler(err)
+			return
+		}
+		var date = time.UnixMilli(ticker.Time).In(this.Config.Location).Format(GO_BIRTHDAY)
+		this.OnSwapTicker(&SwapTicker{
+			Pair:      pair,
+			Last:      ticker.Last,
+			Buy:       ticker.Bid,
+			Sell:      ticker.Ask,
+			High:      ticker.High,
+			Low:       ticker.Low,
+			Vol:       ticker.Volume,
+			Timestamp: ticker.Time,
+			Date:      date,
+		})
+		this.OnFundingRate(&FundingRate{
+			Pair:             pair,
+			Exchange:         KRAKEN,
+			Rate:             ticker.RelativeFundingRate,
+			NextRate:         ticker.RelativeFundingRateP,
+			FundingTimestamp: ticker.NextFundingRateTime,
+			Timestamp:        ticker.Time,
+			Date:             date,
+		})
+		this.OnMarkPrice(&MarkPrice{
+			Pair:       pair,
+			Exchange:   KRAKEN,
+			Price:      ticker.MarkPrice,
+			IndexPrice: ticker.Index,
+			Timestamp:  ticker.Time,
+			Date:       date,
+		})
+	case "trade":
+		var trade = struct {
+			Side  string  `json:"side"`
+			Seq   int64   `json:"seq"`
+			Time  int64   `json:"time"`
+			Qty   float64 `json:"qty"`
+			Price float64 `json:"price"`
+		}{}
+		if err := json.Unmarshal([]byte(msg), &trade); err != nil {
+			this.ErrorHandler(err)
+			return
+		}
+		var side = BUY
+		if trade.Side == "sell" {
+			side = SELL
+		}
+		this.OnTrade(&Trade{
+			Tid:       trade.Seq,
+			Type:      side,
+			Amount:    trade.Qty,
+			Price:     trade.Price,
+			Timestamp: trade.Time,
+			Pair:      pair,
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package kraken
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// go test -v ./kraken/... -count=1 -run=TestSpotMarketStream_Receiver
+func TestSpotMarketStream_Receiver(t *testing.T) {
+	var tickers, trades, klines = 0, 0, 0
+	var stream = &SpotMarketStream{
+		WSSpotMarketKK: &WSSpotMarketKK{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { t.Error(err) },
+		},
+		MarketHandler: MarketHandler{
+			TickerHandler: func(ticker *Ticker) {
+				tickers++
+				if ticker.Last != 26500.1 || ticker.Buy != 26500 || ticker.Sell != 26500.2 {
+					t.Error("The ticker is wrong: ", ticker)
+				}
+			},
+			TradeHandler: func(trade *Trade) {
+				trades++
+				if trade.Tid != 40348 || trade.Type != SELL || trade.Timestamp != 1695628177708 {
+					t.Error("The trade is wrong: ", trade)
+				}
+			},
+			KlineHandler: func(kline *Kline) {
+				klines++
+				if kline.Timestamp != 1695628140000 || kline.Close != 26502 {
+					t.Error("The kline is wrong: ", kline)
+				}
+			},
+		},
+	}
+	stream.put("BTC/USD", BTC_USD)
+
+	stream.Receiver(`{"channel":"heartbeat"}`)
+	stream.Receiver(`{"channel":"ticker","type":"snapshot","data":[{"symbol":"BTC/USD","bid":26500,"bid_qty":1.2,"ask":26500.2,"ask_qty":0.3,"last":26500.1,"volume":1200.5,"vwap":26400,"low":26000,"high":26800,"change":100,"change_pct":0.38}]}`)
+	stream.Receiver(`{"channel":"trade","type":"snapshot","data":[{"symbol":"BTC/USD","side":"buy","price":26400,"qty":0.1,"ord_type":"limit","trade_id":40000,"timestamp":"2023-09-25T07:40:00.000000Z"}]}`)
+	stream.Receiver(`{"channel":"trade","type":"update","data":[{"symbol":"BTC/USD","side":"sell","price":26500.1,"qty":0.1,"ord_type":"market","trade_id":40348,"timestamp":"2023-09-25T07:49:37.708706Z"}]}`)
+	stream.Receiver(`{"channel":"ohlc","type":"update","data":[{"symbol":"BTC/USD","open":26500,"high":26510,"low":26490,"close":26502,"trades":10,"volume":3.5,"vwap":26501,"interval_begin":"2023-09-25T07:49:00.000000Z","interval":1,"timestamp":"2023-09-25T07:50:00.000000Z"}]}`)
+
+	if tickers != 1 || trades != 1 || klines != 1 {
+		t.Error("The handlers are not called as expected. ", tickers, trades, klines)
+	}
+}
+
+// go test -v ./kraken/... -count=1 -run=TestSwapMarketStream_Receiver
+func TestSwapMarketStream_Receiver(t *testing.T) {
+	var tickers, rates, marks, trades = 0, 0, 0, 0
+	var stream = &SwapMarketStream{
+		WSSwapMarketKK: &WSSwapMarketKK{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { t.Error(err) },
+		},
+		MarketHandler: MarketHandler{
+			SwapTickerHandler: func(ticker *SwapTicker) { tickers++ },
+			FundingRateHandler: func(rate *FundingRate) {
+				rates++
+				if rate.Rate != 0.0000125 || rate.FundingTimestamp != 1612270800000 {
+					t.Error("The funding rate is wrong: ", rate)
+				}
+			},
+			MarkPriceHandler: func(mark *MarkPrice) {
+				marks++
+				if mark.Price != 34924.52 {
+					t.Error("The mark price is wrong: ", mark)
+				}
+			},
+			TradeHandler: func(trade *Trade) {
+				trades++
+				if trade.Tid != 653355 || trade.Type != SELL {
+					t.Error("The trade is wrong: ", trade)
+				}
+			},
+		},
+	}
+	var _ MarketWebsocketAPI = stream
+	stream.put("PF_XBTUSD", BTC_USD)
+
+	stream.Receiver(`{"time":1612270825253,"feed":"ticker","product_id":"PF_XBTUSD","bid":34832.5,"ask":34847.5,"bid_size":42864,"ask_size":2300,"volume":262306237,"dtm":0,"leverage":"50x","index":34803.45,"premium":0.1,"last":34852,"change":2.995,"funding_rate":3.59e-10,"funding_rate_prediction":4.07e-10,"suspended":false,"tag":"perpetual","pair":"XBT:USD","openInterest":10845860,"markPrice":34924.52,"maturityTime":0,"relative_funding_rate":0.0000125,"relative_funding_rate_prediction":0.0000141,"next_funding_rate_time":1612270800000}`)
+	stream.Receiver(`{"feed":"trade","product_id":"PF_XBTUSD","uid":"05af78ac","side":"sell","type":"fill","seq":653355,"time":1612266317519,"qty":15000,"price":34969.5}`)
+
+	if tickers != 1 || rates != 1 || marks != 1 || trades != 1 {
+		t.Error("The handlers are not called as expected. ", tickers, rates, marks, trades)
+	}
+}
@@ -113,6 +113,33 @@ var _INERNAL_V5_CANDLE_PERIOD_CONVERTER = map[int]string{
 	KLINE_PERIOD_1WEEK: "1W",
 }
 
+// the period not in the converter is resampled from the smaller one, eg: 8H 3DAY 1MONTH.
+// the candles of the v3 spot are at most 300 in one request.
+func newKlineFallback(location *time.Location) *KlineFallback {
+	return &KlineFallback{
+		Exchange: OKEX,
+		Location: location,
+		MaxSize:  300,
+		Supported: func(period int) bool {
+			var _, exist = _INERNAL_KLINE_PERIOD_CONVERTER[period]
+			return exist
+		},
+	}
+}
+
+// the max size is the limit of the candles request, the swap is 100 and the future is 300.
+func newV5KlineFallback(location *time.Location, maxSize int) *KlineFallback {
+	return &KlineFallback{
+		Exchange: OKEX,
+		Location: location,
+		MaxSize:  maxSize,
+		Supported: func(period int) bool {
+			var _, exist = _INERNAL_V5_CANDLE_PERIOD_CONVERTER[period]
+			return exist
+		},
+	}
+}
+
 var _INTERNAL_ORDER_TYPE_CONVERTER = map[PlaceType]int{
 	NORMAL:     0,
 	ONLY_MAKER: 1,
@@ -121,15 +148,16 @@ var _INTERNAL_ORDER_TYPE_CONVERTER = map[PlaceType]int{
 }
 
 type OKEx struct {
-	config *APIConfig
-	Spot   *Spot
-	Swap   *Swap
-	Future *Future
-	Wallet *Wallet
+	config  *APIConfig
+	Spot    *Spot
+	Swap    *Swap
+	Future  *Future
+	Wallet  *Wallet
+	Limiter *RateLimiter
 }
 
 func New(config *APIConfig) *OKEx {
-	okex := &OKEx{config: config}
+	okex := &OKEx{config: config, Limiter: newRateLimiter(config)}
 	okex.Spot = &Spot{
 		OKEx:        okex,
 		Locker:      new(sync.Mutex),
@@ -159,16 +187,21 @@ func (ok *OKEx) DoRequest(
 	response interface{},
 ) ([]byte, error) {
 	url := ok.config.Endpoint + uri
+	var instId = okexInstId(uri, reqBody)
+	if err := ok.Limiter.Acquire(httpMethod, uri, instId); err != nil {
+		return nil, err
+	}
 	sign, timestamp := ok.doParamSign(httpMethod, uri, reqBody)
-	resp, err := NewHttpRequest(ok.config.HttpClient, httpMethod, url, reqBody, map[string]string{
+	resp, header, err := NewHttpRequestWithHeader(ok.config.HttpClient, httpMethod, url, reqBody, map[string]string{
 		CONTENT_TYPE:         APPLICATION_JSON_UTF8,
 		ACCEPT:               APPLICATION_JSON,
 		OK_ACCESS_KEY:        ok.config.ApiKey,
 		OK_ACCESS_PASSPHRASE: ok.config.ApiPassphrase,
 		OK_ACCESS_SIGN:       sign,
 		OK_ACCESS_TIMESTAMP:  fmt.Sprint(timestamp)})
+	ok.Limiter.Feedback(httpMethod, uri, instId, header, err)
 	if err != nil {
-		return nil, err
+		return nil, okexHttpError(err)
 	} else {
 		nowTimestamp := time.Now().Unix() * 1000
 		if nowTimestamp > ok.config.LastTimestamp {
@@ -186,12 +219,17 @@ func (ok *OKEx) DoRequestMarket(
 ) ([]byte, error) {
 	url := ok.config.Endpoint + uri
 	//sign, timestamp := ok.doParamSign(httpMethod, uri, reqBody)
-	resp, err := NewHttpRequest(ok.config.HttpClient, httpMethod, url, reqBody, map[string]string{
+	var instId = okexInstId(uri, reqBody)
+	if err := ok.Limiter.Acquire(httpMethod, uri, instId); err != nil {
+		return nil, err
+	}
+	resp, header, err := NewHttpRequestWithHeader(ok.config.HttpClient, httpMethod, url, reqBody, map[string]string{
 		CONTENT_TYPE: APPLICATION_JSON_UTF8,
 		ACCEPT:       APPLICATION_JSON,
 	})
+	ok.Limiter.Feedback(httpMethod, uri, instId, header, err)
 	if err != nil {
-		return nil, err
+		return nil, okexHttpError(err)
 	} else {
 		nowTimestamp := time.Now().Unix() * 1000
 		if nowTimestamp > ok.config.LastTimestamp {
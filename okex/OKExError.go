@@ -0,0 +1,45 @@
+package okex
+
+import (
+	"encoding/json"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The error codes of okex v5 api, https://www.okx.com/docs-v5/en/#error-code
+var _OKEX_ERROR_CODES = map[string]Error{
+	"50001": ErrExchangeUnavailable, // Service temporarily unavailable
+	"50013": ErrExchangeUnavailable, // Systems are busy
+	"50026": ErrExchangeUnavailable, // System error
+	"50011": ErrRateLimited,         // Rate limit reached
+	"50061": ErrRateLimited,         // Sub-account rate limit exceeded
+	"50102": ErrAuthFailed,          // Timestamp request expired
+	"50105": ErrAuthFailed,          // Incorrect OK-ACCESS-PASSPHRASE
+	"50111": ErrAuthFailed,          // Invalid OK-ACCESS-KEY
+	"50113": ErrAuthFailed,          // Invalid Sign
+	"51008": ErrInsufficientBalance, // Order failed. Insufficient balance
+	"51006": ErrInvalidPrice,        // Order price is not within the price limit
+	"51400": ErrOrderNotFound,       // Cancellation failed as the order has been filled, canceled or does not exist
+	"51603": ErrOrderNotFound,       // Order does not exist
+}
+
+// okexError the code is the code or sCode in the response.
+func okexError(code, message string) error {
+	return NewExchangeError(OKEX, _OKEX_ERROR_CODES, code, message, nil)
+}
+
+// okexHttpError the non 2xx response of okex also has the code and msg.
+func okexHttpError(err error) error {
+	var body, exist = HttpErrorResponse(err)
+	if !exist {
+		return err
+	}
+	var response = struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+	}{}
+	if json.Unmarshal([]byte(body), &response) != nil || response.Code == "" {
+		return NewExchangeError(OKEX, _OKEX_ERROR_CODES, "", "", err)
+	}
+	return NewExchangeError(OKEX, _OKEX_ERROR_CODES, response.Code, "", err)
+}
@@ -1,7 +1,6 @@
 package okex
 
 import (
-	"errors"
 	"net/http"
 	"net/url"
 	"strings"
@@ -40,7 +39,7 @@ func (future *Future) GetAccount() (*FutureAccount, []byte, error) {
 		return nil, nil, err
 	}
 	if response.Code != "0" {
-		return nil, nil, errors.New(response.Msg)
+		return nil, nil, okexError(response.Code, response.Msg)
 	}
 
 	acc := new(FutureAccount)
@@ -109,7 +108,7 @@ func (future *Future) GetPairFlow(pair Pair) ([]*FutureAccountItem, []byte, erro
 		return nil, resp, err
 	}
 	if response.Code != "0" {
-		return nil, resp, errors.New(response.Msg)
+		return nil, resp, okexError(response.Code, response.Msg)
 	}
 
 	var items = make([]*FutureAccountItem, 0)
@@ -42,7 +42,7 @@ func (future *Future) GetContracts() ([]*FutureContract, []byte, error) {
 		return nil, resp, err
 	}
 	if response.Code != "0" {
-		return nil, resp, errors.New(response.Msg)
+		return nil, resp, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 {
 		return nil, resp, errors.New("The contract api not ready. ")
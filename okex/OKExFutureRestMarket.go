@@ -58,7 +58,7 @@ func (future *Future) GetTicker(pair Pair, contractType string) (*FutureTicker,
 		return nil, nil, err
 	}
 	if response.Code != "0" {
-		return nil, nil, errors.New(response.Msg)
+		return nil, nil, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 {
 		return nil, nil, errors.New("lack response data. ")
@@ -129,7 +129,7 @@ func (future *Future) GetDepth(
 		return nil, nil, err
 	}
 	if response.Code != "0" {
-		return nil, nil, errors.New(response.Msg)
+		return nil, nil, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 {
 		return nil, nil, errors.New("lack response data. ")
@@ -185,7 +185,7 @@ func (future *Future) GetLimit(pair Pair, contractType string) (float64, float64
 		return 0, 0, err
 	}
 	if response.Code != "0" {
-		return 0, 0, errors.New(response.Msg)
+		return 0, 0, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 {
 		return 0, 0, errors.New("lack response data. ")
@@ -219,6 +219,40 @@ func (future *Future) GetKlineRecords(
 	period,
 	size,
 	since int,
+) ([]*FutureKline, []byte, error) {
+	if _, exist := _INERNAL_V5_CANDLE_PERIOD_CONVERTER[period]; !exist {
+		return newV5KlineFallback(future.config.Location, 300).FutureKlines(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*FutureKline, []byte, error) {
+				return future.GetKlineRecords(contractType, pair, period, size, int(since))
+			},
+		)
+	}
+	return future.getKlineRecords(contractType, pair, period, size, since, 0)
+}
+
+// GetKlineRecordsBefore get the latest klines before the end, the kline backfill page the history backward by it.
+func (future *Future) GetKlineRecordsBefore(
+	contractType string,
+	pair Pair,
+	period,
+	size,
+	end int,
+) ([]*FutureKline, []byte, error) {
+	if _, exist := _INERNAL_V5_CANDLE_PERIOD_CONVERTER[period]; !exist {
+		return nil, nil, errors.New("The period is not supported before the end. ")
+	}
+	return future.getKlineRecords(contractType, pair, period, size, 0, end)
+}
+
+// the candles after the since or before the end, the since is used if both.
+func (future *Future) getKlineRecords(
+	contractType string,
+	pair Pair,
+	period,
+	size,
+	since,
+	end int,
 ) ([]*FutureKline, []byte, error) {
 	contract, err := future.GetContract(pair, contractType)
 	if err != nil {
@@ -239,6 +273,8 @@ func (future *Future) GetKlineRecords(
 		endTime := time.Now()
 		params.Set("before", strconv.Itoa(since))
 		params.Set("after", strconv.Itoa(int(endTime.UnixNano()/1000000)))
+	} else if end > 0 {
+		params.Set("after", strconv.Itoa(end))
 	}
 
 	var response struct {
@@ -256,7 +292,7 @@ func (future *Future) GetKlineRecords(
 		return nil, nil, err
 	}
 	if response.Code != "0" {
-		return nil, nil, errors.New(response.Msg)
+		return nil, nil, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 {
 		return make([]*FutureKline, 0), resp, nil
@@ -322,6 +358,14 @@ func (future *Future) GetCandles(
 	size,
 	since int,
 ) ([]*FutureCandle, []byte, error) {
+	if _, exist := _INERNAL_V5_CANDLE_PERIOD_CONVERTER[period]; !exist {
+		return newV5KlineFallback(future.config.Location, 300).FutureCandles(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*FutureCandle, []byte, error) {
+				return future.GetCandles(dueTimestamp, symbol, period, size, int(since))
+			},
+		)
+	}
 
 	var ct, err = future.getContractByDueTimestamp(symbol, dueTimestamp)
 	if err != nil {
@@ -360,7 +404,7 @@ func (future *Future) GetCandles(
 		return nil, nil, err
 	}
 	if response.Code != "0" {
-		return nil, nil, errors.New(response.Msg)
+		return nil, nil, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 {
 		return make([]*FutureCandle, 0), resp, nil
@@ -419,7 +463,7 @@ func (future *Future) GetIndex(pair Pair) (float64, []byte, error) {
 		return 0, resp, err
 	}
 	if response.Code != "0" {
-		return 0, resp, errors.New(response.Msg)
+		return 0, resp, okexError(response.Code, response.Msg)
 	}
 
 	return response.Data[0].IdxPx, resp, nil
@@ -451,7 +495,7 @@ func (future *Future) GetMark(pair Pair, contractType string) (float64, []byte,
 		return 0, resp, err
 	}
 	if response.Code != "0" {
-		return 0, resp, errors.New(response.Msg)
+		return 0, resp, okexError(response.Code, response.Msg)
 	}
 
 	return response.Data[0].MarkPx, resp, nil
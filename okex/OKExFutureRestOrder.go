@@ -73,10 +73,10 @@ func (future *Future) PlaceOrder(order *FutureOrder) ([]byte, error) {
 		return resp, err
 	}
 	if len(response.Data) > 0 && response.Data[0].SCode != "0" {
-		return resp, errors.New(string(resp)) //todo 更好的获取错误码的方案
+		return resp, okexError(response.Data[0].SCode, string(resp)) //todo 更好的获取错误码的方案
 	}
 	if response.Code != "0" {
-		return resp, errors.New(string(resp))
+		return resp, okexError(response.Code, string(resp))
 	}
 
 	now = time.Now()
@@ -128,7 +128,7 @@ func (future *Future) GetOrder(order *FutureOrder) ([]byte, error) {
 		return resp, err
 	}
 	if response.Code != "0" {
-		return resp, errors.New(response.Msg)
+		return resp, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 || response.Data[0].State == "live" {
 		return resp, nil
@@ -203,7 +203,7 @@ func (future *Future) CancelOrder(order *FutureOrder) ([]byte, error) {
 		return resp, errors.New("request lack the data. ")
 	}
 	if len(response.Data) != 0 && response.Data[0].SCode != "0" {
-		return resp, errors.New(response.Data[0].SMsg)
+		return resp, okexError(response.Data[0].SCode, response.Data[0].SMsg)
 	}
 
 	return resp, nil
@@ -169,7 +169,7 @@ func (ok *OKExOne) GetProducts(tradeType string) ([]byte, []*Instrument, error)
 		return rawResp, nil, err
 	}
 	if response.Code != "0" {
-		return rawResp, nil, errors.New(response.Msg)
+		return rawResp, nil, okexError(response.Code, response.Msg)
 	}
 
 	return rawResp, response.Data, nil
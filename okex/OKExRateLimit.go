@@ -0,0 +1,76 @@
+package okex
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+type okexRateLimit struct {
+	limit         float64
+	interval      time.Duration
+	perInstrument bool
+}
+
+// The limit of the okex v5 endpoints, the trade endpoints are limited by the instId.
+// https://www.okx.com/docs-v5/en/#overview-rate-limits
+var _OKEX_RATE_LIMITS = map[string]*okexRateLimit{
+	"POST /api/v5/trade/order":            {60, 2 * time.Second, true},
+	"GET /api/v5/trade/order":             {60, 2 * time.Second, true},
+	"/api/v5/trade/cancel-order":          {60, 2 * time.Second, true},
+	"/api/v5/trade/amend-order":           {60, 2 * time.Second, true},
+	"/api/v5/trade/orders-pending":        {60, 2 * time.Second, false},
+	"/api/v5/trade/orders-history":        {40, 2 * time.Second, false},
+	"/api/v5/trade/fills":                 {60, 2 * time.Second, false},
+	"/api/v5/account/balance":             {10, 2 * time.Second, false},
+	"/api/v5/account/positions":           {10, 2 * time.Second, false},
+	"/api/v5/account/bills":               {5, time.Second, false},
+	"/api/v5/account/bills-archive":       {5, 2 * time.Second, false},
+	"/api/v5/account/set-leverage":        {20, 2 * time.Second, false},
+	"/api/v5/asset/balances":              {6, time.Second, false},
+	"/api/v5/asset/currencies":            {6, time.Second, false},
+	"/api/v5/asset/withdrawal":            {6, time.Second, false},
+	"/api/v5/market/ticker":               {20, 2 * time.Second, false},
+	"/api/v5/market/tickers":              {20, 2 * time.Second, false},
+	"/api/v5/market/books":                {40, 2 * time.Second, false},
+	"/api/v5/market/candles":              {40, 2 * time.Second, false},
+	"/api/v5/market/history-candles":      {20, 2 * time.Second, false},
+	"/api/v5/market/trades":               {100, 2 * time.Second, false},
+	"/api/v5/market/index-tickers":        {20, 2 * time.Second, false},
+	"/api/v5/public/instruments":          {20, 2 * time.Second, false},
+	"/api/v5/public/mark-price":           {10, 2 * time.Second, false},
+	"/api/v5/public/price-limit":          {20, 2 * time.Second, false},
+	"/api/v5/public/open-interest":        {20, 2 * time.Second, false},
+	"/api/v5/public/funding-rate":         {20, 2 * time.Second, false},
+	"/api/v5/public/funding-rate-history": {10, 2 * time.Second, false},
+}
+
+// newRateLimiter every endpoint has its own rule, the rule name is the key of the endpoint.
+func newRateLimiter(config *APIConfig) *RateLimiter {
+	var rules = make(map[string]*RateRule)
+	var costs = make(map[string][]*RateCost)
+	for key, limit := range _OKEX_RATE_LIMITS {
+		rules[key] = &RateRule{Limit: limit.limit, Interval: limit.interval}
+		costs[key] = []*RateCost{{Rule: key, Weight: 1, PerInstrument: limit.perInstrument}}
+	}
+	return NewRateLimiter(OKEX, config.RateLimit, rules, costs)
+}
+
+// okexInstId the instId in the query or the json body.
+func okexInstId(uri, reqBody string) string {
+	if i := strings.Index(uri, "?"); i >= 0 {
+		if values, err := url.ParseQuery(uri[i+1:]); err == nil && values.Get("instId") != "" {
+			return values.Get("instId")
+		}
+	}
+	var body = struct {
+		InstId string `json:"instId"`
+	}{}
+	if strings.HasPrefix(strings.TrimSpace(reqBody), "{") && json.Unmarshal([]byte(reqBody), &body) == nil {
+		return body.InstId
+	}
+	return ""
+}
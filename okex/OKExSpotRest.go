@@ -156,6 +156,27 @@ func (spot *Spot) GetDepth(pair Pair, size int) (*Depth, []byte, error) {
 }
 
 func (spot *Spot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline, []byte, error) {
+	if _, exist := _INERNAL_KLINE_PERIOD_CONVERTER[period]; !exist {
+		return newKlineFallback(spot.config.Location).Klines(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*Kline, []byte, error) {
+				return spot.GetKlineRecords(pair, period, size, int(since))
+			},
+		)
+	}
+	return spot.getKlineRecords(pair, period, size, since, 0)
+}
+
+// GetKlineRecordsBefore get the latest klines before the end, the kline backfill page the history backward by it.
+func (spot *Spot) GetKlineRecordsBefore(pair Pair, period, size, end int) ([]*Kline, []byte, error) {
+	if _, exist := _INERNAL_KLINE_PERIOD_CONVERTER[period]; !exist {
+		return nil, nil, errors.New("The period is not supported before the end. ")
+	}
+	return spot.getKlineRecords(pair, period, size, 0, end)
+}
+
+// the candles after the since or before the end, the since is used if both.
+func (spot *Spot) getKlineRecords(pair Pair, period, size, since, end int) ([]*Kline, []byte, error) {
 	uri := fmt.Sprintf(
 		"/api/spot/v3/instruments/%s/candles?",
 		pair.ToSymbol("-", true),
@@ -175,6 +196,9 @@ func (spot *Spot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline,
 		endTime := time.Now().UTC()
 		params.Add("start", sinceTime.Format(time.RFC3339))
 		params.Add("end", endTime.Format(time.RFC3339))
+	} else if end > 0 {
+		// the end of the candles is included in the v3 api.
+		params.Add("end", time.UnixMilli(int64(end)-1).UTC().Format(time.RFC3339))
 	}
 	granularity, isExist := _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	if !isExist {
@@ -357,7 +381,7 @@ func (spot *Spot) updateInstruments() ([]byte, error) {
 		return resp, err
 	}
 	if response.Code != "0" {
-		return resp, errors.New(response.Msg)
+		return resp, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 {
 		return resp, errors.New("The api data not ready. ")
@@ -1,7 +1,6 @@
 package okex
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -75,10 +74,10 @@ func (spot *Spot) PlaceOrder(order *Order) ([]byte, error) {
 		return resp, err
 	}
 	if len(response.Data) > 0 && response.Data[0].SCode != "0" {
-		return resp, errors.New(string(resp)) // very important cause it has the error code
+		return resp, okexError(response.Data[0].SCode, string(resp)) // very important cause it has the error code
 	}
 	if response.Code != "0" {
-		return resp, errors.New(string(resp)) // very important cause it has the error code
+		return resp, okexError(response.Code, string(resp)) // very important cause it has the error code
 	}
 
 	now = time.Now()
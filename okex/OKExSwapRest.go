@@ -29,10 +29,6 @@ func (swap *Swap) getAmount(price, amountContract float64, contract *SwapContrac
 	return amount
 }
 
-func (swap *Swap) GetAccount() (*SwapAccount, []byte, error) {
-	panic("implement me")
-}
-
 var _INERNAL_V5_FUTURE_TYPE_CONVERTER = map[FutureType][]string{
 	OPEN_LONG:       {"buy", "long"},
 	OPEN_SHORT:      {"sell", "short"},
@@ -99,10 +95,10 @@ func (swap *Swap) PlaceOrder(order *SwapOrder) ([]byte, error) {
 		return resp, err
 	}
 	if len(response.Data) > 0 && response.Data[0].SCode != "0" {
-		return resp, errors.New(string(resp)) // very important cause it has the error code
+		return resp, okexError(response.Data[0].SCode, string(resp)) // very important cause it has the error code
 	}
 	if response.Code != "0" {
-		return resp, errors.New(string(resp)) // very important cause it has the error code
+		return resp, okexError(response.Code, string(resp)) // very important cause it has the error code
 	}
 
 	now = time.Now()
@@ -149,17 +145,13 @@ func (swap *Swap) CancelOrder(order *SwapOrder) ([]byte, error) {
 		return resp, errors.New("request lack the data. ")
 	}
 	if len(response.Data) != 0 && response.Data[0].SCode != "0" {
-		return resp, errors.New(response.Data[0].SMsg)
+		return resp, okexError(response.Data[0].SCode, response.Data[0].SMsg)
 	}
 
 	return resp, nil
 
 }
 
-func (swap *Swap) GetOrders(pair Pair) ([]*SwapOrder, []byte, error) {
-	panic("implement me")
-}
-
 var _INERNAL_V5_FUTURE_ORDER_STATUE_CONVERTER = map[string]TradeStatus{
 	"canceled":         ORDER_CANCEL,
 	"live":             ORDER_UNFINISH,
@@ -203,7 +195,7 @@ func (swap *Swap) GetOrder(order *SwapOrder) ([]byte, error) {
 		return resp, err
 	}
 	if response.Code != "0" {
-		return resp, errors.New(response.Msg)
+		return resp, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 || response.Data[0].State == "live" {
 		return resp, nil
@@ -233,14 +225,6 @@ func (swap *Swap) GetOrder(order *SwapOrder) ([]byte, error) {
 
 }
 
-func (swap *Swap) GetUnFinishOrders(pair Pair) ([]*SwapOrder, []byte, error) {
-	panic("implement me")
-}
-
-func (swap *Swap) GetPosition(pair Pair, openType FutureType) (*SwapPosition, []byte, error) {
-	panic("implement me")
-}
-
 func (swap *Swap) getContract(pair Pair) *SwapContract {
 	defer swap.Unlock()
 	swap.Lock()
@@ -290,7 +274,7 @@ func (swap *Swap) updateContracts() ([]byte, error) {
 		return resp, err
 	}
 	if response.Code != "0" {
-		return resp, errors.New(response.Msg)
+		return resp, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 {
 		return resp, errors.New("The api data not ready. ")
@@ -2,7 +2,6 @@ package okex
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/url"
 	"strings"
@@ -48,7 +47,7 @@ func (swap *Swap) GetAccountFlow() ([]*SwapAccountItem, []byte, error) {
 		return nil, resp, err
 	}
 	if response.Code != "0" {
-		return nil, resp, errors.New(response.Msg)
+		return nil, resp, okexError(response.Code, response.Msg)
 	}
 
 	var items = make([]*SwapAccountItem, 0)
@@ -141,7 +140,7 @@ func (swap *Swap) GetPairFlow(pair Pair) ([]*SwapAccountItem, []byte, error) {
 		return nil, resp, err
 	}
 	if response.Code != "0" {
-		return nil, resp, errors.New(response.Msg)
+		return nil, resp, okexError(response.Code, response.Msg)
 	}
 
 	var items = make([]*SwapAccountItem, 0)
@@ -192,3 +191,163 @@ func (swap *Swap) GetPairFlow(pair Pair) ([]*SwapAccountItem, []byte, error) {
 	}
 	return items, resp, nil
 }
+
+// GetAccount the usdt margin account in cross mode, the positions are the swap positions.
+// The coin margined balances are not in the account, only the positions of them are.
+func (swap *Swap) GetAccount() (*SwapAccount, []byte, error) {
+	var params = url.Values{}
+	params.Set("ccy", USDT.Symbol)
+	var response = struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Details []struct {
+				Ccy       string `json:"ccy"`
+				Eq        string `json:"eq"`
+				CashBal   string `json:"cashBal"`
+				AvailEq   string `json:"availEq"`
+				AvailBal  string `json:"availBal"`
+				FrozenBal string `json:"frozenBal"`
+				OrdFrozen string `json:"ordFrozen"`
+				MgnRatio  string `json:"mgnRatio"`
+				Upl       string `json:"upl"`
+			} `json:"details"`
+		} `json:"data"`
+	}{}
+	var uri = "/api/v5/account/balance?"
+	resp, err := swap.DoRequest(
+		http.MethodGet,
+		uri+params.Encode(),
+		"",
+		&response,
+	)
+	if err != nil {
+		return nil, resp, err
+	}
+	if response.Code != "0" {
+		return nil, resp, okexError(response.Code, response.Msg)
+	}
+
+	var account = &SwapAccount{
+		Exchange:  OKEX,
+		Currency:  USDT,
+		Positions: make([]*SwapPosition, 0),
+	}
+	for _, data := range response.Data {
+		for _, detail := range data.Details {
+			if detail.Ccy != USDT.Symbol {
+				continue
+			}
+			// the availEq is empty in the simple account mode.
+			var avail = ToFloat64(detail.AvailEq)
+			if detail.AvailEq == "" {
+				avail = ToFloat64(detail.AvailBal)
+			}
+			account.Margin = ToFloat64(detail.FrozenBal)
+			account.MarginPosition = ToFloat64(detail.FrozenBal) - ToFloat64(detail.OrdFrozen)
+			account.MarginOpen = ToFloat64(detail.OrdFrozen)
+			account.MarginRate = ToFloat64(detail.MgnRatio)
+			account.BalanceTotal = ToFloat64(detail.CashBal)
+			account.BalanceNet = ToFloat64(detail.Eq)
+			account.BalanceAvail = avail
+			account.ProfitUnreal = ToFloat64(detail.Upl)
+		}
+	}
+
+	positions, _, err := swap.getPositions("")
+	if err != nil {
+		return nil, resp, err
+	}
+	account.Positions = positions
+	return account, resp, nil
+}
+
+// GetPosition the empty position is returned when there is no position of the type.
+func (swap *Swap) GetPosition(pair Pair, openType FutureType) (*SwapPosition, []byte, error) {
+	positions, resp, err := swap.getPositions(pair.ToSymbol("-", true) + "-SWAP")
+	if err != nil {
+		return nil, resp, err
+	}
+	for _, position := range positions {
+		if position.Type == openType && position.Pair.ToSwapContractName() == pair.ToSwapContractName() {
+			return position, resp, nil
+		}
+	}
+	return &SwapPosition{Pair: pair, Type: openType}, resp, nil
+}
+
+// getPositions the positions of all the swap when the instId is empty.
+// The position in net mode is long or short by the sign of the pos.
+func (swap *Swap) getPositions(instId string) ([]*SwapPosition, []byte, error) {
+	var params = url.Values{}
+	params.Set("instType", "SWAP")
+	if instId != "" {
+		params.Set("instId", instId)
+	}
+	var response = struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			InstId  string `json:"instId"`
+			PosSide string `json:"posSide"`
+			Pos     string `json:"pos"`
+			AvgPx   string `json:"avgPx"`
+			MarkPx  string `json:"markPx"`
+			LiqPx   string `json:"liqPx"`
+			MgnMode string `json:"mgnMode"`
+			Margin  string `json:"margin"`
+			Imr     string `json:"imr"`
+			Lever   string `json:"lever"`
+		} `json:"data"`
+	}{}
+	var uri = "/api/v5/account/positions?"
+	resp, err := swap.DoRequest(
+		http.MethodGet,
+		uri+params.Encode(),
+		"",
+		&response,
+	)
+	if err != nil {
+		return nil, resp, err
+	}
+	if response.Code != "0" {
+		return nil, resp, okexError(response.Code, response.Msg)
+	}
+
+	var positions = make([]*SwapPosition, 0, len(response.Data))
+	for _, item := range response.Data {
+		var amount = ToFloat64(item.Pos)
+		if amount == 0 {
+			continue
+		}
+		var positionType = OPEN_LONG
+		if item.PosSide == "short" || (item.PosSide == "net" && amount < 0) {
+			positionType = OPEN_SHORT
+		}
+		if amount < 0 {
+			amount = -amount
+		}
+
+		// the margin is for isolated, the imr is for cross.
+		var marginType, marginAmount = CROSS, ToFloat64(item.Imr)
+		if item.MgnMode == ISOLATED {
+			marginType, marginAmount = ISOLATED, ToFloat64(item.Margin)
+		}
+		var pairInfo = strings.Split(item.InstId, "-")
+		if len(pairInfo) < 2 {
+			continue
+		}
+		positions = append(positions, &SwapPosition{
+			Pair:           NewPair(pairInfo[0]+"-"+pairInfo[1], "-"),
+			Type:           positionType,
+			Amount:         amount,
+			Price:          ToFloat64(item.AvgPx),
+			MarkPrice:      ToFloat64(item.MarkPx),
+			LiquidatePrice: ToFloat64(item.LiqPx),
+			MarginType:     marginType,
+			MarginAmount:   marginAmount,
+			Leverage:       ToInt64(ToFloat64(item.Lever)),
+		})
+	}
+	return positions, resp, nil
+}
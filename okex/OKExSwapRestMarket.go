@@ -43,7 +43,7 @@ func (swap *Swap) GetTicker(pair Pair) (*SwapTicker, []byte, error) {
 		return nil, nil, err
 	}
 	if response.Code != "0" {
-		return nil, nil, errors.New(response.Msg)
+		return nil, nil, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 {
 		err = errors.New("lack response data. ")
@@ -93,7 +93,7 @@ func (swap *Swap) GetDepth(pair Pair, size int) (*SwapDepth, []byte, error) {
 		return nil, nil, err
 	}
 	if response.Code != "0" {
-		return nil, nil, errors.New(response.Msg)
+		return nil, nil, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 {
 		err = errors.New("lack response data. ")
@@ -127,7 +127,27 @@ func (swap *Swap) GetDepth(pair Pair, size int) (*SwapDepth, []byte, error) {
 }
 
 func (swap *Swap) GetKline(pair Pair, period, size, since int) ([]*SwapKline, []byte, error) {
+	if _, exist := _INERNAL_V5_CANDLE_PERIOD_CONVERTER[period]; !exist {
+		return newV5KlineFallback(swap.config.Location, 100).SwapKlines(
+			period, size, int64(since),
+			func(period, size int, since int64) ([]*SwapKline, []byte, error) {
+				return swap.GetKline(pair, period, size, int(since))
+			},
+		)
+	}
+	return swap.getKline(pair, period, size, since, 0)
+}
+
+// GetKlineBefore get the latest klines before the end, the kline backfill page the history backward by it.
+func (swap *Swap) GetKlineBefore(pair Pair, period, size, end int) ([]*SwapKline, []byte, error) {
+	if _, exist := _INERNAL_V5_CANDLE_PERIOD_CONVERTER[period]; !exist {
+		return nil, nil, errors.New("The period is not supported before the end. ")
+	}
+	return swap.getKline(pair, period, size, 0, end)
+}
 
+// the candles after the since or before the end, the since is used if both.
+func (swap *Swap) getKline(pair Pair, period, size, since, end int) ([]*SwapKline, []byte, error) {
 	if size > 100 {
 		size = 100
 	}
@@ -139,6 +159,8 @@ func (swap *Swap) GetKline(pair Pair, period, size, since int) ([]*SwapKline, []
 		endTime := time.Now()
 		params.Set("before", strconv.Itoa(since))
 		params.Set("after", strconv.Itoa(int(endTime.UnixNano()/1000000)))
+	} else if end > 0 {
+		params.Set("after", strconv.Itoa(end))
 	}
 
 	var uri = "/api/v5/market/candles?" + params.Encode()
@@ -157,7 +179,7 @@ func (swap *Swap) GetKline(pair Pair, period, size, since int) ([]*SwapKline, []
 		return nil, nil, err
 	}
 	if response.Code != "0" {
-		return nil, nil, errors.New(response.Msg)
+		return nil, nil, okexError(response.Code, response.Msg)
 	}
 
 	var klines []*SwapKline
@@ -214,7 +236,7 @@ func (swap *Swap) GetLimit(pair Pair) (float64, float64, error) {
 		return 0, 0, err
 	}
 	if response.Code != "0" {
-		return 0, 0, errors.New(response.Msg)
+		return 0, 0, okexError(response.Code, response.Msg)
 	}
 	if len(response.Data) == 0 {
 		return 0, 0, errors.New("lack response data. ")
@@ -82,3 +82,28 @@ func TestSwap_MarketAPI(t *testing.T) {
 	t.Log(string(content))
 
 }
+
+// go test -v ./okex/... -count=1 -run=TestSwap_KlineFallbackMaxSize
+func TestSwap_KlineFallbackMaxSize(t *testing.T) {
+	var ok = New(&APIConfig{
+		Endpoint:   ENDPOINT,
+		HttpClient: &http.Client{},
+		Location:   time.UTC,
+	})
+	// the 8H is resampled from the 4H, the 50 klines need 102 candles, more than the 100 of the swap.
+	if _, _, err := ok.Swap.GetKline(Pair{Basis: BTC, Counter: USDT}, KLINE_PERIOD_8H, 50, 0); err == nil {
+		t.Error("The size over the max size should be an error. ")
+	}
+	// the future allow 300 candles.
+	if _, _, err := newV5KlineFallback(time.UTC, 300).FutureKlines(
+		KLINE_PERIOD_8H, 50, 0,
+		func(period, size int, since int64) ([]*FutureKline, []byte, error) {
+			if period != KLINE_PERIOD_4H || size != 102 {
+				t.Error("The source request is wrong: ", period, size)
+			}
+			return nil, nil, nil
+		},
+	); err != nil {
+		t.Error(err)
+	}
+}
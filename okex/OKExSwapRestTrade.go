@@ -1 +1,111 @@
 package okex
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+var _INERNAL_V5_FUTURE_PLACE_TYPE_REVERTER = map[string]PlaceType{
+	"limit":     NORMAL,
+	"post_only": ONLY_MAKER,
+	"fok":       FOK,
+	"ioc":       IOC,
+	"market":    MARKET,
+}
+
+// the order of v5 in orders-pending and orders-history.
+type swapOrderV5 struct {
+	InstId    string `json:"instId"`
+	OrdId     string `json:"ordId"`
+	ClOrdId   string `json:"clOrdId"`
+	Px        string `json:"px"`
+	Sz        string `json:"sz"`
+	AvgPx     string `json:"avgPx"`
+	AccFillSz string `json:"accFillSz"`
+	State     string `json:"state"`
+	Side      string `json:"side"`
+	PosSide   string `json:"posSide"`
+	OrdType   string `json:"ordType"`
+	TdMode    string `json:"tdMode"`
+	Lever     string `json:"lever"`
+	Fee       string `json:"fee"`
+	UTime     int64  `json:"uTime,string"`
+	CTime     int64  `json:"cTime,string"`
+}
+
+func (this *swapOrderV5) New(location *time.Location) *SwapOrder {
+	var pairInfo = strings.Split(this.InstId, "-")
+	var order = &SwapOrder{
+		Cid:        this.ClOrdId,
+		OrderId:    this.OrdId,
+		Price:      ToFloat64(this.Px),
+		Amount:     ToFloat64(this.Sz),
+		AvgPrice:   ToFloat64(this.AvgPx),
+		DealAmount: ToFloat64(this.AccFillSz),
+		Status:     _INERNAL_V5_FUTURE_ORDER_STATUE_CONVERTER[this.State],
+		PlaceType:  _INERNAL_V5_FUTURE_PLACE_TYPE_REVERTER[this.OrdType],
+		MarginType: this.TdMode,
+		LeverRate:  ToInt64(ToFloat64(this.Lever)),
+		Fee:        ToFloat64(this.Fee),
+		Exchange:   OKEX,
+
+		PlaceTimestamp: this.CTime,
+		PlaceDatetime:  time.Unix(this.CTime/1000, 0).In(location).Format(GO_BIRTHDAY),
+		DealTimestamp:  this.UTime,
+		DealDatetime:   time.Unix(this.UTime/1000, 0).In(location).Format(GO_BIRTHDAY),
+	}
+	if len(pairInfo) >= 2 {
+		order.Pair = NewPair(pairInfo[0]+"-"+pairInfo[1], "-")
+	}
+	for futureType, sideInfo := range _INERNAL_V5_FUTURE_TYPE_CONVERTER {
+		if sideInfo[0] == this.Side && sideInfo[1] == this.PosSide {
+			order.Type = futureType
+		}
+	}
+	return order
+}
+
+// GetOrders the recent finished orders in 7 days, include the filled and the canceled.
+func (swap *Swap) GetOrders(pair Pair) ([]*SwapOrder, []byte, error) {
+	return swap.getOrders(pair, "/api/v5/trade/orders-history?")
+}
+
+func (swap *Swap) GetUnFinishOrders(pair Pair) ([]*SwapOrder, []byte, error) {
+	return swap.getOrders(pair, "/api/v5/trade/orders-pending?")
+}
+
+// getOrders only the first page of the latest 100 orders, the older orders are not paged.
+func (swap *Swap) getOrders(pair Pair, uri string) ([]*SwapOrder, []byte, error) {
+	var params = url.Values{}
+	params.Set("instType", "SWAP")
+	params.Set("limit", "100")
+	params.Set("instId", pair.ToSymbol("-", true)+"-SWAP")
+
+	var response = struct {
+		Code string         `json:"code"`
+		Msg  string         `json:"msg"`
+		Data []*swapOrderV5 `json:"data"`
+	}{}
+	resp, err := swap.DoRequest(
+		http.MethodGet,
+		uri+params.Encode(),
+		"",
+		&response,
+	)
+	if err != nil {
+		return nil, resp, err
+	}
+	if response.Code != "0" {
+		return nil, resp, okexError(response.Code, response.Msg)
+	}
+
+	var orders = make([]*SwapOrder, 0, len(response.Data))
+	for _, item := range response.Data {
+		orders = append(orders, item.New(swap.config.Location))
+	}
+	return orders, resp, nil
+}
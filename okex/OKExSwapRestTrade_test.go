@@ -0,0 +1,79 @@
+package okex
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+var _ SwapRestAPI = (*Swap)(nil)
+
+// go test -v ./okex/... -count=1 -run=TestSwapOrderV5_New
+func TestSwapOrderV5_New(t *testing.T) {
+	var raw = `{"instId":"BTC-USDT-SWAP","ordId":"312269865356374016","clOrdId":"b1","px":"30000","sz":"10",
+		"avgPx":"29999.5","accFillSz":"4","state":"partially_filled","side":"sell","posSide":"long",
+		"ordType":"post_only","tdMode":"cross","lever":"5","fee":"-0.01","uTime":"1597026383085","cTime":"1597026383000"}`
+	var remote = &swapOrderV5{}
+	if err := json.Unmarshal([]byte(raw), remote); err != nil {
+		t.Fatal(err)
+	}
+	var order = remote.New(time.UTC)
+	if order.OrderId != "312269865356374016" || order.Cid != "b1" || order.Type != LIQUIDATE_LONG ||
+		order.PlaceType != ONLY_MAKER || order.Status != ORDER_PART_FINISH || order.MarginType != CROSS {
+		t.Error("The order is wrong. ", order)
+	}
+	if order.Amount != 10 || order.DealAmount != 4 || order.AvgPrice != 29999.5 || order.LeverRate != 5 ||
+		order.Fee != -0.01 || order.Pair.ToSwapContractName() != "BTC-USDT-SWAP" {
+		t.Error("The deal is wrong. ", order)
+	}
+	if order.PlaceTimestamp != 1597026383000 || order.DealTimestamp != 1597026383085 {
+		t.Error("The timestamp is wrong. ", order)
+	}
+}
+
+// go test -v ./okex/... -count=1 -run=TestSwap_GetPosition
+func TestSwap_GetPosition(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/v5/account/positions"):
+			_, _ = w.Write([]byte(`{"code":"0","msg":"","data":[
+				{"instId":"BTC-USDT-SWAP","posSide":"net","pos":"-3","avgPx":"30000","markPx":"30100",
+				 "liqPx":"45000","mgnMode":"isolated","margin":"180","imr":"","lever":"5"},
+				{"instId":"ETH-USDT-SWAP","posSide":"long","pos":"2","avgPx":"2000","markPx":"2010",
+				 "liqPx":"1500","mgnMode":"cross","margin":"","imr":"40.2","lever":"10"}]}`))
+		case strings.HasPrefix(r.URL.Path, "/api/v5/account/balance"):
+			_, _ = w.Write([]byte(`{"code":"0","msg":"","data":[{"details":[{"ccy":"USDT","eq":"1010",
+				"cashBal":"1000","availEq":"700","frozenBal":"300","ordFrozen":"80","mgnRatio":"12.5","upl":"10"}]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var ok = New(&APIConfig{Endpoint: server.URL, HttpClient: server.Client(), Location: time.UTC})
+	var position, _, err = ok.Swap.GetPosition(Pair{Basis: BTC, Counter: USDT}, OPEN_SHORT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position.Amount != 3 || position.Price != 30000 || position.LiquidatePrice != 45000 ||
+		position.MarginType != ISOLATED || position.MarginAmount != 180 || position.Leverage != 5 {
+		t.Error("The short position is wrong. ", position)
+	}
+	if position, _, _ = ok.Swap.GetPosition(Pair{Basis: BTC, Counter: USDT}, OPEN_LONG); position.Amount != 0 {
+		t.Error("The long position should be empty. ", position)
+	}
+
+	var account *SwapAccount
+	if account, _, err = ok.Swap.GetAccount(); err != nil {
+		t.Fatal(err)
+	}
+	if account.BalanceNet != 1010 || account.BalanceAvail != 700 || account.MarginPosition != 220 ||
+		account.MarginOpen != 80 || len(account.Positions) != 2 || account.Positions[1].MarginAmount != 40.2 {
+		t.Error("The account is wrong. ", account)
+	}
+}
@@ -2,26 +2,35 @@ package okex
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"sort"
-	"strconv"
 	"sync"
 	"time"
 
 	. "github.com/deforceHK/goghostex"
 )
 
+// LocalOrderBooks every instrument has its own book and lock, the booksMux only guard the map of the books,
+// so the readers of the different instruments don't wait for each other. The levels of the book are sorted
+// in the update, the snapshot and the top of book are read without sorting.
+// Breaking change: the exported BidData, AskData, SeqData, TsData maps and the OrderBookMux are replaced by
+// the read accessors of the same names, eg: books.BidData[instId] is books.BidData(instId) now. The accessors
+// return the copies, use the BookDataById to read the levels, seq and ts of the product consistently, it's what
+// the OrderBookMux was locked for.
 type LocalOrderBooks struct {
 	*WSMarketOKEx
-	BidData      map[string]map[int64]float64
-	AskData      map[string]map[int64]float64
-	SeqData      map[string]int64
-	TsData       map[string]int64
-	OrderBookMux *sync.RWMutex
 
 	// if the channel is not nil, send the update message to the channel. User should read the channel in the loop.
 	UpdateChan chan string
+	// if the channel is not nil, send the level changes to the channel after the checksum passed.
+	LevelChan chan *BookLevelEvent
+	// ResubscribeHandler rebuild the book of the product when the sequence gap or the checksum mismatch found,
+	// the default is the Resubscribe.
+	ResubscribeHandler func(productId string)
+
+	books    map[string]*instrumentBook
+	booksMux sync.RWMutex
 }
 
 type OKBook struct {
@@ -41,105 +50,193 @@ type OKBook struct {
 }
 
 func (this *LocalOrderBooks) Init() error {
-	if this.OrderBookMux == nil {
-		this.OrderBookMux = &sync.RWMutex{}
-	}
-	if this.BidData == nil {
-		this.BidData = make(map[string]map[int64]float64)
-	}
-	if this.AskData == nil {
-		this.AskData = make(map[string]map[int64]float64)
+	this.initBooks()
+	this.WSMarketOKEx.RecvHandler = func(s string) {
+		this.Receiver(s)
 	}
-	if this.SeqData == nil {
-		this.SeqData = make(map[string]int64)
+	return this.Start()
+}
+
+func (this *LocalOrderBooks) initBooks() {
+	this.booksMux.Lock()
+	defer this.booksMux.Unlock()
+	if this.books == nil {
+		this.books = make(map[string]*instrumentBook)
 	}
-	if this.TsData == nil {
-		this.TsData = make(map[string]int64)
+}
+
+// getBook return the book of the product, create it if the create is true.
+func (this *LocalOrderBooks) getBook(productId string, create bool) *instrumentBook {
+	this.booksMux.RLock()
+	var book = this.books[productId]
+	this.booksMux.RUnlock()
+	if book != nil || !create {
+		return book
 	}
 
-	this.WSMarketOKEx.RecvHandler = func(s string) {
-		this.Receiver(s)
+	this.booksMux.Lock()
+	defer this.booksMux.Unlock()
+	if book = this.books[productId]; book == nil {
+		book = newInstrumentBook()
+		this.books[productId] = book
 	}
-	return this.Start()
+	return book
 }
 
 func (this *LocalOrderBooks) Receiver(msg string) {
-	this.OrderBookMux.Lock()
-	//defer this.OrderBookMux.Unlock()
 	var rawData = []byte(msg)
 	var delta = OKBook{}
 	_ = json.Unmarshal(rawData, &delta)
 
-	if delta.Action == "snapshot" || delta.Action == "update" {
-		var instId = delta.Arg.InstId
-		var seqId = delta.Data[0].SeqId
-		var prevSeqId = delta.Data[0].PrevSeqId
-		var timestamp = delta.Data[0].Timestamp
-
-		if delta.Action == "snapshot" {
-			var bidData = make(map[int64]float64)
-			var askData = make(map[int64]float64)
-
-			for _, bid := range delta.Data[0].Bids {
-				var price, _ = strconv.ParseFloat(bid[0], 64)
-				var stdPrice = int64(price * 100000000)
-				var amount, _ = strconv.ParseFloat(bid[1], 64)
-				bidData[stdPrice] = amount
-			}
-
-			for _, ask := range delta.Data[0].Asks {
-				var price, _ = strconv.ParseFloat(ask[0], 64)
-				var stdPrice = int64(price * 100000000)
-				var amount, _ = strconv.ParseFloat(ask[1], 64)
-				askData[stdPrice] = amount
-			}
-
-			this.BidData[instId] = bidData
-			this.AskData[instId] = askData
-			this.SeqData[instId] = seqId
-			this.TsData[instId] = timestamp
-		} else {
-			if prevSeqId != this.SeqData[instId] {
-				log.Println(fmt.Sprintf(
-					"The prevSeqId %d is not equal to the last seqId %d, in product %s. ",
-					prevSeqId, this.SeqData[instId], instId,
-				))
-				this.OrderBookMux.Unlock()
-				this.Resubscribe(instId)
-				return
-			}
-
-			for _, bid := range delta.Data[0].Bids {
-				var price, _ = strconv.ParseFloat(bid[0], 64)
-				var stdPrice = int64(price * 100000000)
-				var amount, _ = strconv.ParseFloat(bid[1], 64)
-				this.BidData[instId][stdPrice] = amount
-				if amount == 0 {
-					delete(this.BidData[instId], stdPrice)
-				}
-			}
-
-			for _, ask := range delta.Data[0].Asks {
-				var price, _ = strconv.ParseFloat(ask[0], 64)
-				var stdPrice = int64(price * 100000000)
-				var amount, _ = strconv.ParseFloat(ask[1], 64)
-				this.AskData[instId][stdPrice] = amount
-				if amount == 0 {
-					delete(this.AskData[instId], stdPrice)
-				}
-			}
-			this.SeqData[instId] = seqId
-			this.TsData[instId] = timestamp
-
-			if this.UpdateChan != nil {
-				this.UpdateChan <- fmt.Sprintf("%s:%d", instId, timestamp)
-			}
-		}
-	} else {
+	if delta.Action != "snapshot" && delta.Action != "update" {
 		fmt.Println(msg)
 		fmt.Println("The action must in snapshot/update. ")
+		return
+	}
+
+	var instId = delta.Arg.InstId
+	var seqId = delta.Data[0].SeqId
+	var prevSeqId = delta.Data[0].PrevSeqId
+	var timestamp = delta.Data[0].Timestamp
+	var event *BookLevelEvent
+	if this.LevelChan != nil {
+		event = &BookLevelEvent{
+			Exchange:     OKEX,
+			ProductId:    instId,
+			Snapshot:     delta.Action == "snapshot",
+			Sequence:     seqId,
+			PrevSequence: prevSeqId,
+			Timestamp:    timestamp,
+			Changes:      make([]BookLevelChange, 0, len(delta.Data[0].Bids)+len(delta.Data[0].Asks)),
+		}
+	}
+
+	var book = this.getBook(instId, delta.Action == "snapshot")
+	if book == nil {
+		log.Println(fmt.Sprintf("There is no snapshot of the product %s before the update. ", instId))
+		this.resubscribe(instId)
+		return
+	}
+
+	book.Lock()
+	if delta.Action == "snapshot" {
+		book.reset(delta.Data[0].Bids, delta.Data[0].Asks, seqId, timestamp, event)
+	} else {
+		// the book is resubscribing, wait for the snapshot.
+		if book.invalid {
+			book.Unlock()
+			return
+		}
+		if prevSeqId != book.seq {
+			log.Println(fmt.Sprintf(
+				"The prevSeqId %d is not equal to the last seqId %d, in product %s. ",
+				prevSeqId, book.seq, instId,
+			))
+			book.invalid = true
+			book.Unlock()
+			this.resubscribe(instId)
+			return
+		}
+		book.update(delta.Data[0].Bids, delta.Data[0].Asks, seqId, timestamp, event)
+	}
+
+	var checksum = delta.Data[0].Checksum
+	if actual := book.checksum(); actual != checksum {
+		book.checksumFails++
+		var checksumErr = &BookChecksumError{
+			Exchange:  OKEX,
+			ProductId: instId,
+			Expect:    checksum,
+			Actual:    actual,
+			FailCount: book.checksumFails,
+		}
+		book.invalid = true
+		book.Unlock()
+		this.ErrorHandler(checksumErr)
+		this.resubscribe(instId)
+		return
+	}
+	book.Unlock()
+
+	if delta.Action == "update" && this.UpdateChan != nil {
+		this.UpdateChan <- fmt.Sprintf("%s:%d", instId, timestamp)
+	}
+	if event != nil {
+		this.LevelChan <- event
+	}
+}
+
+// BookDataById return the copy of the bids and asks keyed by the price * 1e8, the seq and the ts of the product,
+// they are read in the same lock. The maps are nil if the product has no snapshot yet, or the book is invalid
+// after the sequence gap or the checksum mismatch.
+func (this *LocalOrderBooks) BookDataById(productId string) (map[int64]float64, map[int64]float64, int64, int64) {
+	var book = this.getBook(productId, false)
+	if book == nil {
+		return nil, nil, 0, 0
+	}
+	book.RLock()
+	defer book.RUnlock()
+	if book.invalid {
+		return nil, nil, 0, 0
 	}
-	this.OrderBookMux.Unlock()
+	return book.bids.data(), book.asks.data(), book.seq, book.ts
+}
+
+// BidData the copy of the bids of the product keyed by the price * 1e8, like the removed BidData[productId].
+func (this *LocalOrderBooks) BidData(productId string) map[int64]float64 {
+	var bids, _, _, _ = this.BookDataById(productId)
+	return bids
+}
+
+// AskData the copy of the asks of the product keyed by the price * 1e8, like the removed AskData[productId].
+func (this *LocalOrderBooks) AskData(productId string) map[int64]float64 {
+	var _, asks, _, _ = this.BookDataById(productId)
+	return asks
+}
+
+// SeqData the last seqId of the product, like the removed SeqData[productId].
+func (this *LocalOrderBooks) SeqData(productId string) int64 {
+	var book = this.getBook(productId, false)
+	if book == nil {
+		return 0
+	}
+	book.RLock()
+	defer book.RUnlock()
+	return book.seq
+}
+
+// TsData the timestamp of the last message of the product, like the removed TsData[productId].
+func (this *LocalOrderBooks) TsData(productId string) int64 {
+	var book = this.getBook(productId, false)
+	if book == nil {
+		return 0
+	}
+	book.RLock()
+	defer book.RUnlock()
+	return book.ts
+}
+
+// ChecksumFails return the checksum fail times of the product.
+func (this *LocalOrderBooks) ChecksumFails(productId string) int64 {
+	var book = this.getBook(productId, false)
+	if book == nil {
+		return 0
+	}
+	book.RLock()
+	defer book.RUnlock()
+	return book.checksumFails
+}
+
+func invalidBookError(productId string) error {
+	return fmt.Errorf("The order book of %s is invalid after the gap or the checksum mismatch, it's resubscribing. ", productId)
+}
+
+func (this *LocalOrderBooks) resubscribe(productId string) {
+	if this.ResubscribeHandler != nil {
+		this.ResubscribeHandler(productId)
+		return
+	}
+	this.Resubscribe(productId)
 }
 
 func (this *LocalOrderBooks) Resubscribe(productId string) {
@@ -153,7 +250,7 @@ func (this *LocalOrderBooks) Resubscribe(productId string) {
 		},
 	}
 
-	var err = this.conn.WriteJSON(unSub)
+	var err = this.WriteJSON(unSub)
 	if err != nil {
 		this.ErrorHandler(err)
 	}
@@ -169,44 +266,28 @@ func (this *LocalOrderBooks) Resubscribe(productId string) {
 		},
 	}
 
-	err = this.conn.WriteJSON(sub)
+	err = this.WriteJSON(sub)
 	if err != nil {
 		this.ErrorHandler(err)
 	}
 }
 
 func (this *LocalOrderBooks) Snapshot(pair Pair) (*SwapDepth, error) {
-	this.OrderBookMux.RLock()
-	defer this.OrderBookMux.RUnlock()
 	var symbol = pair.ToSymbol("-", true)
 	var productId = fmt.Sprintf("%s-SWAP", symbol)
 
-	var lastTime = time.UnixMilli(this.TsData[productId]).In(this.WSMarketOKEx.Config.Location)
-	var depth = &SwapDepth{
-		Pair:      pair,
-		Timestamp: this.TsData[productId],
-		Sequence:  this.SeqData[productId],
-		Date:      lastTime.Format(GO_BIRTHDAY),
-		AskList:   make(DepthRecords, 0),
-		BidList:   make(DepthRecords, 0),
-	}
-
-	for stdPrice, amount := range this.BidData[productId] {
-		depth.BidList = append(depth.BidList, DepthRecord{
-			Price:  float64(stdPrice) / 100000000,
-			Amount: amount,
-		})
-	}
-
-	for stdPrice, amount := range this.AskData[productId] {
-		depth.AskList = append(depth.AskList, DepthRecord{
-			Price:  float64(stdPrice) / 100000000,
-			Amount: amount,
-		})
+	var depth, err = this.SnapshotTopById(productId, 0)
+	if err != nil {
+		return nil, err
 	}
-	sort.Sort(sort.Reverse(depth.BidList))
-	sort.Sort(depth.AskList)
-	return depth, nil
+	return &SwapDepth{
+		Pair:      pair,
+		Timestamp: depth.Timestamp,
+		Sequence:  depth.Sequence,
+		Date:      depth.Date,
+		AskList:   depth.AskList,
+		BidList:   depth.BidList,
+	}, nil
 }
 
 func (this *LocalOrderBooks) Subscribe(pair Pair) {
@@ -289,32 +370,47 @@ func (this *LocalOrderBooks) UnsubscribeById(productId string) {
 }
 
 func (this *LocalOrderBooks) SnapshotById(productId string) (*Depth, error) {
-	this.OrderBookMux.RLock()
-	defer this.OrderBookMux.RUnlock()
+	return this.SnapshotTopById(productId, 0)
+}
 
-	var lastTime = time.UnixMilli(this.TsData[productId]).In(this.WSMarketOKEx.Config.Location)
-	var depth = &Depth{
-		Timestamp: this.TsData[productId],
-		Sequence:  this.SeqData[productId],
-		Date:      lastTime.Format(GO_BIRTHDAY),
-		AskList:   make(DepthRecords, 0),
-		BidList:   make(DepthRecords, 0),
+// SnapshotTopById the depth of the top levels, all the levels when the levels <= 0.
+func (this *LocalOrderBooks) SnapshotTopById(productId string, levels int) (*Depth, error) {
+	var book = this.getBook(productId, false)
+	if book == nil {
+		return nil, errors.New("The order book data is not ready or you need subscribe the productId. ")
+	}
+
+	book.RLock()
+	defer book.RUnlock()
+	if book.invalid {
+		return nil, invalidBookError(productId)
 	}
+	var lastTime = time.UnixMilli(book.ts).In(this.WSMarketOKEx.Config.Location)
+	return &Depth{
+		Timestamp: book.ts,
+		Sequence:  book.seq,
+		Date:      lastTime.Format(GO_BIRTHDAY),
+		AskList:   book.asks.records(levels),
+		BidList:   book.bids.records(levels),
+	}, nil
+}
 
-	for stdPrice, amount := range this.BidData[productId] {
-		depth.BidList = append(depth.BidList, DepthRecord{
-			Price:  float64(stdPrice) / 100000000,
-			Amount: amount,
-		})
+// TopById the best bid and ask of the product, it's not sorted in the read.
+func (this *LocalOrderBooks) TopById(productId string) (DepthRecord, DepthRecord, error) {
+	var book = this.getBook(productId, false)
+	if book == nil {
+		return DepthRecord{}, DepthRecord{}, errors.New("The order book data is not ready or you need subscribe the productId. ")
 	}
 
-	for stdPrice, amount := range this.AskData[productId] {
-		depth.AskList = append(depth.AskList, DepthRecord{
-			Price:  float64(stdPrice) / 100000000,
-			Amount: amount,
-		})
+	book.RLock()
+	defer book.RUnlock()
+	if book.invalid {
+		return DepthRecord{}, DepthRecord{}, invalidBookError(productId)
+	}
+	var bid, bidExist = book.bids.top(0)
+	var ask, askExist = book.asks.top(0)
+	if !bidExist || !askExist {
+		return DepthRecord{}, DepthRecord{}, errors.New("The bids or asks of the order book is empty. ")
 	}
-	sort.Sort(sort.Reverse(depth.BidList))
-	sort.Sort(depth.AskList)
-	return depth, nil
+	return DepthRecord{Price: bid.price, Amount: bid.amount}, DepthRecord{Price: ask.price, Amount: ask.amount}, nil
 }
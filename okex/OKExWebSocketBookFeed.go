@@ -0,0 +1,96 @@
+package okex
+
+import (
+	"fmt"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// SwapBookFeed the LocalOrderBooks as the OrderBookFeed, the UpdateChan of the books is read by the feed.
+// The sizes of the okex book are in contracts, the snapshot convert them into the basis by the contract,
+// eg: 1 contract of BTC-USDT-SWAP is 0.01 BTC, 1 contract of BTC-USD-SWAP is 100 USD.
+type SwapBookFeed struct {
+	*LocalOrderBooks
+	Contract func(pair Pair) *SwapContract // eg: the Swap.GetContract
+
+	relay *BookUpdateRelay
+}
+
+func NewSwapBookFeed(books *LocalOrderBooks, contract func(pair Pair) *SwapContract) *SwapBookFeed {
+	return &SwapBookFeed{
+		LocalOrderBooks: books,
+		Contract:        contract,
+		relay:           NewBookUpdateRelay(OKEX, TRADE_TYPE_SWAP),
+	}
+}
+
+func (this *SwapBookFeed) GetExchangeName() string {
+	return OKEX
+}
+
+func (this *SwapBookFeed) GetMarket() string {
+	return TRADE_TYPE_SWAP
+}
+
+func (this *SwapBookFeed) Start() error {
+	if this.UpdateChan == nil {
+		this.UpdateChan = make(chan string, 1024)
+	}
+	this.relay.Start(this.UpdateChan)
+	return this.LocalOrderBooks.Init()
+}
+
+func (this *SwapBookFeed) Stop() {
+	this.relay.Stop()
+	this.LocalOrderBooks.Stop()
+}
+
+func (this *SwapBookFeed) Subscribe(pair Pair) {
+	this.relay.Bind(fmt.Sprintf("%s-SWAP", pair.ToSymbol("-", true)), pair)
+	this.LocalOrderBooks.Subscribe(pair)
+}
+
+func (this *SwapBookFeed) Unsubscribe(pair Pair) {
+	this.LocalOrderBooks.Unsubscribe(pair)
+	this.relay.Unbind(fmt.Sprintf("%s-SWAP", pair.ToSymbol("-", true)))
+}
+
+func (this *SwapBookFeed) Snapshot(pair Pair) (*Depth, error) {
+	var contract *SwapContract
+	if this.Contract != nil {
+		contract = this.Contract(pair)
+	}
+	if contract == nil || contract.UnitAmount <= 0 {
+		return nil, fmt.Errorf("The contract of %s is not found, the sizes can not be converted. ", pair.ToSymbol("-", true))
+	}
+
+	var depth, err = this.LocalOrderBooks.Snapshot(pair)
+	if err != nil {
+		return nil, err
+	}
+	return &Depth{
+		Pair:      depth.Pair,
+		Timestamp: depth.Timestamp,
+		Sequence:  depth.Sequence,
+		Date:      depth.Date,
+		AskList:   basisRecords(depth.AskList, contract),
+		BidList:   basisRecords(depth.BidList, contract),
+	}, nil
+}
+
+// the amount of the records in contracts to the amount in the basis.
+func basisRecords(records DepthRecords, contract *SwapContract) DepthRecords {
+	var basis = make(DepthRecords, 0, len(records))
+	for _, record := range records {
+		var amount = record.Amount * contract.UnitAmount
+		if contract.SettleMode == SETTLE_MODE_BASIS {
+			amount = amount / record.Price
+		}
+		basis = append(basis, DepthRecord{Price: record.Price, Amount: amount})
+	}
+	return basis
+}
+
+func (this *SwapBookFeed) SetUpdateHandler(handler func(*BookUpdate)) {
+	this.relay.SetUpdateHandler(handler)
+}
@@ -0,0 +1,179 @@
+package okex
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+type bookLevel struct {
+	stdPrice int64
+	price    float64
+	amount   float64
+	raw      string // the raw price:size text, the checksum is computed by it.
+}
+
+// bookSide keep the levels in the sorted slice from the worst price to the best one, the best is at the end.
+// The books channel of okex has 400 levels, the update is mostly near the top, so the insert and the remove
+// move a few levels only, and the top levels are read without sorting.
+type bookSide struct {
+	isBid  bool
+	levels []bookLevel
+}
+
+// better the price a is better than the price b in the side.
+func (side *bookSide) better(a, b int64) bool {
+	if side.isBid {
+		return a > b
+	}
+	return a < b
+}
+
+// search the index of the price, or the index to insert it.
+func (side *bookSide) search(stdPrice int64) (int, bool) {
+	var i = sort.Search(len(side.levels), func(i int) bool {
+		return !side.better(stdPrice, side.levels[i].stdPrice)
+	})
+	return i, i < len(side.levels) && side.levels[i].stdPrice == stdPrice
+}
+
+func (side *bookSide) amount(stdPrice int64) float64 {
+	if i, exist := side.search(stdPrice); exist {
+		return side.levels[i].amount
+	}
+	return 0
+}
+
+// data the amount of the levels keyed by the std price.
+func (side *bookSide) data() map[int64]float64 {
+	var data = make(map[int64]float64, len(side.levels))
+	for _, level := range side.levels {
+		data[level.stdPrice] = level.amount
+	}
+	return data
+}
+
+// set the level, the zero amount remove it.
+func (side *bookSide) set(level bookLevel) {
+	var i, exist = side.search(level.stdPrice)
+	if exist {
+		if level.amount == 0 {
+			side.levels = append(side.levels[:i], side.levels[i+1:]...)
+		} else {
+			side.levels[i] = level
+		}
+		return
+	}
+	if level.amount == 0 {
+		return
+	}
+	side.levels = append(side.levels, bookLevel{})
+	copy(side.levels[i+1:], side.levels[i:])
+	side.levels[i] = level
+}
+
+// top the nth best level, the 0 is the best.
+func (side *bookSide) top(n int) (bookLevel, bool) {
+	if n >= len(side.levels) {
+		return bookLevel{}, false
+	}
+	return side.levels[len(side.levels)-1-n], true
+}
+
+// records the top levels from the best, all the levels when the size <= 0.
+func (side *bookSide) records(size int) DepthRecords {
+	if size <= 0 || size > len(side.levels) {
+		size = len(side.levels)
+	}
+	var records = make(DepthRecords, 0, size)
+	for i := 0; i < size; i++ {
+		var level, _ = side.top(i)
+		records = append(records, DepthRecord{Price: level.price, Amount: level.amount})
+	}
+	return records
+}
+
+// instrumentBook is the book of one instrument, it has its own lock.
+type instrumentBook struct {
+	sync.RWMutex
+	bids bookSide
+	asks bookSide
+	seq  int64
+	ts   int64
+
+	// the book is invalid after the sequence gap or the checksum mismatch, until the next snapshot.
+	invalid       bool
+	checksumFails int64
+}
+
+func newInstrumentBook() *instrumentBook {
+	return &instrumentBook{
+		bids: bookSide{isBid: true},
+		asks: bookSide{isBid: false},
+	}
+}
+
+func parseLevel(item []string) bookLevel {
+	var price, _ = strconv.ParseFloat(item[0], 64)
+	var amount, _ = strconv.ParseFloat(item[1], 64)
+	return bookLevel{
+		stdPrice: int64(price * 100000000),
+		price:    price,
+		amount:   amount,
+		raw:      item[0] + ":" + item[1],
+	}
+}
+
+// reset the book by the snapshot, must be called with the book locked.
+func (book *instrumentBook) reset(bids, asks [][]string, seq, ts int64, event *BookLevelEvent) {
+	book.bids.levels = make([]bookLevel, 0, len(bids))
+	book.asks.levels = make([]bookLevel, 0, len(asks))
+	for _, bid := range bids {
+		var level = parseLevel(bid)
+		book.bids.set(level)
+		event.Add(BOOK_SIDE_BID, level.price, 0, level.amount)
+	}
+	for _, ask := range asks {
+		var level = parseLevel(ask)
+		book.asks.set(level)
+		event.Add(BOOK_SIDE_ASK, level.price, 0, level.amount)
+	}
+	book.seq = seq
+	book.ts = ts
+	book.invalid = false
+}
+
+// update the book by the delta, must be called with the book locked.
+func (book *instrumentBook) update(bids, asks [][]string, seq, ts int64, event *BookLevelEvent) {
+	for _, bid := range bids {
+		var level = parseLevel(bid)
+		event.Add(BOOK_SIDE_BID, level.price, book.bids.amount(level.stdPrice), level.amount)
+		book.bids.set(level)
+	}
+	for _, ask := range asks {
+		var level = parseLevel(ask)
+		event.Add(BOOK_SIDE_ASK, level.price, book.asks.amount(level.stdPrice), level.amount)
+		book.asks.set(level)
+	}
+	book.seq = seq
+	book.ts = ts
+}
+
+// The checksum of okex: the top 25 levels of bids and asks, join by bid:ask one by one,
+// then crc32 in signed int32. Must be called with the book locked.
+func (book *instrumentBook) checksum() int64 {
+	var items = make([]string, 0, 50)
+	for i := 0; i < 25; i++ {
+		if bid, exist := book.bids.top(i); exist {
+			items = append(items, bid.raw)
+		}
+		if ask, exist := book.asks.top(i); exist {
+			items = append(items, ask.raw)
+		}
+	}
+	return int64(int32(crc32.ChecksumIEEE([]byte(strings.Join(items, ":")))))
+}
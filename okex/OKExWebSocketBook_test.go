@@ -2,6 +2,9 @@ package okex
 
 import (
 	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"math"
 	"net/http"
 	"testing"
 	"time"
@@ -9,6 +12,8 @@ import (
 	. "github.com/deforceHK/goghostex"
 )
 
+var _ OrderBookFeed = (*SwapBookFeed)(nil)
+
 // go test -v ./okex/... -count=1 -run=TestLocalOrderBooks_Init
 func TestLocalOrderBooks_Init(t *testing.T) {
 	var config = &APIConfig{
@@ -72,3 +77,314 @@ func TestLocalOrderBooks_Init(t *testing.T) {
 
 	select {}
 }
+
+// go test -v ./okex/... -count=1 -run=TestLocalOrderBooks_Checksum
+func TestLocalOrderBooks_Checksum(t *testing.T) {
+	var wsOK = &LocalOrderBooks{
+		WSMarketOKEx: &WSMarketOKEx{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { t.Error(err) },
+		},
+	}
+	wsOK.initBooks()
+
+	wsOK.Receiver(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"snapshot","data":[{"asks":[["3366.8","9","10","3"],["3368","8","3","4"]],"bids":[["3366.1","7","0","3"],["3366","6","3","4"]],"ts":"1597026383085","checksum":-1881014294,"prevSeqId":-1,"seqId":123456}]}`)
+	wsOK.Receiver(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[],"bids":[["3365.5","2","0","1"]],"ts":"1597026383185","checksum":946606151,"prevSeqId":123456,"seqId":123457}]}`)
+
+	if wsOK.ChecksumFails("BTC-USDT") != 0 {
+		t.Error("The checksum should be passed. ")
+	}
+	var depth, _ = wsOK.SnapshotById("BTC-USDT")
+	if len(depth.BidList) != 3 || depth.BidList[2].Price != 3365.5 {
+		t.Error("The bid list is wrong: ", depth.BidList)
+	}
+}
+
+// go test -v ./okex/... -count=1 -run=TestLocalOrderBooks_ChecksumMismatch
+func TestLocalOrderBooks_ChecksumMismatch(t *testing.T) {
+	var errs = make([]error, 0)
+	var resubscribes = make([]string, 0)
+	var wsOK = &LocalOrderBooks{
+		WSMarketOKEx: &WSMarketOKEx{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { errs = append(errs, err) },
+		},
+		ResubscribeHandler: func(productId string) { resubscribes = append(resubscribes, productId) },
+	}
+	wsOK.initBooks()
+
+	wsOK.Receiver(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"snapshot","data":[{"asks":[["3366.8","9","10","3"],["3368","8","3","4"]],"bids":[["3366.1","7","0","3"],["3366","6","3","4"]],"ts":"1597026383085","checksum":-1881014294,"prevSeqId":-1,"seqId":123456}]}`)
+	if len(errs) != 0 || len(resubscribes) != 0 {
+		t.Fatal("The snapshot checksum should be passed. ", errs, resubscribes)
+	}
+	wsOK.Receiver(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[],"bids":[["3365.5","2","0","1"]],"ts":"1597026383185","checksum":12345,"prevSeqId":123456,"seqId":123457}]}`)
+
+	if wsOK.ChecksumFails("BTC-USDT") != 1 {
+		t.Error("The checksum fails should be 1: ", wsOK.ChecksumFails("BTC-USDT"))
+	}
+	if len(errs) != 1 {
+		t.Fatal("The checksum error should be handled once: ", errs)
+	}
+	var checksumErr, ok = errs[0].(*BookChecksumError)
+	if !ok || checksumErr.Exchange != OKEX || checksumErr.ProductId != "BTC-USDT" ||
+		checksumErr.Expect != 12345 || checksumErr.Actual != 946606151 || checksumErr.FailCount != 1 {
+		t.Error("The checksum error is wrong: ", errs[0])
+	}
+	if len(resubscribes) != 1 || resubscribes[0] != "BTC-USDT" {
+		t.Error("The book should be resubscribed: ", resubscribes)
+	}
+
+	// the invalid book is not served, the updates are ignored until the snapshot.
+	if _, err := wsOK.SnapshotTopById("BTC-USDT", 0); err == nil {
+		t.Error("The invalid book should be an error. ")
+	}
+	if _, _, err := wsOK.TopById("BTC-USDT"); err == nil || wsOK.BidData("BTC-USDT") != nil {
+		t.Error("The invalid book should be an error. ")
+	}
+	wsOK.Receiver(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[],"bids":[["3365.4","2","0","1"]],"ts":"1597026383285","checksum":12345,"prevSeqId":123457,"seqId":123458}]}`)
+	if len(errs) != 1 || len(resubscribes) != 1 {
+		t.Error("The update of the invalid book should be ignored: ", errs, resubscribes)
+	}
+	wsOK.Receiver(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"snapshot","data":[{"asks":[["3366.8","9","10","3"],["3368","8","3","4"]],"bids":[["3366.1","7","0","3"],["3366","6","3","4"]],"ts":"1597026393085","checksum":-1881014294,"prevSeqId":-1,"seqId":123500}]}`)
+	if depth, err := wsOK.SnapshotTopById("BTC-USDT", 0); err != nil || depth.Sequence != 123500 {
+		t.Error("The book should be valid after the snapshot: ", err)
+	}
+
+	// the sequence gap make the book invalid too.
+	wsOK.Receiver(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[],"bids":[["3365.5","2","0","1"]],"ts":"1597026393185","checksum":946606151,"prevSeqId":123499,"seqId":123501}]}`)
+	if _, err := wsOK.SnapshotTopById("BTC-USDT", 0); err == nil || len(resubscribes) != 2 {
+		t.Error("The book with the gap should be invalid and resubscribed: ", err, resubscribes)
+	}
+}
+
+// go test -v ./okex/... -count=1 -run=TestLocalOrderBooks_LevelChan
+func TestLocalOrderBooks_LevelChan(t *testing.T) {
+	var wsOK = &LocalOrderBooks{
+		WSMarketOKEx: &WSMarketOKEx{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { t.Error(err) },
+		},
+		LevelChan: make(chan *BookLevelEvent, 4),
+	}
+	wsOK.initBooks()
+
+	wsOK.Receiver(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"snapshot","data":[{"asks":[["3366.8","9","10","3"],["3368","8","3","4"]],"bids":[["3366.1","7","0","3"],["3366","6","3","4"]],"ts":"1597026383085","checksum":-1881014294,"prevSeqId":-1,"seqId":123456}]}`)
+	wsOK.Receiver(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[],"bids":[["3365.5","2","0","1"]],"ts":"1597026383185","checksum":946606151,"prevSeqId":123456,"seqId":123457}]}`)
+	var checksum = int32(crc32.ChecksumIEEE([]byte("3366.1:7:3366.8:9:3365.5:2:3368:5")))
+	wsOK.Receiver(fmt.Sprintf(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[["3368","5","0","1"]],"bids":[["3366","0","0","0"]],"ts":"1597026383285","checksum":%d,"prevSeqId":123457,"seqId":123458}]}`, checksum))
+
+	var snapshot = <-wsOK.LevelChan
+	if !snapshot.Snapshot || snapshot.Sequence != 123456 || len(snapshot.Changes) != 4 || snapshot.Exchange != OKEX {
+		t.Fatal("The snapshot event is wrong. ", snapshot)
+	}
+	var update = <-wsOK.LevelChan
+	if update.Snapshot || update.PrevSequence != 123456 || update.Timestamp != 1597026383185 ||
+		len(update.Changes) != 1 || update.Changes[0] != (BookLevelChange{Side: BOOK_SIDE_BID, Price: 3365.5, OldAmount: 0, NewAmount: 2}) {
+		t.Fatal("The new level event is wrong. ", update)
+	}
+	update = <-wsOK.LevelChan
+	if len(update.Changes) != 2 || update.Sequence != 123458 ||
+		update.Changes[0] != (BookLevelChange{Side: BOOK_SIDE_BID, Price: 3366, OldAmount: 6, NewAmount: 0}) ||
+		update.Changes[1] != (BookLevelChange{Side: BOOK_SIDE_ASK, Price: 3368, OldAmount: 8, NewAmount: 5}) {
+		t.Fatal("The changed and removed level event is wrong. ", update.Changes)
+	}
+	if len(wsOK.LevelChan) != 0 {
+		t.Error("There should be no more event. ")
+	}
+}
+
+// go test -v ./okex/... -count=1 -run=TestLocalOrderBooks_TopById
+func TestLocalOrderBooks_TopById(t *testing.T) {
+	var wsOK = &LocalOrderBooks{
+		WSMarketOKEx: &WSMarketOKEx{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { t.Error(err) },
+		},
+	}
+	wsOK.initBooks()
+	if _, _, err := wsOK.TopById("BTC-USDT"); err == nil {
+		t.Fatal("The book is not ready, it should be an error. ")
+	}
+
+	wsOK.Receiver(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"snapshot","data":[{"asks":[["3366.8","9","10","3"],["3368","8","3","4"]],"bids":[["3366.1","7","0","3"],["3366","6","3","4"]],"ts":"1597026383085","checksum":-1881014294,"prevSeqId":-1,"seqId":123456}]}`)
+	var checksum = int32(crc32.ChecksumIEEE([]byte("3366.2:1:3366.8:9:3366:6:3368:8")))
+	wsOK.Receiver(fmt.Sprintf(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[],"bids":[["3366.2","1","0","1"],["3366.1","0","0","0"]],"ts":"1597026383185","checksum":%d,"prevSeqId":123456,"seqId":123457}]}`, checksum))
+
+	var bid, ask, err = wsOK.TopById("BTC-USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bid.Price != 3366.2 || bid.Amount != 1 || ask.Price != 3366.8 || ask.Amount != 9 {
+		t.Error("The top of book is wrong: ", bid, ask)
+	}
+
+	depth, err := wsOK.SnapshotTopById("BTC-USDT", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(depth.BidList) != 1 || len(depth.AskList) != 1 || depth.BidList[0].Price != 3366.2 || depth.Sequence != 123457 {
+		t.Error("The top depth is wrong: ", depth)
+	}
+
+	var bids, asks = wsOK.BidData("BTC-USDT"), wsOK.AskData("BTC-USDT")
+	if len(bids) != 2 || bids[336620000000] != 1 || bids[336610000000] != 0 || len(asks) != 2 || asks[336680000000] != 9 {
+		t.Error("The book data is wrong: ", bids, asks)
+	}
+	if wsOK.SeqData("BTC-USDT") != 123457 || wsOK.TsData("BTC-USDT") != 1597026383185 {
+		t.Error("The seq or ts is wrong. ")
+	}
+	if wsOK.BidData("ETH-USDT") != nil || wsOK.SeqData("ETH-USDT") != 0 {
+		t.Error("The product without the snapshot should be empty. ")
+	}
+}
+
+// go test -v ./okex/... -count=1 -run=TestSwapBookFeed_Snapshot
+func TestSwapBookFeed_Snapshot(t *testing.T) {
+	var wsOK = &LocalOrderBooks{
+		WSMarketOKEx: &WSMarketOKEx{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { t.Error(err) },
+		},
+	}
+	wsOK.initBooks()
+	var checksum = int32(crc32.ChecksumIEEE([]byte("3366.1:7:3366.8:9:3366:6:3368:8")))
+	for _, instId := range []string{"BTC-USDT-SWAP", "BTC-USD-SWAP"} {
+		wsOK.Receiver(fmt.Sprintf(`{"arg":{"channel":"books","instId":"%s"},"action":"snapshot","data":[{"asks":[["3366.8","9","10","3"],["3368","8","3","4"]],"bids":[["3366.1","7","0","3"],["3366","6","3","4"]],"ts":"1597026383085","checksum":%d,"prevSeqId":-1,"seqId":123456}]}`, instId, checksum))
+	}
+
+	var contracts = map[string]*SwapContract{
+		"BTC-USDT-SWAP": {SettleMode: SETTLE_MODE_COUNTER, UnitAmount: 0.01},
+		"BTC-USD-SWAP":  {SettleMode: SETTLE_MODE_BASIS, UnitAmount: 100},
+	}
+	var feed = NewSwapBookFeed(wsOK, func(pair Pair) *SwapContract {
+		return contracts[pair.ToSymbol("-", true)+"-SWAP"]
+	})
+
+	var depth, err = feed.Snapshot(BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth.AskList[0].Price != 3366.8 || math.Abs(depth.AskList[0].Amount-0.09) > 1e-12 ||
+		math.Abs(depth.BidList[1].Amount-0.06) > 1e-12 {
+		t.Error("The counter settled sizes should be in the basis: ", depth.AskList, depth.BidList)
+	}
+	if depth, err = feed.Snapshot(BTC_USD); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(depth.AskList[0].Amount-900/3366.8) > 1e-12 {
+		t.Error("The basis settled sizes should be in the basis: ", depth.AskList)
+	}
+	if _, err = feed.Snapshot(ETH_USDT); err == nil {
+		t.Error("The pair without the contract should be an error. ")
+	}
+}
+
+func benchBookLevels(mid float64, count int) ([][]string, [][]string) {
+	var bids, asks = make([][]string, 0, count), make([][]string, 0, count)
+	for i := 0; i < count; i++ {
+		bids = append(bids, []string{fmt.Sprintf("%.1f", mid-0.1*float64(i+1)), "1", "0", "1"})
+		asks = append(asks, []string{fmt.Sprintf("%.1f", mid+0.1*float64(i+1)), "1", "0", "1"})
+	}
+	return bids, asks
+}
+
+// go test ./okex/... -run=^$ -bench=BenchmarkInstrumentBook_Update -benchmem
+func BenchmarkInstrumentBook_Update(b *testing.B) {
+	var book = newInstrumentBook()
+	var bids, asks = benchBookLevels(30000, 400)
+	book.reset(bids, asks, 1, 0, nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var level = i % 50
+		var amount = "0"
+		if i%2 == 0 {
+			amount = "2"
+		}
+		book.Lock()
+		book.update(
+			[][]string{{bids[level][0], amount, "0", "1"}},
+			[][]string{{asks[level][0], amount, "0", "1"}},
+			int64(i+2), 0, nil,
+		)
+		book.checksum()
+		book.Unlock()
+	}
+}
+
+func benchLocalOrderBooks(count int) (*LocalOrderBooks, []string) {
+	var wsOK = &LocalOrderBooks{
+		WSMarketOKEx: &WSMarketOKEx{
+			Config: &APIConfig{Location: time.UTC},
+		},
+	}
+	wsOK.initBooks()
+
+	var productIds = make([]string, 0, count)
+	var bids, asks = benchBookLevels(30000, 400)
+	for i := 0; i < count; i++ {
+		var productId = fmt.Sprintf("COIN%d-USDT-SWAP", i)
+		var book = wsOK.getBook(productId, true)
+		book.reset(bids, asks, 1, 0, nil)
+		productIds = append(productIds, productId)
+	}
+	return wsOK, productIds
+}
+
+// go test ./okex/... -run=^$ -bench=BenchmarkLocalOrderBooks_TopById -benchmem
+func BenchmarkLocalOrderBooks_TopById(b *testing.B) {
+	var wsOK, productIds = benchLocalOrderBooks(200)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i = 0
+		for pb.Next() {
+			_, _, _ = wsOK.TopById(productIds[i%len(productIds)])
+			i++
+		}
+	})
+}
+
+// go test ./okex/... -run=^$ -bench=BenchmarkLocalOrderBooks_SnapshotTopById -benchmem
+func BenchmarkLocalOrderBooks_SnapshotTopById(b *testing.B) {
+	var wsOK, productIds = benchLocalOrderBooks(200)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i = 0
+		for pb.Next() {
+			_, _ = wsOK.SnapshotTopById(productIds[i%len(productIds)], 20)
+			i++
+		}
+	})
+}
+
+// go test ./okex/... -run=^$ -bench=BenchmarkLocalOrderBooks_TopByIdWhileUpdate -benchmem
+func BenchmarkLocalOrderBooks_TopByIdWhileUpdate(b *testing.B) {
+	var wsOK, productIds = benchLocalOrderBooks(200)
+	var bids, asks = benchBookLevels(30000, 400)
+	var stop = make(chan bool)
+	defer close(stop)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			var book = wsOK.getBook(productIds[i%len(productIds)], false)
+			book.Lock()
+			book.update([][]string{{bids[i%50][0], "3", "0", "1"}}, [][]string{{asks[i%50][0], "3", "0", "1"}}, 2, 0, nil)
+			book.Unlock()
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i = 0
+		for pb.Next() {
+			_, _, _ = wsOK.TopById(productIds[i%len(productIds)])
+			i++
+		}
+	})
+}
@@ -2,9 +2,12 @@ package okex
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -16,6 +19,7 @@ type WSMarketOKEx struct {
 	RecvHandler  func(string)
 	ErrorHandler func(error)
 	Config       *APIConfig
+	WsUrl        string // default is the public url, the candle channels need the business url.
 
 	conn   *websocket.Conn
 	connId string
@@ -28,35 +32,68 @@ type WSMarketOKEx struct {
 
 	subscribed []interface{}
 
-	lastPingTS int64
+	lastPingTS int64 // read and write in atomic
 
 	stopPingSign chan bool
 	stopChecSign chan bool
+
+	// the mux guard the conn, the connId, the subscribed and the stop signs, the writeMux guard the writes of the conn.
+	mux      sync.Mutex
+	writeMux sync.Mutex
+}
+
+// WriteJSON write the message in the current connection, the writes are serialized.
+func (this *WSMarketOKEx) WriteJSON(v interface{}) error {
+	this.mux.Lock()
+	var conn = this.conn
+	this.mux.Unlock()
+	if conn == nil {
+		return errors.New("The websocket is not connected. ")
+	}
+	return this.write(conn, func(conn *websocket.Conn) error {
+		return conn.WriteJSON(v)
+	})
+}
+
+func (this *WSMarketOKEx) write(conn *websocket.Conn, write func(conn *websocket.Conn) error) error {
+	this.writeMux.Lock()
+	defer this.writeMux.Unlock()
+	return write(conn)
 }
 
-func (this *WSMarketOKEx) Subscribe(v interface{}) {
-	if err := this.conn.WriteJSON(v); err != nil {
+func (this *WSMarketOKEx) Subscribe(v interface{}) error {
+	if err := this.WriteJSON(v); err != nil {
 		this.ErrorHandler(err)
-		return
+		return err
 	}
+	this.mux.Lock()
 	this.subscribed = append(this.subscribed, v)
+	this.mux.Unlock()
+	return nil
 }
 
-func (this *WSMarketOKEx) Unsubscribe(v interface{}) {
-	if err := this.conn.WriteJSON(v); err != nil {
+func (this *WSMarketOKEx) Unsubscribe(v interface{}) error {
+	if err := this.WriteJSON(v); err != nil {
 		this.ErrorHandler(err)
-		return
+		return err
 	}
+	this.mux.Lock()
 	this.subscribed = append(this.subscribed, v)
+	this.mux.Unlock()
+	return nil
 }
 
 func (this *WSMarketOKEx) Stop() {
+	this.mux.Lock()
+	defer this.mux.Unlock()
 	if this.stopPingSign != nil {
 		this.stopPingSign <- true
+		this.stopPingSign = nil
 	}
 
 	if this.stopChecSign != nil {
 		this.stopChecSign <- true
+		this.stopChecSign = nil
 	}
 
 	if this.conn != nil {
@@ -70,7 +107,9 @@ func (this *WSMarketOKEx) Restart() {
 	this.ErrorHandler(
 		&WSRestartError{Msg: fmt.Sprintf("websocket will restart in next %d seconds...", this.restartSec)},
 	)
+	this.mux.Lock()
 	this.restartTS[time.Now().Unix()] = this.connId
+	this.mux.Unlock()
 	this.Stop()
 
 	time.Sleep(time.Duration(this.restartSec) * time.Second)
@@ -79,10 +118,15 @@ func (this *WSMarketOKEx) Restart() {
 		return
 	}
 
+	this.mux.Lock()
 	var conn = this.conn
+	var subscribed = append([]interface{}{}, this.subscribed...)
+	this.mux.Unlock()
 	// subscribe unsubscribe the channel
-	for _, v := range this.subscribed {
-		var err = conn.WriteJSON(v)
+	for _, v := range subscribed {
+		var err = this.write(conn, func(conn *websocket.Conn) error {
+			return conn.WriteJSON(v)
+		})
 		if err != nil {
 			this.ErrorHandler(err)
 			var errMsg, _ = json.Marshal(v)
@@ -98,24 +142,40 @@ func (this *WSMarketOKEx) Start() error {
 		return err
 	}
 
-	var conn, err = this.getConn("wss://ws.okx.com:8443/ws/v5/public")
+	if this.WsUrl == "" {
+		this.WsUrl = WEBSOCKET_PUBLIC_URL
+	}
+	var conn, err = this.getConn(this.WsUrl)
 	if err != nil {
-		if len(this.restartTS) != 0 {
+		this.mux.Lock()
+		var restarted = len(this.restartTS) != 0
+		this.mux.Unlock()
+		if restarted {
 			this.Restart()
 		}
 		return err
 	}
+
+	// the stop signs are made before the routines, the Stop can stop them at once.
+	var stopPingChn, stopChecChn = make(chan bool, 1), make(chan bool, 1)
+	this.mux.Lock()
 	this.conn = conn
 	this.connId = UUID()
+	this.stopPingSign = stopPingChn
+	this.stopChecSign = stopChecChn
+	this.mux.Unlock()
+	atomic.StoreInt64(&this.lastPingTS, time.Now().Unix())
 
-	go this.pingRoutine()
-	go this.checkRoutine()
-	go this.recvRoutine()
+	go this.pingRoutine(conn, stopPingChn)
+	go this.checkRoutine(stopChecChn)
+	go this.recvRoutine(conn)
 
 	return nil
 }
 
 func (this *WSMarketOKEx) startCheck() error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
 	var restartNum, limitTS = 0, time.Now().Unix() - int64(this.restartLimitSec)
 	for ts, _ := range this.restartTS {
 		if ts > limitTS {
@@ -141,6 +201,8 @@ func (this *WSMarketOKEx) getConn(wss string) (*websocket.Conn, error) {
 		nil,
 	)
 	if err != nil {
+		this.mux.Lock()
+		defer this.mux.Unlock()
 		this.restartTS[time.Now().Unix()] = this.connId
 		if this.conn != nil {
 			_ = this.conn.Close()
@@ -153,6 +215,8 @@ func (this *WSMarketOKEx) getConn(wss string) (*websocket.Conn, error) {
 }
 
 func (this *WSMarketOKEx) initDefaultValue() {
+	this.mux.Lock()
+	defer this.mux.Unlock()
 	if this.RecvHandler == nil {
 		this.RecvHandler = func(msg string) {
 			log.Println(msg)
@@ -181,35 +245,25 @@ func (this *WSMarketOKEx) initDefaultValue() {
 
 }
 
-func (this *WSMarketOKEx) pingRoutine() {
-	var stopPingChn = make(chan bool, 1)
-	this.stopPingSign = stopPingChn
+func (this *WSMarketOKEx) pingRoutine(conn *websocket.Conn, stopPingChn chan bool) {
 	var ticker = time.NewTicker(DEFAULT_WEBSOCKET_PING_SEC * time.Second)
 	defer ticker.Stop()
-	var conn = this.conn
 	for {
 		select {
 		case <-ticker.C:
-			if this.conn == nil {
-				continue
-			}
-			if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+			var err = this.write(conn, func(conn *websocket.Conn) error {
+				return conn.WriteMessage(websocket.TextMessage, []byte("ping"))
+			})
+			if err != nil {
 				fmt.Println(err)
 			}
-		case _, opened := <-stopPingChn:
-			if opened {
-				this.stopPingSign = nil
-				close(stopPingChn)
-			}
+		case <-stopPingChn:
 			return
 		}
 	}
 }
 
-func (this *WSMarketOKEx) checkRoutine() {
-	var stopChecChn = make(chan bool, 1)
-	this.stopChecSign = stopChecChn
-
+func (this *WSMarketOKEx) checkRoutine(stopChecChn chan bool) {
 	var ticker = time.NewTicker(DEFAULT_WEBSOCKET_PENDING_SEC * time.Second)
 	defer ticker.Stop()
 
@@ -217,23 +271,19 @@ func (this *WSMarketOKEx) checkRoutine() {
 		select {
 		case <-ticker.C:
 			// 超过x秒没有收到消息，重新连接，如果超出重连次数，ws将停止。
-			if time.Now().Unix()-this.lastPingTS > DEFAULT_WEBSOCKET_PENDING_SEC {
-				this.ErrorHandler(fmt.Errorf("ping timeout, last ping ts: %d", this.lastPingTS))
+			var lastPingTS = atomic.LoadInt64(&this.lastPingTS)
+			if time.Now().Unix()-lastPingTS > DEFAULT_WEBSOCKET_PENDING_SEC {
+				this.ErrorHandler(fmt.Errorf("ping timeout, last ping ts: %d", lastPingTS))
 				this.Restart()
 				continue
 			}
-		case _, opened := <-stopChecChn:
-			if opened {
-				this.stopChecSign = nil
-				close(stopChecChn)
-			}
+		case <-stopChecChn:
 			return
 		}
 	}
 }
 
-func (this *WSMarketOKEx) recvRoutine() {
-	var conn = this.conn
+func (this *WSMarketOKEx) recvRoutine(conn *websocket.Conn) {
 	for {
 		var msgType, msg, readErr = conn.ReadMessage()
 		if readErr != nil {
@@ -251,7 +301,7 @@ func (this *WSMarketOKEx) recvRoutine() {
 			continue
 		}
 
-		this.lastPingTS = time.Now().Unix()
+		atomic.StoreInt64(&this.lastPingTS, time.Now().Unix())
 		var msgStr = string(msg)
 		if msgStr != "pong" {
 			this.RecvHandler(msgStr)
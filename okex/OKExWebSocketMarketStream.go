@@ -0,0 +1,375 @@
+package okex
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// MarketStream decode the public channels of okex v5 into the typed models.
+// The TradeType decide the instId of the pair, TRADE_TYPE_SWAP(default) or TRADE_TYPE_SPOT.
+// The candle channels are only in the business url, the stream open the business connection
+// at the first SubscribeKline, and restart or stop it with the stream.
+type MarketStream struct {
+	*WSMarketOKEx
+	MarketHandler
+	TradeType   string
+	BusinessUrl string // default is WEBSOCKET_BUSINESS_URL
+
+	pairs    map[string]Pair
+	pairsMux sync.RWMutex
+
+	business    *WSMarketOKEx
+	businessMux sync.Mutex
+}
+
+type okMarketPush struct {
+	Arg struct {
+		Channel string `json:"channel"`
+		InstId  string `json:"instId"`
+	} `json:"arg"`
+	Event string          `json:"event"`
+	Code  string          `json:"code"`
+	Msg   string          `json:"msg"`
+	Data  json.RawMessage `json:"data"`
+}
+
+func (this *MarketStream) Init() error {
+	if this.TradeType == "" {
+		this.TradeType = TRADE_TYPE_SWAP
+	}
+	this.pairsMux.Lock()
+	if this.pairs == nil {
+		this.pairs = make(map[string]Pair)
+	}
+	this.pairsMux.Unlock()
+
+	this.WSMarketOKEx.RecvHandler = func(s string) {
+		this.Receiver(s)
+	}
+	return this.Start()
+}
+
+// Stop the public and the business connection.
+func (this *MarketStream) Stop() {
+	this.WSMarketOKEx.Stop()
+	this.businessMux.Lock()
+	defer this.businessMux.Unlock()
+	if this.business != nil {
+		this.business.Stop()
+		this.business = nil
+	}
+}
+
+// Restart the public and the business connection, the channels are subscribed again in them.
+func (this *MarketStream) Restart() {
+	this.WSMarketOKEx.Restart()
+	this.businessMux.Lock()
+	var business = this.business
+	this.businessMux.Unlock()
+	if business != nil {
+		business.Restart()
+	}
+}
+
+func (this *MarketStream) GetExchangeName() string {
+	return OKEX
+}
+
+func (this *MarketStream) SubscribeTicker(pair Pair) error {
+	return this.subscribe("tickers", pair)
+}
+
+func (this *MarketStream) SubscribeTrade(pair Pair) error {
+	return this.subscribe("trades", pair)
+}
+
+func (this *MarketStream) SubscribeKline(pair Pair, period int) error {
+	var okPeriod, exist = _INERNAL_V5_CANDLE_PERIOD_CONVERTER[period]
+	if !exist {
+		return fmt.Errorf("The period %d is not supported by okex. ", period)
+	}
+	var conn, err = this.getBusiness()
+	if err != nil {
+		return err
+	}
+	return this.subscribeIn(conn, "candle"+okPeriod, pair)
+}
+
+// getBusiness return the connection of the business url, it is the stream itself if it is in the business url.
+func (this *MarketStream) getBusiness() (*WSMarketOKEx, error) {
+	if this.BusinessUrl == "" {
+		this.BusinessUrl = WEBSOCKET_BUSINESS_URL
+	}
+	if this.WsUrl == this.BusinessUrl {
+		return this.WSMarketOKEx, nil
+	}
+
+	this.businessMux.Lock()
+	defer this.businessMux.Unlock()
+	if this.business != nil {
+		return this.business, nil
+	}
+	var business = &WSMarketOKEx{
+		RecvHandler: func(s string) {
+			this.Receiver(s)
+		},
+		ErrorHandler: this.ErrorHandler,
+		Config:       this.Config,
+		WsUrl:        this.BusinessUrl,
+	}
+	if err := business.Start(); err != nil {
+		return nil, err
+	}
+	this.business = business
+	return business, nil
+}
+
+func (this *MarketStream) SubscribeFundingRate(pair Pair) error {
+	if this.TradeType != TRADE_TYPE_SWAP {
+		return fmt.Errorf("The funding rate is only in swap. ")
+	}
+	return this.subscribe("funding-rate", pair)
+}
+
+func (this *MarketStream) SubscribeMarkPrice(pair Pair) error {
+	return this.subscribe("mark-price", pair)
+}
+
+func (this *MarketStream) subscribe(channel string, pair Pair) error {
+	return this.subscribeIn(this.WSMarketOKEx, channel, pair)
+}
+
+func (this *MarketStream) subscribeIn(conn *WSMarketOKEx, channel string, pair Pair) error {
+	var instId = this.getInstId(pair)
+	this.pairsMux.Lock()
+	this.pairs[instId] = pair
+	this.pairsMux.Unlock()
+
+	return conn.Subscribe(WSOpOKEx{
+		Op: "subscribe",
+		Args: []map[string]string{
+			{
+				"channel": channel,
+				"instId":  instId,
+			},
+		},
+	})
+}
+
+func (this *MarketStream) getInstId(pair Pair) string {
+	if this.TradeType == TRADE_TYPE_SPOT {
+		return pair.ToSymbol("-", true)
+	}
+	return pair.ToSwapContractName()
+}
+
+func (this *MarketStream) Receiver(msg string) {
+	var push = okMarketPush{}
+	if err := json.Unmarshal([]byte(msg), &push); err != nil {
+		this.ErrorHandler(err)
+		return
+	}
+	if push.Event == "error" {
+		this.ErrorHandler(fmt.Errorf("okex websocket error, code: %s, msg: %s", push.Code, push.Msg))
+		return
+	}
+	// subscribe or unsubscribe event
+	if push.Event != "" || len(push.Data) == 0 {
+		return
+	}
+
+	this.pairsMux.RLock()
+	var pair, exist = this.pairs[push.Arg.InstId]
+	this.pairsMux.RUnlock()
+	if !exist {
+		return
+	}
+
+	var err error
+	switch {
+	case push.Arg.Channel == "tickers":
+		err = this.recvTicker(pair, push.Data)
+	case push.Arg.Channel == "trades":
+		err = this.recvTrade(pair, push.Data)
+	case push.Arg.Channel == "funding-rate":
+		err = this.recvFundingRate(pair, push.Data)
+	case push.Arg.Channel == "mark-price":
+		err = this.recvMarkPrice(pair, push.Data)
+	case len(push.Arg.Channel) > 6 && push.Arg.Channel[:6] == "candle":
+		err = this.recvCandle(pair, push.Data)
+	}
+	if err != nil {
+		this.ErrorHandler(err)
+	}
+}
+
+func (this *MarketStream) recvTicker(pair Pair, raw json.RawMessage) error {
+	var data = make([]struct {
+		Last   float64 `json:"last,string"`
+		AskPx  float64 `json:"askPx,string"`
+		BidPx  float64 `json:"bidPx,string"`
+		High24 float64 `json:"high24h,string"`
+		Low24  float64 `json:"low24h,string"`
+		Vol24  float64 `json:"vol24h,string"`
+		Ts     int64   `json:"ts,string"`
+	}, 0)
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		var date = time.UnixMilli(d.Ts).In(this.Config.Location).Format(GO_BIRTHDAY)
+		if this.TradeType == TRADE_TYPE_SPOT {
+			this.OnTicker(&Ticker{
+				Pair:      pair,
+				Last:      d.Last,
+				Buy:       d.BidPx,
+				Sell:      d.AskPx,
+				High:      d.High24,
+				Low:       d.Low24,
+				Vol:       d.Vol24,
+				Timestamp: d.Ts,
+				Date:      date,
+			})
+		} else {
+			this.OnSwapTicker(&SwapTicker{
+				Pair:      pair,
+				Last:      d.Last,
+				Buy:       d.BidPx,
+				Sell:      d.AskPx,
+				High:      d.High24,
+				Low:       d.Low24,
+				Vol:       d.Vol24,
+				Timestamp: d.Ts,
+				Date:      date,
+			})
+		}
+	}
+	return nil
+}
+
+func (this *MarketStream) recvTrade(pair Pair, raw json.RawMessage) error {
+	var data = make([]struct {
+		TradeId string  `json:"tradeId"`
+		Px      float64 `json:"px,string"`
+		Sz      float64 `json:"sz,string"`
+		Side    string  `json:"side"`
+		Ts      int64   `json:"ts,string"`
+	}, 0)
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		var tid, _ = strconv.ParseInt(d.TradeId, 10, 64)
+		var side = BUY
+		if d.Side == "sell" {
+			side = SELL
+		}
+		this.OnTrade(&Trade{
+			Tid:       tid,
+			Type:      side,
+			Amount:    d.Sz,
+			Price:     d.Px,
+			Timestamp: d.Ts,
+			Pair:      pair,
+		})
+	}
+	return nil
+}
+
+func (this *MarketStream) recvCandle(pair Pair, raw json.RawMessage) error {
+	var data = make([][]string, 0)
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		if len(d) < 6 {
+			return fmt.Errorf("The candle data is wrong: %s", string(raw))
+		}
+		var ts, _ = strconv.ParseInt(d[0], 10, 64)
+		var date = time.UnixMilli(ts).In(this.Config.Location).Format(GO_BIRTHDAY)
+		if this.TradeType == TRADE_TYPE_SPOT {
+			this.OnKline(&Kline{
+				Pair:      pair,
+				Exchange:  OKEX,
+				Timestamp: ts,
+				Date:      date,
+				Open:      ToFloat64(d[1]),
+				High:      ToFloat64(d[2]),
+				Low:       ToFloat64(d[3]),
+				Close:     ToFloat64(d[4]),
+				Vol:       ToFloat64(d[5]),
+			})
+		} else {
+			this.OnSwapKline(&SwapKline{
+				Pair:      pair,
+				Exchange:  OKEX,
+				Timestamp: ts,
+				Date:      date,
+				Open:      ToFloat64(d[1]),
+				High:      ToFloat64(d[2]),
+				Low:       ToFloat64(d[3]),
+				Close:     ToFloat64(d[4]),
+				Vol:       ToFloat64(d[5]),
+			})
+		}
+	}
+	return nil
+}
+
+func (this *MarketStream) recvFundingRate(pair Pair, raw json.RawMessage) error {
+	var data = make([]struct {
+		FundingRate     string `json:"fundingRate"`
+		NextFundingRate string `json:"nextFundingRate"`
+		FundingTime     int64  `json:"fundingTime,string"`
+		Ts              string `json:"ts"`
+	}, 0)
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		var ts = ToInt64(d.Ts)
+		if ts == 0 {
+			ts = time.Now().UnixMilli()
+		}
+		this.OnFundingRate(&FundingRate{
+			Pair:             pair,
+			Exchange:         OKEX,
+			Rate:             ToFloat64(d.FundingRate),
+			NextRate:         ToFloat64(d.NextFundingRate),
+			FundingTimestamp: d.FundingTime,
+			Timestamp:        ts,
+			Date:             time.UnixMilli(ts).In(this.Config.Location).Format(GO_BIRTHDAY),
+		})
+	}
+	return nil
+}
+
+func (this *MarketStream) recvMarkPrice(pair Pair, raw json.RawMessage) error {
+	var data = make([]struct {
+		MarkPx float64 `json:"markPx,string"`
+		Ts     int64   `json:"ts,string"`
+	}, 0)
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		this.OnMarkPrice(&MarkPrice{
+			Pair:      pair,
+			Exchange:  OKEX,
+			Price:     d.MarkPx,
+			Timestamp: d.Ts,
+			Date:      time.UnixMilli(d.Ts).In(this.Config.Location).Format(GO_BIRTHDAY),
+		})
+	}
+	return nil
+}
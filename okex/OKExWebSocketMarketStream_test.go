@@ -0,0 +1,155 @@
+package okex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// go test -v ./okex/... -count=1 -run=TestMarketStream_Receiver
+func TestMarketStream_Receiver(t *testing.T) {
+	var tickers, trades, klines, rates, marks = 0, 0, 0, 0, 0
+	var stream = &MarketStream{
+		WSMarketOKEx: &WSMarketOKEx{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { t.Error(err) },
+		},
+		MarketHandler: MarketHandler{
+			SwapTickerHandler: func(ticker *SwapTicker) {
+				tickers++
+				if ticker.Last != 43000.1 || ticker.Buy != 43000 || ticker.Sell != 43000.2 {
+					t.Error("The ticker is wrong: ", ticker)
+				}
+			},
+			TradeHandler: func(trade *Trade) {
+				trades++
+				if trade.Tid != 130639474 || trade.Type != SELL || trade.Amount != 0.5 {
+					t.Error("The trade is wrong: ", trade)
+				}
+			},
+			SwapKlineHandler: func(kline *SwapKline) {
+				klines++
+				if kline.Open != 8533 || kline.Close != 8553.7 || kline.Timestamp != 1597026383085 {
+					t.Error("The kline is wrong: ", kline)
+				}
+			},
+			FundingRateHandler: func(rate *FundingRate) {
+				rates++
+				if rate.Rate != 0.0001 || rate.FundingTimestamp != 1703088000000 {
+					t.Error("The funding rate is wrong: ", rate)
+				}
+			},
+			MarkPriceHandler: func(mark *MarkPrice) {
+				marks++
+				if mark.Price != 43001.5 {
+					t.Error("The mark price is wrong: ", mark)
+				}
+			},
+		},
+		TradeType: TRADE_TYPE_SWAP,
+		pairs:     map[string]Pair{"BTC-USDT-SWAP": BTC_USDT},
+	}
+
+	var _ MarketWebsocketAPI = stream
+
+	stream.Receiver(`{"event":"subscribe","arg":{"channel":"tickers","instId":"BTC-USDT-SWAP"}}`)
+	stream.Receiver(`{"arg":{"channel":"tickers","instId":"BTC-USDT-SWAP"},"data":[{"instType":"SWAP","instId":"BTC-USDT-SWAP","last":"43000.1","askPx":"43000.2","bidPx":"43000","open24h":"42000","high24h":"43500","low24h":"41800","vol24h":"1000","ts":"1597026383085"}]}`)
+	stream.Receiver(`{"arg":{"channel":"trades","instId":"BTC-USDT-SWAP"},"data":[{"instId":"BTC-USDT-SWAP","tradeId":"130639474","px":"43000.1","sz":"0.5","side":"sell","ts":"1597026383085"}]}`)
+	stream.Receiver(`{"arg":{"channel":"candle1m","instId":"BTC-USDT-SWAP"},"data":[["1597026383085","8533","8553.74","8527.17","8553.7","45247","529.5858061","529.58","0"]]}`)
+	stream.Receiver(`{"arg":{"channel":"funding-rate","instId":"BTC-USDT-SWAP"},"data":[{"fundingRate":"0.0001","fundingTime":"1703088000000","instId":"BTC-USDT-SWAP","instType":"SWAP","nextFundingRate":"","ts":"1703070685309"}]}`)
+	stream.Receiver(`{"arg":{"channel":"mark-price","instId":"BTC-USDT-SWAP"},"data":[{"instType":"SWAP","instId":"BTC-USDT-SWAP","markPx":"43001.5","ts":"1597026383085"}]}`)
+	// the unknown instId will be dropped.
+	stream.Receiver(`{"arg":{"channel":"tickers","instId":"ETH-USDT-SWAP"},"data":[{"last":"1"}]}`)
+
+	if tickers != 1 || trades != 1 || klines != 1 || rates != 1 || marks != 1 {
+		t.Error("The handlers are not called as expected. ", tickers, trades, klines, rates, marks)
+	}
+}
+
+// go test -v ./okex/... -count=1 -run=TestMarketStream_Business
+func TestMarketStream_Business(t *testing.T) {
+	var channels = make(chan string, 8)
+	var upgrader = websocket.Upgrader{}
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var conn, err = upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var op = WSOpOKEx{}
+			if err := conn.ReadJSON(&op); err != nil {
+				return
+			}
+			for _, arg := range op.Args {
+				channels <- r.URL.Path + " " + arg["channel"]
+			}
+		}
+	}))
+	defer server.Close()
+
+	var wsUrl = "ws" + strings.TrimPrefix(server.URL, "http")
+	var stream = &MarketStream{
+		WSMarketOKEx: &WSMarketOKEx{
+			Config:       &APIConfig{Location: time.UTC},
+			ErrorHandler: func(err error) { t.Log(err) },
+			WsUrl:        wsUrl + "/public",
+		},
+		BusinessUrl: wsUrl + "/business",
+	}
+	if err := stream.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Stop()
+
+	if err := stream.SubscribeTicker(BTC_USDT); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SubscribeKline(BTC_USDT, KLINE_PERIOD_1MIN); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SubscribeMarkPrice(BTC_USDT); err != nil {
+		t.Fatal(err)
+	}
+
+	var received = make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		select {
+		case channel := <-channels:
+			received[channel] = true
+		case <-time.After(3 * time.Second):
+			t.Fatal("The subscribe is not received: ", received)
+		}
+	}
+	if !received["/public tickers"] || !received["/business candle1m"] || !received["/public mark-price"] {
+		t.Error("The candle should be in the business url, the others in the public url: ", received)
+	}
+
+	// the channels are subscribed again in both connections after the restart.
+	stream.WSMarketOKEx.restartSec = 1
+	stream.business.restartSec = 1
+	stream.Restart()
+	received = make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		select {
+		case channel := <-channels:
+			received[channel] = true
+		case <-time.After(3 * time.Second):
+			t.Fatal("The subscribe is not received after the restart: ", received)
+		}
+	}
+	if !received["/public tickers"] || !received["/business candle1m"] || !received["/public mark-price"] {
+		t.Error("The channels should be subscribed again after the restart: ", received)
+	}
+
+	stream.Stop()
+	if err := stream.SubscribeTicker(BTC_USDT); err == nil {
+		t.Error("The subscribe of the stopped stream should be an error. ")
+	}
+}
@@ -18,6 +18,9 @@ const (
 	DEFAULT_WEBSOCKET_PENDING_SEC        = 100
 	DERFAULT_WEBSOCKET_RESTART_LIMIT_NUM = 5
 	DERFAULT_WEBSOCKET_RESTART_LIMIT_SEC = 300
+
+	WEBSOCKET_PUBLIC_URL   = "wss://ws.okx.com:8443/ws/v5/public"
+	WEBSOCKET_BUSINESS_URL = "wss://ws.okx.com:8443/ws/v5/business"
 )
 
 type WSArgOKEx struct {
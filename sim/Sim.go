@@ -0,0 +1,249 @@
+package sim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+const (
+	SIM = "sim"
+
+	DEFAULT_TICK_SIZE        = 0.01
+	DEFAULT_AMOUNT_PRECISION = 4
+	DEFAULT_LEVERAGE         = 10
+)
+
+// The balance and order errors are ErrInsufficientBalance and ErrOrderNotFound of goghostex.
+var ErrNoMarketData = errors.New("There is no market data of the pair, update the depth or kline first. ")
+
+// Option is the behavior of the simulator.
+type Option struct {
+	MakerFee float64 // the fee rate of maker, eg: 0.0002
+	TakerFee float64 // the fee rate of taker, eg: 0.0005
+
+	// The delay of every private api, it's useful in testing the strategy with timeout.
+	Latency time.Duration
+
+	// The max ratio can be filled in every match, 0 means 1 (fill all).
+	// In depth, it's the ratio of every level amount. In kline, it's the ratio of the order left amount.
+	FillRatio float64
+
+	TickSize        float64
+	AmountPrecision int64
+	Leverage        int64
+
+	// The init balances, the key is the currency symbol, eg: USDT BTC.
+	// The spot use all the balances, the swap and future use the counter currency of the pair as the margin.
+	Balances map[string]float64
+}
+
+// Sim is the offline exchange, it implements SpotRestAPI, SwapRestAPI and FutureRestAPI.
+// It's driven by the market data pushed in by UpdateDepth, UpdateKline or the synthetic feed.
+type Sim struct {
+	config *APIConfig
+	option *Option
+	locker *sync.Mutex
+
+	markets  map[string]*market
+	orders   map[string]*simOrder
+	orderSeq int64
+	clock    int64
+
+	Spot   *Spot
+	Swap   *Swap
+	Future *Future
+}
+
+func New(config *APIConfig, option *Option) *Sim {
+	if option == nil {
+		option = &Option{}
+	}
+	if option.FillRatio <= 0 || option.FillRatio > 1 {
+		option.FillRatio = 1
+	}
+	if option.TickSize <= 0 {
+		option.TickSize = DEFAULT_TICK_SIZE
+	}
+	if option.AmountPrecision <= 0 {
+		option.AmountPrecision = DEFAULT_AMOUNT_PRECISION
+	}
+	if option.Leverage <= 0 {
+		option.Leverage = DEFAULT_LEVERAGE
+	}
+	if option.Balances == nil {
+		option.Balances = make(map[string]float64)
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+
+	var s = &Sim{
+		config:  config,
+		option:  option,
+		locker:  new(sync.Mutex),
+		markets: make(map[string]*market),
+		orders:  make(map[string]*simOrder),
+	}
+
+	s.Spot = &Spot{Sim: s, balances: make(map[string]*SubAccount)}
+	for symbol, amount := range option.Balances {
+		var currency = NewCurrency(symbol, "")
+		s.Spot.balances[currency.Symbol] = &SubAccount{Currency: currency, Amount: amount}
+	}
+	s.Swap = &Swap{Sim: s, ledger: newLedger(s, TRADE_TYPE_SWAP)}
+	s.Future = &Future{Sim: s, ledger: newLedger(s, TRADE_TYPE_FUTURE)}
+	return s
+}
+
+func (s *Sim) GetExchangeName() string {
+	return SIM
+}
+
+// UpdateDepth push the depth of the market, the tradeType is one of TRADE_TYPE_SPOT TRADE_TYPE_SWAP TRADE_TYPE_FUTURE,
+// the contractType is only necessary in future.
+func (s *Sim) UpdateDepth(tradeType string, pair Pair, contractType string, depth *Depth) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	var m = s.getMarket(tradeType, pair, contractType)
+	m.bids = append(DepthRecords{}, depth.BidList...)
+	m.asks = append(DepthRecords{}, depth.AskList...)
+	sort.Sort(sort.Reverse(m.bids))
+	sort.Sort(m.asks)
+	m.synthetic = false
+	if len(m.bids) > 0 && len(m.asks) > 0 {
+		m.last = (m.bids[0].Price + m.asks[0].Price) / 2
+	}
+	s.tick(depth.Timestamp)
+	m.timestamp = s.clock
+	s.matchResting(m, nil)
+	s.liquidate(m, nil)
+}
+
+// UpdateKline push the kline of the market. If there is no depth of the market,
+// the close price will be the synthetic depth with the kline volume.
+func (s *Sim) UpdateKline(tradeType string, pair Pair, contractType string, kline *Kline) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	var m = s.getMarket(tradeType, pair, contractType)
+	m.klines = append(m.klines, kline)
+	m.last = kline.Close
+	if len(m.bids) == 0 || m.synthetic {
+		var amount = kline.Vol
+		if amount <= 0 {
+			amount = 1e12
+		}
+		m.bids = DepthRecords{{Price: kline.Close, Amount: amount}}
+		m.asks = DepthRecords{{Price: kline.Close, Amount: amount}}
+		m.synthetic = true
+	}
+	s.tick(kline.Timestamp)
+	m.timestamp = s.clock
+	s.matchResting(m, kline)
+	s.liquidate(m, kline)
+}
+
+// liquidate the positions of the swap and future market, the spot has no position.
+func (s *Sim) liquidate(m *market, kline *Kline) {
+	switch m.tradeType {
+	case TRADE_TYPE_SWAP:
+		s.Swap.ledger.liquidate(m, kline)
+	case TRADE_TYPE_FUTURE:
+		s.Future.ledger.liquidate(m, kline)
+	}
+}
+
+// ReplayKlines push the klines one by one, the handler is called after every kline, it's the place of strategy.
+func (s *Sim) ReplayKlines(tradeType string, pair Pair, contractType string, klines []*Kline, handler func(*Kline)) {
+	for _, kline := range GetAscKline(klines) {
+		s.UpdateKline(tradeType, pair, contractType, kline)
+		if handler != nil {
+			handler(kline)
+		}
+	}
+}
+
+// SyntheticDepth build the depth around the mid price, it's the synthetic feed when there is no history data.
+func SyntheticDepth(pair Pair, mid, tickSize, amount float64, levels int, timestamp int64) *Depth {
+	var depth = &Depth{
+		Pair:      pair,
+		Timestamp: timestamp,
+		AskList:   make(DepthRecords, 0, levels),
+		BidList:   make(DepthRecords, 0, levels),
+	}
+	for i := 1; i <= levels; i++ {
+		depth.AskList = append(depth.AskList, DepthRecord{Price: mid + float64(i)*tickSize, Amount: amount})
+		depth.BidList = append(depth.BidList, DepthRecord{Price: mid - float64(i)*tickSize, Amount: amount})
+	}
+	return depth
+}
+
+// Now return the clock of the simulator, it's the timestamp of the last market data.
+func (s *Sim) Now() int64 {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	return s.now()
+}
+
+func (s *Sim) now() int64 {
+	if s.clock == 0 {
+		return time.Now().UnixMilli()
+	}
+	return s.clock
+}
+
+func (s *Sim) tick(timestamp int64) {
+	if timestamp > s.clock {
+		s.clock = timestamp
+	}
+}
+
+func (s *Sim) date(timestamp int64) string {
+	return time.UnixMilli(timestamp).In(s.config.Location).Format(GO_BIRTHDAY)
+}
+
+func (s *Sim) delay() {
+	if s.option.Latency > 0 {
+		time.Sleep(s.option.Latency)
+	}
+}
+
+func (s *Sim) nextOrderId() string {
+	s.orderSeq++
+	return fmt.Sprintf("%d", s.orderSeq)
+}
+
+func marketKey(tradeType string, pair Pair, contractType string) string {
+	return strings.Join([]string{tradeType, pair.String(), contractType}, ":")
+}
+
+func (s *Sim) getMarket(tradeType string, pair Pair, contractType string) *market {
+	var key = marketKey(tradeType, pair, contractType)
+	var m, exist = s.markets[key]
+	if !exist {
+		m = &market{key: key, pair: pair, tradeType: tradeType, contractType: contractType}
+		s.markets[key] = m
+	}
+	return m
+}
+
+func (s *Sim) findMarket(tradeType string, pair Pair, contractType string) (*market, error) {
+	var m, exist = s.markets[marketKey(tradeType, pair, contractType)]
+	if !exist || len(m.bids) == 0 || len(m.asks) == 0 {
+		return nil, ErrNoMarketData
+	}
+	return m, nil
+}
+
+func raw(v interface{}) []byte {
+	var data, _ = json.Marshal(v)
+	return data
+}
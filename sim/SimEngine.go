@@ -0,0 +1,307 @@
+package sim
+
+import (
+	"math"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+type market struct {
+	key          string
+	pair         Pair
+	tradeType    string
+	contractType string
+
+	bids      DepthRecords // Descending order
+	asks      DepthRecords // Ascending order
+	synthetic bool         // the depth is built by the kline close price
+	last      float64
+	timestamp int64
+
+	klines  []*Kline
+	trades  []*Trade
+	resting []*simOrder
+}
+
+type simOrder struct {
+	id        string
+	cid       string
+	market    *market
+	buy       bool
+	price     float64
+	amount    float64
+	placeType PlaceType
+
+	// only in swap and future
+	futureType FutureType
+	leverage   int64
+
+	status   TradeStatus
+	deal     float64
+	avgPrice float64
+	fee      float64
+	placeTS  int64
+	dealTS   int64
+
+	// the frozen balance or position of the order, the ledger release it in onFill and onClose.
+	frozen float64
+
+	onFill  func(o *simOrder, price, amount, fee float64)
+	onClose func(o *simOrder)
+}
+
+func (o *simOrder) left() float64 {
+	return o.amount - o.deal
+}
+
+func (o *simOrder) isFinal() bool {
+	return o.status == ORDER_FINISH || o.status == ORDER_CANCEL || o.status == ORDER_REJECT || o.status == ORDER_FAIL
+}
+
+// The amount precision of the fill, the dust less than the precision is filled at once.
+// The future amount is the count of contracts, so it's integer.
+// The liquidity is the whole amount can be filled, the fill never exceeds it.
+func (s *Sim) roundAmount(o *simOrder, amount, liquidity float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	var left = o.left()
+	var precision = s.option.AmountPrecision
+	if o.market.tradeType == TRADE_TYPE_FUTURE {
+		precision = 0
+	}
+	var base = math.Pow10(int(precision))
+	var rounded = math.Floor(amount*base) / base
+	if left-rounded < 1/base && left <= liquidity {
+		return left
+	}
+	// at least one lot is filled, or the order with the ratio will never finish.
+	// the liquidity less than one lot is not filled, the order rests for the next depth.
+	if rounded <= 0 {
+		if 1/base > liquidity {
+			return 0
+		}
+		return 1 / base
+	}
+	return rounded
+}
+
+func (s *Sim) crossed(o *simOrder, price float64) bool {
+	if o.placeType == MARKET {
+		return true
+	}
+	if o.buy {
+		return price <= o.price
+	}
+	return price >= o.price
+}
+
+// place match the order as taker, then the left amount rests in the book or is cancelled by the place type.
+func (s *Sim) place(o *simOrder) {
+	var m = o.market
+	var levels = m.asks
+	if !o.buy {
+		levels = m.bids
+	}
+
+	o.placeTS = s.now()
+	o.status = ORDER_UNFINISH
+	s.orders[o.id] = o
+
+	if o.placeType == ONLY_MAKER && len(levels) > 0 && s.crossed(o, levels[0].Price) {
+		o.status = ORDER_REJECT
+		o.onClose(o)
+		return
+	}
+
+	var fillable = 0.0
+	for _, level := range levels {
+		if !s.crossed(o, level.Price) {
+			break
+		}
+		fillable += level.Amount * s.option.FillRatio
+	}
+	if o.placeType == FOK && fillable < o.amount {
+		o.status = ORDER_CANCEL
+		o.onClose(o)
+		return
+	}
+
+	// the filled amount is taken from every level, the levels keep the left amount until the next depth.
+	for i := range levels {
+		if o.left() <= 0 || !s.crossed(o, levels[i].Price) {
+			break
+		}
+		var amount = s.roundAmount(o, math.Min(o.left(), levels[i].Amount*s.option.FillRatio), levels[i].Amount)
+		if amount <= 0 {
+			continue
+		}
+		s.fill(o, levels[i].Price, amount, s.option.TakerFee)
+		levels[i].Amount -= amount
+	}
+	prune(m)
+
+	if o.left() <= 0 {
+		o.status = ORDER_FINISH
+		o.onClose(o)
+		return
+	}
+	if o.placeType == IOC || o.placeType == FOK || o.placeType == MARKET {
+		o.status = ORDER_CANCEL
+		o.onClose(o)
+		return
+	}
+	m.resting = append(m.resting, o)
+}
+
+// matchResting fill the resting orders as maker. In depth, the opposite levels which cross the order price are filled.
+// In kline, the order is filled if the price trade through the order price.
+func (s *Sim) matchResting(m *market, kline *Kline) {
+	var resting = make([]*simOrder, 0, len(m.resting))
+	for _, o := range m.resting {
+		var available, liquidity = 0.0, 0.0
+		if kline != nil {
+			if (o.buy && kline.Low < o.price) || (!o.buy && kline.High > o.price) {
+				available, liquidity = o.left()*s.option.FillRatio, o.left()
+			}
+		} else {
+			var levels = m.asks
+			if !o.buy {
+				levels = m.bids
+			}
+			for _, level := range levels {
+				if !s.crossed(o, level.Price) {
+					break
+				}
+				available += level.Amount * s.option.FillRatio
+				liquidity += level.Amount
+			}
+		}
+
+		var amount = s.roundAmount(o, math.Min(o.left(), available), liquidity)
+		if amount > 0 {
+			s.fill(o, o.price, amount, s.option.MakerFee)
+			// the next resting order can not fill the same liquidity.
+			if kline == nil {
+				s.consume(o, amount)
+			}
+		}
+
+		if o.left() <= 0 {
+			o.status = ORDER_FINISH
+			o.onClose(o)
+			continue
+		}
+		resting = append(resting, o)
+	}
+	m.resting = resting
+}
+
+// consume take the amount from the opposite levels which cross the order price, from the best one.
+func (s *Sim) consume(o *simOrder, amount float64) {
+	var levels = o.market.asks
+	if !o.buy {
+		levels = o.market.bids
+	}
+	for i := range levels {
+		if amount <= 0 || !s.crossed(o, levels[i].Price) {
+			break
+		}
+		var taken = math.Min(amount, levels[i].Amount)
+		levels[i].Amount -= taken
+		amount -= taken
+	}
+	prune(o.market)
+}
+
+// prune remove the levels without the amount left.
+func prune(m *market) {
+	var filter = func(levels DepthRecords) DepthRecords {
+		var left = make(DepthRecords, 0, len(levels))
+		for _, level := range levels {
+			if level.Amount > 0 {
+				left = append(left, level)
+			}
+		}
+		return left
+	}
+	m.bids = filter(m.bids)
+	m.asks = filter(m.asks)
+}
+
+func (s *Sim) cancel(o *simOrder) error {
+	if o.isFinal() {
+		return ErrOrderNotFound
+	}
+	var resting = make([]*simOrder, 0, len(o.market.resting))
+	for _, r := range o.market.resting {
+		if r != o {
+			resting = append(resting, r)
+		}
+	}
+	o.market.resting = resting
+	o.status = ORDER_CANCEL
+	o.onClose(o)
+	return nil
+}
+
+func (s *Sim) fill(o *simOrder, price, amount, feeRate float64) {
+	var fee = price * amount * feeRate
+	o.avgPrice = (o.avgPrice*o.deal + price*amount) / (o.deal + amount)
+	o.deal += amount
+	o.fee += fee
+	o.dealTS = s.now()
+	if o.left() > 0 {
+		o.status = ORDER_PART_FINISH
+	}
+	o.onFill(o, price, amount, fee)
+
+	var side = BUY
+	if !o.buy {
+		side = SELL
+	}
+	o.market.trades = append(o.market.trades, &Trade{
+		Tid:       int64(len(o.market.trades) + 1),
+		Type:      side,
+		Amount:    amount,
+		Price:     price,
+		Timestamp: o.dealTS,
+		Pair:      o.market.pair,
+	})
+}
+
+// findOrder find the order by the order id, if the order id is empty, find it by the cid.
+func (s *Sim) findOrder(tradeType, orderId, cid string) (*simOrder, error) {
+	if o, exist := s.orders[orderId]; exist && o.market.tradeType == tradeType {
+		return o, nil
+	}
+	if cid != "" {
+		for _, o := range s.orders {
+			if o.cid == cid && o.market.tradeType == tradeType {
+				return o, nil
+			}
+		}
+	}
+	return nil, ErrOrderNotFound
+}
+
+// estimate the average price of the amount in the opposite levels, it's the price of the market order.
+func (s *Sim) estimatePrice(m *market, buy bool, amount float64) float64 {
+	var levels = m.asks
+	if !buy {
+		levels = m.bids
+	}
+	var left, cost = amount, 0.0
+	for _, level := range levels {
+		var fill = math.Min(left, level.Amount*s.option.FillRatio)
+		cost += fill * level.Price
+		left -= fill
+		if left <= 0 {
+			break
+		}
+	}
+	if left > 0 && len(levels) > 0 {
+		cost += left * levels[len(levels)-1].Price
+	}
+	return cost / amount
+}
@@ -0,0 +1,315 @@
+package sim
+
+import (
+	"fmt"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// Future is the linear delivery future of the simulator, the market is separated by the contractType.
+// There is no delivery in simulator, close the positions before the contract due.
+type Future struct {
+	*Sim
+
+	ledger *ledger
+}
+
+func (future *Future) GetContract(pair Pair, contractType string) (*FutureContract, error) {
+	return &FutureContract{
+		Pair:            pair,
+		Symbol:          pair.ToSymbol("_", false),
+		Exchange:        SIM,
+		ContractType:    contractType,
+		ContractName:    future.contractName(pair, contractType),
+		SettleMode:      SETTLE_MODE_COUNTER,
+		Status:          CONTRACT_STATUS_LIVE,
+		Type:            "linear",
+		UnitAmount:      UNIT_AMOUNT,
+		TickSize:        future.option.TickSize,
+		PricePrecision:  GetPrecisionInt64(future.option.TickSize),
+		AmountPrecision: 0,
+	}, nil
+}
+
+func (future *Future) GetTicker(pair Pair, contractType string) (*FutureTicker, []byte, error) {
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var m, err = future.findMarket(TRADE_TYPE_FUTURE, pair, contractType)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ticker = &FutureTicker{
+		Ticker: Ticker{
+			Pair:      pair,
+			Last:      m.last,
+			Buy:       m.bids[0].Price,
+			Sell:      m.asks[0].Price,
+			Timestamp: m.timestamp,
+			Date:      future.date(m.timestamp),
+		},
+		ContractType: contractType,
+		ContractName: future.contractName(pair, contractType),
+	}
+	if len(m.klines) > 0 {
+		var kline = m.klines[len(m.klines)-1]
+		ticker.High, ticker.Low, ticker.Vol = kline.High, kline.Low, kline.Vol
+	}
+	return ticker, raw(ticker), nil
+}
+
+func (future *Future) GetDepth(pair Pair, contractType string, size int) (*FutureDepth, []byte, error) {
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var m, err = future.findMarket(TRADE_TYPE_FUTURE, pair, contractType)
+	if err != nil {
+		return nil, nil, err
+	}
+	var depth = &FutureDepth{
+		ContractType: contractType,
+		ContractName: future.contractName(pair, contractType),
+		Pair:         pair,
+		Timestamp:    m.timestamp,
+		Sequence:     m.timestamp,
+		Date:         future.date(m.timestamp),
+		AskList:      topLevels(m.asks, size),
+		BidList:      topLevels(m.bids, size),
+	}
+	return depth, raw(depth), nil
+}
+
+// GetLimit the limit is 10% around the last price.
+func (future *Future) GetLimit(pair Pair, contractType string) (float64, float64, error) {
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var m, err = future.findMarket(TRADE_TYPE_FUTURE, pair, contractType)
+	if err != nil {
+		return 0, 0, err
+	}
+	return m.last * 1.1, m.last * 0.9, nil
+}
+
+// GetIndex there is no index in simulator, it's the last price of the spot, or the swap.
+func (future *Future) GetIndex(pair Pair) (float64, []byte, error) {
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	for _, tradeType := range []string{TRADE_TYPE_SPOT, TRADE_TYPE_SWAP} {
+		if m, err := future.findMarket(tradeType, pair, ""); err == nil {
+			return m.last, raw(m.last), nil
+		}
+	}
+	return 0, nil, ErrNoMarketData
+}
+
+// GetMark the last price is the mark price in simulator.
+func (future *Future) GetMark(pair Pair, contractType string) (float64, []byte, error) {
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var m, err = future.findMarket(TRADE_TYPE_FUTURE, pair, contractType)
+	if err != nil {
+		return 0, nil, err
+	}
+	return m.last, raw(m.last), nil
+}
+
+func (future *Future) GetKlineRecords(contractType string, pair Pair, period, size, since int) ([]*FutureKline, []byte, error) {
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var m, exist = future.markets[marketKey(TRADE_TYPE_FUTURE, pair, contractType)]
+	if !exist || len(m.klines) == 0 {
+		return nil, nil, ErrNoMarketData
+	}
+	var klines = make([]*FutureKline, 0)
+	for _, k := range future.klines(m, size, since) {
+		var kline = &FutureKline{Kline: *k, ContractType: contractType}
+		kline.Exchange = SIM
+		klines = append(klines, kline)
+	}
+	return klines, raw(klines), nil
+}
+
+// GetTrades return the fills of the orders in simulator.
+func (future *Future) GetTrades(pair Pair, contractType string) ([]*Trade, []byte, error) {
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var m, exist = future.markets[marketKey(TRADE_TYPE_FUTURE, pair, contractType)]
+	if !exist {
+		return nil, nil, ErrNoMarketData
+	}
+	var trades = append([]*Trade{}, m.trades...)
+	return trades, raw(trades), nil
+}
+
+func (future *Future) GetAccount() (*FutureAccount, []byte, error) {
+	future.delay()
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var account = &FutureAccount{
+		Exchange:   SIM,
+		SubAccount: make(map[Currency]FutureSubAccount),
+	}
+	for symbol := range future.ledger.balances {
+		var currency = NewCurrency(symbol, "")
+		var sub = future.ledger.subAccount(currency)
+		account.SubAccount[currency] = FutureSubAccount{
+			Currency:       currency,
+			Margin:         sub.Margin,
+			MarginDealed:   sub.MarginPosition,
+			MarginUnDealed: sub.MarginOpen,
+			MarginRate:     sub.MarginRate,
+			BalanceTotal:   sub.BalanceTotal,
+			BalanceNet:     sub.BalanceNet,
+			BalanceAvail:   sub.BalanceAvail,
+			ProfitReal:     sub.ProfitReal,
+			ProfitUnreal:   sub.ProfitUnreal,
+		}
+	}
+	return account, raw(account), nil
+}
+
+func (future *Future) PlaceOrder(order *FutureOrder) ([]byte, error) {
+	future.delay()
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var m, err = future.findMarket(TRADE_TYPE_FUTURE, order.Pair, order.ContractType)
+	if err != nil {
+		return nil, err
+	}
+	var o = &simOrder{
+		id:         future.nextOrderId(),
+		cid:        order.Cid,
+		market:     m,
+		price:      order.Price,
+		amount:     float64(order.Amount),
+		placeType:  order.PlaceType,
+		futureType: order.Type,
+		leverage:   order.LeverRate,
+	}
+	if err := future.ledger.place(m, o); err != nil {
+		return nil, err
+	}
+	future.toOrder(o, order)
+	return raw(order), nil
+}
+
+func (future *Future) CancelOrder(order *FutureOrder) ([]byte, error) {
+	future.delay()
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var o, err = future.findOrder(TRADE_TYPE_FUTURE, order.OrderId, order.Cid)
+	if err != nil {
+		return nil, err
+	}
+	if err := future.cancel(o); err != nil {
+		return nil, err
+	}
+	future.toOrder(o, order)
+	return raw(order), nil
+}
+
+func (future *Future) GetOrders(pair Pair, contractType string) ([]*FutureOrder, []byte, error) {
+	future.delay()
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var orders = make([]*FutureOrder, 0)
+	for _, o := range future.sortedOrders(TRADE_TYPE_FUTURE, pair) {
+		if o.market.contractType == contractType {
+			var order = &FutureOrder{}
+			future.toOrder(o, order)
+			orders = append(orders, order)
+		}
+	}
+	return orders, raw(orders), nil
+}
+
+func (future *Future) GetOrder(order *FutureOrder) ([]byte, error) {
+	future.delay()
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var o, err = future.findOrder(TRADE_TYPE_FUTURE, order.OrderId, order.Cid)
+	if err != nil {
+		return nil, err
+	}
+	future.toOrder(o, order)
+	return raw(order), nil
+}
+
+// GetPosition the FutureRestAPI has no position api, it's the position in the swap models.
+func (future *Future) GetPosition(pair Pair, contractType string, openType FutureType) (*SwapPosition, error) {
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var m, err = future.findMarket(TRADE_TYPE_FUTURE, pair, contractType)
+	if err != nil {
+		return nil, err
+	}
+	return future.ledger.snapshot(m, future.ledger.position(m, openType)), nil
+}
+
+func (future *Future) GetPairFlow(pair Pair) ([]*FutureAccountItem, []byte, error) {
+	future.delay()
+	future.locker.Lock()
+	defer future.locker.Unlock()
+
+	var items = make([]*FutureAccountItem, 0)
+	for _, f := range future.ledger.flows {
+		if !f.pair.Eq(pair) {
+			continue
+		}
+		items = append(items, &FutureAccountItem{
+			Pair:           f.pair,
+			ContractName:   future.contractName(f.pair, f.contractType),
+			Exchange:       SIM,
+			Subject:        f.subject,
+			SettleMode:     SETTLE_MODE_COUNTER,
+			SettleCurrency: f.currency,
+			Amount:         f.amount,
+			Timestamp:      f.timestamp,
+			DateTime:       future.date(f.timestamp),
+			Info:           f.info,
+		})
+	}
+	return items, raw(items), nil
+}
+
+func (future *Future) KeepAlive() {}
+
+// the contract name is the contract type, there is no due date in simulator. eg: BTC-USDT-quarter
+func (future *Future) contractName(pair Pair, contractType string) string {
+	return fmt.Sprintf("%s-%s", pair.ToSymbol("-", true), contractType)
+}
+
+func (future *Future) toOrder(o *simOrder, order *FutureOrder) {
+	order.OrderId = o.id
+	order.Cid = o.cid
+	order.Pair = o.market.pair
+	order.ContractType = o.market.contractType
+	order.ContractName = future.contractName(o.market.pair, o.market.contractType)
+	order.Exchange = SIM
+	order.Price = o.price
+	order.Amount = int64(o.amount)
+	order.AvgPrice = o.avgPrice
+	order.DealAmount = int64(o.deal)
+	order.Fee = o.fee
+	order.Status = o.status
+	order.PlaceType = o.placeType
+	order.Type = o.futureType
+	order.LeverRate = o.leverage
+	order.PlaceTimestamp = o.placeTS
+	order.PlaceDatetime = future.date(o.placeTS)
+	if o.dealTS > 0 {
+		order.DealTimestamp = o.dealTS
+		order.DealDatetime = future.date(o.dealTS)
+	}
+}
@@ -0,0 +1,61 @@
+package sim
+
+import (
+	"testing"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// go test -v ./sim/... -count=1 -run=TestFuture_PlaceOrder
+func TestFuture_PlaceOrder(t *testing.T) {
+	var s = newTestSim(&Option{
+		MakerFee:  0.0002,
+		FillRatio: 0.5,
+		Balances:  map[string]float64{"USDT": 1000},
+	})
+	var _ FutureRestAPI = s.Future
+
+	// the future amount is integer, the fill of the ratio is rounded down.
+	var order = &FutureOrder{Pair: BTC_USDT, ContractType: "quarter", Type: OPEN_SHORT, PlaceType: NORMAL, Price: 103, Amount: 3}
+	if _, err := s.Future.PlaceOrder(order); err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != ORDER_UNFINISH || order.ContractName != "BTC-USDT-quarter" {
+		t.Fatal("The order should rest in the book: ", order)
+	}
+
+	s.UpdateKline(TRADE_TYPE_FUTURE, BTC_USDT, "quarter", &Kline{Timestamp: 1700000060000, Open: 100, High: 104, Low: 100, Close: 102})
+	if _, err := s.Future.GetOrder(order); err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != ORDER_PART_FINISH || order.DealAmount != 1 {
+		t.Error("The part finish order is wrong: ", order)
+	}
+
+	s.UpdateKline(TRADE_TYPE_FUTURE, BTC_USDT, "quarter", &Kline{Timestamp: 1700000120000, Open: 102, High: 105, Low: 101, Close: 101})
+	if _, err := s.Future.GetOrder(order); err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != ORDER_PART_FINISH || order.DealAmount != 2 {
+		t.Error("The part finish order is wrong: ", order)
+	}
+
+	// the left 1 * 0.5 is rounded up to one contract.
+	s.UpdateKline(TRADE_TYPE_FUTURE, BTC_USDT, "quarter", &Kline{Timestamp: 1700000180000, Open: 101, High: 104, Low: 100, Close: 101})
+	if _, err := s.Future.GetOrder(order); err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != ORDER_FINISH || order.DealAmount != 3 || order.DealTimestamp != 1700000180000 {
+		t.Error("The finish order is wrong: ", order)
+	}
+
+	var position, _ = s.Future.GetPosition(BTC_USDT, "quarter", OPEN_SHORT)
+	if !floatEq(position.Amount, 3) || !floatEq(position.MarkPrice, 101) {
+		t.Error("The position is wrong: ", position)
+	}
+	var account, _, _ = s.Future.GetAccount()
+	var sub = account.SubAccount[USDT]
+	if !floatEq(sub.ProfitUnreal, 6) || !floatEq(sub.MarginDealed, 30.9) || !floatEq(sub.BalanceTotal, 1000-3*103*0.0002) {
+		t.Error("The account is wrong: ", sub)
+	}
+}
@@ -0,0 +1,334 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// The contract in simulator is linear, the margin is the counter currency of the pair and the unit amount is 1 basis.
+const UNIT_AMOUNT = 1.0
+
+type flow struct {
+	id           string
+	pair         Pair
+	contractType string
+	subject      string
+	currency     Currency
+	amount       float64
+	timestamp    int64
+	info         string
+}
+
+type position struct {
+	*SwapPosition
+	market *market
+}
+
+type funding struct {
+	rate      float64
+	timestamp int64
+}
+
+// ledger is the margin account shared by swap and future, the positions are isolated.
+type ledger struct {
+	*Sim
+	tradeType string
+
+	balances   map[string]float64 // the cash balance of the margin currency, the realized profit is in it.
+	profitReal map[string]float64
+	marginOpen map[string]float64 // the margin frozen by the unfinished open orders
+
+	positions map[string]*position
+	closing   map[string]float64 // the position amount frozen by the unfinished liquidate orders
+
+	flows    []*flow
+	fundings map[string][]*funding
+}
+
+func newLedger(s *Sim, tradeType string) *ledger {
+	var l = &ledger{
+		Sim:        s,
+		tradeType:  tradeType,
+		balances:   make(map[string]float64),
+		profitReal: make(map[string]float64),
+		marginOpen: make(map[string]float64),
+		positions:  make(map[string]*position),
+		closing:    make(map[string]float64),
+		flows:      make([]*flow, 0),
+		fundings:   make(map[string][]*funding),
+	}
+	for symbol, amount := range s.option.Balances {
+		l.balances[NewCurrency(symbol, "").Symbol] = amount
+	}
+	return l
+}
+
+func positionKey(m *market, openType FutureType) string {
+	return fmt.Sprintf("%s:%d", m.key, openType)
+}
+
+func (l *ledger) position(m *market, openType FutureType) *SwapPosition {
+	var key = positionKey(m, openType)
+	var pos, exist = l.positions[key]
+	if !exist {
+		pos = &position{
+			SwapPosition: &SwapPosition{
+				Pair:       m.pair,
+				Type:       openType,
+				MarginType: ISOLATED,
+				Leverage:   l.option.Leverage,
+			},
+			market: m,
+		}
+		l.positions[key] = pos
+	}
+	return pos.SwapPosition
+}
+
+func (l *ledger) addFlow(m *market, subject string, amount float64, info string) {
+	l.flows = append(l.flows, &flow{
+		id:           fmt.Sprintf("%d", len(l.flows)+1),
+		pair:         m.pair,
+		contractType: m.contractType,
+		subject:      subject,
+		currency:     m.pair.Counter,
+		amount:       amount,
+		timestamp:    l.now(),
+		info:         info,
+	})
+}
+
+// unreal return the unrealized profit of the position by the last price.
+func (l *ledger) unreal(m *market, pos *SwapPosition) float64 {
+	if pos.Amount <= 0 {
+		return 0
+	}
+	if pos.Type == OPEN_LONG {
+		return (m.last - pos.Price) * pos.Amount * UNIT_AMOUNT
+	}
+	return (pos.Price - m.last) * pos.Amount * UNIT_AMOUNT
+}
+
+// avail return the balance can be used to open, the unrealized loss is deducted.
+func (l *ledger) avail(currency Currency) float64 {
+	var margin, unreal = l.margin(currency)
+	return l.balances[currency.Symbol] + unreal - margin - l.marginOpen[currency.Symbol]
+}
+
+func (l *ledger) margin(currency Currency) (float64, float64) {
+	var margin, unreal = 0.0, 0.0
+	for _, pos := range l.positions {
+		if pos.Amount <= 0 || !pos.Pair.Counter.Eq(currency) {
+			continue
+		}
+		margin += pos.MarginAmount
+		unreal += l.unreal(pos.market, pos.SwapPosition)
+	}
+	return margin, unreal
+}
+
+// place freeze the margin in opening or the position in liquidating, then match the order.
+func (l *ledger) place(m *market, o *simOrder) error {
+	if o.amount <= 0 || (o.placeType != MARKET && o.price <= 0) {
+		return errors.New("The price or amount of the order is wrong. ")
+	}
+	if o.leverage <= 0 {
+		o.leverage = l.option.Leverage
+	}
+	var futureType, leverage = o.futureType, o.leverage
+
+	var currency = m.pair.Counter
+	switch futureType {
+	case OPEN_LONG, OPEN_SHORT:
+		o.buy = futureType == OPEN_LONG
+		var price = o.price
+		if o.placeType == MARKET {
+			price = l.estimatePrice(m, o.buy, o.amount)
+		}
+		var notional = price * o.amount * UNIT_AMOUNT
+		o.frozen = notional/float64(leverage) + notional*math.Max(l.option.MakerFee, l.option.TakerFee)
+		if l.avail(currency) < o.frozen {
+			return ErrInsufficientBalance
+		}
+		l.marginOpen[currency.Symbol] += o.frozen
+
+		o.onFill = func(o *simOrder, price, amount, fee float64) {
+			var release = o.frozen * amount / (o.left() + amount)
+			o.frozen -= release
+			l.marginOpen[currency.Symbol] -= release
+
+			var pos = l.position(m, futureType)
+			pos.Price = (pos.Price*pos.Amount + price*amount) / (pos.Amount + amount)
+			pos.Amount += amount
+			pos.MarginAmount += price * amount * UNIT_AMOUNT / float64(leverage)
+			pos.Leverage = leverage
+			l.liquidatePrice(pos)
+
+			l.balances[currency.Symbol] -= fee
+			l.addFlow(m, SUBJECT_COMMISSION, -fee, o.id)
+		}
+		o.onClose = func(o *simOrder) {
+			l.marginOpen[currency.Symbol] -= o.frozen
+			o.frozen = 0
+		}
+	case LIQUIDATE_LONG, LIQUIDATE_SHORT:
+		var openType = OPEN_LONG
+		if futureType == LIQUIDATE_SHORT {
+			openType = OPEN_SHORT
+		}
+		o.buy = futureType == LIQUIDATE_SHORT
+		var key = positionKey(m, openType)
+		var pos = l.position(m, openType)
+		if pos.Amount-l.closing[key] < o.amount {
+			return ErrInsufficientBalance
+		}
+		o.frozen = o.amount
+		l.closing[key] += o.amount
+
+		o.onFill = func(o *simOrder, price, amount, fee float64) {
+			o.frozen -= amount
+			l.closing[key] -= amount
+
+			var profit = (price - pos.Price) * amount * UNIT_AMOUNT
+			if openType == OPEN_SHORT {
+				profit = -profit
+			}
+			pos.MarginAmount -= pos.MarginAmount * amount / pos.Amount
+			pos.Amount -= amount
+			if pos.Amount <= 0 {
+				pos.Amount, pos.Price, pos.MarginAmount, pos.LiquidatePrice = 0, 0, 0, 0
+			}
+
+			l.balances[currency.Symbol] += profit - fee
+			l.profitReal[currency.Symbol] += profit
+			l.addFlow(m, SUBJECT_SETTLE, profit, o.id)
+			l.addFlow(m, SUBJECT_COMMISSION, -fee, o.id)
+		}
+		o.onClose = func(o *simOrder) {
+			l.closing[key] -= o.frozen
+			o.frozen = 0
+		}
+	default:
+		return errors.New("The type of the order is wrong. ")
+	}
+
+	l.Sim.place(o)
+	return nil
+}
+
+// the isolated liquidate price without the maintenance margin.
+func (l *ledger) liquidatePrice(pos *SwapPosition) {
+	if pos.Type == OPEN_LONG {
+		pos.LiquidatePrice = pos.Price * (1 - 1/float64(pos.Leverage))
+	} else {
+		pos.LiquidatePrice = pos.Price * (1 + 1/float64(pos.Leverage))
+	}
+}
+
+// liquidate close the position at the liquidate price when the price touches it, the margin of the position is lost.
+// In kline, the low and high are checked, in depth, the last price is checked.
+func (l *ledger) liquidate(m *market, kline *Kline) {
+	var low, high = m.last, m.last
+	if kline != nil {
+		low, high = kline.Low, kline.High
+	}
+	for _, openType := range []FutureType{OPEN_LONG, OPEN_SHORT} {
+		var pos, exist = l.positions[positionKey(m, openType)]
+		if !exist || pos.Amount <= 0 {
+			continue
+		}
+		if (openType == OPEN_LONG && low > pos.LiquidatePrice) || (openType == OPEN_SHORT && high < pos.LiquidatePrice) {
+			continue
+		}
+
+		// the liquidate orders of the position can not fill any more.
+		var liquidateType = LIQUIDATE_LONG
+		if openType == OPEN_SHORT {
+			liquidateType = LIQUIDATE_SHORT
+		}
+		for _, o := range append([]*simOrder{}, m.resting...) {
+			if o.futureType == liquidateType {
+				_ = l.cancel(o)
+			}
+		}
+
+		var profit = (pos.LiquidatePrice - pos.Price) * pos.Amount * UNIT_AMOUNT
+		if openType == OPEN_SHORT {
+			profit = -profit
+		}
+		pos.Amount, pos.Price, pos.MarginAmount, pos.LiquidatePrice = 0, 0, 0, 0
+
+		l.balances[m.pair.Counter.Symbol] += profit
+		l.profitReal[m.pair.Counter.Symbol] += profit
+		l.addFlow(m, SUBJECT_SETTLE, profit, "liquidation")
+	}
+}
+
+// snapshot copy the position with the mark price, the last price is the mark price in simulator.
+func (l *ledger) snapshot(m *market, pos *SwapPosition) *SwapPosition {
+	var p = *pos
+	p.MarkPrice = m.last
+	return &p
+}
+
+// settleFunding the long pay the short when the rate is positive.
+func (l *ledger) settleFunding(m *market, rate float64) {
+	l.fundings[m.key] = append(l.fundings[m.key], &funding{rate: rate, timestamp: l.now()})
+	for _, openType := range []FutureType{OPEN_LONG, OPEN_SHORT} {
+		var pos, exist = l.positions[positionKey(m, openType)]
+		if !exist || pos.Amount <= 0 {
+			continue
+		}
+		var fee = m.last * pos.Amount * UNIT_AMOUNT * rate
+		if openType == OPEN_LONG {
+			fee = -fee
+		}
+		l.balances[m.pair.Counter.Symbol] += fee
+		l.addFlow(m, SUBJECT_FUNDING_FEE, fee, fmt.Sprintf("%f", rate))
+	}
+}
+
+// subAccount return the account of the margin currency in the swap models.
+func (l *ledger) subAccount(currency Currency) *SwapAccount {
+	var margin, unreal = l.margin(currency)
+	var account = &SwapAccount{
+		Exchange:       SIM,
+		Currency:       currency,
+		MarginPosition: margin,
+		MarginOpen:     l.marginOpen[currency.Symbol],
+		BalanceTotal:   l.balances[currency.Symbol],
+		ProfitReal:     l.profitReal[currency.Symbol],
+		ProfitUnreal:   unreal,
+		Positions:      make([]*SwapPosition, 0),
+	}
+	account.Margin = account.MarginPosition + account.MarginOpen
+	account.BalanceNet = account.BalanceTotal + account.ProfitUnreal
+	account.BalanceAvail = account.BalanceNet - account.Margin
+	if account.BalanceNet > 0 {
+		account.MarginRate = account.Margin / account.BalanceNet
+	}
+	for _, pos := range l.positions {
+		if pos.Amount <= 0 || !pos.Pair.Counter.Eq(currency) {
+			continue
+		}
+		account.Positions = append(account.Positions, l.snapshot(pos.market, pos.SwapPosition))
+	}
+	return account
+}
+
+// defaultCurrency the usdt is default, or the first margin currency in alphabet.
+func (l *ledger) defaultCurrency() Currency {
+	if _, exist := l.balances[USDT.Symbol]; exist || len(l.balances) == 0 {
+		return USDT
+	}
+	var first = ""
+	for symbol := range l.balances {
+		if first == "" || symbol < first {
+			first = symbol
+		}
+	}
+	return NewCurrency(first, "")
+}
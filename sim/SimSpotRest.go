@@ -0,0 +1,337 @@
+package sim
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// Spot is the spot of the simulator. The Amount of SubAccount is the available balance,
+// the AmountFrozen is frozen by the unfinished orders.
+type Spot struct {
+	*Sim
+
+	balances map[string]*SubAccount
+}
+
+func (spot *Spot) GetTicker(pair Pair) (*Ticker, []byte, error) {
+	spot.locker.Lock()
+	defer spot.locker.Unlock()
+
+	var m, err = spot.findMarket(TRADE_TYPE_SPOT, pair, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var ticker = &Ticker{
+		Pair:      pair,
+		Last:      m.last,
+		Buy:       m.bids[0].Price,
+		Sell:      m.asks[0].Price,
+		Timestamp: m.timestamp,
+		Date:      spot.date(m.timestamp),
+	}
+	if len(m.klines) > 0 {
+		var kline = m.klines[len(m.klines)-1]
+		ticker.High, ticker.Low, ticker.Vol = kline.High, kline.Low, kline.Vol
+	}
+	return ticker, raw(ticker), nil
+}
+
+func (spot *Spot) GetDepth(pair Pair, size int) (*Depth, []byte, error) {
+	spot.locker.Lock()
+	defer spot.locker.Unlock()
+
+	var m, err = spot.findMarket(TRADE_TYPE_SPOT, pair, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var depth = &Depth{
+		Pair:      pair,
+		Timestamp: m.timestamp,
+		Sequence:  m.timestamp,
+		Date:      spot.date(m.timestamp),
+		AskList:   topLevels(m.asks, size),
+		BidList:   topLevels(m.bids, size),
+	}
+	return depth, raw(depth), nil
+}
+
+// GetKlineRecords return the klines pushed in, the period is decided by the pushed klines.
+func (spot *Spot) GetKlineRecords(pair Pair, period, size, since int) ([]*Kline, []byte, error) {
+	spot.locker.Lock()
+	defer spot.locker.Unlock()
+
+	var m, exist = spot.markets[marketKey(TRADE_TYPE_SPOT, pair, "")]
+	if !exist || len(m.klines) == 0 {
+		return nil, nil, ErrNoMarketData
+	}
+	var klines = spot.klines(m, size, since)
+	return klines, raw(klines), nil
+}
+
+// GetTrades return the fills of the orders in simulator.
+func (spot *Spot) GetTrades(pair Pair, since int64) ([]*Trade, error) {
+	spot.locker.Lock()
+	defer spot.locker.Unlock()
+
+	var m, exist = spot.markets[marketKey(TRADE_TYPE_SPOT, pair, "")]
+	if !exist {
+		return nil, ErrNoMarketData
+	}
+	var trades = make([]*Trade, 0)
+	for _, trade := range m.trades {
+		if trade.Timestamp >= since {
+			trades = append(trades, trade)
+		}
+	}
+	return trades, nil
+}
+
+// GetAccount return the balances, the Asset is valued in USDT by the last price of the spot markets.
+func (spot *Spot) GetAccount() (*Account, []byte, error) {
+	spot.delay()
+	spot.locker.Lock()
+	defer spot.locker.Unlock()
+
+	var account = &Account{
+		Exchange:    SIM,
+		SubAccounts: make(map[string]SubAccount),
+	}
+	for symbol, sub := range spot.balances {
+		account.SubAccounts[symbol] = *sub
+		var total = sub.Amount + sub.AmountFrozen
+		if symbol == USDT.Symbol {
+			account.Asset += total
+		} else if m, exist := spot.markets[marketKey(TRADE_TYPE_SPOT, Pair{Basis: sub.Currency, Counter: USDT}, "")]; exist {
+			account.Asset += total * m.last
+		}
+	}
+	account.NetAsset = account.Asset
+	return account, raw(account), nil
+}
+
+func (spot *Spot) PlaceOrder(order *Order) ([]byte, error) {
+	spot.delay()
+	spot.locker.Lock()
+	defer spot.locker.Unlock()
+
+	var m, err = spot.findMarket(TRADE_TYPE_SPOT, order.Pair, "")
+	if err != nil {
+		return nil, err
+	}
+	if order.Amount <= 0 || (order.OrderType != MARKET && order.Price <= 0) {
+		return nil, errors.New("The price or amount of the order is wrong. ")
+	}
+	if order.Side != BUY && order.Side != SELL {
+		return nil, errors.New("The side of the order is wrong. ")
+	}
+
+	var o = &simOrder{
+		id:        spot.nextOrderId(),
+		cid:       order.Cid,
+		market:    m,
+		buy:       order.Side == BUY,
+		price:     order.Price,
+		amount:    order.Amount,
+		placeType: order.OrderType,
+	}
+
+	// freeze the counter in buying, the basis in selling.
+	var frozen = spot.balance(order.Pair.Basis)
+	o.frozen = order.Amount
+	if o.buy {
+		var price = order.Price
+		if order.OrderType == MARKET {
+			price = spot.estimatePrice(m, true, order.Amount)
+		}
+		frozen = spot.balance(order.Pair.Counter)
+		o.frozen = price * order.Amount * (1 + math.Max(spot.option.MakerFee, spot.option.TakerFee))
+	}
+	if frozen.Amount < o.frozen {
+		return nil, ErrInsufficientBalance
+	}
+	frozen.Amount -= o.frozen
+	frozen.AmountFrozen += o.frozen
+
+	o.onFill = func(o *simOrder, price, amount, fee float64) {
+		var basis, counter = spot.balance(m.pair.Basis), spot.balance(m.pair.Counter)
+		if o.buy {
+			counter.AmountFrozen -= price*amount + fee
+			o.frozen -= price*amount + fee
+			basis.Amount += amount
+		} else {
+			basis.AmountFrozen -= amount
+			o.frozen -= amount
+			counter.Amount += price*amount - fee
+		}
+	}
+	o.onClose = func(o *simOrder) {
+		var sub = spot.balance(m.pair.Basis)
+		if o.buy {
+			sub = spot.balance(m.pair.Counter)
+		}
+		sub.AmountFrozen -= o.frozen
+		sub.Amount += o.frozen
+		o.frozen = 0
+	}
+
+	spot.place(o)
+	spot.toOrder(o, order)
+	return raw(order), nil
+}
+
+func (spot *Spot) CancelOrder(order *Order) ([]byte, error) {
+	spot.delay()
+	spot.locker.Lock()
+	defer spot.locker.Unlock()
+
+	var o, err = spot.findOrder(TRADE_TYPE_SPOT, order.OrderId, order.Cid)
+	if err != nil {
+		return nil, err
+	}
+	if err := spot.cancel(o); err != nil {
+		return nil, err
+	}
+	spot.toOrder(o, order)
+	return raw(order), nil
+}
+
+func (spot *Spot) GetOrder(order *Order) ([]byte, error) {
+	spot.delay()
+	spot.locker.Lock()
+	defer spot.locker.Unlock()
+
+	var o, err = spot.findOrder(TRADE_TYPE_SPOT, order.OrderId, order.Cid)
+	if err != nil {
+		return nil, err
+	}
+	spot.toOrder(o, order)
+	return raw(order), nil
+}
+
+// GetOrders return the finished orders which have dealt.
+func (spot *Spot) GetOrders(pair Pair) ([]*Order, error) {
+	spot.delay()
+	spot.locker.Lock()
+	defer spot.locker.Unlock()
+
+	var orders = make([]*Order, 0)
+	for _, o := range spot.sortedOrders(TRADE_TYPE_SPOT, pair) {
+		if o.isFinal() && o.deal > 0 {
+			var order = &Order{}
+			spot.toOrder(o, order)
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (spot *Spot) GetUnFinishOrders(pair Pair) ([]*Order, []byte, error) {
+	spot.delay()
+	spot.locker.Lock()
+	defer spot.locker.Unlock()
+
+	var orders = make([]*Order, 0)
+	for _, o := range spot.sortedOrders(TRADE_TYPE_SPOT, pair) {
+		if !o.isFinal() {
+			var order = &Order{}
+			spot.toOrder(o, order)
+			orders = append(orders, order)
+		}
+	}
+	return orders, raw(orders), nil
+}
+
+func (spot *Spot) KeepAlive() {}
+
+// GetOHLCs the symbol is the pair string, eg: btc_usdt
+func (spot *Spot) GetOHLCs(symbol string, period, size, since int) ([]*OHLC, []byte, error) {
+	var klines, _, err = spot.GetKlineRecords(NewPair(symbol, "_"), period, size, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ohlcs = make([]*OHLC, 0, len(klines))
+	for _, k := range klines {
+		ohlcs = append(ohlcs, &OHLC{
+			Symbol:    symbol,
+			Exchange:  SIM,
+			Timestamp: k.Timestamp,
+			Date:      k.Date,
+			Open:      k.Open,
+			Close:     k.Close,
+			High:      k.High,
+			Low:       k.Low,
+			Vol:       k.Vol,
+		})
+	}
+	return ohlcs, raw(ohlcs), nil
+}
+
+func (spot *Spot) balance(currency Currency) *SubAccount {
+	var sub, exist = spot.balances[currency.Symbol]
+	if !exist {
+		sub = &SubAccount{Currency: currency}
+		spot.balances[currency.Symbol] = sub
+	}
+	return sub
+}
+
+func (spot *Spot) toOrder(o *simOrder, order *Order) {
+	order.OrderId = o.id
+	order.Cid = o.cid
+	order.Pair = o.market.pair
+	order.Price = o.price
+	order.Amount = o.amount
+	order.AvgPrice = o.avgPrice
+	order.DealAmount = o.deal
+	order.Fee = o.fee
+	order.Status = o.status
+	order.OrderType = o.placeType
+	order.Side = SELL
+	if o.buy {
+		order.Side = BUY
+	}
+	order.OrderTimestamp = o.placeTS
+	order.OrderDate = spot.date(o.placeTS)
+	order.PlaceTimestamp = o.placeTS
+	order.PlaceDatetime = spot.date(o.placeTS)
+	if o.dealTS > 0 {
+		order.DealTimestamp = o.dealTS
+		order.DealDatetime = spot.date(o.dealTS)
+	}
+}
+
+func (s *Sim) sortedOrders(tradeType string, pair Pair) []*simOrder {
+	var orders = make([]*simOrder, 0)
+	for _, o := range s.orders {
+		if o.market.tradeType == tradeType && o.market.pair.Eq(pair) {
+			orders = append(orders, o)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return ToInt64(orders[i].id) < ToInt64(orders[j].id)
+	})
+	return orders
+}
+
+func (s *Sim) klines(m *market, size, since int) []*Kline {
+	var klines = make([]*Kline, 0, len(m.klines))
+	for _, k := range m.klines {
+		if k.Timestamp >= int64(since) {
+			klines = append(klines, k)
+		}
+	}
+	if size > 0 && len(klines) > size {
+		klines = klines[len(klines)-size:]
+	}
+	return klines
+}
+
+func topLevels(levels DepthRecords, size int) DepthRecords {
+	if size > 0 && len(levels) > size {
+		levels = levels[:size]
+	}
+	return append(DepthRecords{}, levels...)
+}
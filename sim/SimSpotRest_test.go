@@ -0,0 +1,264 @@
+package sim
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+func newTestSim(option *Option) *Sim {
+	var s = New(&APIConfig{}, option)
+	var depth = SyntheticDepth(BTC_USDT, 100, 1, 1, 5, 1700000000000)
+	s.UpdateDepth(TRADE_TYPE_SPOT, BTC_USDT, "", depth)
+	s.UpdateDepth(TRADE_TYPE_SWAP, BTC_USDT, "", depth)
+	s.UpdateDepth(TRADE_TYPE_FUTURE, BTC_USDT, "quarter", depth)
+	return s
+}
+
+func floatEq(a, b float64) bool {
+	return math.Abs(a-b) < 1e-8
+}
+
+// go test -v ./sim/... -count=1 -run=TestSpot_PlaceOrder
+func TestSpot_PlaceOrder(t *testing.T) {
+	var s = newTestSim(&Option{
+		MakerFee: 0.001,
+		TakerFee: 0.002,
+		Balances: map[string]float64{"USDT": 10000, "BTC": 1},
+	})
+	var _ SpotRestAPI = s.Spot
+
+	// the taker buy walk the asks 101 102, the fee is taker.
+	var buy = &Order{Pair: BTC_USDT, Side: BUY, Price: 102, Amount: 1.5, OrderType: NORMAL}
+	if _, err := s.Spot.PlaceOrder(buy); err != nil {
+		t.Fatal(err)
+	}
+	if buy.Status != ORDER_FINISH || !floatEq(buy.DealAmount, 1.5) || !floatEq(buy.AvgPrice, (101+51)/1.5) {
+		t.Error("The taker buy is wrong: ", buy)
+	}
+	if !floatEq(buy.Fee, 152*0.002) {
+		t.Error("The taker fee is wrong: ", buy.Fee)
+	}
+	var account, _, _ = s.Spot.GetAccount()
+	if !floatEq(account.SubAccounts["USDT"].Amount, 10000-152-152*0.002) ||
+		!floatEq(account.SubAccounts["USDT"].AmountFrozen, 0) ||
+		!floatEq(account.SubAccounts["BTC"].Amount, 2.5) {
+		t.Error("The balance after buy is wrong: ", account.SubAccounts)
+	}
+
+	// only maker is rejected when it cross the book.
+	var maker = &Order{Pair: BTC_USDT, Side: SELL, Price: 99, Amount: 1, OrderType: ONLY_MAKER}
+	if _, err := s.Spot.PlaceOrder(maker); err != nil {
+		t.Fatal(err)
+	}
+	if maker.Status != ORDER_REJECT {
+		t.Error("The only maker order should be rejected: ", maker.Status)
+	}
+
+	// fok is cancelled when the book is not enough, ioc fill the part.
+	var fok = &Order{Pair: BTC_USDT, Side: SELL, Price: 98, Amount: 2.5, OrderType: FOK}
+	var ioc = &Order{Pair: BTC_USDT, Side: SELL, Price: 98, Amount: 2.5, OrderType: IOC}
+	if _, err := s.Spot.PlaceOrder(fok); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Spot.PlaceOrder(ioc); err != nil {
+		t.Fatal(err)
+	}
+	if fok.Status != ORDER_CANCEL || fok.DealAmount != 0 {
+		t.Error("The fok order is wrong: ", fok)
+	}
+	if ioc.Status != ORDER_CANCEL || !floatEq(ioc.DealAmount, 2) {
+		t.Error("The ioc order is wrong: ", ioc)
+	}
+
+	account, _, _ = s.Spot.GetAccount()
+	if !floatEq(account.SubAccounts["BTC"].Amount, 0.5) || !floatEq(account.SubAccounts["BTC"].AmountFrozen, 0) {
+		t.Error("The balance after sell is wrong: ", account.SubAccounts)
+	}
+
+	if _, err := s.Spot.PlaceOrder(&Order{Pair: BTC_USDT, Side: SELL, Price: 100, Amount: 1}); err != ErrInsufficientBalance {
+		t.Error("The balance should be insufficient: ", err)
+	}
+}
+
+// go test -v ./sim/... -count=1 -run=TestSpot_RestingOrder
+func TestSpot_RestingOrder(t *testing.T) {
+	var s = newTestSim(&Option{
+		MakerFee:  0.001,
+		FillRatio: 0.5,
+		Balances:  map[string]float64{"USDT": 1000},
+	})
+
+	var order = &Order{Pair: BTC_USDT, Cid: "cid-1", Side: BUY, Price: 95, Amount: 2, OrderType: ONLY_MAKER}
+	if _, err := s.Spot.PlaceOrder(order); err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != ORDER_UNFINISH {
+		t.Fatal("The order should rest in the book: ", order.Status)
+	}
+	var account, _, _ = s.Spot.GetAccount()
+	if !floatEq(account.SubAccounts["USDT"].AmountFrozen, 95*2*1.001) {
+		t.Error("The frozen is wrong: ", account.SubAccounts["USDT"])
+	}
+
+	// the kline trade through the price, fill the half by the ratio.
+	s.UpdateKline(TRADE_TYPE_SPOT, BTC_USDT, "", &Kline{Timestamp: 1700000060000, Open: 100, High: 100, Low: 94, Close: 96, Vol: 10})
+	var query = &Order{Cid: "cid-1"}
+	if _, err := s.Spot.GetOrder(query); err != nil {
+		t.Fatal(err)
+	}
+	if query.Status != ORDER_PART_FINISH || !floatEq(query.DealAmount, 1) || !floatEq(query.Fee, 95*0.001) {
+		t.Error("The part finish order is wrong: ", query)
+	}
+
+	var unfinish, _, _ = s.Spot.GetUnFinishOrders(BTC_USDT)
+	if len(unfinish) != 1 {
+		t.Error("The unfinish orders are wrong: ", len(unfinish))
+	}
+
+	if _, err := s.Spot.CancelOrder(query); err != nil {
+		t.Fatal(err)
+	}
+	account, _, _ = s.Spot.GetAccount()
+	if !floatEq(account.SubAccounts["USDT"].Amount, 1000-95-95*0.001) ||
+		!floatEq(account.SubAccounts["USDT"].AmountFrozen, 0) ||
+		!floatEq(account.SubAccounts["BTC"].Amount, 1) {
+		t.Error("The balance after cancel is wrong: ", account.SubAccounts)
+	}
+
+	var orders, _ = s.Spot.GetOrders(BTC_USDT)
+	if len(orders) != 1 || orders[0].Status != ORDER_CANCEL {
+		t.Error("The dealed orders are wrong: ", orders)
+	}
+	if _, err := s.Spot.CancelOrder(query); err != ErrOrderNotFound {
+		t.Error("The cancelled order can not be cancelled again: ", err)
+	}
+}
+
+// go test -v ./sim/... -count=1 -run=TestSim_LevelLiquidity
+func TestSim_LevelLiquidity(t *testing.T) {
+	var s = New(&APIConfig{}, &Option{
+		FillRatio: 0.5,
+		Balances:  map[string]float64{"USDT": 10000},
+	})
+	s.UpdateDepth(TRADE_TYPE_SPOT, BTC_USDT, "", &Depth{
+		Timestamp: 1700000000000,
+		BidList:   DepthRecords{{Price: 98, Amount: 1}},
+		AskList:   DepthRecords{{Price: 100, Amount: 1}, {Price: 100.5, Amount: 0.0001}, {Price: 101, Amount: 5}},
+	})
+
+	// the dust level 100.5 is used up, the partly filled level 100 is still in the book.
+	var buy = &Order{Pair: BTC_USDT, Side: BUY, Price: 101, Amount: 0.6, OrderType: IOC}
+	if _, err := s.Spot.PlaceOrder(buy); err != nil {
+		t.Fatal(err)
+	}
+	if !floatEq(buy.DealAmount, 0.6) {
+		t.Error("The taker buy is wrong: ", buy)
+	}
+	var depth, _, _ = s.Spot.GetDepth(BTC_USDT, 0)
+	if len(depth.AskList) != 2 || depth.AskList[0].Price != 100 || !floatEq(depth.AskList[0].Amount, 0.5) ||
+		depth.AskList[1].Price != 101 || !floatEq(depth.AskList[1].Amount, 4.9001) {
+		t.Error("The asks left are wrong: ", depth.AskList)
+	}
+
+	// the resting orders share the liquidity of the level, the second one fill the half of the left.
+	var first = &Order{Pair: BTC_USDT, Side: BUY, Price: 99, Amount: 1, OrderType: ONLY_MAKER}
+	var second = &Order{Pair: BTC_USDT, Side: BUY, Price: 99, Amount: 1, OrderType: ONLY_MAKER}
+	for _, order := range []*Order{first, second} {
+		if _, err := s.Spot.PlaceOrder(order); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.UpdateDepth(TRADE_TYPE_SPOT, BTC_USDT, "", &Depth{
+		Timestamp: 1700000060000,
+		BidList:   DepthRecords{{Price: 97, Amount: 1}},
+		AskList:   DepthRecords{{Price: 98.5, Amount: 1}, {Price: 99.5, Amount: 3}},
+	})
+	for _, order := range []*Order{first, second} {
+		if _, err := s.Spot.GetOrder(order); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !floatEq(first.DealAmount, 0.5) || !floatEq(second.DealAmount, 0.25) {
+		t.Error("The resting orders are wrong: ", first.DealAmount, second.DealAmount)
+	}
+	depth, _, _ = s.Spot.GetDepth(BTC_USDT, 0)
+	if len(depth.AskList) != 2 || !floatEq(depth.AskList[0].Amount, 0.25) || !floatEq(depth.AskList[1].Amount, 3) {
+		t.Error("The asks left are wrong: ", depth.AskList)
+	}
+}
+
+// go test -v ./sim/... -count=1 -run=TestSim_ReplayKlines
+func TestSim_ReplayKlines(t *testing.T) {
+	var s = New(&APIConfig{}, &Option{Balances: map[string]float64{"USDT": 1000}})
+	var klines = []*Kline{
+		{Timestamp: 1700000120000, Open: 102, High: 103, Low: 101, Close: 102},
+		{Timestamp: 1700000060000, Open: 101, High: 102, Low: 100, Close: 102},
+		{Timestamp: 1700000000000, Open: 100, High: 101, Low: 99, Close: 101},
+	}
+
+	var placed = false
+	s.ReplayKlines(TRADE_TYPE_SPOT, BTC_USDT, "", klines, func(kline *Kline) {
+		if placed {
+			return
+		}
+		placed = true
+		var order = &Order{Pair: BTC_USDT, Side: BUY, Price: 100.5, Amount: 1}
+		if _, err := s.Spot.PlaceOrder(order); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if s.Now() != 1700000120000 {
+		t.Error("The clock is wrong: ", s.Now())
+	}
+	var orders, _ = s.Spot.GetOrders(BTC_USDT)
+	if len(orders) != 1 || orders[0].Status != ORDER_FINISH || orders[0].DealTimestamp != 1700000060000 {
+		t.Error("The order should be filled by the second kline: ", orders)
+	}
+	var history, _, _ = s.Spot.GetKlineRecords(BTC_USDT, KLINE_PERIOD_1MIN, 2, 0)
+	if len(history) != 2 || history[1].Timestamp != 1700000120000 {
+		t.Error("The kline records are wrong: ", history)
+	}
+}
+
+// go test -v ./sim/... -count=1 -run=TestSpot_DustLiquidity
+func TestSpot_DustLiquidity(t *testing.T) {
+	var s = newTestSim(&Option{
+		FillRatio:       0.5,
+		AmountPrecision: 1,
+		Balances:        map[string]float64{"USDT": 10000},
+	})
+	s.UpdateDepth(TRADE_TYPE_SPOT, BTC_USDT, "", &Depth{
+		Timestamp: 1700000060000,
+		AskList:   DepthRecords{{Price: 101, Amount: 0.05}},
+		BidList:   DepthRecords{{Price: 99, Amount: 1}},
+	})
+
+	// the ask is less than one lot, the order is not filled and rests.
+	var buy = &Order{Pair: BTC_USDT, Side: BUY, Price: 101, Amount: 1, OrderType: NORMAL}
+	if _, err := s.Spot.PlaceOrder(buy); err != nil {
+		t.Fatal(err)
+	}
+	if buy.Status != ORDER_UNFINISH || buy.DealAmount != 0 {
+		t.Error("The buy should rest without fill: ", buy)
+	}
+	var depth, _, _ = s.Spot.GetDepth(BTC_USDT, 5)
+	if len(depth.AskList) != 1 || !floatEq(depth.AskList[0].Amount, 0.05) {
+		t.Error("The ask level should not be overfilled: ", depth.AskList)
+	}
+
+	// the dust left is filled only when the level has the whole amount.
+	s.UpdateDepth(TRADE_TYPE_SPOT, BTC_USDT, "", &Depth{
+		Timestamp: 1700000120000,
+		AskList:   DepthRecords{{Price: 101, Amount: 4}},
+		BidList:   DepthRecords{{Price: 99, Amount: 1}},
+	})
+	if _, err := s.Spot.GetOrder(buy); err != nil {
+		t.Fatal(err)
+	}
+	if buy.Status != ORDER_FINISH || !floatEq(buy.DealAmount, 1) {
+		t.Error("The buy should be filled: ", buy)
+	}
+}
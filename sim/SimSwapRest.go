@@ -0,0 +1,333 @@
+package sim
+
+import (
+	"errors"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// Swap is the linear perpetual swap of the simulator, the margin is the counter currency of the pair.
+type Swap struct {
+	*Sim
+
+	ledger *ledger
+}
+
+func (swap *Swap) GetTicker(pair Pair) (*SwapTicker, []byte, error) {
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var m, err = swap.findMarket(TRADE_TYPE_SWAP, pair, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var ticker = &SwapTicker{
+		Pair:      pair,
+		Last:      m.last,
+		Buy:       m.bids[0].Price,
+		Sell:      m.asks[0].Price,
+		Timestamp: m.timestamp,
+		Date:      swap.date(m.timestamp),
+	}
+	if len(m.klines) > 0 {
+		var kline = m.klines[len(m.klines)-1]
+		ticker.High, ticker.Low, ticker.Vol = kline.High, kline.Low, kline.Vol
+	}
+	return ticker, raw(ticker), nil
+}
+
+func (swap *Swap) GetDepth(pair Pair, size int) (*SwapDepth, []byte, error) {
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var m, err = swap.findMarket(TRADE_TYPE_SWAP, pair, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var depth = &SwapDepth{
+		Pair:      pair,
+		Timestamp: m.timestamp,
+		Sequence:  m.timestamp,
+		Date:      swap.date(m.timestamp),
+		AskList:   topLevels(m.asks, size),
+		BidList:   topLevels(m.bids, size),
+	}
+	return depth, raw(depth), nil
+}
+
+func (swap *Swap) GetContract(pair Pair) *SwapContract {
+	return &SwapContract{
+		Pair:            pair,
+		Symbol:          pair.ToSymbol("_", false),
+		Exchange:        SIM,
+		ContractName:    pair.ToSwapContractName(),
+		SettleMode:      SETTLE_MODE_COUNTER,
+		UnitAmount:      UNIT_AMOUNT,
+		TickSize:        swap.option.TickSize,
+		PricePrecision:  GetPrecisionInt64(swap.option.TickSize),
+		AmountPrecision: swap.option.AmountPrecision,
+	}
+}
+
+// GetLimit the limit is 10% around the last price.
+func (swap *Swap) GetLimit(pair Pair) (float64, float64, error) {
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var m, err = swap.findMarket(TRADE_TYPE_SWAP, pair, "")
+	if err != nil {
+		return 0, 0, err
+	}
+	return m.last * 1.1, m.last * 0.9, nil
+}
+
+func (swap *Swap) GetKline(pair Pair, period, size, since int) ([]*SwapKline, []byte, error) {
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var m, exist = swap.markets[marketKey(TRADE_TYPE_SWAP, pair, "")]
+	if !exist || len(m.klines) == 0 {
+		return nil, nil, ErrNoMarketData
+	}
+	var klines = make([]*SwapKline, 0)
+	for _, k := range swap.klines(m, size, since) {
+		klines = append(klines, &SwapKline{
+			Pair:      pair,
+			Exchange:  SIM,
+			Timestamp: k.Timestamp,
+			Date:      k.Date,
+			Open:      k.Open,
+			Close:     k.Close,
+			High:      k.High,
+			Low:       k.Low,
+			Vol:       k.Vol,
+		})
+	}
+	return klines, raw(klines), nil
+}
+
+// GetOpenAmount the open interest of the market is unknown in simulator, it's always 0.
+func (swap *Swap) GetOpenAmount(pair Pair) (float64, int64, []byte, error) {
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var m, err = swap.findMarket(TRADE_TYPE_SWAP, pair, "")
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return 0, m.timestamp, nil, nil
+}
+
+// GetFundingFees return the rates settled by SettleFunding, every item is [rate, timestamp].
+func (swap *Swap) GetFundingFees(pair Pair) ([][]interface{}, []byte, error) {
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var fees = make([][]interface{}, 0)
+	for _, f := range swap.ledger.fundings[marketKey(TRADE_TYPE_SWAP, pair, "")] {
+		fees = append(fees, []interface{}{f.rate, f.timestamp})
+	}
+	return fees, raw(fees), nil
+}
+
+func (swap *Swap) GetFundingFee(pair Pair) (float64, error) {
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var fundings = swap.ledger.fundings[marketKey(TRADE_TYPE_SWAP, pair, "")]
+	if len(fundings) == 0 {
+		return 0, nil
+	}
+	return fundings[len(fundings)-1].rate, nil
+}
+
+// SettleFunding settle the funding fee of the positions at the last price, the long pay the short when the rate is positive.
+func (swap *Swap) SettleFunding(pair Pair, rate float64) error {
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var m, err = swap.findMarket(TRADE_TYPE_SWAP, pair, "")
+	if err != nil {
+		return err
+	}
+	swap.ledger.settleFunding(m, rate)
+	return nil
+}
+
+// GetAccount return the account of USDT, if there is no USDT, the first margin currency.
+func (swap *Swap) GetAccount() (*SwapAccount, []byte, error) {
+	swap.delay()
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var account = swap.ledger.subAccount(swap.ledger.defaultCurrency())
+	return account, raw(account), nil
+}
+
+func (swap *Swap) PlaceOrder(order *SwapOrder) ([]byte, error) {
+	swap.delay()
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var m, err = swap.findMarket(TRADE_TYPE_SWAP, order.Pair, "")
+	if err != nil {
+		return nil, err
+	}
+	var o = &simOrder{
+		id:         swap.nextOrderId(),
+		cid:        order.Cid,
+		market:     m,
+		price:      order.Price,
+		amount:     order.Amount,
+		placeType:  order.PlaceType,
+		futureType: order.Type,
+		leverage:   order.LeverRate,
+	}
+	if err := swap.ledger.place(m, o); err != nil {
+		return nil, err
+	}
+	swap.toOrder(o, order)
+	return raw(order), nil
+}
+
+func (swap *Swap) CancelOrder(order *SwapOrder) ([]byte, error) {
+	swap.delay()
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var o, err = swap.findOrder(TRADE_TYPE_SWAP, order.OrderId, order.Cid)
+	if err != nil {
+		return nil, err
+	}
+	if err := swap.cancel(o); err != nil {
+		return nil, err
+	}
+	swap.toOrder(o, order)
+	return raw(order), nil
+}
+
+func (swap *Swap) GetOrder(order *SwapOrder) ([]byte, error) {
+	swap.delay()
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var o, err = swap.findOrder(TRADE_TYPE_SWAP, order.OrderId, order.Cid)
+	if err != nil {
+		return nil, err
+	}
+	swap.toOrder(o, order)
+	return raw(order), nil
+}
+
+// GetOrders return all the orders of the pair.
+func (swap *Swap) GetOrders(pair Pair) ([]*SwapOrder, []byte, error) {
+	swap.delay()
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var orders = make([]*SwapOrder, 0)
+	for _, o := range swap.sortedOrders(TRADE_TYPE_SWAP, pair) {
+		var order = &SwapOrder{}
+		swap.toOrder(o, order)
+		orders = append(orders, order)
+	}
+	return orders, raw(orders), nil
+}
+
+func (swap *Swap) GetUnFinishOrders(pair Pair) ([]*SwapOrder, []byte, error) {
+	swap.delay()
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var orders = make([]*SwapOrder, 0)
+	for _, o := range swap.sortedOrders(TRADE_TYPE_SWAP, pair) {
+		if !o.isFinal() {
+			var order = &SwapOrder{}
+			swap.toOrder(o, order)
+			orders = append(orders, order)
+		}
+	}
+	return orders, raw(orders), nil
+}
+
+func (swap *Swap) GetPosition(pair Pair, openType FutureType) (*SwapPosition, []byte, error) {
+	swap.delay()
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	if openType != OPEN_LONG && openType != OPEN_SHORT {
+		return nil, nil, errors.New("The openType must be OPEN_LONG or OPEN_SHORT. ")
+	}
+	var m, err = swap.findMarket(TRADE_TYPE_SWAP, pair, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var position = swap.ledger.snapshot(m, swap.ledger.position(m, openType))
+	return position, raw(position), nil
+}
+
+func (swap *Swap) GetAccountFlow() ([]*SwapAccountItem, []byte, error) {
+	swap.delay()
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var items = make([]*SwapAccountItem, 0)
+	for _, f := range swap.ledger.flows {
+		items = append(items, swap.toItem(f))
+	}
+	return items, raw(items), nil
+}
+
+func (swap *Swap) GetPairFlow(pair Pair) ([]*SwapAccountItem, []byte, error) {
+	swap.delay()
+	swap.locker.Lock()
+	defer swap.locker.Unlock()
+
+	var items = make([]*SwapAccountItem, 0)
+	for _, f := range swap.ledger.flows {
+		if f.pair.Eq(pair) {
+			items = append(items, swap.toItem(f))
+		}
+	}
+	return items, raw(items), nil
+}
+
+func (swap *Swap) KeepAlive() {}
+
+func (swap *Swap) toItem(f *flow) *SwapAccountItem {
+	return &SwapAccountItem{
+		Pair:           f.pair,
+		Exchange:       SIM,
+		Subject:        f.subject,
+		Id:             f.id,
+		SettleMode:     SETTLE_MODE_COUNTER,
+		SettleCurrency: f.currency,
+		Amount:         f.amount,
+		Timestamp:      f.timestamp,
+		DateTime:       swap.date(f.timestamp),
+		Info:           f.info,
+	}
+}
+
+func (swap *Swap) toOrder(o *simOrder, order *SwapOrder) {
+	order.OrderId = o.id
+	order.Cid = o.cid
+	order.Pair = o.market.pair
+	order.Exchange = SIM
+	order.Price = o.price
+	order.Amount = o.amount
+	order.AvgPrice = o.avgPrice
+	order.DealAmount = o.deal
+	order.Fee = o.fee
+	order.Status = o.status
+	order.PlaceType = o.placeType
+	order.Type = o.futureType
+	order.MarginType = ISOLATED
+	order.LeverRate = o.leverage
+	order.PlaceTimestamp = o.placeTS
+	order.PlaceDatetime = swap.date(o.placeTS)
+	if o.dealTS > 0 {
+		order.DealTimestamp = o.dealTS
+		order.DealDatetime = swap.date(o.dealTS)
+	}
+}
@@ -0,0 +1,135 @@
+package sim
+
+import (
+	"testing"
+
+	. "github.com/deforceHK/goghostex"
+)
+
+// go test -v ./sim/... -count=1 -run=TestSwap_Position
+func TestSwap_Position(t *testing.T) {
+	var s = newTestSim(&Option{
+		TakerFee: 0.001,
+		Leverage: 10,
+		Balances: map[string]float64{"USDT": 100},
+	})
+	var _ SwapRestAPI = s.Swap
+
+	// the margin is 101*2/10, the fee reserve is 101*2*0.001
+	var open = &SwapOrder{Pair: BTC_USDT, Type: OPEN_LONG, PlaceType: IOC, Price: 101, Amount: 2}
+	if _, err := s.Swap.PlaceOrder(open); err != nil {
+		t.Fatal(err)
+	}
+	if open.Status != ORDER_CANCEL || !floatEq(open.DealAmount, 1) {
+		t.Error("The ioc open is wrong: ", open)
+	}
+
+	var position, _, _ = s.Swap.GetPosition(BTC_USDT, OPEN_LONG)
+	if !floatEq(position.Amount, 1) || !floatEq(position.Price, 101) || !floatEq(position.MarginAmount, 10.1) {
+		t.Error("The position is wrong: ", position)
+	}
+	var account, _, _ = s.Swap.GetAccount()
+	if !floatEq(account.MarginPosition, 10.1) || !floatEq(account.MarginOpen, 0) ||
+		!floatEq(account.BalanceTotal, 100-0.101) {
+		t.Error("The account after open is wrong: ", account)
+	}
+
+	// the price go up, close the long with the profit.
+	s.UpdateDepth(TRADE_TYPE_SWAP, BTC_USDT, "", SyntheticDepth(BTC_USDT, 111, 1, 1, 5, 1700000060000))
+	account, _, _ = s.Swap.GetAccount()
+	if !floatEq(account.ProfitUnreal, 10) {
+		t.Error("The unreal profit is wrong: ", account.ProfitUnreal)
+	}
+
+	if _, err := s.Swap.PlaceOrder(&SwapOrder{Pair: BTC_USDT, Type: LIQUIDATE_LONG, Price: 110, Amount: 2}); err != ErrInsufficientBalance {
+		t.Error("The position is not enough to close: ", err)
+	}
+	var close = &SwapOrder{Pair: BTC_USDT, Type: LIQUIDATE_LONG, PlaceType: MARKET, Amount: 1}
+	if _, err := s.Swap.PlaceOrder(close); err != nil {
+		t.Fatal(err)
+	}
+	if close.Status != ORDER_FINISH || !floatEq(close.AvgPrice, 110) {
+		t.Error("The close order is wrong: ", close)
+	}
+
+	account, _, _ = s.Swap.GetAccount()
+	if !floatEq(account.ProfitReal, 9) || !floatEq(account.BalanceTotal, 100-0.101+9-0.11) ||
+		!floatEq(account.Margin, 0) || len(account.Positions) != 0 {
+		t.Error("The account after close is wrong: ", account)
+	}
+
+	var flows, _, _ = s.Swap.GetPairFlow(BTC_USDT)
+	var subjects = map[string]float64{}
+	for _, flow := range flows {
+		subjects[flow.Subject] += flow.Amount
+	}
+	if !floatEq(subjects[SUBJECT_SETTLE], 9) || !floatEq(subjects[SUBJECT_COMMISSION], -0.211) {
+		t.Error("The flows are wrong: ", subjects)
+	}
+}
+
+// go test -v ./sim/... -count=1 -run=TestSwap_Funding
+func TestSwap_Funding(t *testing.T) {
+	var s = newTestSim(&Option{Balances: map[string]float64{"USDT": 1000}})
+
+	var open = &SwapOrder{Pair: BTC_USDT, Type: OPEN_SHORT, Price: 99, Amount: 1, LeverRate: 5}
+	if _, err := s.Swap.PlaceOrder(open); err != nil {
+		t.Fatal(err)
+	}
+	var position, _, _ = s.Swap.GetPosition(BTC_USDT, OPEN_SHORT)
+	if position.Leverage != 5 || !floatEq(position.LiquidatePrice, 99*1.2) {
+		t.Error("The short position is wrong: ", position)
+	}
+
+	// the funding fee is settled at the last price 100.
+	if err := s.Swap.SettleFunding(BTC_USDT, 0.001); err != nil {
+		t.Fatal(err)
+	}
+	var rate, _ = s.Swap.GetFundingFee(BTC_USDT)
+	var fees, _, _ = s.Swap.GetFundingFees(BTC_USDT)
+	if rate != 0.001 || len(fees) != 1 {
+		t.Error("The funding fees are wrong: ", rate, fees)
+	}
+	var account, _, _ = s.Swap.GetAccount()
+	if !floatEq(account.BalanceTotal, 1000+0.1) {
+		t.Error("The short should receive the funding fee: ", account.BalanceTotal)
+	}
+}
+
+// go test -v ./sim/... -count=1 -run=TestSwap_Liquidate
+func TestSwap_Liquidate(t *testing.T) {
+	var s = newTestSim(&Option{Leverage: 10, Balances: map[string]float64{"USDT": 1000}})
+
+	var open = &SwapOrder{Pair: BTC_USDT, Type: OPEN_LONG, PlaceType: MARKET, Amount: 1}
+	if _, err := s.Swap.PlaceOrder(open); err != nil {
+		t.Fatal(err)
+	}
+	var close = &SwapOrder{Pair: BTC_USDT, Type: LIQUIDATE_LONG, Price: 120, Amount: 1}
+	if _, err := s.Swap.PlaceOrder(close); err != nil {
+		t.Fatal(err)
+	}
+	var position, _, _ = s.Swap.GetPosition(BTC_USDT, OPEN_LONG)
+	if !floatEq(position.LiquidatePrice, 101*0.9) {
+		t.Fatal("The liquidate price is wrong: ", position)
+	}
+
+	// the low of the kline touches the liquidate price, the margin 10.1 is lost.
+	s.UpdateKline(TRADE_TYPE_SWAP, BTC_USDT, "", &Kline{
+		Pair: BTC_USDT, Timestamp: 1700000060000, Open: 100, High: 100, Low: 90, Close: 95, Vol: 10,
+	})
+	position, _, _ = s.Swap.GetPosition(BTC_USDT, OPEN_LONG)
+	if position.Amount != 0 {
+		t.Error("The position should be liquidated: ", position)
+	}
+	if _, err := s.Swap.GetOrder(close); err != nil {
+		t.Fatal(err)
+	}
+	if close.Status != ORDER_CANCEL {
+		t.Error("The close order should be canceled: ", close)
+	}
+	var account, _, _ = s.Swap.GetAccount()
+	if !floatEq(account.ProfitReal, -10.1) || !floatEq(account.BalanceTotal, 1000-10.1) ||
+		!floatEq(account.Margin, 0) || len(account.Positions) != 0 {
+		t.Error("The account after liquidation is wrong: ", account)
+	}
+}